@@ -5,6 +5,7 @@ import (
 	"log"
 	"time"
 
+	"scorehub/internal/http/handlers"
 	"scorehub/internal/realtime"
 	"scorehub/internal/store"
 )
@@ -15,7 +16,7 @@ const (
 	autoEndRunTimeout  = 15 * time.Second
 )
 
-func startAutoEndInactiveScorebooksJob(ctx context.Context, st *store.Store, hub *realtime.Hub) {
+func startAutoEndInactiveScorebooksJob(ctx context.Context, st store.ScorebookRepo, hub *realtime.Hub) {
 	go func() {
 		ticker := time.NewTicker(autoEndCheckEvery)
 		defer ticker.Stop()
@@ -31,51 +32,36 @@ func startAutoEndInactiveScorebooksJob(ctx context.Context, st *store.Store, hub
 			}
 
 			for _, sb := range ended {
-				var champion any
-				var runnerUp any
-				var third any
-				if tops, err := st.GetTopWinners(runCtx, sb.ID); err == nil {
-					if len(tops) >= 1 {
-						champion = map[string]any{
-							"memberId":  tops[0].ID,
-							"nickname":  tops[0].Nickname,
-							"avatarUrl": tops[0].AvatarURL,
-							"score":     tops[0].Score,
-						}
-					}
-					if len(tops) >= 2 {
-						runnerUp = map[string]any{
-							"memberId":  tops[1].ID,
-							"nickname":  tops[1].Nickname,
-							"avatarUrl": tops[1].AvatarURL,
-							"score":     tops[1].Score,
-						}
-					}
-					if len(tops) >= 3 {
-						third = map[string]any{
-							"memberId":  tops[2].ID,
-							"nickname":  tops[2].Nickname,
-							"avatarUrl": tops[2].AvatarURL,
-							"score":     tops[2].Score,
-						}
+				tops, err := st.GetTopWinners(runCtx, sb.ID)
+				if err != nil {
+					log.Printf("auto end scorebook winners failed: scorebook_id=%s err=%v", sb.ID, err)
+				}
+
+				settlement := []any{}
+				if transfers, err := st.EnsureSettlement(runCtx, sb.ID); err == nil {
+					for _, t := range transfers {
+						settlement = append(settlement, handlers.SettlementTransferDTO(t))
 					}
 				} else {
-					log.Printf("auto end scorebook winners failed: scorebook_id=%s err=%v", sb.ID, err)
+					log.Printf("auto end scorebook settlement failed: scorebook_id=%s err=%v", sb.ID, err)
 				}
-				winners := map[string]any{
-					"champion": champion,
-					"runnerUp": runnerUp,
-					"third":    third,
+
+				// 与手动结束的 scorebook.ended 相同的金额汇总；掌柜一定是成员，用来读取单价
+				money, err := handlers.EndedMoney(runCtx, st, sb.ID, sb.CreatedByUserID)
+				if err != nil {
+					log.Printf("auto end scorebook money failed: scorebook_id=%s err=%v", sb.ID, err)
 				}
 
 				hub.Broadcast(sb.ID, map[string]any{
 					"type": "scorebook.ended",
 					"data": map[string]any{
-						"id":        sb.ID,
-						"endedAt":   sb.EndedAt,
-						"updatedAt": sb.UpdatedAt,
-						"winners":   winners,
-						"autoEnded": true,
+						"id":         sb.ID,
+						"endedAt":    sb.EndedAt,
+						"updatedAt":  sb.UpdatedAt,
+						"winners":    handlers.WinnersDTO(tops),
+						"settlement": settlement,
+						"money":      money,
+						"autoEnded":  true,
 					},
 				})
 			}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"flag"
 	"io/fs"
 	"log"
 	"mime"
@@ -16,12 +17,20 @@ import (
 	appconfig "scorehub/internal/config"
 	"scorehub/internal/http/handlers"
 	"scorehub/internal/http/middleware"
+	"scorehub/internal/migrate"
 	"scorehub/internal/realtime"
 	"scorehub/internal/store"
+	"scorehub/sql/migrations"
 )
 
 func main() {
+	runMigrations := flag.Bool("migrate", false, "apply pending database migrations before starting")
+	flag.Parse()
+
 	cfg := appconfig.Load()
+	if err := cfg.Validate(); err != nil {
+		log.Fatalf("config: %v", err)
+	}
 
 	ctx := context.Background()
 	st, err := store.New(ctx, cfg.DBDSN)
@@ -30,7 +39,32 @@ func main() {
 	}
 	defer st.Close()
 
+	migrator, err := migrate.New(st.Pool(), migrations.FS)
+	if err != nil {
+		log.Fatalf("load migrations: %v", err)
+	}
+	if *runMigrations {
+		applied, err := migrator.Up(ctx)
+		if err != nil {
+			log.Fatalf("migrate up: %v", err)
+		}
+		for _, m := range applied {
+			log.Printf("migration applied: %04d_%s", m.Version, m.Name)
+		}
+	}
+	if err := migrator.Check(ctx); err != nil {
+		log.Fatalf("schema check: %v (run `go run ./cmd/migrate up` or start with --migrate)", err)
+	}
+
 	hub := realtime.NewHub()
+	hub.UseEventLog(st)
+	switch cfg.RealtimeBackend {
+	case "postgres":
+		hub.Attach(ctx, realtime.NewPGBackend(st.Pool()))
+	case "local", "":
+	default:
+		log.Printf("unknown SCOREHUB_REALTIME_BACKEND %q, falling back to local", cfg.RealtimeBackend)
+	}
 	startAutoEndInactiveScorebooksJob(ctx, st, hub)
 
 	h := server.Default(server.WithHostPorts(cfg.Addr))
@@ -42,72 +76,20 @@ func main() {
 	}))
 	h.GET("/static/*filepath", staticAssetsHandler())
 
-	authHandlers := handlers.NewAuthHandlers(cfg, st)
-	meHandlers := handlers.NewMeHandlers(st)
-	scorebookHandlers := handlers.NewScorebookHandlers(cfg, st, hub)
-	ledgerHandlers := handlers.NewLedgerHandlers(cfg, st)
-	birthdayHandlers := handlers.NewBirthdayHandlers(st)
-	depositHandlers := handlers.NewDepositHandlers(st)
-	locationHandlers := handlers.NewLocationHandlers(cfg)
-
-	api := h.Group("/api/v1")
-	auth := api.Group("/auth")
-	auth.POST("/dev_login", authHandlers.DevLogin)
-	auth.POST("/wechat_login", authHandlers.WechatLogin)
-
-	authed := api.Group("", middleware.AuthRequired(cfg, st))
-	authed.GET("/me", meHandlers.GetMe)
-	authed.PATCH("/me", meHandlers.UpdateMe)
-	authed.POST("/scorebooks", scorebookHandlers.CreateScorebook)
-	authed.GET("/scorebooks", scorebookHandlers.ListMyScorebooks)
-	authed.GET("/scorebooks/:id", scorebookHandlers.GetScorebookDetail)
-	authed.PATCH("/scorebooks/:id", scorebookHandlers.UpdateScorebook)
-	authed.DELETE("/scorebooks/:id", scorebookHandlers.DeleteScorebook)
-	authed.POST("/scorebooks/:id/end", scorebookHandlers.EndScorebook)
-	authed.POST("/scorebooks/:id/join", scorebookHandlers.JoinScorebook)
-	authed.PATCH("/scorebooks/:id/members/me", scorebookHandlers.UpdateMyProfile)
-	authed.GET("/scorebooks/:id/invite_qrcode", scorebookHandlers.GetInviteQRCode)
-	authed.POST("/scorebooks/:id/records", scorebookHandlers.CreateRecord)
-	authed.GET("/scorebooks/:id/records", scorebookHandlers.ListRecords)
-	authed.POST("/invites/:code/join", scorebookHandlers.JoinByInviteCode)
-	authed.POST("/ledgers", ledgerHandlers.CreateLedger)
-	authed.GET("/ledgers", ledgerHandlers.ListLedgers)
-	authed.PATCH("/ledgers/:id", ledgerHandlers.UpdateLedger)
-	authed.DELETE("/ledgers/:id", ledgerHandlers.DeleteLedger)
-	authed.GET("/ledgers/:id/invite_qrcode", ledgerHandlers.GetInviteQRCode)
-	authed.POST("/ledgers/:id/bind", ledgerHandlers.BindLedgerMember)
-	authed.POST("/ledgers/:id/members", ledgerHandlers.AddLedgerMember)
-	authed.PATCH("/ledgers/:id/members/:memberId", ledgerHandlers.UpdateLedgerMember)
-	authed.POST("/ledgers/:id/records", ledgerHandlers.AddLedgerRecord)
-	authed.POST("/ledgers/:id/end", ledgerHandlers.EndLedger)
-	authed.POST("/birthdays", birthdayHandlers.CreateBirthday)
-	authed.GET("/birthdays", birthdayHandlers.ListBirthdays)
-	authed.GET("/birthdays/:id", birthdayHandlers.GetBirthday)
-	authed.PATCH("/birthdays/:id", birthdayHandlers.UpdateBirthday)
-	authed.DELETE("/birthdays/:id", birthdayHandlers.DeleteBirthday)
-	authed.POST("/deposits/accounts", depositHandlers.CreateDepositAccount)
-	authed.GET("/deposits/accounts", depositHandlers.ListDepositAccounts)
-	authed.GET("/deposits/accounts/:id", depositHandlers.GetDepositAccount)
-	authed.PATCH("/deposits/accounts/:id", depositHandlers.UpdateDepositAccount)
-	authed.DELETE("/deposits/accounts/:id", depositHandlers.DeleteDepositAccount)
-	authed.POST("/deposits/accounts/:id/records", depositHandlers.CreateDepositRecord)
-	authed.GET("/deposits/accounts/:id/records", depositHandlers.ListDepositAccountRecords)
-	authed.GET("/deposits/records", depositHandlers.ListDepositRecords)
-	authed.GET("/deposits/records/:id", depositHandlers.GetDepositRecord)
-	authed.PATCH("/deposits/records/:id", depositHandlers.UpdateDepositRecord)
-	authed.DELETE("/deposits/records/:id", depositHandlers.DeleteDepositRecord)
-	authed.GET("/deposits/tags", depositHandlers.ListDepositTags)
-	authed.GET("/deposits/stats", depositHandlers.GetDepositStats)
-
-	// Public: allow location & invite info lookup without login.
-	api.GET("/location/reverse_geocode", locationHandlers.ReverseGeocode)
-	api.GET("/invites/:code", scorebookHandlers.GetInviteInfo)
-	api.GET("/ledgers/:id", ledgerHandlers.GetLedgerDetail)
-
-	h.GET("/ws/scorebooks/:id", scorebookHandlers.ScorebookWS)
+	handlers.RegisterRoutes(h, cfg, handlers.Repos{
+		Users:      st,
+		Scorebooks: st,
+		Ledgers:    st,
+		Templates:  st,
+		Birthdays:  st,
+		Deposits:   st,
+		People:     st,
+	}, hub)
 
 	log.Printf("scorehub api listening on %s", cfg.Addr)
 	h.Spin()
+	// 把已提交的实时事件写完再关闭数据库连接
+	hub.Wait()
 }
 
 func staticAssetsHandler() app.HandlerFunc {
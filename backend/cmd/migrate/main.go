@@ -0,0 +1,100 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+
+	appconfig "scorehub/internal/config"
+	"scorehub/internal/migrate"
+	"scorehub/internal/store"
+	"scorehub/sql/migrations"
+)
+
+const usage = `usage: migrate <command>
+
+commands:
+  up          apply all pending migrations
+  status      show applied / pending migrations
+  down [n]    roll back the last n applied migrations (default 1)
+`
+
+func main() {
+	flag.Usage = func() { fmt.Fprint(os.Stderr, usage) }
+	flag.Parse()
+	if flag.NArg() < 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	cfg := appconfig.Load()
+
+	ctx := context.Background()
+	st, err := store.New(ctx, cfg.DBDSN)
+	if err != nil {
+		log.Fatalf("init db: %v", err)
+	}
+	defer st.Close()
+
+	runner, err := migrate.New(st.Pool(), migrations.FS)
+	if err != nil {
+		log.Fatalf("load migrations: %v", err)
+	}
+
+	switch cmd := flag.Arg(0); cmd {
+	case "up":
+		applied, err := runner.Up(ctx)
+		if err != nil {
+			log.Fatalf("migrate up: %v", err)
+		}
+		if len(applied) == 0 {
+			fmt.Println("already up to date")
+		}
+		for _, m := range applied {
+			fmt.Printf("applied  %04d_%s\n", m.Version, m.Name)
+		}
+	case "down":
+		steps := 1
+		if flag.NArg() > 1 {
+			n, err := strconv.Atoi(flag.Arg(1))
+			if err != nil || n <= 0 {
+				log.Fatalf("invalid step count %q", flag.Arg(1))
+			}
+			steps = n
+		}
+		reverted, err := runner.Down(ctx, steps)
+		if err != nil {
+			log.Fatalf("migrate down: %v", err)
+		}
+		if len(reverted) == 0 {
+			fmt.Println("nothing to roll back")
+		}
+		for _, m := range reverted {
+			fmt.Printf("reverted %04d_%s\n", m.Version, m.Name)
+		}
+	case "status":
+		statuses, err := runner.Status(ctx)
+		if err != nil {
+			log.Fatalf("migrate status: %v", err)
+		}
+		for _, s := range statuses {
+			state := "pending"
+			appliedAt := ""
+			if s.Applied {
+				state = "applied"
+				appliedAt = s.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			if s.ChecksumMismatch {
+				state = "modified"
+			}
+			fmt.Printf("%-8s %04d_%-24s %s\n", state, s.Version, s.Name, appliedAt)
+		}
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n", cmd)
+		flag.Usage()
+		os.Exit(2)
+	}
+}
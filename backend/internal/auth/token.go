@@ -2,9 +2,11 @@ package auth
 
 import (
 	"crypto/hmac"
+	"crypto/rand"
 	"crypto/sha256"
 	"crypto/subtle"
 	"encoding/base64"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -13,65 +15,149 @@ import (
 )
 
 type tokenPayload struct {
-	UserID int64 `json:"uid"`
-	Exp    int64 `json:"exp"`
+	UserID    int64  `json:"uid"`
+	SessionID string `json:"sid,omitempty"`
+	Exp       int64  `json:"exp"`
 }
 
-func SignToken(secret []byte, userID int64, expiresIn time.Duration) (string, error) {
-	if len(secret) == 0 {
+// Claims 是访问令牌中携带的身份信息。
+type Claims struct {
+	UserID    int64
+	SessionID string
+}
+
+// Key 是一把令牌签名密钥。ID 写入令牌，校验时据此选择密钥，
+// 因此轮换时旧密钥可以继续用于校验，已签发的令牌不会立即失效。
+type Key struct {
+	ID     string
+	Secret []byte
+}
+
+// ValidKeyID 限制 kid 为 1-32 位字母、数字、下划线或连字符（不能含令牌分隔符 "."）。
+func ValidKeyID(id string) bool {
+	if id == "" || len(id) > 32 {
+		return false
+	}
+	for _, r := range id {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '_', r == '-':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// SignToken 用 key 签发绑定到登录会话的短期访问令牌，格式为 sh2.<kid>.<payload>.<sig>。
+func SignToken(key Key, userID int64, sessionID string, expiresIn time.Duration) (string, error) {
+	if len(key.Secret) == 0 {
 		return "", errors.New("empty token secret")
 	}
+	if !ValidKeyID(key.ID) {
+		return "", errors.New("invalid token key id")
+	}
 	p := tokenPayload{
-		UserID: userID,
-		Exp:    time.Now().Add(expiresIn).Unix(),
+		UserID:    userID,
+		SessionID: sessionID,
+		Exp:       time.Now().Add(expiresIn).Unix(),
 	}
 	raw, err := json.Marshal(p)
 	if err != nil {
 		return "", err
 	}
 
-	payload := base64.RawURLEncoding.EncodeToString(raw)
-	sig := sign(secret, payload)
-	return "sh1." + payload + "." + sig, nil
+	signed := "sh2." + key.ID + "." + base64.RawURLEncoding.EncodeToString(raw)
+	return signed + "." + sign(key.Secret, signed), nil
 }
 
-func ParseToken(secret []byte, token string) (int64, error) {
-	if len(secret) == 0 {
-		return 0, errors.New("empty token secret")
+// ParseToken 用 keys 中与令牌 kid 对应的密钥校验访问令牌。
+// 不带 kid 的旧格式 sh1 令牌依次尝试所有密钥；未绑定会话的令牌视为无效。
+func ParseToken(keys []Key, token string) (Claims, error) {
+	if len(keys) == 0 {
+		return Claims{}, errors.New("no token keys")
 	}
 	token = strings.TrimSpace(token)
 	if token == "" {
-		return 0, errors.New("empty token")
+		return Claims{}, errors.New("empty token")
 	}
 
+	var payloadPart string
 	parts := strings.Split(token, ".")
-	if len(parts) != 3 || parts[0] != "sh1" {
-		return 0, errors.New("invalid token format")
-	}
-
-	payloadPart := parts[1]
-	sigPart := parts[2]
-	wantSig := sign(secret, payloadPart)
-	if subtle.ConstantTimeCompare([]byte(sigPart), []byte(wantSig)) != 1 {
-		return 0, errors.New("invalid token signature")
+	switch {
+	case len(parts) == 4 && parts[0] == "sh2":
+		key, ok := findKey(keys, parts[1])
+		if !ok {
+			return Claims{}, errors.New("unknown token key")
+		}
+		if !validSig(key.Secret, strings.Join(parts[:3], "."), parts[3]) {
+			return Claims{}, errors.New("invalid token signature")
+		}
+		payloadPart = parts[2]
+	case len(parts) == 3 && parts[0] == "sh1":
+		matched := false
+		for _, key := range keys {
+			if validSig(key.Secret, parts[1], parts[2]) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return Claims{}, errors.New("invalid token signature")
+		}
+		payloadPart = parts[1]
+	default:
+		return Claims{}, errors.New("invalid token format")
 	}
 
 	payloadRaw, err := base64.RawURLEncoding.DecodeString(payloadPart)
 	if err != nil {
-		return 0, fmt.Errorf("decode payload: %w", err)
+		return Claims{}, fmt.Errorf("decode payload: %w", err)
 	}
 
 	var p tokenPayload
 	if err := json.Unmarshal(payloadRaw, &p); err != nil {
-		return 0, fmt.Errorf("parse payload: %w", err)
+		return Claims{}, fmt.Errorf("parse payload: %w", err)
 	}
 	if p.UserID <= 0 {
-		return 0, errors.New("invalid token user")
+		return Claims{}, errors.New("invalid token user")
+	}
+	if p.SessionID == "" {
+		return Claims{}, errors.New("token has no session")
 	}
 	if p.Exp <= 0 || time.Now().Unix() > p.Exp {
-		return 0, errors.New("token expired")
+		return Claims{}, errors.New("token expired")
 	}
-	return p.UserID, nil
+	return Claims{UserID: p.UserID, SessionID: p.SessionID}, nil
+}
+
+func findKey(keys []Key, id string) (Key, bool) {
+	for _, k := range keys {
+		if k.ID == id && len(k.Secret) > 0 {
+			return k, true
+		}
+	}
+	return Key{}, false
+}
+
+func validSig(secret []byte, signed, sig string) bool {
+	if len(secret) == 0 {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(sig), []byte(sign(secret, signed))) == 1
+}
+
+// NewRefreshToken 生成随机的刷新令牌；服务端只保存其哈希（HashRefreshToken）。
+func NewRefreshToken() (string, error) {
+	var b [32]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", err
+	}
+	return "shr." + base64.RawURLEncoding.EncodeToString(b[:]), nil
+}
+
+func HashRefreshToken(token string) string {
+	sum := sha256.Sum256([]byte(strings.TrimSpace(token)))
+	return hex.EncodeToString(sum[:])
 }
 
 func sign(secret []byte, payload string) string {
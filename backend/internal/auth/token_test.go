@@ -0,0 +1,60 @@
+package auth
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTokenKeyRotation(t *testing.T) {
+	old := Key{ID: "2025", Secret: []byte("old-secret")}
+	cur := Key{ID: "2026", Secret: []byte("new-secret")}
+
+	token, err := SignToken(old, 7, "sess", time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(token, "sh2.2025.") {
+		t.Fatalf("token = %q, want sh2.<kid> prefix", token)
+	}
+
+	// 轮换后旧密钥仍在校验列表中，已签发的令牌继续有效
+	claims, err := ParseToken([]Key{cur, old}, token)
+	if err != nil || claims.UserID != 7 || claims.SessionID != "sess" {
+		t.Fatalf("parse after rotation = %+v, %v", claims, err)
+	}
+	// 旧密钥移除后失效
+	if _, err := ParseToken([]Key{cur}, token); err == nil {
+		t.Fatal("token signed by a retired key accepted")
+	}
+	// 篡改 kid 不能换用其他密钥校验
+	forged := "sh2.2026." + strings.TrimPrefix(token, "sh2.2025.")
+	if _, err := ParseToken([]Key{cur, {ID: "2026x", Secret: old.Secret}}, forged); err == nil {
+		t.Fatal("token with swapped kid accepted")
+	}
+}
+
+func TestParseTokenLegacyFormat(t *testing.T) {
+	key := Key{ID: "default", Secret: []byte("secret")}
+	token, err := SignToken(key, 1, "sess", time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	parts := strings.Split(token, ".")
+	legacy := "sh1." + parts[2] + "." + sign(key.Secret, parts[2])
+
+	if _, err := ParseToken([]Key{{ID: "other", Secret: []byte("x")}, key}, legacy); err != nil {
+		t.Fatalf("legacy token: %v", err)
+	}
+	if _, err := ParseToken([]Key{{ID: "other", Secret: []byte("x")}}, legacy); err == nil {
+		t.Fatal("legacy token accepted with wrong key")
+	}
+}
+
+func TestSignTokenRejectsBadKey(t *testing.T) {
+	for _, k := range []Key{{ID: "a.b", Secret: []byte("s")}, {ID: "", Secret: []byte("s")}, {ID: "k"}} {
+		if _, err := SignToken(k, 1, "sess", time.Hour); err == nil {
+			t.Fatalf("SignToken(%+v) succeeded", k)
+		}
+	}
+}
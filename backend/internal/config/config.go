@@ -1,16 +1,43 @@
 package config
 
 import (
+	"errors"
+	"fmt"
 	"os"
 	"strconv"
+	"strings"
+	"time"
+
+	"scorehub/internal/auth"
 )
 
+// DefaultTokenSecret 是未配置 SCOREHUB_TOKEN_SECRET 时的占位密钥，仅允许在 DevAuth 下使用。
+const DefaultTokenSecret = "change-me"
+
+// defaultTokenKeyID 是单密钥配置（SCOREHUB_TOKEN_SECRET）对应的 kid。
+const defaultTokenKeyID = "default"
+
 type Config struct {
 	Addr        string
 	DBDSN       string
 	TokenSecret string
 	DevAuth     bool
 
+	// TokenKeys 来自 SCOREHUB_TOKEN_SECRETS（"kid:secret,kid:secret"），用于轮换签名密钥：
+	// TokenKeyID 指定签发使用的密钥，其余密钥仍可校验已签发的令牌。未配置时使用 TokenSecret。
+	TokenKeys  []auth.Key
+	TokenKeyID string
+
+	// AccessTokenTTL 访问令牌有效期；RefreshTokenTTL 刷新令牌（登录会话）有效期，每次刷新后顺延。
+	AccessTokenTTL  time.Duration
+	RefreshTokenTTL time.Duration
+
+	// RecordVoidWindow 记分记录创建后允许作废的时长；<= 0 表示不限制。
+	RecordVoidWindow time.Duration
+
+	// RealtimeBackend 实时推送后端：local（仅本进程，默认）或 postgres（LISTEN/NOTIFY 跨实例广播）。
+	RealtimeBackend string
+
 	WeChatAppID  string
 	WeChatSecret string
 
@@ -24,16 +51,85 @@ func Load() Config {
 	return Config{
 		Addr:          getenv("SCOREHUB_ADDR", ":8080"),
 		DBDSN:         getenv("SCOREHUB_DB_DSN", ""),
-		TokenSecret:   getenv("SCOREHUB_TOKEN_SECRET", "change-me"),
+		TokenSecret:   getenv("SCOREHUB_TOKEN_SECRET", DefaultTokenSecret),
 		DevAuth:       getenvBool("SCOREHUB_DEV_AUTH", false),
 		WeChatAppID:   getenv("SCOREHUB_WECHAT_APPID", ""),
 		WeChatSecret:  getenv("SCOREHUB_WECHAT_SECRET", ""),
 		TencentMapKey: getenv("SCOREHUB_TENCENT_MAP_KEY", ""),
 		AmapKey:       getenv("SCOREHUB_AMAP_KEY", ""),
 		BaiduMapAK:    getenv("SCOREHUB_BAIDU_MAP_AK", ""),
+
+		AccessTokenTTL:   getenvDuration("SCOREHUB_ACCESS_TOKEN_TTL", 2*time.Hour),
+		RefreshTokenTTL:  getenvDuration("SCOREHUB_REFRESH_TOKEN_TTL", 30*24*time.Hour),
+		RecordVoidWindow: getenvDuration("SCOREHUB_RECORD_VOID_WINDOW", 10*time.Minute),
+		RealtimeBackend:  getenv("SCOREHUB_REALTIME_BACKEND", "local"),
+
+		TokenKeys:  parseTokenKeys(os.Getenv("SCOREHUB_TOKEN_SECRETS")),
+		TokenKeyID: strings.TrimSpace(os.Getenv("SCOREHUB_TOKEN_KEY_ID")),
 	}
 }
 
+// Validate 检查启动所需的配置。非 DevAuth 环境拒绝使用默认密钥。
+func (c Config) Validate() error {
+	if c.TokenKeyID != "" && len(c.TokenKeys) == 0 {
+		return errors.New("SCOREHUB_TOKEN_KEY_ID requires SCOREHUB_TOKEN_SECRETS")
+	}
+	keys := c.VerifyKeys()
+	seen := make(map[string]bool, len(keys))
+	for i, k := range keys {
+		if !auth.ValidKeyID(k.ID) || len(k.Secret) == 0 {
+			return fmt.Errorf("SCOREHUB_TOKEN_SECRETS: entry %d must be kid:secret with kid of [A-Za-z0-9_-]{1,32}", i+1)
+		}
+		if seen[k.ID] {
+			return fmt.Errorf("SCOREHUB_TOKEN_SECRETS: duplicate kid %q", k.ID)
+		}
+		seen[k.ID] = true
+		if !c.DevAuth && string(k.Secret) == DefaultTokenSecret {
+			return errors.New("refusing to start with the default token secret: set SCOREHUB_TOKEN_SECRET or SCOREHUB_TOKEN_SECRETS (or enable SCOREHUB_DEV_AUTH for local development)")
+		}
+	}
+	if !seen[c.SigningKey().ID] {
+		return fmt.Errorf("SCOREHUB_TOKEN_KEY_ID %q is not in SCOREHUB_TOKEN_SECRETS", c.TokenKeyID)
+	}
+	return nil
+}
+
+// SigningKey 返回签发令牌使用的密钥：TokenKeyID 对应的密钥，未指定时为第一个。
+func (c Config) SigningKey() auth.Key {
+	keys := c.VerifyKeys()
+	if c.TokenKeyID == "" {
+		return keys[0]
+	}
+	for _, k := range keys {
+		if k.ID == c.TokenKeyID {
+			return k
+		}
+	}
+	return auth.Key{ID: c.TokenKeyID}
+}
+
+// VerifyKeys 返回校验令牌时接受的全部密钥。
+func (c Config) VerifyKeys() []auth.Key {
+	if len(c.TokenKeys) > 0 {
+		return c.TokenKeys
+	}
+	return []auth.Key{{ID: defaultTokenKeyID, Secret: []byte(c.TokenSecret)}}
+}
+
+// parseTokenKeys 解析 "kid:secret,kid:secret"；格式错误的条目保留为不完整的 Key，由 Validate 报错。
+func parseTokenKeys(v string) []auth.Key {
+	var keys []auth.Key
+	for _, item := range strings.Split(v, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		id, secret, _ := strings.Cut(item, ":")
+		keys = append(keys, auth.Key{ID: strings.TrimSpace(id), Secret: []byte(strings.TrimSpace(secret))})
+	}
+	return keys
+}
+
 func getenv(key, def string) string {
 	if v := os.Getenv(key); v != "" {
 		return v
@@ -52,3 +148,15 @@ func getenvBool(key string, def bool) bool {
 	}
 	return b
 }
+
+func getenvDuration(key string, def time.Duration) time.Duration {
+	v := os.Getenv(key)
+	if v == "" {
+		return def
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		return def
+	}
+	return d
+}
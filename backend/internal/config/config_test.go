@@ -0,0 +1,32 @@
+package config
+
+import "testing"
+
+func TestValidateTokenKeys(t *testing.T) {
+	cases := []struct {
+		name    string
+		cfg     Config
+		wantErr bool
+		signing string
+	}{
+		{"default secret in dev", Config{TokenSecret: DefaultTokenSecret, DevAuth: true}, false, "default"},
+		{"default secret in prod", Config{TokenSecret: DefaultTokenSecret}, true, ""},
+		{"single secret", Config{TokenSecret: "s3cret"}, false, "default"},
+		{"keys, first signs", Config{TokenKeys: parseTokenKeys("a:one, b:two")}, false, "a"},
+		{"keys with active id", Config{TokenKeys: parseTokenKeys("a:one,b:two"), TokenKeyID: "b"}, false, "b"},
+		{"active id without keys", Config{TokenSecret: "s3cret", TokenKeyID: "a"}, true, ""},
+		{"unknown active id", Config{TokenKeys: parseTokenKeys("a:one"), TokenKeyID: "c"}, true, ""},
+		{"malformed entry", Config{TokenKeys: parseTokenKeys("a:one,oops")}, true, ""},
+		{"duplicate kid", Config{TokenKeys: parseTokenKeys("a:one,a:two")}, true, ""},
+		{"default among keys", Config{TokenKeys: parseTokenKeys("a:one,b:" + DefaultTokenSecret)}, true, ""},
+	}
+	for _, tc := range cases {
+		err := tc.cfg.Validate()
+		if (err != nil) != tc.wantErr {
+			t.Fatalf("%s: Validate() = %v, wantErr %v", tc.name, err, tc.wantErr)
+		}
+		if err == nil && tc.cfg.SigningKey().ID != tc.signing {
+			t.Fatalf("%s: signing key = %q, want %q", tc.name, tc.cfg.SigningKey().ID, tc.signing)
+		}
+	}
+}
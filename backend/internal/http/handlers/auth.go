@@ -5,21 +5,21 @@ import (
 	"encoding/json"
 	"net/http"
 	"strings"
-	"time"
 
 	"github.com/cloudwego/hertz/pkg/app"
 
 	appauth "scorehub/internal/auth"
 	appconfig "scorehub/internal/config"
+	"scorehub/internal/http/middleware"
 	"scorehub/internal/store"
 )
 
 type AuthHandlers struct {
 	cfg appconfig.Config
-	st  *store.Store
+	st  store.UserRepo
 }
 
-func NewAuthHandlers(cfg appconfig.Config, st *store.Store) *AuthHandlers {
+func NewAuthHandlers(cfg appconfig.Config, st store.UserRepo) *AuthHandlers {
 	return &AuthHandlers{cfg: cfg, st: st}
 }
 
@@ -57,21 +57,7 @@ func (h *AuthHandlers) DevLogin(ctx context.Context, c *app.RequestContext) {
 		return
 	}
 
-	token, err := appauth.SignToken([]byte(h.cfg.TokenSecret), u.ID, 30*24*time.Hour)
-	if err != nil {
-		writeError(c, http.StatusInternalServerError, "internal", "sign token failed", err)
-		return
-	}
-
-	c.JSON(http.StatusOK, map[string]any{
-		"token": token,
-		"user": map[string]any{
-			"id":        u.ID,
-			"openid":    u.WeChatOpenID,
-			"nickname":  u.WeChatNickname,
-			"avatarUrl": u.WeChatAvatarURL,
-		},
-	})
+	h.startSession(ctx, c, u)
 }
 
 type wechatLoginRequest struct {
@@ -104,14 +90,34 @@ func (h *AuthHandlers) WechatLogin(ctx context.Context, c *app.RequestContext) {
 		return
 	}
 
-	token, err := appauth.SignToken([]byte(h.cfg.TokenSecret), u.ID, 30*24*time.Hour)
+	h.startSession(ctx, c, u)
+}
+
+// startSession 创建登录会话并返回访问令牌与刷新令牌。
+func (h *AuthHandlers) startSession(ctx context.Context, c *app.RequestContext, u store.User) {
+	refreshToken, err := appauth.NewRefreshToken()
+	if err != nil {
+		writeError(c, http.StatusInternalServerError, "internal", "generate token failed", err)
+		return
+	}
+	session, err := h.st.CreateSession(ctx, u.ID, appauth.HashRefreshToken(refreshToken),
+		truncate(string(c.UserAgent()), 256), c.ClientIP(), h.cfg.RefreshTokenTTL)
+	if err != nil {
+		writeError(c, http.StatusInternalServerError, "internal", "db error", err)
+		return
+	}
+
+	token, err := appauth.SignToken(h.cfg.SigningKey(), u.ID, session.ID, h.cfg.AccessTokenTTL)
 	if err != nil {
 		writeError(c, http.StatusInternalServerError, "internal", "sign token failed", err)
 		return
 	}
 
 	c.JSON(http.StatusOK, map[string]any{
-		"token": token,
+		"token":        token,
+		"expiresIn":    int64(h.cfg.AccessTokenTTL.Seconds()),
+		"refreshToken": refreshToken,
+		"sessionId":    session.ID,
 		"user": map[string]any{
 			"id":        u.ID,
 			"openid":    u.WeChatOpenID,
@@ -121,6 +127,89 @@ func (h *AuthHandlers) WechatLogin(ctx context.Context, c *app.RequestContext) {
 	})
 }
 
+type refreshRequest struct {
+	RefreshToken string `json:"refreshToken"`
+}
+
+// Refresh 用刷新令牌换取新的访问令牌，刷新令牌同时轮换（旧的立即失效）。
+func (h *AuthHandlers) Refresh(ctx context.Context, c *app.RequestContext) {
+	var req refreshRequest
+	body, err := c.Body()
+	if err != nil {
+		writeError(c, http.StatusBadRequest, "bad_request", "read body failed")
+		return
+	}
+	if err := json.Unmarshal(body, &req); err != nil {
+		writeError(c, http.StatusBadRequest, "bad_request", "invalid json")
+		return
+	}
+	req.RefreshToken = strings.TrimSpace(req.RefreshToken)
+	if req.RefreshToken == "" {
+		writeError(c, http.StatusBadRequest, "bad_request", "refreshToken required")
+		return
+	}
+
+	next, err := appauth.NewRefreshToken()
+	if err != nil {
+		writeError(c, http.StatusInternalServerError, "internal", "generate token failed", err)
+		return
+	}
+	session, err := h.st.RotateSession(ctx, appauth.HashRefreshToken(req.RefreshToken), appauth.HashRefreshToken(next), h.cfg.RefreshTokenTTL)
+	if err != nil {
+		switch err {
+		case store.ErrNotFound:
+			writeError(c, http.StatusUnauthorized, "unauthorized", "invalid refresh token")
+			return
+		case store.ErrSessionRevoked:
+			writeError(c, http.StatusUnauthorized, "unauthorized", "session revoked")
+			return
+		default:
+			writeError(c, http.StatusInternalServerError, "internal", "db error", err)
+			return
+		}
+	}
+
+	token, err := appauth.SignToken(h.cfg.SigningKey(), session.UserID, session.ID, h.cfg.AccessTokenTTL)
+	if err != nil {
+		writeError(c, http.StatusInternalServerError, "internal", "sign token failed", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, map[string]any{
+		"token":        token,
+		"expiresIn":    int64(h.cfg.AccessTokenTTL.Seconds()),
+		"refreshToken": next,
+		"sessionId":    session.ID,
+	})
+}
+
+// Logout 注销当前会话，其访问令牌与刷新令牌随即失效。
+func (h *AuthHandlers) Logout(ctx context.Context, c *app.RequestContext) {
+	uid, ok := middleware.UserID(c)
+	if !ok {
+		writeError(c, http.StatusUnauthorized, "unauthorized", "missing user")
+		return
+	}
+	sid, ok := middleware.SessionID(c)
+	if !ok {
+		c.JSON(http.StatusOK, map[string]any{"ok": true})
+		return
+	}
+
+	if err := h.st.RevokeSession(ctx, uid, sid); err != nil && err != store.ErrNotFound {
+		writeError(c, http.StatusInternalServerError, "internal", "db error", err)
+		return
+	}
+	c.JSON(http.StatusOK, map[string]any{"ok": true})
+}
+
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n]
+}
+
 func (h *AuthHandlers) exchangeWeChatCode(ctx context.Context, code string) (string, error) {
 	code = strings.TrimSpace(code)
 	if code == "" {
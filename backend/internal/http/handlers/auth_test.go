@@ -0,0 +1,131 @@
+package handlers_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/cloudwego/hertz/pkg/common/config"
+	"github.com/cloudwego/hertz/pkg/route"
+
+	"scorehub/internal/http/handlers"
+	"scorehub/internal/store/memstore"
+)
+
+func TestAuthRequired(t *testing.T) {
+	api := newTestAPI(t)
+
+	api.expectError(401, "unauthorized", "GET", "/api/v1/me", "", nil)
+	api.expectError(401, "unauthorized", "GET", "/api/v1/me", "not-a-token", nil)
+	api.expectError(400, "bad_request", "POST", "/api/v1/auth/dev_login", "", map[string]any{"openid": " "})
+}
+
+// sessionsDown 模拟查询会话时数据库不可用。
+type sessionsDown struct{ *memstore.Store }
+
+func (sessionsDown) SessionActive(ctx context.Context, userID int64, sessionID string) (bool, error) {
+	return false, errors.New("connection refused")
+}
+
+func TestAuthSessionCheckFailure(t *testing.T) {
+	api := newTestAPI(t)
+	token := api.login("alice", "Alice")
+	ledgerID := str(api.expect(200, "POST", "/api/v1/ledgers", token, map[string]any{"name": "礼金"}), "ledger", "id")
+
+	// 会话查不到时是服务端故障，不能当作令牌无效让客户端退出登录
+	down := *api
+	down.engine = route.NewEngine(config.NewOptions(nil))
+	handlers.RegisterRoutes(down.engine, api.cfg, handlers.Repos{
+		Users:      sessionsDown{api.st},
+		Scorebooks: api.st,
+		Ledgers:    api.st,
+		Templates:  api.st,
+		Birthdays:  api.st,
+		Deposits:   api.st,
+		People:     api.st,
+	}, api.hub)
+	down.expectError(500, "internal", "GET", "/api/v1/me", token, nil)
+	down.expectError(500, "internal", "GET", "/api/v1/invites/NOPE1234", token, nil)
+	down.expectError(500, "internal", "GET", "/api/v1/ledgers/"+ledgerID, token, nil)
+	down.expectError(401, "unauthorized", "GET", "/api/v1/me", "not-a-token", nil)
+	down.expectError(404, "not_found", "GET", "/api/v1/invites/NOPE1234", "not-a-token", nil)
+}
+
+func TestMe(t *testing.T) {
+	api := newTestAPI(t)
+	token := api.login("alice", "Alice")
+
+	resp := api.expect(200, "GET", "/api/v1/me", token, nil)
+	if got := str(resp, "user", "nickname"); got != "Alice" {
+		t.Fatalf("nickname = %q, want Alice", got)
+	}
+
+	resp = api.expect(200, "PATCH", "/api/v1/me", token, map[string]any{"nickname": "爱丽丝"})
+	if got := str(resp, "user", "nickname"); got != "爱丽丝" {
+		t.Fatalf("updated nickname = %q", got)
+	}
+
+	// 再次登录不应覆盖为空昵称
+	again := api.login("alice", "")
+	resp = api.expect(200, "GET", "/api/v1/me", again, nil)
+	if got := str(resp, "user", "nickname"); got != "爱丽丝" {
+		t.Fatalf("nickname after re-login = %q", got)
+	}
+}
+
+func TestRefreshAndSessions(t *testing.T) {
+	api := newTestAPI(t)
+
+	login := api.expect(200, "POST", "/api/v1/auth/dev_login", "", map[string]any{"openid": "alice", "nickname": "Alice"})
+	token, refresh := str(login, "token"), str(login, "refreshToken")
+	if refresh == "" || num(login, "expiresIn") <= 0 {
+		t.Fatalf("login: %v", login)
+	}
+
+	// 刷新后旧的刷新令牌失效，新的访问令牌可用
+	resp := api.expect(200, "POST", "/api/v1/auth/refresh", "", map[string]any{"refreshToken": refresh})
+	token2, refresh2 := str(resp, "token"), str(resp, "refreshToken")
+	if refresh2 == "" || refresh2 == refresh || str(resp, "sessionId") != str(login, "sessionId") {
+		t.Fatalf("refresh: %v", resp)
+	}
+	api.expect(200, "GET", "/api/v1/me", token2, nil)
+	api.expectError(400, "bad_request", "POST", "/api/v1/auth/refresh", "", map[string]any{"refreshToken": " "})
+	api.expectError(401, "unauthorized", "POST", "/api/v1/auth/refresh", "", map[string]any{"refreshToken": "shr.unknown"})
+
+	// 重放已轮换的刷新令牌视为泄露，整个会话被注销
+	api.expectError(401, "unauthorized", "POST", "/api/v1/auth/refresh", "", map[string]any{"refreshToken": refresh})
+	api.expectError(401, "unauthorized", "POST", "/api/v1/auth/refresh", "", map[string]any{"refreshToken": refresh2})
+	api.expectError(401, "unauthorized", "GET", "/api/v1/me", token, nil)
+	api.expectError(401, "unauthorized", "GET", "/api/v1/me", token2, nil)
+
+	phone := api.login("alice", "")
+	pad := api.expect(200, "POST", "/api/v1/auth/dev_login", "", map[string]any{"openid": "alice"})
+	resp = api.expect(200, "GET", "/api/v1/me/sessions", phone, nil)
+	items := list(resp, "items")
+	if len(items) != 2 {
+		t.Fatalf("sessions: %v", resp)
+	}
+	current := 0
+	for _, it := range items {
+		if boolean(it, "current") {
+			current++
+		}
+	}
+	if current != 1 {
+		t.Fatalf("current sessions: %v", resp)
+	}
+
+	// 远程注销另一台设备；其他用户无法注销
+	bob := api.login("bob", "Bob")
+	api.expectError(404, "not_found", "DELETE", "/api/v1/me/sessions/"+str(pad, "sessionId"), bob, nil)
+	api.expectError(404, "not_found", "DELETE", "/api/v1/me/sessions/not-a-uuid", phone, nil)
+	api.expect(200, "DELETE", "/api/v1/me/sessions/"+str(pad, "sessionId"), phone, nil)
+	api.expectError(401, "unauthorized", "GET", "/api/v1/me", str(pad, "token"), nil)
+	api.expectError(401, "unauthorized", "POST", "/api/v1/auth/refresh", "", map[string]any{"refreshToken": str(pad, "refreshToken")})
+
+	resp = api.expect(200, "POST", "/api/v1/auth/logout", phone, nil)
+	if !boolean(resp, "ok") {
+		t.Fatalf("logout: %v", resp)
+	}
+	api.expectError(401, "unauthorized", "GET", "/api/v1/me", phone, nil)
+}
@@ -16,10 +16,10 @@ import (
 )
 
 type BirthdayHandlers struct {
-	st *store.Store
+	st store.BirthdayRepo
 }
 
-func NewBirthdayHandlers(st *store.Store) *BirthdayHandlers {
+func NewBirthdayHandlers(st store.BirthdayRepo) *BirthdayHandlers {
 	return &BirthdayHandlers{st: st}
 }
 
@@ -0,0 +1,74 @@
+package handlers_test
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBirthdays(t *testing.T) {
+	api := newTestAPI(t)
+	api.st.Now = func() time.Time { return time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC) }
+	alice := api.login("alice", "Alice")
+	bob := api.login("bob", "Bob")
+
+	resp := api.expect(200, "POST", "/api/v1/birthdays", alice, map[string]any{
+		"name": "妈妈", "gender": "女", "solarBirthday": "1990-03-05", "primaryType": "solar",
+	})
+	mom := str(resp, "birthday", "id")
+	if num(resp, "birthday", "primaryMonth") != 3 || num(resp, "birthday", "primaryDay") != 5 {
+		t.Fatalf("create solar birthday: %v", resp)
+	}
+	api.expect(200, "POST", "/api/v1/birthdays", alice, map[string]any{
+		"name": "爸爸", "primaryType": "lunar", "lunarBirthday": "二月初一", "primaryMonth": 3, "primaryDay": 1,
+	})
+	api.expect(200, "POST", "/api/v1/birthdays", alice, map[string]any{
+		"name": "闰年", "solarBirthday": "1988-02-29",
+	})
+
+	api.expectError(400, "bad_request", "POST", "/api/v1/birthdays", alice, map[string]any{"name": " ", "solarBirthday": "1990-01-01"})
+	api.expectError(400, "bad_request", "POST", "/api/v1/birthdays", alice, map[string]any{"name": "x", "gender": "?", "solarBirthday": "1990-01-01"})
+	api.expectError(400, "bad_request", "POST", "/api/v1/birthdays", alice, map[string]any{"name": "x", "solarBirthday": "1990/01/01"})
+	api.expectError(400, "bad_request", "POST", "/api/v1/birthdays", alice, map[string]any{"name": "x", "primaryType": "lunar", "lunarBirthday": "正月初一"})
+
+	// 按距离下次生日的天数排序，2月29日在平年按2月28日计算
+	resp = api.expect(200, "GET", "/api/v1/birthdays", alice, nil)
+	items := list(resp, "items")
+	if len(items) != 3 {
+		t.Fatalf("list birthdays: %v", resp)
+	}
+	want := []struct {
+		name string
+		days float64
+		next string
+	}{
+		{"爸爸", 0, "2026-03-01"},
+		{"妈妈", 4, "2026-03-05"},
+		{"闰年", 364, "2027-02-28"},
+	}
+	for i, w := range want {
+		if str(items[i], "name") != w.name || num(items[i], "daysLeft") != w.days || str(items[i], "nextBirthday") != w.next {
+			t.Fatalf("item %d = %v, want %+v", i, items[i], w)
+		}
+	}
+
+	resp = api.expect(200, "GET", "/api/v1/birthdays", bob, nil)
+	if len(list(resp, "items")) != 0 {
+		t.Fatalf("bob sees alice's contacts: %v", resp)
+	}
+	api.expectError(404, "not_found", "GET", "/api/v1/birthdays/"+mom, bob, nil)
+
+	resp = api.expect(200, "PATCH", "/api/v1/birthdays/"+mom, alice, map[string]any{"phone": " 13800000000 ", "solarBirthday": "1990-04-10", "primaryType": "solar"})
+	if str(resp, "birthday", "phone") != "13800000000" || num(resp, "birthday", "primaryMonth") != 4 || str(resp, "birthday", "solarBirthday") != "1990-04-10" {
+		t.Fatalf("update birthday: %v", resp)
+	}
+	api.expectError(400, "bad_request", "PATCH", "/api/v1/birthdays/"+mom, alice, map[string]any{})
+	api.expectError(400, "bad_request", "PATCH", "/api/v1/birthdays/"+mom, alice, map[string]any{"primaryMonth": 13, "primaryDay": 1})
+	api.expectError(404, "not_found", "PATCH", "/api/v1/birthdays/"+mom, bob, map[string]any{"name": "x"})
+
+	api.expectError(404, "not_found", "DELETE", "/api/v1/birthdays/"+mom, bob, nil)
+	resp = api.expect(200, "DELETE", "/api/v1/birthdays/"+mom, alice, nil)
+	if !boolean(resp, "ok") {
+		t.Fatalf("delete birthday: %v", resp)
+	}
+	api.expectError(404, "not_found", "GET", "/api/v1/birthdays/"+mom, alice, nil)
+}
@@ -15,10 +15,10 @@ import (
 )
 
 type DepositHandlers struct {
-	st *store.Store
+	st store.DepositRepo
 }
 
-func NewDepositHandlers(st *store.Store) *DepositHandlers {
+func NewDepositHandlers(st store.DepositRepo) *DepositHandlers {
 	return &DepositHandlers{st: st}
 }
 
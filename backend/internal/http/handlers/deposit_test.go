@@ -0,0 +1,113 @@
+package handlers_test
+
+import (
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestDeposits(t *testing.T) {
+	api := newTestAPI(t)
+	api.st.Now = func() time.Time { return time.Date(2026, 6, 1, 9, 0, 0, 0, time.UTC) }
+	alice := api.login("alice", "Alice")
+	bob := api.login("bob", "Bob")
+
+	resp := api.expect(200, "POST", "/api/v1/deposits/accounts", alice, map[string]any{"bank": "招商银行", "holder": "Alice"})
+	cmb := str(resp, "account", "id")
+	resp = api.expect(200, "POST", "/api/v1/deposits/accounts", alice, map[string]any{"bank": "工商银行"})
+	icbc := str(resp, "account", "id")
+	api.expectError(400, "bad_request", "POST", "/api/v1/deposits/accounts", alice, map[string]any{"bank": " "})
+
+	resp = api.expect(200, "GET", "/api/v1/deposits/accounts", alice, nil)
+	if len(list(resp, "items")) != 2 {
+		t.Fatalf("list accounts: %v", resp)
+	}
+	api.expectError(404, "not_found", "GET", "/api/v1/deposits/accounts/"+cmb, bob, nil)
+
+	resp = api.expect(200, "PATCH", "/api/v1/deposits/accounts/"+cmb, alice, map[string]any{"branch": "科技园支行"})
+	if str(resp, "account", "branch") != "科技园支行" {
+		t.Fatalf("update account: %v", resp)
+	}
+
+	record := func(accountID string, fields map[string]any) string {
+		t.Helper()
+		body := map[string]any{
+			"currency": "CNY", "amount": 10000, "termValue": 1, "termUnit": "year", "rate": 2,
+			"startDate": "2025-06-01", "endDate": "2026-06-01", "interest": 200,
+		}
+		for k, v := range fields {
+			body[k] = v
+		}
+		resp := api.expect(200, "POST", "/api/v1/deposits/accounts/"+accountID+"/records", alice, body)
+		return str(resp, "record", "id")
+	}
+	r1 := record(cmb, map[string]any{"tags": []string{"应急", "定期"}})
+	record(cmb, map[string]any{"currency": "USD", "amount": 500, "interest": 20, "startDate": "2026-01-01", "endDate": "2027-01-01", "tags": []string{"定期"}})
+	record(icbc, map[string]any{"status": "已支取", "amount": 3000, "interest": 60})
+
+	api.expectError(400, "bad_request", "POST", "/api/v1/deposits/accounts/"+cmb+"/records", alice, map[string]any{
+		"currency": "JPY", "amount": 1, "termValue": 1, "termUnit": "year", "rate": 1, "startDate": "2025-01-01", "endDate": "2026-01-01",
+	})
+	api.expectError(400, "bad_request", "POST", "/api/v1/deposits/accounts/"+cmb+"/records", alice, map[string]any{
+		"currency": "CNY", "amount": 0, "termValue": 1, "termUnit": "year", "rate": 1, "startDate": "2025-01-01", "endDate": "2026-01-01",
+	})
+	api.expectError(400, "bad_request", "POST", "/api/v1/deposits/accounts/"+cmb+"/records", alice, map[string]any{
+		"currency": "CNY", "amount": 1, "termValue": 1, "termUnit": "week", "rate": 1, "startDate": "2025-01-01", "endDate": "2026-01-01",
+	})
+	api.expectError(404, "not_found", "POST", "/api/v1/deposits/accounts/missing/records", alice, map[string]any{
+		"currency": "CNY", "amount": 1, "termValue": 1, "termUnit": "year", "rate": 1, "startDate": "2025-01-01", "endDate": "2026-01-01",
+	})
+
+	resp = api.expect(200, "GET", "/api/v1/deposits/records", alice, nil)
+	if len(list(resp, "items")) != 3 {
+		t.Fatalf("list records: %v", resp)
+	}
+	resp = api.expect(200, "GET", "/api/v1/deposits/records?status="+url.QueryEscape("已支取"), alice, nil)
+	if items := list(resp, "items"); len(items) != 1 || str(items[0], "withdrawnAt") != "2026-06-01" {
+		t.Fatalf("withdrawn records: %v", resp)
+	}
+	resp = api.expect(200, "GET", "/api/v1/deposits/records?tags="+url.QueryEscape("应急"), alice, nil)
+	if items := list(resp, "items"); len(items) != 1 || str(items[0], "id") != r1 {
+		t.Fatalf("records by tag: %v", resp)
+	}
+	resp = api.expect(200, "GET", "/api/v1/deposits/accounts/"+cmb+"/records", alice, nil)
+	if len(list(resp, "items")) != 2 {
+		t.Fatalf("account records: %v", resp)
+	}
+	api.expectError(400, "bad_request", "GET", "/api/v1/deposits/records?status=bogus", alice, nil)
+
+	resp = api.expect(200, "GET", "/api/v1/deposits/tags", alice, nil)
+	tags := list(resp, "items")
+	if len(tags) != 2 || str(tags[0], "tag") != "定期" || num(tags[0], "count") != 2 {
+		t.Fatalf("tags: %v", resp)
+	}
+
+	resp = api.expect(200, "GET", "/api/v1/deposits/stats", alice, nil)
+	totals := list(resp, "stats", "totals")
+	if num(findBy(t, totals, "currency", "CNY"), "amount") != 13000 || num(findBy(t, totals, "currency", "USD"), "amount") != 500 {
+		t.Fatalf("stats totals: %v", resp)
+	}
+	if len(list(resp, "stats", "accountTotals")) != 3 {
+		t.Fatalf("stats account totals: %v", resp)
+	}
+
+	resp = api.expect(200, "PATCH", "/api/v1/deposits/records/"+r1, alice, map[string]any{"note": "备用金", "rate": 2.1})
+	if str(resp, "record", "note") != "备用金" || num(resp, "record", "rate") != 2.1 {
+		t.Fatalf("update record: %v", resp)
+	}
+	api.expectError(404, "not_found", "PATCH", "/api/v1/deposits/records/"+r1, bob, map[string]any{"note": "x"})
+
+	resp = api.expect(200, "DELETE", "/api/v1/deposits/records/"+r1, alice, nil)
+	if !boolean(resp, "ok") {
+		t.Fatalf("delete record: %v", resp)
+	}
+	api.expectError(404, "not_found", "GET", "/api/v1/deposits/records/"+r1, alice, nil)
+
+	// 删除账户同时删除其下的存单
+	api.expectError(404, "not_found", "DELETE", "/api/v1/deposits/accounts/"+icbc, bob, nil)
+	api.expect(200, "DELETE", "/api/v1/deposits/accounts/"+icbc, alice, nil)
+	resp = api.expect(200, "GET", "/api/v1/deposits/records", alice, nil)
+	if len(list(resp, "items")) != 1 {
+		t.Fatalf("records after account delete: %v", resp)
+	}
+}
@@ -0,0 +1,272 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/cloudwego/hertz/pkg/app"
+
+	"scorehub/internal/http/middleware"
+	"scorehub/internal/sheet"
+	"scorehub/internal/store"
+)
+
+var (
+	exportScorebookTable = sheet.Table{Key: "scorebook", Title: "得分簿", Single: true, Columns: []sheet.Column{
+		{Key: "id", Title: "ID"}, {Key: "name", Title: "名称"}, {Key: "locationText", Title: "地点"}, {Key: "status", Title: "状态"},
+		{Key: "startTime", Title: "开始时间"}, {Key: "endedAt", Title: "结束时间"}, {Key: "pointValue", Title: "积分单价"}, {Key: "currency", Title: "币种"},
+	}}
+	exportMembersTable = sheet.Table{Key: "members", Title: "成员", Columns: []sheet.Column{
+		{Key: "id", Title: "成员ID"}, {Key: "nickname", Title: "昵称"}, {Key: "role", Title: "身份"}, {Key: "joinedAt", Title: "加入时间"},
+		{Key: "score", Title: "最终分数"}, {Key: "money", Title: "金额"}, {Key: "removed", Title: "已移出"},
+	}}
+	exportWinnersTable = sheet.Table{Key: "winners", Title: "名次", Columns: []sheet.Column{
+		{Key: "rank", Title: "名次"}, {Key: "memberId", Title: "成员ID"}, {Key: "nickname", Title: "昵称"}, {Key: "score", Title: "分数"}, {Key: "money", Title: "金额"},
+	}}
+	exportRecordsTable = sheet.Table{Key: "records", Title: "记录", Columns: []sheet.Column{
+		{Key: "id", Title: "记录ID"}, {Key: "createdAt", Title: "时间"},
+		{Key: "fromMemberId", Title: "付出成员ID"}, {Key: "fromNickname", Title: "付出"},
+		{Key: "toMemberId", Title: "收到成员ID"}, {Key: "toNickname", Title: "收到"},
+		{Key: "delta", Title: "分数"}, {Key: "note", Title: "备注"}, {Key: "roundId", Title: "整局ID"},
+		{Key: "voided", Title: "已作废"}, {Key: "voidedAt", Title: "作废时间"},
+	}}
+
+	exportLedgerTable = sheet.Table{Key: "ledger", Title: "账本", Single: true, Columns: []sheet.Column{
+		{Key: "id", Title: "ID"}, {Key: "name", Title: "名称"}, {Key: "status", Title: "状态"}, {Key: "createdAt", Title: "创建时间"}, {Key: "endedAt", Title: "结束时间"},
+	}}
+	exportLedgerMembersTable = sheet.Table{Key: "members", Title: "成员", Columns: []sheet.Column{
+		{Key: "id", Title: "成员ID"}, {Key: "nickname", Title: "昵称"}, {Key: "remark", Title: "备注"}, {Key: "role", Title: "身份"}, {Key: "score", Title: "余额"},
+	}}
+	exportLedgerRecordsTable = sheet.Table{Key: "records", Title: "记录", Columns: []sheet.Column{
+		{Key: "id", Title: "记录ID"}, {Key: "createdAt", Title: "时间"}, {Key: "type", Title: "类型"},
+		{Key: "memberId", Title: "成员ID"}, {Key: "memberNickname", Title: "成员"},
+		{Key: "fromMemberId", Title: "付出成员ID"}, {Key: "fromNickname", Title: "付出"},
+		{Key: "toMemberId", Title: "收到成员ID"}, {Key: "toNickname", Title: "收到"},
+		{Key: "amount", Title: "金额"}, {Key: "note", Title: "备注"}, {Key: "category", Title: "分类"},
+	}}
+)
+
+// ExportScorebook 导出得分簿（成员、最终分数、名次与全部记录），format 为 csv / xlsx / json，
+// 边查边写，不在内存中拼整个文件。仅成员可导出。
+func (h *ScorebookHandlers) ExportScorebook(ctx context.Context, c *app.RequestContext) {
+	uid, ok := middleware.UserID(c)
+	if !ok {
+		writeError(c, http.StatusUnauthorized, "unauthorized", "missing user")
+		return
+	}
+	id := strings.TrimSpace(c.Param("id"))
+	if id == "" {
+		writeError(c, http.StatusBadRequest, "bad_request", "id required")
+		return
+	}
+	format, ok := exportFormat(c)
+	if !ok {
+		writeError(c, http.StatusBadRequest, "bad_request", "format must be csv, xlsx or json")
+		return
+	}
+
+	sb, _, _, members, err := h.st.GetScorebookDetail(ctx, id, uid)
+	if err != nil {
+		if err == store.ErrNotFound {
+			writeError(c, http.StatusNotFound, "not_found", "scorebook not found")
+			return
+		}
+		writeError(c, http.StatusInternalServerError, "internal", "db error", err)
+		return
+	}
+	winners, err := h.st.GetTopWinners(ctx, sb.ID)
+	if err != nil {
+		writeError(c, http.StatusInternalServerError, "internal", "db error", err)
+		return
+	}
+
+	nicknames := map[string]string{}
+	for _, m := range members {
+		nicknames[m.ID] = m.Nickname
+	}
+
+	streamExport(ctx, c, format, "scorebook-"+sb.ID, sb.Name, func(ctx context.Context, w sheet.Writer) error {
+		if err := w.Begin(exportScorebookTable); err != nil {
+			return err
+		}
+		if err := w.Row(sb.ID, sb.Name, sb.LocationText, sb.Status, sb.StartTime, sb.EndedAt, sb.Game.PointValue, sb.Game.Currency); err != nil {
+			return err
+		}
+
+		if err := w.Begin(exportMembersTable); err != nil {
+			return err
+		}
+		for _, m := range members {
+			if err := w.Row(m.ID, m.Nickname, m.Role, m.JoinedAt, m.Score, m.Money, m.UserID == 0 && !m.Placeholder); err != nil {
+				return err
+			}
+		}
+
+		if err := w.Begin(exportWinnersTable); err != nil {
+			return err
+		}
+		for i, m := range winners {
+			if i >= 3 {
+				break
+			}
+			if err := w.Row(i+1, m.ID, m.Nickname, m.Score, m.Money); err != nil {
+				return err
+			}
+		}
+
+		if err := w.Begin(exportRecordsTable); err != nil {
+			return err
+		}
+		return h.st.EachRecord(ctx, sb.ID, func(r store.ScoreRecord) error {
+			return w.Row(r.ID, r.CreatedAt, r.FromMemberID, nicknames[r.FromMemberID], r.ToMemberID, nicknames[r.ToMemberID],
+				r.Delta, r.Note, r.RoundID, r.VoidedAt != nil, r.VoidedAt)
+		})
+	})
+}
+
+// ExportLedger 导出账本（成员余额与全部记录），仅账本创建者可导出。
+// layout=import 时只导出与导入相同格式的一张表（姓名、备注、类型、金额）。
+func (h *LedgerHandlers) ExportLedger(ctx context.Context, c *app.RequestContext) {
+	uid, ok := middleware.UserID(c)
+	if !ok {
+		writeError(c, http.StatusUnauthorized, "unauthorized", "missing user")
+		return
+	}
+	id := strings.TrimSpace(c.Param("id"))
+	if id == "" {
+		writeError(c, http.StatusBadRequest, "bad_request", "id required")
+		return
+	}
+	format, ok := exportFormat(c)
+	if !ok {
+		writeError(c, http.StatusBadRequest, "bad_request", "format must be csv, xlsx or json")
+		return
+	}
+	layout := strings.TrimSpace(string(c.Query("layout")))
+	if layout != "" && layout != "full" && layout != "import" {
+		writeError(c, http.StatusBadRequest, "bad_request", "layout must be full or import")
+		return
+	}
+
+	ledger, members, _, err := h.st.GetLedgerDetail(ctx, id, 0, 0)
+	if err != nil {
+		if err == store.ErrNotFound {
+			writeError(c, http.StatusNotFound, "not_found", "ledger not found")
+			return
+		}
+		writeError(c, http.StatusInternalServerError, "internal", "db error", err)
+		return
+	}
+	if ledger.CreatedByUserID != uid {
+		writeError(c, http.StatusForbidden, "forbidden", "only owner can export")
+		return
+	}
+
+	nicknames := map[string]string{}
+	for _, m := range members {
+		nicknames[m.ID] = m.Nickname
+	}
+	categories, err := h.st.ListLedgerCategories(ctx, ledger.ID, uid)
+	if err != nil {
+		writeError(c, http.StatusInternalServerError, "internal", "db error", err)
+		return
+	}
+	categoryNames := map[string]string{}
+	for _, cat := range categories {
+		categoryNames[cat.ID] = cat.Name
+	}
+
+	if layout == "import" {
+		remarks := map[string]string{}
+		for _, m := range members {
+			remarks[m.ID] = m.Remark
+		}
+		streamExport(ctx, c, format, "ledger-"+ledger.ID+"-import", ledger.Name, func(ctx context.Context, w sheet.Writer) error {
+			if err := w.Begin(exportLedgerImportTable); err != nil {
+				return err
+			}
+			return h.st.EachLedgerRecord(ctx, ledger.ID, func(r store.LedgerRecord) error {
+				if r.Type == "remark" {
+					return nil
+				}
+				return w.Row(nicknames[r.MemberID], remarks[r.MemberID], r.Type, r.Amount)
+			})
+		})
+		return
+	}
+
+	streamExport(ctx, c, format, "ledger-"+ledger.ID, ledger.Name, func(ctx context.Context, w sheet.Writer) error {
+		if err := w.Begin(exportLedgerTable); err != nil {
+			return err
+		}
+		if err := w.Row(ledger.ID, ledger.Name, ledger.Status, ledger.StartTime, ledger.EndedAt); err != nil {
+			return err
+		}
+
+		if err := w.Begin(exportLedgerMembersTable); err != nil {
+			return err
+		}
+		for _, m := range members {
+			if err := w.Row(m.ID, m.Nickname, m.Remark, m.Role, m.Score); err != nil {
+				return err
+			}
+		}
+
+		if err := w.Begin(exportLedgerRecordsTable); err != nil {
+			return err
+		}
+		return h.st.EachLedgerRecord(ctx, ledger.ID, func(r store.LedgerRecord) error {
+			return w.Row(r.ID, r.CreatedAt, r.Type, r.MemberID, nicknames[r.MemberID], r.FromMemberID, nicknames[r.FromMemberID],
+				r.ToMemberID, nicknames[r.ToMemberID], r.Amount, r.Note, categoryNames[r.CategoryID])
+		})
+	})
+}
+
+func exportFormat(c *app.RequestContext) (string, bool) {
+	format := strings.ToLower(strings.TrimSpace(string(c.Query("format"))))
+	switch format {
+	case "":
+		return sheet.FormatCSV, true
+	case sheet.FormatCSV, sheet.FormatXLSX, sheet.FormatJSON:
+		return format, true
+	default:
+		return "", false
+	}
+}
+
+// streamExport sends the file as a body stream: write runs in its own goroutine and
+// feeds the response through a pipe while hertz copies it to the client. Access must
+// be checked before calling, since errors can no longer change the status code.
+func streamExport(ctx context.Context, c *app.RequestContext, format, baseName, title string, write func(ctx context.Context, w sheet.Writer) error) {
+	filename := baseName + "." + format
+	disposition := `attachment; filename="` + filename + `"`
+	if title = strings.TrimSpace(title); title != "" {
+		disposition += "; filename*=UTF-8''" + url.PathEscape(title+"."+format)
+	}
+	c.Response.Header.Set("Content-Type", sheet.ContentType(format))
+	c.Response.Header.Set("Content-Disposition", disposition)
+
+	// 请求结束后 ctx 可能被取消，写入协程用不带取消的 ctx；客户端断开时管道写失败即停止。
+	ctx = context.WithoutCancel(ctx)
+	pr, pw := io.Pipe()
+	go func() {
+		w, err := sheet.NewWriter(format, pw)
+		if err == nil {
+			err = write(ctx, w)
+		}
+		if err == nil {
+			err = w.Close()
+		}
+		if err != nil && !errors.Is(err, io.ErrClosedPipe) {
+			log.Printf("export %s failed: %v", filename, err)
+		}
+		_ = pw.CloseWithError(err)
+	}()
+	c.SetStatusCode(http.StatusOK)
+	c.SetBodyStream(pr, -1)
+}
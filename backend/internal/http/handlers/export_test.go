@@ -0,0 +1,119 @@
+package handlers_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestScorebookExport(t *testing.T) {
+	f := newScorebook(t)
+	api := f.api
+	dave := api.login("dave", "Dave")
+
+	api.expect(200, "POST", f.path("/records"), f.alice, map[string]any{"toMemberId": f.bobM, "delta": 10, "note": "自摸"})
+	resp := api.expect(200, "POST", f.path("/records"), f.bob, map[string]any{"toMemberId": f.carolM, "delta": 4})
+	api.expect(200, "POST", f.path("/records/"+str(resp, "record", "id")+"/void"), f.bob, nil)
+	api.expect(200, "POST", f.path("/records"), f.carol, map[string]any{"toMemberId": f.bobM, "delta": 2})
+	api.expect(200, "POST", f.path("/end"), f.alice, nil)
+
+	// JSON：成员、名次、全部记录（作废的带标记）
+	res := api.download(f.path("/export?format=json"), f.carol)
+	if res.StatusCode() != 200 || !strings.HasPrefix(string(res.Header.ContentType()), "application/json") {
+		t.Fatalf("json export: %d %s", res.StatusCode(), res.Body())
+	}
+	if cd := string(res.Header.Peek("Content-Disposition")); !strings.Contains(cd, `filename="scorebook-`+f.id+`.json"`) {
+		t.Fatalf("content disposition: %s", cd)
+	}
+	var doc map[string]any
+	if err := json.Unmarshal(res.Body(), &doc); err != nil {
+		t.Fatalf("decode export: %v %s", err, res.Body())
+	}
+	if str(doc, "scorebook", "name") != "周五麻将" || str(doc, "scorebook", "status") != "ended" || len(list(doc, "members")) != 3 {
+		t.Fatalf("export header: %v", doc)
+	}
+	winners := list(doc, "winners")
+	if len(winners) != 1 || str(winners[0], "memberId") != f.bobM || num(winners[0], "score") != 12 || num(winners[0], "rank") != 1 {
+		t.Fatalf("export winners: %v", winners)
+	}
+	records := list(doc, "records")
+	if len(records) != 3 || str(records[0], "fromNickname") != "Alice" || str(records[0], "toNickname") != "Bob" || str(records[0], "note") != "自摸" {
+		t.Fatalf("export records: %v", records)
+	}
+	if !boolean(records[1], "voided") || boolean(records[2], "voided") || num(records[2], "delta") != 2 {
+		t.Fatalf("export voided: %v", records)
+	}
+
+	// CSV：按分节输出，记录在最后
+	res = api.download(f.path("/export"), f.alice)
+	if res.StatusCode() != 200 || !strings.HasPrefix(string(res.Header.ContentType()), "text/csv") {
+		t.Fatalf("csv export: %d %s", res.StatusCode(), res.Body())
+	}
+	r := csv.NewReader(bytes.NewReader(bytes.TrimPrefix(res.Body(), []byte("\xef\xbb\xbf"))))
+	r.FieldsPerRecord = -1
+	rows, err := r.ReadAll()
+	if err != nil {
+		t.Fatalf("read csv: %v", err)
+	}
+	if rows[0][0] != "得分簿" || rows[len(rows)-5][0] != "记录" || rows[len(rows)-1][7] != "" || rows[len(rows)-3][7] != "自摸" {
+		t.Fatalf("csv rows: %v", rows)
+	}
+
+	// XLSX：合法 zip，每个表一个工作表
+	res = api.download(f.path("/export?format=xlsx"), f.alice)
+	body := res.Body()
+	zr, err := zip.NewReader(bytes.NewReader(body), int64(len(body)))
+	if err != nil {
+		t.Fatalf("open xlsx: %v", err)
+	}
+	sheets := 0
+	for _, file := range zr.File {
+		if strings.HasPrefix(file.Name, "xl/worksheets/") {
+			sheets++
+		}
+	}
+	if sheets != 4 {
+		t.Fatalf("xlsx sheets: %d", sheets)
+	}
+
+	api.expectError(400, "bad_request", "GET", f.path("/export?format=pdf"), f.alice, nil)
+	api.expectError(404, "not_found", "GET", f.path("/export"), dave, nil)
+}
+
+func TestLedgerExport(t *testing.T) {
+	api := newTestAPI(t)
+	owner := api.login("owner", "店主")
+	guest := api.login("guest", "来宾")
+
+	resp := api.expect(200, "POST", "/api/v1/ledgers", owner, map[string]any{"name": "婚礼礼金"})
+	path := "/api/v1/ledgers/" + str(resp, "ledger", "id")
+	resp = api.expect(200, "POST", path+"/members", owner, map[string]any{"nickname": "张三", "remark": "大学同学"})
+	zhang := str(resp, "member", "id")
+	api.expect(200, "POST", path+"/records", owner, map[string]any{"memberId": zhang, "type": "income", "amount": 888, "note": "红包"})
+	api.expect(200, "POST", path+"/records", owner, map[string]any{"memberId": zhang, "type": "expense", "amount": 100})
+
+	res := api.download(path+"/export?format=json", owner)
+	var doc map[string]any
+	if err := json.Unmarshal(res.Body(), &doc); err != nil {
+		t.Fatalf("decode export: %v %s", err, res.Body())
+	}
+	if str(doc, "ledger", "name") != "婚礼礼金" {
+		t.Fatalf("ledger export: %v", doc)
+	}
+	if m := findBy(t, list(doc, "members"), "id", zhang); str(m, "remark") != "大学同学" || num(m, "score") != -788 {
+		t.Fatalf("ledger members: %v", m)
+	}
+	records := list(doc, "records")
+	if len(records) != 2 || str(records[0], "type") != "income" || num(records[0], "amount") != 888 || str(records[0], "memberNickname") != "张三" || str(records[0], "toNickname") != "店主" {
+		t.Fatalf("ledger records: %v", records)
+	}
+	if str(records[1], "type") != "expense" || str(records[1], "fromNickname") != "店主" {
+		t.Fatalf("ledger expense: %v", records)
+	}
+
+	api.expectError(403, "forbidden", "GET", path+"/export", guest, nil)
+	api.expectError(404, "not_found", "GET", "/api/v1/ledgers/missing/export", owner, nil)
+}
@@ -0,0 +1,178 @@
+package handlers_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/cloudwego/hertz/pkg/common/config"
+	"github.com/cloudwego/hertz/pkg/common/hlog"
+	"github.com/cloudwego/hertz/pkg/common/ut"
+	"github.com/cloudwego/hertz/pkg/protocol"
+	"github.com/cloudwego/hertz/pkg/route"
+
+	appconfig "scorehub/internal/config"
+	"scorehub/internal/http/handlers"
+	"scorehub/internal/realtime"
+	"scorehub/internal/store/memstore"
+)
+
+func TestMain(m *testing.M) {
+	// 路由注册的调试日志太多，测试中只保留警告以上
+	hlog.SetLevel(hlog.LevelWarn)
+	os.Exit(m.Run())
+}
+
+type testAPI struct {
+	t      *testing.T
+	cfg    appconfig.Config
+	st     *memstore.Store
+	hub    *realtime.Hub
+	engine *route.Engine
+}
+
+func newTestAPI(t *testing.T) *testAPI {
+	t.Helper()
+	st := memstore.New()
+	cfg := appconfig.Config{
+		TokenSecret:      "test-secret",
+		DevAuth:          true,
+		AccessTokenTTL:   2 * time.Hour,
+		RefreshTokenTTL:  30 * 24 * time.Hour,
+		RecordVoidWindow: 10 * time.Minute,
+	}
+	hub := realtime.NewHub()
+	hub.UseEventLog(st)
+	api := &testAPI{t: t, cfg: cfg, st: st, hub: hub, engine: route.NewEngine(config.NewOptions(nil))}
+	api.register(api.engine)
+	return api
+}
+
+func (a *testAPI) register(r route.IRouter) {
+	handlers.RegisterRoutes(r, a.cfg, handlers.Repos{
+		Users:      a.st,
+		Scorebooks: a.st,
+		Ledgers:    a.st,
+		Templates:  a.st,
+		Birthdays:  a.st,
+		Deposits:   a.st,
+		People:     a.st,
+	}, a.hub)
+}
+
+// login signs in through the dev login endpoint and returns the bearer token.
+func (a *testAPI) login(openid, nickname string) string {
+	a.t.Helper()
+	resp := a.expect(200, "POST", "/api/v1/auth/dev_login", "", map[string]any{"openid": openid, "nickname": nickname})
+	return str(resp, "token")
+}
+
+// do performs a request and returns the status code and decoded JSON body.
+func (a *testAPI) do(method, path, token string, body any) (int, map[string]any) {
+	a.t.Helper()
+	var reqBody *ut.Body
+	if body != nil {
+		raw, err := json.Marshal(body)
+		if err != nil {
+			a.t.Fatalf("marshal body: %v", err)
+		}
+		reqBody = &ut.Body{Body: bytes.NewReader(raw), Len: len(raw)}
+	}
+	headers := []ut.Header{{Key: "Content-Type", Value: "application/json"}}
+	if token != "" {
+		headers = append(headers, ut.Header{Key: "Authorization", Value: "Bearer " + token})
+	}
+	w := ut.PerformRequest(a.engine, method, path, reqBody, headers...)
+	resp := w.Result()
+	// 推送在后台完成；等它们发出，测试看到的事件顺序才是确定的
+	a.hub.Wait()
+
+	out := map[string]any{}
+	if raw := resp.Body(); len(raw) > 0 {
+		if err := json.Unmarshal(raw, &out); err != nil {
+			a.t.Fatalf("%s %s: decode %q: %v", method, path, raw, err)
+		}
+	}
+	return resp.StatusCode(), out
+}
+
+// download performs a GET and returns the raw response, for non-JSON bodies.
+func (a *testAPI) download(path, token string) *protocol.Response {
+	a.t.Helper()
+	var headers []ut.Header
+	if token != "" {
+		headers = append(headers, ut.Header{Key: "Authorization", Value: "Bearer " + token})
+	}
+	return ut.PerformRequest(a.engine, "GET", path, nil, headers...).Result()
+}
+
+// expect performs a request and fails the test unless it returns status.
+func (a *testAPI) expect(status int, method, path, token string, body any) map[string]any {
+	a.t.Helper()
+	got, resp := a.do(method, path, token, body)
+	if got != status {
+		a.t.Fatalf("%s %s: status = %d, want %d (body %v)", method, path, got, status, resp)
+	}
+	return resp
+}
+
+// expectError performs a request and checks both the status and the error code.
+func (a *testAPI) expectError(status int, code, method, path, token string, body any) {
+	a.t.Helper()
+	resp := a.expect(status, method, path, token, body)
+	if got := str(obj(resp, "error"), "code"); got != code {
+		a.t.Fatalf("%s %s: error code = %q, want %q", method, path, got, code)
+	}
+}
+
+// field walks nested JSON objects by key.
+func field(v any, keys ...string) any {
+	for _, k := range keys {
+		m, ok := v.(map[string]any)
+		if !ok {
+			return nil
+		}
+		v = m[k]
+	}
+	return v
+}
+
+func obj(v any, keys ...string) map[string]any {
+	m, _ := field(v, keys...).(map[string]any)
+	return m
+}
+
+func list(v any, keys ...string) []any {
+	l, _ := field(v, keys...).([]any)
+	return l
+}
+
+func str(v any, keys ...string) string {
+	s, _ := field(v, keys...).(string)
+	return s
+}
+
+func num(v any, keys ...string) float64 {
+	n, _ := field(v, keys...).(float64)
+	return n
+}
+
+func boolean(v any, keys ...string) bool {
+	b, _ := field(v, keys...).(bool)
+	return b
+}
+
+// findBy returns the first object in items whose key equals value.
+func findBy(t *testing.T, items []any, key, value string) map[string]any {
+	t.Helper()
+	for _, it := range items {
+		if m, ok := it.(map[string]any); ok && fmt.Sprint(m[key]) == value {
+			return m
+		}
+	}
+	t.Fatalf("no item with %s=%s in %v", key, value, items)
+	return nil
+}
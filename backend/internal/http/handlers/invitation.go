@@ -0,0 +1,210 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/cloudwego/hertz/pkg/app"
+
+	"scorehub/internal/http/middleware"
+	"scorehub/internal/store"
+)
+
+// maxInvitees 为一次最多邀请的人数。
+const maxInvitees = 20
+
+// ListFrequentPlayers 列出和自己一起玩过最多得分簿的用户，用于创建时快速邀请。
+func (h *ScorebookHandlers) ListFrequentPlayers(ctx context.Context, c *app.RequestContext) {
+	uid, ok := middleware.UserID(c)
+	if !ok {
+		writeError(c, http.StatusUnauthorized, "unauthorized", "missing user")
+		return
+	}
+
+	limit := int32(20)
+	if v := strings.TrimSpace(string(c.Query("limit"))); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 && n <= 200 {
+			limit = int32(n)
+		}
+	}
+
+	players, err := h.st.ListFrequentPlayers(ctx, uid, limit)
+	if err != nil {
+		writeError(c, http.StatusInternalServerError, "internal", "db error", err)
+		return
+	}
+	items := make([]map[string]any, 0, len(players))
+	for _, p := range players {
+		items = append(items, map[string]any{
+			"userId":           p.UserID,
+			"nickname":         p.Nickname,
+			"avatarUrl":        p.AvatarURL,
+			"sharedScorebooks": p.SharedScorebooks,
+			"lastPlayedAt":     p.LastPlayedAt,
+		})
+	}
+	c.JSON(http.StatusOK, map[string]any{"items": items})
+}
+
+type inviteUsersRequest struct {
+	UserIDs []int64 `json:"userIds"`
+}
+
+// InviteUsers 邀请一起玩过的用户加入得分簿（仅掌柜或管理员），被邀请人无需邀请码即可接受。
+func (h *ScorebookHandlers) InviteUsers(ctx context.Context, c *app.RequestContext) {
+	uid, ok := middleware.UserID(c)
+	if !ok {
+		writeError(c, http.StatusUnauthorized, "unauthorized", "missing user")
+		return
+	}
+	id := strings.TrimSpace(c.Param("id"))
+	if id == "" {
+		writeError(c, http.StatusBadRequest, "bad_request", "id required")
+		return
+	}
+
+	var req inviteUsersRequest
+	body, err := c.Body()
+	if err != nil {
+		writeError(c, http.StatusBadRequest, "bad_request", "read body failed")
+		return
+	}
+	if err := json.Unmarshal(body, &req); err != nil {
+		writeError(c, http.StatusBadRequest, "bad_request", "invalid json")
+		return
+	}
+	if len(req.UserIDs) == 0 {
+		writeError(c, http.StatusBadRequest, "bad_request", "userIds required")
+		return
+	}
+	if len(req.UserIDs) > maxInvitees {
+		writeError(c, http.StatusBadRequest, "bad_request", "too many invitees")
+		return
+	}
+
+	invs, err := h.st.InviteUsers(ctx, id, uid, req.UserIDs)
+	if err != nil {
+		writeInvitationError(c, err)
+		return
+	}
+	items := make([]map[string]any, 0, len(invs))
+	for _, inv := range invs {
+		items = append(items, toInvitationDTO(inv))
+	}
+	c.JSON(http.StatusOK, map[string]any{"items": items})
+}
+
+// ListMyInvitations 列出发给自己的得分簿邀请，可按 status 过滤。
+func (h *ScorebookHandlers) ListMyInvitations(ctx context.Context, c *app.RequestContext) {
+	uid, ok := middleware.UserID(c)
+	if !ok {
+		writeError(c, http.StatusUnauthorized, "unauthorized", "missing user")
+		return
+	}
+
+	limit := int32(20)
+	offset := int32(0)
+	if v := strings.TrimSpace(string(c.Query("limit"))); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 && n <= 200 {
+			limit = int32(n)
+		}
+	}
+	if v := strings.TrimSpace(string(c.Query("offset"))); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n >= 0 {
+			offset = int32(n)
+		}
+	}
+	status := strings.TrimSpace(string(c.Query("status")))
+
+	invs, err := h.st.ListMyInvitations(ctx, uid, status, limit, offset)
+	if err != nil {
+		writeInvitationError(c, err)
+		return
+	}
+	items := make([]map[string]any, 0, len(invs))
+	for _, inv := range invs {
+		items = append(items, toInvitationDTO(inv))
+	}
+	c.JSON(http.StatusOK, map[string]any{"items": items, "limit": limit, "offset": offset})
+}
+
+// AcceptInvitation 接受邀请直接成为成员（不需要邀请码，也不走加入审核）。
+func (h *ScorebookHandlers) AcceptInvitation(ctx context.Context, c *app.RequestContext) {
+	h.respondInvitation(ctx, c, true)
+}
+
+// DeclineInvitation 拒绝邀请。
+func (h *ScorebookHandlers) DeclineInvitation(ctx context.Context, c *app.RequestContext) {
+	h.respondInvitation(ctx, c, false)
+}
+
+func (h *ScorebookHandlers) respondInvitation(ctx context.Context, c *app.RequestContext, accept bool) {
+	uid, ok := middleware.UserID(c)
+	if !ok {
+		writeError(c, http.StatusUnauthorized, "unauthorized", "missing user")
+		return
+	}
+	id := strings.TrimSpace(c.Param("id"))
+	if id == "" {
+		writeError(c, http.StatusBadRequest, "bad_request", "id required")
+		return
+	}
+
+	user, err := h.users.GetUserByID(ctx, uid)
+	if err != nil {
+		writeError(c, http.StatusUnauthorized, "unauthorized", "user not found")
+		return
+	}
+
+	inv, m, err := h.st.RespondInvitation(ctx, id, user, accept)
+	if err != nil {
+		writeInvitationError(c, err)
+		return
+	}
+
+	resp := map[string]any{"invitation": toInvitationDTO(inv)}
+	if m != nil {
+		h.broadcastMemberJoined(*m)
+		resp["scorebookId"] = inv.ScorebookID
+		resp["member"] = toMemberDTO(*m, 0, m.ID)
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+func writeInvitationError(c *app.RequestContext, err error) {
+	switch err {
+	case store.ErrNotFound:
+		writeError(c, http.StatusNotFound, "not_found", "not found")
+	case store.ErrForbidden:
+		writeError(c, http.StatusForbidden, "forbidden", "only owner or admin can invite")
+	case store.ErrInvalidArgument:
+		writeError(c, http.StatusBadRequest, "bad_request", "can only invite users you have played with")
+	case store.ErrConflict:
+		writeError(c, http.StatusConflict, "conflict", "invitation already resolved")
+	case store.ErrScorebookEnded:
+		writeError(c, http.StatusBadRequest, "ended", "scorebook ended")
+	case store.ErrBanned:
+		writeError(c, http.StatusForbidden, "banned", "user is banned from this scorebook")
+	default:
+		writeError(c, http.StatusInternalServerError, "internal", "db error", err)
+	}
+}
+
+func toInvitationDTO(inv store.ScorebookInvitation) map[string]any {
+	return map[string]any{
+		"id":                inv.ID,
+		"scorebookId":       inv.ScorebookID,
+		"scorebookName":     inv.ScorebookName,
+		"scorebookStatus":   inv.ScorebookStatus,
+		"userId":            inv.UserID,
+		"invitedByUserId":   inv.InvitedByUserID,
+		"invitedByNickname": inv.InvitedByNickname,
+		"status":            inv.Status,
+		"memberId":          inv.MemberID,
+		"createdAt":         inv.CreatedAt,
+		"resolvedAt":        inv.ResolvedAt,
+	}
+}
@@ -0,0 +1,81 @@
+package handlers_test
+
+import "testing"
+
+func TestScorebookInvitations(t *testing.T) {
+	f := newScorebook(t)
+	api := f.api
+	dave := api.login("dave", "Dave")
+
+	// 常一起玩的人：bob、carol 各同簿一次，dave 没有一起玩过
+	resp := api.expect(200, "GET", "/api/v1/me/frequent_players", f.alice, nil)
+	items := list(resp, "items")
+	if len(items) != 2 || num(items[0], "sharedScorebooks") != 1 {
+		t.Fatalf("frequent players: %v", resp)
+	}
+	bobID := int64(num(findBy(t, items, "nickname", "Bob"), "userId"))
+	carolID := int64(num(findBy(t, items, "nickname", "小C"), "userId"))
+	if resp := api.expect(200, "GET", "/api/v1/me/frequent_players", dave, nil); len(list(resp, "items")) != 0 {
+		t.Fatalf("dave frequent players: %v", resp)
+	}
+
+	// 只能邀请一起玩过的人
+	resp = api.expect(200, "GET", "/api/v1/me/frequent_players", f.bob, nil)
+	aliceID := int64(num(findBy(t, list(resp, "items"), "nickname", "Alice"), "userId"))
+	api.expectError(400, "bad_request", "POST", "/api/v1/scorebooks", f.alice, map[string]any{"inviteUserIds": []int64{aliceID}})
+
+	resp = api.expect(200, "POST", "/api/v1/scorebooks", f.alice, map[string]any{"name": "周六", "inviteUserIds": []int64{bobID, carolID, bobID}})
+	second := str(resp, "scorebook", "id")
+	secondCode := str(resp, "scorebook", "inviteCode")
+
+	resp = api.expect(200, "GET", "/api/v1/me/invitations?status=pending", f.bob, nil)
+	items = list(resp, "items")
+	if len(items) != 1 || str(items[0], "scorebookId") != second || str(items[0], "scorebookName") != "周六" || str(items[0], "invitedByNickname") != "Alice" {
+		t.Fatalf("bob invitations: %v", resp)
+	}
+	bobInv := str(items[0], "id")
+	api.expectError(404, "not_found", "GET", "/api/v1/scorebooks/"+second, f.bob, nil)
+	api.expectError(404, "not_found", "POST", "/api/v1/me/invitations/"+bobInv+"/accept", f.carol, nil)
+	api.expectError(400, "bad_request", "GET", "/api/v1/me/invitations?status=bogus", f.bob, nil)
+
+	// 接受后直接成为成员，即使开启了加入审核
+	api.expect(200, "PATCH", "/api/v1/scorebooks/"+second+"/invite", f.alice, map[string]any{"joinApproval": true})
+	resp = api.expect(200, "POST", "/api/v1/me/invitations/"+bobInv+"/accept", f.bob, nil)
+	if str(resp, "invitation", "status") != "accepted" || str(resp, "scorebookId") != second || str(resp, "member", "role") != "member" {
+		t.Fatalf("accept: %v", resp)
+	}
+	if str(resp, "invitation", "memberId") != str(resp, "member", "id") {
+		t.Fatalf("accepted member id: %v", resp)
+	}
+	api.expect(200, "GET", "/api/v1/scorebooks/"+second, f.bob, nil)
+	api.expectError(409, "conflict", "POST", "/api/v1/me/invitations/"+bobInv+"/decline", f.bob, nil)
+
+	// 拒绝
+	resp = api.expect(200, "GET", "/api/v1/me/invitations", f.carol, nil)
+	carolInv := str(list(resp, "items")[0], "id")
+	resp = api.expect(200, "POST", "/api/v1/me/invitations/"+carolInv+"/decline", f.carol, nil)
+	if str(resp, "invitation", "status") != "declined" || field(resp, "member") != nil {
+		t.Fatalf("decline: %v", resp)
+	}
+	api.expectError(404, "not_found", "GET", "/api/v1/scorebooks/"+second, f.carol, nil)
+
+	// 已有得分簿里邀请：仅掌柜/管理员；已是成员的跳过
+	api.expectError(403, "forbidden", "POST", "/api/v1/scorebooks/"+second+"/invitations", f.bob, map[string]any{"userIds": []int64{carolID}})
+	api.expectError(400, "bad_request", "POST", "/api/v1/scorebooks/"+second+"/invitations", f.alice, map[string]any{"userIds": []int64{}})
+	resp = api.expect(200, "POST", "/api/v1/scorebooks/"+second+"/invitations", f.alice, map[string]any{"userIds": []int64{bobID, carolID}})
+	if items := list(resp, "items"); len(items) != 1 || int64(num(items[0], "userId")) != carolID || str(items[0], "status") != "pending" {
+		t.Fatalf("invite in scorebook: %v", resp)
+	}
+
+	// 通过其他方式加入后，待处理的邀请随之完成
+	api.expect(200, "PATCH", "/api/v1/scorebooks/"+second+"/invite", f.alice, map[string]any{"joinApproval": false})
+	api.expect(200, "POST", "/api/v1/scorebooks/"+second+"/join", f.carol, map[string]any{"inviteCode": secondCode})
+	resp = api.expect(200, "GET", "/api/v1/me/invitations?status=accepted", f.carol, nil)
+	if len(list(resp, "items")) != 1 {
+		t.Fatalf("carol accepted invitations: %v", resp)
+	}
+
+	// 已结束的得分簿不能再邀请
+	api.expect(200, "POST", "/api/v1/scorebooks/"+second+"/end", f.alice, nil)
+	api.expectError(400, "ended", "POST", "/api/v1/scorebooks/"+second+"/invitations", f.alice, map[string]any{"userIds": []int64{carolID}})
+}
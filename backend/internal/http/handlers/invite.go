@@ -0,0 +1,192 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cloudwego/hertz/pkg/app"
+
+	"scorehub/internal/http/middleware"
+	"scorehub/internal/store"
+)
+
+type updateInviteRequest struct {
+	// ExpiresAt 为 RFC3339 时间，空串表示不过期
+	ExpiresAt *string `json:"expiresAt"`
+	// MaxUses 为 0 表示不限次数
+	MaxUses *int `json:"maxUses"`
+	// JoinApproval 为 true 时加入需要掌柜或管理员审核
+	JoinApproval *bool `json:"joinApproval"`
+}
+
+// GetInvite 返回当前邀请码及其有效期、次数限制（仅掌柜或管理员）。
+func (h *ScorebookHandlers) GetInvite(ctx context.Context, c *app.RequestContext) {
+	uid, ok := middleware.UserID(c)
+	if !ok {
+		writeError(c, http.StatusUnauthorized, "unauthorized", "missing user")
+		return
+	}
+	id := strings.TrimSpace(c.Param("id"))
+	if id == "" {
+		writeError(c, http.StatusBadRequest, "bad_request", "id required")
+		return
+	}
+
+	inv, err := h.st.GetInviteSettings(ctx, id, uid)
+	if err != nil {
+		writeInviteError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, map[string]any{"invite": toInviteSettingsDTO(inv)})
+}
+
+// UpdateInvite 设置邀请码的过期时间、可用次数以及加入是否需要审核。
+func (h *ScorebookHandlers) UpdateInvite(ctx context.Context, c *app.RequestContext) {
+	uid, ok := middleware.UserID(c)
+	if !ok {
+		writeError(c, http.StatusUnauthorized, "unauthorized", "missing user")
+		return
+	}
+	id := strings.TrimSpace(c.Param("id"))
+	if id == "" {
+		writeError(c, http.StatusBadRequest, "bad_request", "id required")
+		return
+	}
+
+	var req updateInviteRequest
+	body, err := c.Body()
+	if err != nil {
+		writeError(c, http.StatusBadRequest, "bad_request", "read body failed")
+		return
+	}
+	if err := json.Unmarshal(body, &req); err != nil {
+		writeError(c, http.StatusBadRequest, "bad_request", "invalid json")
+		return
+	}
+	if req.ExpiresAt == nil && req.MaxUses == nil && req.JoinApproval == nil {
+		writeError(c, http.StatusBadRequest, "bad_request", "expiresAt, maxUses or joinApproval required")
+		return
+	}
+
+	in := store.InviteSettingsUpdate{JoinApproval: req.JoinApproval}
+	if req.ExpiresAt != nil {
+		v := strings.TrimSpace(*req.ExpiresAt)
+		if v == "" {
+			in.ExpiresSetNull = true
+		} else {
+			t, err := time.Parse(time.RFC3339, v)
+			if err != nil {
+				writeError(c, http.StatusBadRequest, "bad_request", "invalid expiresAt")
+				return
+			}
+			in.ExpiresAt = &t
+		}
+	}
+	if req.MaxUses != nil {
+		if *req.MaxUses < 0 {
+			writeError(c, http.StatusBadRequest, "bad_request", "invalid maxUses")
+			return
+		}
+		in.MaxUses = req.MaxUses
+	}
+
+	inv, err := h.st.UpdateInviteSettings(ctx, id, uid, in)
+	if err != nil {
+		writeInviteError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, map[string]any{"invite": toInviteSettingsDTO(inv)})
+}
+
+// RegenerateInvite 生成新的邀请码，旧邀请码立即失效。
+func (h *ScorebookHandlers) RegenerateInvite(ctx context.Context, c *app.RequestContext) {
+	uid, ok := middleware.UserID(c)
+	if !ok {
+		writeError(c, http.StatusUnauthorized, "unauthorized", "missing user")
+		return
+	}
+	id := strings.TrimSpace(c.Param("id"))
+	if id == "" {
+		writeError(c, http.StatusBadRequest, "bad_request", "id required")
+		return
+	}
+
+	inv, err := h.st.RegenerateInviteCode(ctx, id, uid)
+	if err != nil {
+		writeInviteError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, map[string]any{"invite": toInviteSettingsDTO(inv)})
+}
+
+// ListInviteUses 列出通过邀请码加入的记录（含已失效的邀请码）。
+func (h *ScorebookHandlers) ListInviteUses(ctx context.Context, c *app.RequestContext) {
+	uid, ok := middleware.UserID(c)
+	if !ok {
+		writeError(c, http.StatusUnauthorized, "unauthorized", "missing user")
+		return
+	}
+	id := strings.TrimSpace(c.Param("id"))
+	if id == "" {
+		writeError(c, http.StatusBadRequest, "bad_request", "id required")
+		return
+	}
+
+	limit := int32(20)
+	offset := int32(0)
+	if v := strings.TrimSpace(string(c.Query("limit"))); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 && n <= 200 {
+			limit = int32(n)
+		}
+	}
+	if v := strings.TrimSpace(string(c.Query("offset"))); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n >= 0 {
+			offset = int32(n)
+		}
+	}
+
+	uses, err := h.st.ListInviteUses(ctx, id, uid, limit, offset)
+	if err != nil {
+		writeInviteError(c, err)
+		return
+	}
+	items := make([]map[string]any, 0, len(uses))
+	for _, u := range uses {
+		items = append(items, map[string]any{
+			"code":     u.Code,
+			"userId":   u.UserID,
+			"memberId": u.MemberID,
+			"nickname": u.Nickname,
+			"joinedAt": u.CreatedAt,
+		})
+	}
+	c.JSON(http.StatusOK, map[string]any{"items": items, "limit": limit, "offset": offset})
+}
+
+func writeInviteError(c *app.RequestContext, err error) {
+	switch err {
+	case store.ErrNotFound:
+		writeError(c, http.StatusNotFound, "not_found", "scorebook not found")
+	case store.ErrForbidden:
+		writeError(c, http.StatusForbidden, "forbidden", "only owner or admin can manage invites")
+	case store.ErrInvalidArgument:
+		writeError(c, http.StatusBadRequest, "bad_request", "invalid payload")
+	default:
+		writeError(c, http.StatusInternalServerError, "internal", "db error", err)
+	}
+}
+
+func toInviteSettingsDTO(inv store.InviteSettings) map[string]any {
+	return map[string]any{
+		"scorebookId":  inv.ScorebookID,
+		"code":         inv.Code,
+		"expiresAt":    inv.ExpiresAt,
+		"maxUses":      inv.MaxUses,
+		"uses":         inv.Uses,
+		"joinApproval": inv.JoinApproval,
+	}
+}
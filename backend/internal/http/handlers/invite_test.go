@@ -0,0 +1,87 @@
+package handlers_test
+
+import (
+	"testing"
+	"time"
+)
+
+func TestInviteLifecycle(t *testing.T) {
+	f := newScorebook(t)
+	api := f.api
+	dave := api.login("dave", "Dave")
+	erin := api.login("erin", "Erin")
+
+	api.expectError(403, "forbidden", "GET", f.path("/invite"), f.carol, nil)
+	resp := api.expect(200, "GET", f.path("/invite"), f.alice, nil)
+	if str(resp, "invite", "code") != f.code || num(resp, "invite", "uses") != 2 {
+		t.Fatalf("invite settings: %v", resp)
+	}
+	resp = api.expect(200, "GET", f.path("/invite/uses"), f.alice, nil)
+	if items := list(resp, "items"); len(items) != 2 || str(findBy(t, items, "memberId", f.bobM), "nickname") != "Bob" {
+		t.Fatalf("invite uses: %v", resp)
+	}
+	resp = api.expect(200, "GET", "/api/v1/invites/"+f.code, "", nil)
+	if field(resp, "invite", "bookId") != nil || field(resp, "invite", "scorebookId") != nil {
+		t.Fatalf("invite info leaks scorebook id: %v", resp)
+	}
+
+	// 按 id 加入需要当前邀请码
+	api.expectError(403, "invite_required", "POST", f.path("/join"), erin, map[string]any{})
+	api.expectError(403, "invite_required", "POST", f.path("/join"), erin, map[string]any{"inviteCode": "WRONG123"})
+
+	// 次数用尽
+	api.expectError(400, "bad_request", "PATCH", f.path("/invite"), f.alice, map[string]any{"maxUses": -1})
+	resp = api.expect(200, "PATCH", f.path("/invite"), f.alice, map[string]any{"maxUses": 3})
+	if num(resp, "invite", "maxUses") != 3 {
+		t.Fatalf("set maxUses: %v", resp)
+	}
+	api.expect(200, "POST", "/api/v1/invites/"+f.code+"/join", dave, map[string]any{})
+	resp = api.expect(200, "GET", "/api/v1/invites/"+f.code, "", nil)
+	if boolean(resp, "invite", "usable") || str(resp, "invite", "reason") != "exhausted" || num(resp, "invite", "uses") != 3 {
+		t.Fatalf("exhausted invite info: %v", resp)
+	}
+	api.expectError(410, "invite_exhausted", "POST", "/api/v1/invites/"+f.code+"/join", erin, map[string]any{})
+	api.expectError(410, "invite_exhausted", "POST", f.path("/join"), erin, map[string]any{"inviteCode": f.code})
+	// 已加入的成员重复加入不受限制，也不计次
+	api.expect(200, "POST", "/api/v1/invites/"+f.code+"/join", dave, map[string]any{})
+
+	// 过期（管理员也可以设置）
+	api.expect(200, "PATCH", f.path("/members/"+f.bobM), f.alice, map[string]any{"role": "admin"})
+	api.expect(200, "PATCH", f.path("/invite"), f.bob, map[string]any{"maxUses": 0, "expiresAt": time.Now().Add(time.Hour).Format(time.RFC3339)})
+	api.st.Now = func() time.Time { return time.Now().Add(2 * time.Hour) }
+	resp = api.expect(200, "GET", "/api/v1/invites/"+f.code, "", nil)
+	if str(resp, "invite", "reason") != "expired" {
+		t.Fatalf("expired invite info: %v", resp)
+	}
+	api.expectError(410, "invite_expired", "POST", "/api/v1/invites/"+f.code+"/join", erin, map[string]any{})
+	api.expect(200, "PATCH", f.path("/invite"), f.alice, map[string]any{"expiresAt": ""})
+	api.st.Now = time.Now
+
+	// 重新生成：旧邀请码失效，次数从新邀请码重新计算
+	resp = api.expect(200, "POST", f.path("/invite/regenerate"), f.alice, nil)
+	code := str(resp, "invite", "code")
+	if code == "" || code == f.code || num(resp, "invite", "uses") != 0 {
+		t.Fatalf("regenerate: %v", resp)
+	}
+	api.expectError(410, "invite_regenerated", "GET", "/api/v1/invites/"+f.code, "", nil)
+	api.expectError(410, "invite_regenerated", "POST", "/api/v1/invites/"+f.code+"/join", erin, map[string]any{})
+	api.expectError(410, "invite_regenerated", "POST", f.path("/join"), erin, map[string]any{"inviteCode": f.code})
+	resp = api.expect(200, "POST", "/api/v1/invites/"+code+"/join", erin, map[string]any{})
+	if str(resp, "scorebookId") != f.id {
+		t.Fatalf("join with new code: %v", resp)
+	}
+	resp = api.expect(200, "GET", f.path(""), f.alice, nil)
+	if str(resp, "scorebook", "inviteCode") != code {
+		t.Fatalf("detail invite code: %v", resp)
+	}
+	resp = api.expect(200, "GET", f.path("/invite/uses"), f.alice, nil)
+	if items := list(resp, "items"); len(items) != 4 || str(items[0], "code") != code || str(items[0], "nickname") != "Erin" {
+		t.Fatalf("uses after regenerate: %v", resp)
+	}
+
+	api.expect(200, "POST", f.path("/end"), f.alice, nil)
+	resp = api.expect(200, "GET", "/api/v1/invites/"+code, "", nil)
+	if str(resp, "invite", "reason") != "ended" {
+		t.Fatalf("ended invite info: %v", resp)
+	}
+}
@@ -0,0 +1,186 @@
+package handlers
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/cloudwego/hertz/pkg/app"
+
+	"scorehub/internal/http/middleware"
+	"scorehub/internal/store"
+)
+
+// ListJoinRequests 列出加入申请（仅掌柜或管理员），可按 status 过滤。
+func (h *ScorebookHandlers) ListJoinRequests(ctx context.Context, c *app.RequestContext) {
+	uid, ok := middleware.UserID(c)
+	if !ok {
+		writeError(c, http.StatusUnauthorized, "unauthorized", "missing user")
+		return
+	}
+	id := strings.TrimSpace(c.Param("id"))
+	if id == "" {
+		writeError(c, http.StatusBadRequest, "bad_request", "id required")
+		return
+	}
+
+	limit := int32(20)
+	offset := int32(0)
+	if v := strings.TrimSpace(string(c.Query("limit"))); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 && n <= 200 {
+			limit = int32(n)
+		}
+	}
+	if v := strings.TrimSpace(string(c.Query("offset"))); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n >= 0 {
+			offset = int32(n)
+		}
+	}
+	status := strings.TrimSpace(string(c.Query("status")))
+
+	reqs, err := h.st.ListJoinRequests(ctx, id, uid, status, limit, offset)
+	if err != nil {
+		writeJoinRequestError(c, err)
+		return
+	}
+	items := make([]map[string]any, 0, len(reqs))
+	for _, r := range reqs {
+		items = append(items, toJoinRequestDTO(r))
+	}
+	c.JSON(http.StatusOK, map[string]any{"items": items, "limit": limit, "offset": offset})
+}
+
+// ApproveJoinRequest 批准加入申请，申请人以申请时的身份成为成员。
+func (h *ScorebookHandlers) ApproveJoinRequest(ctx context.Context, c *app.RequestContext) {
+	uid, ok := middleware.UserID(c)
+	if !ok {
+		writeError(c, http.StatusUnauthorized, "unauthorized", "missing user")
+		return
+	}
+	id := strings.TrimSpace(c.Param("id"))
+	requestID := strings.TrimSpace(c.Param("requestId"))
+	if id == "" || requestID == "" {
+		writeError(c, http.StatusBadRequest, "bad_request", "id and requestId required")
+		return
+	}
+
+	r, m, err := h.st.ApproveJoinRequest(ctx, id, uid, requestID)
+	if err != nil {
+		writeJoinRequestError(c, err)
+		return
+	}
+
+	h.broadcastMemberJoined(m)
+	h.notifyManagers(ctx, id, map[string]any{
+		"type": "join_request.resolved",
+		"data": map[string]any{"joinRequest": toJoinRequestDTO(r)},
+	})
+	c.JSON(http.StatusOK, map[string]any{
+		"joinRequest": toJoinRequestDTO(r),
+		"member":      toMemberDTO(m, 0, ""),
+	})
+}
+
+// RejectJoinRequest 拒绝加入申请；申请人之后可以重新申请。
+func (h *ScorebookHandlers) RejectJoinRequest(ctx context.Context, c *app.RequestContext) {
+	uid, ok := middleware.UserID(c)
+	if !ok {
+		writeError(c, http.StatusUnauthorized, "unauthorized", "missing user")
+		return
+	}
+	id := strings.TrimSpace(c.Param("id"))
+	requestID := strings.TrimSpace(c.Param("requestId"))
+	if id == "" || requestID == "" {
+		writeError(c, http.StatusBadRequest, "bad_request", "id and requestId required")
+		return
+	}
+
+	r, err := h.st.RejectJoinRequest(ctx, id, uid, requestID)
+	if err != nil {
+		writeJoinRequestError(c, err)
+		return
+	}
+
+	h.notifyManagers(ctx, id, map[string]any{
+		"type": "join_request.resolved",
+		"data": map[string]any{"joinRequest": toJoinRequestDTO(r)},
+	})
+	c.JSON(http.StatusOK, map[string]any{"joinRequest": toJoinRequestDTO(r)})
+}
+
+// notifyJoinRequested 通知得分簿内的掌柜与管理员有新的加入申请。
+func (h *ScorebookHandlers) notifyJoinRequested(ctx context.Context, r store.JoinRequest) {
+	h.notifyManagers(ctx, r.ScorebookID, map[string]any{
+		"type": "member.join_requested",
+		"data": map[string]any{"joinRequest": toJoinRequestDTO(r)},
+	})
+}
+
+// notifyManagers 只向掌柜与管理员的连接推送 v。加入申请带有申请人的昵称与头像，
+// 不能广播给其他成员和观众，也不写入事件日志（断线重连不补发，重新拉取申请列表即可）。
+func (h *ScorebookHandlers) notifyManagers(ctx context.Context, scorebookID string, v any) {
+	ids, err := h.st.ListManagerUserIDs(ctx, scorebookID)
+	if err != nil {
+		log.Printf("notify managers of scorebook %s: %v", scorebookID, err)
+		return
+	}
+	h.hub.SendTo(scorebookID, ids, v)
+}
+
+func (h *ScorebookHandlers) broadcastMemberJoined(m store.Member) {
+	h.hub.Broadcast(m.ScorebookID, map[string]any{
+		"type": "member.joined",
+		"data": map[string]any{
+			"member": map[string]any{
+				"id":        m.ID,
+				"nickname":  m.Nickname,
+				"avatarUrl": m.AvatarURL,
+				"role":      m.Role,
+				"joinedAt":  m.JoinedAt,
+			},
+		},
+	})
+}
+
+func writeJoinRequestError(c *app.RequestContext, err error) {
+	switch err {
+	case store.ErrNotFound:
+		writeError(c, http.StatusNotFound, "not_found", "join request not found")
+	case store.ErrForbidden:
+		writeError(c, http.StatusForbidden, "forbidden", "only owner or admin can review join requests")
+	case store.ErrInvalidArgument:
+		writeError(c, http.StatusBadRequest, "bad_request", "invalid status")
+	case store.ErrConflict:
+		writeError(c, http.StatusConflict, "conflict", "join request already resolved")
+	case store.ErrScorebookEnded:
+		writeError(c, http.StatusBadRequest, "ended", "scorebook ended")
+	case store.ErrBanned:
+		writeError(c, http.StatusForbidden, "banned", "user is banned from this scorebook")
+	case store.ErrInviteRegenerated:
+		writeError(c, http.StatusGone, "invite_regenerated", "the invite code used for this request has been regenerated")
+	case store.ErrInviteExpired:
+		writeError(c, http.StatusGone, "invite_expired", "the invite code used for this request has expired")
+	case store.ErrInviteExhausted:
+		writeError(c, http.StatusGone, "invite_exhausted", "the invite code used for this request reached its usage limit")
+	default:
+		writeError(c, http.StatusInternalServerError, "internal", "db error", err)
+	}
+}
+
+func toJoinRequestDTO(r store.JoinRequest) map[string]any {
+	return map[string]any{
+		"id":               r.ID,
+		"scorebookId":      r.ScorebookID,
+		"userId":           r.UserID,
+		"nickname":         r.Nickname,
+		"avatarUrl":        r.AvatarURL,
+		"role":             r.Role,
+		"status":           r.Status,
+		"memberId":         r.MemberID,
+		"resolvedByUserId": r.ResolvedByUserID,
+		"createdAt":        r.CreatedAt,
+		"resolvedAt":       r.ResolvedAt,
+	}
+}
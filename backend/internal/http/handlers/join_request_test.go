@@ -0,0 +1,110 @@
+package handlers_test
+
+import "testing"
+
+func TestScorebookJoinApproval(t *testing.T) {
+	f := newScorebook(t)
+	api := f.api
+	dave := api.login("dave", "Dave")
+	erin := api.login("erin", "Erin")
+
+	api.expectError(403, "forbidden", "PATCH", f.path("/invite"), f.carol, map[string]any{"joinApproval": true})
+	resp := api.expect(200, "PATCH", f.path("/invite"), f.alice, map[string]any{"joinApproval": true})
+	if !boolean(resp, "invite", "joinApproval") {
+		t.Fatalf("enable approval: %v", resp)
+	}
+
+	// 已是成员不受影响
+	api.expect(200, "POST", "/api/v1/invites/"+f.code+"/join", f.bob, map[string]any{})
+
+	// 通过邀请码与直接加入都只提交申请，重复提交沿用同一个申请
+	resp = api.expect(202, "POST", "/api/v1/invites/"+f.code+"/join", dave, map[string]any{"nickname": "小D"})
+	daveReq := str(resp, "joinRequest", "id")
+	if str(resp, "scorebookId") != f.id || str(resp, "joinRequest", "status") != "pending" || daveReq == "" {
+		t.Fatalf("dave join request: %v", resp)
+	}
+	resp = api.expect(202, "POST", "/api/v1/invites/"+f.code+"/join", dave, map[string]any{"nickname": "大D", "role": "spectator"})
+	if str(resp, "joinRequest", "id") != daveReq || str(resp, "joinRequest", "nickname") != "大D" {
+		t.Fatalf("repeat join request: %v", resp)
+	}
+	resp = api.expect(202, "POST", f.path("/join"), erin, map[string]any{"inviteCode": f.code})
+	erinReq := str(resp, "joinRequest", "id")
+	api.expectError(404, "not_found", "GET", f.path(""), dave, nil)
+
+	resp = api.expect(200, "GET", "/api/v1/invites/"+f.code, dave, nil)
+	if !boolean(resp, "invite", "joinApproval") || str(resp, "invite", "myJoinRequest", "status") != "pending" {
+		t.Fatalf("pending invite info: %v", resp)
+	}
+	resp = api.expect(200, "GET", "/api/v1/invites/"+f.code, "", nil)
+	if field(resp, "invite", "myJoinRequest") != nil {
+		t.Fatalf("anonymous invite info: %v", resp)
+	}
+
+	api.expectError(403, "forbidden", "GET", f.path("/join_requests"), f.carol, nil)
+	api.expectError(400, "bad_request", "GET", f.path("/join_requests?status=bogus"), f.alice, nil)
+	resp = api.expect(200, "GET", f.path("/join_requests?status=pending"), f.alice, nil)
+	if items := list(resp, "items"); len(items) != 2 || str(items[0], "id") != erinReq {
+		t.Fatalf("pending requests: %v", resp)
+	}
+
+	// 批准：按申请时的身份加入，并计入邀请码使用次数
+	api.expectError(403, "forbidden", "POST", f.path("/join_requests/"+daveReq+"/approve"), f.carol, nil)
+	resp = api.expect(200, "POST", f.path("/join_requests/"+daveReq+"/approve"), f.alice, nil)
+	if str(resp, "joinRequest", "status") != "approved" || str(resp, "member", "role") != "spectator" || str(resp, "member", "nickname") != "大D" {
+		t.Fatalf("approve: %v", resp)
+	}
+	if str(resp, "joinRequest", "memberId") != str(resp, "member", "id") {
+		t.Fatalf("approved member id: %v", resp)
+	}
+	api.expectError(409, "conflict", "POST", f.path("/join_requests/"+daveReq+"/reject"), f.alice, nil)
+	api.expect(200, "GET", f.path(""), dave, nil)
+	resp = api.expect(200, "GET", "/api/v1/invites/"+f.code, dave, nil)
+	if str(resp, "invite", "myJoinRequest", "status") != "approved" || num(resp, "invite", "uses") != 3 {
+		t.Fatalf("approved invite info: %v", resp)
+	}
+
+	// 拒绝后可以重新申请；管理员也能审核
+	api.expect(200, "PATCH", f.path("/members/"+f.bobM), f.alice, map[string]any{"role": "admin"})
+	resp = api.expect(200, "POST", f.path("/join_requests/"+erinReq+"/reject"), f.bob, nil)
+	if str(resp, "joinRequest", "status") != "rejected" {
+		t.Fatalf("reject: %v", resp)
+	}
+	api.expectError(404, "not_found", "GET", f.path(""), erin, nil)
+	resp = api.expect(202, "POST", f.path("/join"), erin, map[string]any{"inviteCode": f.code})
+	if str(resp, "joinRequest", "id") == erinReq {
+		t.Fatalf("new request after reject: %v", resp)
+	}
+
+	// 关闭审核后直接加入，待审核的申请随之完成
+	api.expect(200, "PATCH", f.path("/invite"), f.alice, map[string]any{"joinApproval": false})
+	api.expect(200, "POST", f.path("/join"), erin, map[string]any{"inviteCode": f.code})
+	resp = api.expect(200, "GET", f.path("/join_requests?status=pending"), f.alice, nil)
+	if items := list(resp, "items"); len(items) != 0 {
+		t.Fatalf("pending after approval disabled: %v", resp)
+	}
+}
+
+func TestScorebookJoinApprovalRespectsInviteLimits(t *testing.T) {
+	f := newScorebook(t)
+	api := f.api
+	dave := api.login("dave", "Dave")
+	erin := api.login("erin", "Erin")
+
+	// bob、carol 已用掉 2 次；再允许 1 次，但两个人都提交了申请
+	api.expect(200, "PATCH", f.path("/invite"), f.alice, map[string]any{"joinApproval": true, "maxUses": 3})
+	daveReq := str(api.expect(202, "POST", "/api/v1/invites/"+f.code+"/join", dave, map[string]any{}), "joinRequest", "id")
+	erinReq := str(api.expect(202, "POST", "/api/v1/invites/"+f.code+"/join", erin, map[string]any{}), "joinRequest", "id")
+
+	api.expect(200, "POST", f.path("/join_requests/"+daveReq+"/approve"), f.alice, nil)
+	api.expectError(410, "invite_exhausted", "POST", f.path("/join_requests/"+erinReq+"/approve"), f.alice, nil)
+	resp := api.expect(200, "GET", f.path("/join_requests?status=pending"), f.alice, nil)
+	if items := list(resp, "items"); len(items) != 1 || str(items[0], "id") != erinReq {
+		t.Fatalf("pending after exhausted approval: %v", resp)
+	}
+
+	// 申请所用的邀请码被重新生成后也不能再批准
+	api.expect(200, "PATCH", f.path("/invite"), f.alice, map[string]any{"maxUses": 0})
+	api.expect(200, "POST", f.path("/invite/regenerate"), f.alice, nil)
+	api.expectError(410, "invite_regenerated", "POST", f.path("/join_requests/"+erinReq+"/approve"), f.alice, nil)
+	api.expectError(404, "not_found", "GET", f.path(""), erin, nil)
+}
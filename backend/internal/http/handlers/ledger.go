@@ -10,20 +10,33 @@ import (
 	"time"
 
 	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/hertz-contrib/websocket"
 
-	appauth "scorehub/internal/auth"
 	appconfig "scorehub/internal/config"
 	"scorehub/internal/http/middleware"
+	"scorehub/internal/realtime"
 	"scorehub/internal/store"
 )
 
 type LedgerHandlers struct {
-	cfg appconfig.Config
-	st  *store.Store
+	cfg   appconfig.Config
+	users store.UserRepo
+	st    store.LedgerRepo
+	hub   *realtime.Hub
+
+	upgrader websocket.HertzUpgrader
 }
 
-func NewLedgerHandlers(cfg appconfig.Config, st *store.Store) *LedgerHandlers {
-	return &LedgerHandlers{cfg: cfg, st: st}
+func NewLedgerHandlers(cfg appconfig.Config, users store.UserRepo, st store.LedgerRepo, hub *realtime.Hub) *LedgerHandlers {
+	return &LedgerHandlers{
+		cfg:   cfg,
+		users: users,
+		st:    st,
+		hub:   hub,
+		upgrader: websocket.HertzUpgrader{
+			CheckOrigin: func(ctx *app.RequestContext) bool { return true },
+		},
+	}
 }
 
 type createLedgerRequest struct {
@@ -64,7 +77,7 @@ func (h *LedgerHandlers) CreateLedger(ctx context.Context, c *app.RequestContext
 		name = time.Now().Format("2006-01-02 15:04") + " 记账"
 	}
 
-	user, err := h.st.GetUserByID(ctx, uid)
+	user, err := h.users.GetUserByID(ctx, uid)
 	if err != nil {
 		writeError(c, http.StatusUnauthorized, "unauthorized", "user not found")
 		return
@@ -119,6 +132,7 @@ func (h *LedgerHandlers) ListLedgers(ctx context.Context, c *app.RequestContext)
 			"endedAt":     it.EndedAt,
 			"memberCount": it.MemberCount,
 			"recordCount": it.RecordCount,
+			"role":        it.Role,
 		})
 	}
 
@@ -155,11 +169,24 @@ func (h *LedgerHandlers) GetLedgerDetail(ctx context.Context, c *app.RequestCont
 		return
 	}
 
-	uid, ok := optionalUserID(c, h.cfg)
-	isOwner := ok && ledger.CreatedByUserID == uid
+	// 备注只给账本主人和记账员看
+	canSeeNotes := false
+	uid, ok, err := h.optionalUserID(ctx, c)
+	if err != nil {
+		writeError(c, http.StatusInternalServerError, "internal", "auth check failed", err)
+		return
+	}
+	if ok {
+		role, err := h.st.GetLedgerRole(ctx, id, uid)
+		if err != nil && err != store.ErrNotFound {
+			writeError(c, http.StatusInternalServerError, "internal", "db error", err)
+			return
+		}
+		canSeeNotes = role != ""
+	}
 
 	remarkByMember := map[string]string{}
-	if isOwner {
+	if canSeeNotes {
 		for _, m := range members {
 			remark := strings.TrimSpace(m.Remark)
 			if remark != "" {
@@ -170,14 +197,14 @@ func (h *LedgerHandlers) GetLedgerDetail(ctx context.Context, c *app.RequestCont
 
 	var memOut []any
 	for _, m := range members {
-		if !isOwner {
+		if !canSeeNotes {
 			m.Remark = ""
 		}
 		memOut = append(memOut, toLedgerMemberDTO(m))
 	}
 	var recOut []any
 	for _, r := range records {
-		if !isOwner {
+		if !canSeeNotes {
 			if r.Type == "remark" {
 				continue
 			}
@@ -202,27 +229,32 @@ func (h *LedgerHandlers) GetLedgerDetail(ctx context.Context, c *app.RequestCont
 	})
 }
 
-func optionalUserID(c *app.RequestContext, cfg appconfig.Config) (int64, bool) {
+// optionalUserID 解析可选的登录态：公开接口带了有效令牌时识别出当前用户。
+// 无效或已注销的令牌按未登录处理；校验会话本身失败时返回错误。
+func (h *LedgerHandlers) optionalUserID(ctx context.Context, c *app.RequestContext) (int64, bool, error) {
 	if uid, ok := middleware.UserID(c); ok {
-		return uid, true
+		return uid, true, nil
 	}
 	authHeader := strings.TrimSpace(string(c.GetHeader("Authorization")))
 	if authHeader == "" {
-		return 0, false
+		return 0, false, nil
 	}
 	const prefix = "Bearer "
 	if !strings.HasPrefix(authHeader, prefix) {
-		return 0, false
+		return 0, false, nil
 	}
 	token := strings.TrimSpace(authHeader[len(prefix):])
 	if token == "" {
-		return 0, false
+		return 0, false, nil
 	}
-	uid, err := appauth.ParseToken([]byte(cfg.TokenSecret), token)
+	claims, err := middleware.Authenticate(ctx, h.cfg, h.users, token)
 	if err != nil {
-		return 0, false
+		if middleware.IsAuthError(err) {
+			return 0, false, nil
+		}
+		return 0, false, err
 	}
-	return uid, true
+	return claims.UserID, true, nil
 }
 
 func (h *LedgerHandlers) UpdateLedger(ctx context.Context, c *app.RequestContext) {
@@ -278,6 +310,7 @@ func (h *LedgerHandlers) UpdateLedger(ctx context.Context, c *app.RequestContext
 		}
 	}
 
+	h.broadcast(ledger.ID, "ledger.updated", toLedgerDTO(ledger))
 	c.JSON(http.StatusOK, map[string]any{"ledger": toLedgerDTO(ledger)})
 }
 
@@ -333,6 +366,7 @@ func (h *LedgerHandlers) BindLedgerMember(ctx context.Context, c *app.RequestCon
 		}
 	}
 
+	h.broadcast(id, "member.updated", toLedgerMemberDTO(member))
 	c.JSON(http.StatusOK, map[string]any{"member": toLedgerMemberDTO(member)})
 }
 
@@ -439,14 +473,16 @@ func (h *LedgerHandlers) AddLedgerMember(ctx context.Context, c *app.RequestCont
 		}
 	}
 
+	h.broadcast(id, "member.added", toLedgerMemberDTO(m))
 	c.JSON(http.StatusOK, map[string]any{"member": toLedgerMemberDTO(m)})
 }
 
 type addLedgerRecordRequest struct {
-	MemberID string  `json:"memberId"`
-	Type     string  `json:"type"`
-	Amount   float64 `json:"amount"`
-	Note     string  `json:"note"`
+	MemberID   string  `json:"memberId"`
+	Type       string  `json:"type"`
+	Amount     float64 `json:"amount"`
+	Note       string  `json:"note"`
+	CategoryID string  `json:"categoryId"`
 }
 
 type updateLedgerMemberRequest struct {
@@ -499,7 +535,7 @@ func (h *LedgerHandlers) AddLedgerRecord(ctx context.Context, c *app.RequestCont
 		return
 	}
 
-	r, err := h.st.AddLedgerRecord(ctx, id, uid, strings.TrimSpace(req.MemberID), t, amount, strings.TrimSpace(req.Note))
+	r, err := h.st.AddLedgerRecord(ctx, id, uid, strings.TrimSpace(req.MemberID), t, amount, strings.TrimSpace(req.Note), strings.TrimSpace(req.CategoryID))
 	if err != nil {
 		switch err {
 		case store.ErrForbidden:
@@ -520,6 +556,7 @@ func (h *LedgerHandlers) AddLedgerRecord(ctx context.Context, c *app.RequestCont
 		}
 	}
 
+	h.broadcast(id, "record.created", toLedgerRecordDTO(r))
 	c.JSON(http.StatusOK, map[string]any{"record": toLedgerRecordDTO(r)})
 }
 
@@ -568,6 +605,7 @@ func (h *LedgerHandlers) UpdateLedgerMember(ctx context.Context, c *app.RequestC
 		}
 	}
 
+	h.broadcast(ledgerID, "member.updated", toLedgerMemberDTO(m))
 	c.JSON(http.StatusOK, map[string]any{"member": toLedgerMemberDTO(m)})
 }
 
@@ -598,6 +636,7 @@ func (h *LedgerHandlers) EndLedger(ctx context.Context, c *app.RequestContext) {
 		}
 	}
 
+	h.broadcast(ledger.ID, "ledger.ended", toLedgerDTO(ledger))
 	c.JSON(http.StatusOK, map[string]any{"ledger": toLedgerDTO(ledger)})
 }
 
@@ -630,9 +669,18 @@ func (h *LedgerHandlers) DeleteLedger(ctx context.Context, c *app.RequestContext
 		return
 	}
 
+	h.broadcast(ledger.ID, "ledger.deleted", map[string]any{"id": ledger.ID})
 	c.JSON(http.StatusOK, map[string]any{"ledger": toLedgerDTO(ledger)})
 }
 
+// broadcast 把账本变更推送给连在 /ws/ledgers/:id 上的主人与记账员。
+func (h *LedgerHandlers) broadcast(ledgerID, typ string, data any) {
+	if h.hub == nil {
+		return
+	}
+	h.hub.Broadcast(ledgerID, map[string]any{"type": typ, "data": data})
+}
+
 func toLedgerDTO(sb store.Scorebook) map[string]any {
 	return map[string]any{
 		"id":              sb.ID,
@@ -666,14 +714,25 @@ func toLedgerMemberDTO(m store.LedgerMember) map[string]any {
 }
 
 func toLedgerRecordDTO(r store.LedgerRecord) map[string]any {
+	var categoryID any
+	if r.CategoryID != "" {
+		categoryID = r.CategoryID
+	}
+	var createdBy any
+	if r.CreatedByUserID != nil {
+		createdBy = *r.CreatedByUserID
+	}
 	return map[string]any{
-		"id":           r.ID,
-		"memberId":     r.MemberID,
-		"fromMemberId": r.FromMemberID,
-		"toMemberId":   r.ToMemberID,
-		"type":         r.Type,
-		"amount":       r.Amount,
-		"note":         r.Note,
-		"createdAt":    r.CreatedAt,
+		"id":                r.ID,
+		"memberId":          r.MemberID,
+		"fromMemberId":      r.FromMemberID,
+		"toMemberId":        r.ToMemberID,
+		"type":              r.Type,
+		"amount":            r.Amount,
+		"note":              r.Note,
+		"categoryId":        categoryID,
+		"createdByUserId":   createdBy,
+		"createdByNickname": r.CreatedByNickname,
+		"createdAt":         r.CreatedAt,
 	}
 }
@@ -0,0 +1,220 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/cloudwego/hertz/pkg/app"
+
+	"scorehub/internal/http/middleware"
+	"scorehub/internal/store"
+)
+
+// maxLedgerCategoryNameLen 为分类名称的长度上限（字符数）。
+const maxLedgerCategoryNameLen = 20
+
+type ledgerCategoryRequest struct {
+	Name string `json:"name"`
+}
+
+// ListLedgerCategories 列出账本的分类（按创建顺序），仅账本创建者。
+func (h *LedgerHandlers) ListLedgerCategories(ctx context.Context, c *app.RequestContext) {
+	uid, ok := middleware.UserID(c)
+	if !ok {
+		writeError(c, http.StatusUnauthorized, "unauthorized", "missing user")
+		return
+	}
+	id := strings.TrimSpace(c.Param("id"))
+	if id == "" {
+		writeError(c, http.StatusBadRequest, "bad_request", "id required")
+		return
+	}
+
+	categories, err := h.st.ListLedgerCategories(ctx, id, uid)
+	if err != nil {
+		writeLedgerCategoryError(c, err)
+		return
+	}
+	items := make([]map[string]any, 0, len(categories))
+	for _, cat := range categories {
+		items = append(items, toLedgerCategoryDTO(cat))
+	}
+	c.JSON(http.StatusOK, map[string]any{"items": items})
+}
+
+func (h *LedgerHandlers) CreateLedgerCategory(ctx context.Context, c *app.RequestContext) {
+	uid, ok := middleware.UserID(c)
+	if !ok {
+		writeError(c, http.StatusUnauthorized, "unauthorized", "missing user")
+		return
+	}
+	id := strings.TrimSpace(c.Param("id"))
+	if id == "" {
+		writeError(c, http.StatusBadRequest, "bad_request", "id required")
+		return
+	}
+	name, ok := readLedgerCategoryName(c)
+	if !ok {
+		return
+	}
+
+	cat, err := h.st.CreateLedgerCategory(ctx, id, uid, name)
+	if err != nil {
+		writeLedgerCategoryError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, map[string]any{"category": toLedgerCategoryDTO(cat)})
+}
+
+func (h *LedgerHandlers) UpdateLedgerCategory(ctx context.Context, c *app.RequestContext) {
+	uid, ok := middleware.UserID(c)
+	if !ok {
+		writeError(c, http.StatusUnauthorized, "unauthorized", "missing user")
+		return
+	}
+	id := strings.TrimSpace(c.Param("id"))
+	categoryID := strings.TrimSpace(c.Param("categoryId"))
+	if id == "" || categoryID == "" {
+		writeError(c, http.StatusBadRequest, "bad_request", "id required")
+		return
+	}
+	name, ok := readLedgerCategoryName(c)
+	if !ok {
+		return
+	}
+
+	cat, err := h.st.UpdateLedgerCategory(ctx, id, uid, categoryID, name)
+	if err != nil {
+		writeLedgerCategoryError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, map[string]any{"category": toLedgerCategoryDTO(cat)})
+}
+
+// DeleteLedgerCategory 删除分类，原属该分类的记录变为未分类。
+func (h *LedgerHandlers) DeleteLedgerCategory(ctx context.Context, c *app.RequestContext) {
+	uid, ok := middleware.UserID(c)
+	if !ok {
+		writeError(c, http.StatusUnauthorized, "unauthorized", "missing user")
+		return
+	}
+	id := strings.TrimSpace(c.Param("id"))
+	categoryID := strings.TrimSpace(c.Param("categoryId"))
+	if id == "" || categoryID == "" {
+		writeError(c, http.StatusBadRequest, "bad_request", "id required")
+		return
+	}
+
+	if err := h.st.DeleteLedgerCategory(ctx, id, uid, categoryID); err != nil {
+		writeLedgerCategoryError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, map[string]any{"ok": true})
+}
+
+// GetLedgerSummary 汇总账本：总计、按类型、按分类、按成员，仅账本创建者。
+func (h *LedgerHandlers) GetLedgerSummary(ctx context.Context, c *app.RequestContext) {
+	uid, ok := middleware.UserID(c)
+	if !ok {
+		writeError(c, http.StatusUnauthorized, "unauthorized", "missing user")
+		return
+	}
+	id := strings.TrimSpace(c.Param("id"))
+	if id == "" {
+		writeError(c, http.StatusBadRequest, "bad_request", "id required")
+		return
+	}
+
+	sum, err := h.st.GetLedgerSummary(ctx, id, uid)
+	if err != nil {
+		writeLedgerCategoryError(c, err)
+		return
+	}
+
+	byType := make([]map[string]any, 0, len(sum.ByType))
+	for _, t := range sum.ByType {
+		byType = append(byType, map[string]any{"type": t.Type, "records": t.Records, "amount": t.Amount})
+	}
+	byCategory := make([]map[string]any, 0, len(sum.ByCategory))
+	for _, t := range sum.ByCategory {
+		var categoryID any
+		if t.CategoryID != "" {
+			categoryID = t.CategoryID
+		}
+		item := toLedgerTotalsDTO(t.LedgerTotals)
+		item["categoryId"] = categoryID
+		item["name"] = t.Name
+		byCategory = append(byCategory, item)
+	}
+	byMember := make([]map[string]any, 0, len(sum.ByMember))
+	for _, t := range sum.ByMember {
+		item := toLedgerTotalsDTO(t.LedgerTotals)
+		item["memberId"] = t.MemberID
+		item["nickname"] = t.Nickname
+		byMember = append(byMember, item)
+	}
+
+	c.JSON(http.StatusOK, map[string]any{
+		"total":      toLedgerTotalsDTO(sum.Total),
+		"byType":     byType,
+		"byCategory": byCategory,
+		"byMember":   byMember,
+	})
+}
+
+func readLedgerCategoryName(c *app.RequestContext) (string, bool) {
+	var req ledgerCategoryRequest
+	body, err := c.Body()
+	if err != nil {
+		writeError(c, http.StatusBadRequest, "bad_request", "read body failed")
+		return "", false
+	}
+	if err := json.Unmarshal(body, &req); err != nil {
+		writeError(c, http.StatusBadRequest, "bad_request", "invalid json")
+		return "", false
+	}
+	name := strings.TrimSpace(req.Name)
+	if name == "" || utf8.RuneCountInString(name) > maxLedgerCategoryNameLen {
+		writeError(c, http.StatusBadRequest, "bad_request", "invalid name")
+		return "", false
+	}
+	return name, true
+}
+
+func writeLedgerCategoryError(c *app.RequestContext, err error) {
+	switch err {
+	case store.ErrNotFound:
+		writeError(c, http.StatusNotFound, "not_found", "not found")
+	case store.ErrForbidden:
+		writeError(c, http.StatusForbidden, "forbidden", "no permission")
+	case store.ErrScorebookEnded:
+		writeError(c, http.StatusBadRequest, "ended", "ledger ended")
+	case store.ErrConflict:
+		writeError(c, http.StatusConflict, "conflict", "category name exists")
+	case store.ErrInvalidArgument:
+		writeError(c, http.StatusBadRequest, "bad_request", "invalid name")
+	default:
+		writeError(c, http.StatusInternalServerError, "internal", "db error", err)
+	}
+}
+
+func toLedgerCategoryDTO(cat store.LedgerCategory) map[string]any {
+	return map[string]any{
+		"id":        cat.ID,
+		"name":      cat.Name,
+		"createdAt": cat.CreatedAt,
+		"updatedAt": cat.UpdatedAt,
+	}
+}
+
+func toLedgerTotalsDTO(t store.LedgerTotals) map[string]any {
+	return map[string]any{
+		"records": t.Records,
+		"income":  t.Income,
+		"expense": t.Expense,
+		"net":     t.Net,
+	}
+}
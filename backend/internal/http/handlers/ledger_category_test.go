@@ -0,0 +1,85 @@
+package handlers_test
+
+import "testing"
+
+func TestLedgerCategoriesAndSummary(t *testing.T) {
+	api := newTestAPI(t)
+	owner := api.login("owner", "店主")
+	guest := api.login("guest", "来宾")
+
+	resp := api.expect(200, "POST", "/api/v1/ledgers", owner, map[string]any{"name": "婚礼"})
+	path := "/api/v1/ledgers/" + str(resp, "ledger", "id")
+	zhang := str(api.expect(200, "POST", path+"/members", owner, map[string]any{"nickname": "张三"}), "member", "id")
+	li := str(api.expect(200, "POST", path+"/members", owner, map[string]any{"nickname": "李四"}), "member", "id")
+	hotel := str(api.expect(200, "POST", path+"/members", owner, map[string]any{"nickname": "酒店"}), "member", "id")
+
+	gift := str(api.expect(200, "POST", path+"/categories", owner, map[string]any{"name": "礼金"}), "category", "id")
+	catering := str(api.expect(200, "POST", path+"/categories", owner, map[string]any{"name": " 餐饮 "}), "category", "id")
+	venue := str(api.expect(200, "POST", path+"/categories", owner, map[string]any{"name": "场地"}), "category", "id")
+	api.expectError(409, "conflict", "POST", path+"/categories", owner, map[string]any{"name": "礼金"})
+	api.expectError(400, "bad_request", "POST", path+"/categories", owner, map[string]any{"name": " "})
+	api.expectError(403, "forbidden", "POST", path+"/categories", guest, map[string]any{"name": "其他"})
+	api.expectError(409, "conflict", "PATCH", path+"/categories/"+venue, owner, map[string]any{"name": "餐饮"})
+	resp = api.expect(200, "PATCH", path+"/categories/"+venue, owner, map[string]any{"name": "场地布置"})
+	if str(resp, "category", "name") != "场地布置" {
+		t.Fatalf("rename category: %v", resp)
+	}
+	items := list(api.expect(200, "GET", path+"/categories", owner, nil), "items")
+	if len(items) != 3 || str(items[1], "name") != "餐饮" {
+		t.Fatalf("categories: %v", items)
+	}
+	api.expectError(403, "forbidden", "GET", path+"/categories", guest, nil)
+
+	resp = api.expect(200, "POST", path+"/records", owner, map[string]any{"memberId": zhang, "type": "income", "amount": 888, "categoryId": gift})
+	if str(resp, "record", "categoryId") != gift {
+		t.Fatalf("record category: %v", resp)
+	}
+	api.expect(200, "POST", path+"/records", owner, map[string]any{"memberId": li, "type": "income", "amount": 600.5, "categoryId": gift})
+	api.expect(200, "POST", path+"/records", owner, map[string]any{"memberId": hotel, "type": "expense", "amount": 3000, "categoryId": catering})
+	api.expect(200, "POST", path+"/records", owner, map[string]any{"memberId": hotel, "type": "expense", "amount": 500, "categoryId": venue})
+	resp = api.expect(200, "POST", path+"/records", owner, map[string]any{"memberId": zhang, "type": "expense", "amount": 100})
+	if field(resp, "record", "categoryId") != nil {
+		t.Fatalf("uncategorized record: %v", resp)
+	}
+	api.expectError(400, "bad_request", "POST", path+"/records", owner, map[string]any{"memberId": zhang, "type": "income", "amount": 1, "categoryId": "missing"})
+
+	resp = api.expect(200, "GET", path+"/summary", owner, nil)
+	total := obj(resp, "total")
+	if num(total, "records") != 5 || num(total, "income") != 1488.5 || num(total, "expense") != 3600 || num(total, "net") != -2111.5 {
+		t.Fatalf("summary total: %v", total)
+	}
+	byType := list(resp, "byType")
+	if str(byType[0], "type") != "income" || num(byType[0], "records") != 2 || num(byType[1], "amount") != 3600 {
+		t.Fatalf("summary by type: %v", byType)
+	}
+	byCategory := list(resp, "byCategory")
+	if len(byCategory) != 4 || str(byCategory[0], "name") != "礼金" || num(byCategory[0], "income") != 1488.5 ||
+		num(byCategory[1], "expense") != 3000 || field(byCategory[3], "categoryId") != nil || num(byCategory[3], "expense") != 100 {
+		t.Fatalf("summary by category: %v", byCategory)
+	}
+	byMember := list(resp, "byMember")
+	if len(byMember) != 3 || str(byMember[0], "memberId") != hotel || num(byMember[0], "net") != -3500 {
+		t.Fatalf("summary by member: %v", byMember)
+	}
+	if m := findBy(t, byMember, "memberId", zhang); num(m, "records") != 2 || num(m, "net") != 788 || str(m, "nickname") != "张三" {
+		t.Fatalf("summary member: %v", m)
+	}
+	api.expectError(403, "forbidden", "GET", path+"/summary", guest, nil)
+	api.expectError(404, "not_found", "GET", "/api/v1/ledgers/missing/summary", owner, nil)
+
+	// 删除分类后其记录归入未分类
+	api.expect(200, "DELETE", path+"/categories/"+venue, owner, nil)
+	api.expectError(404, "not_found", "DELETE", path+"/categories/"+venue, owner, nil)
+	byCategory = list(api.expect(200, "GET", path+"/summary", owner, nil), "byCategory")
+	if len(byCategory) != 3 || num(byCategory[2], "expense") != 600 || num(byCategory[2], "records") != 2 {
+		t.Fatalf("summary after delete: %v", byCategory)
+	}
+	records := list(api.expect(200, "GET", path, owner, nil), "records")
+	if r := findBy(t, records, "amount", "500"); field(r, "categoryId") != nil {
+		t.Fatalf("record keeps deleted category: %v", r)
+	}
+
+	api.expect(200, "POST", path+"/end", owner, nil)
+	api.expectError(400, "ended", "POST", path+"/categories", owner, map[string]any{"name": "其他"})
+	api.expect(200, "GET", path+"/summary", owner, nil)
+}
@@ -0,0 +1,322 @@
+package handlers
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/cloudwego/hertz/pkg/app"
+
+	"scorehub/internal/http/middleware"
+	"scorehub/internal/sheet"
+	"scorehub/internal/store"
+)
+
+const (
+	// maxImportFileSize 为导入文件大小上限
+	maxImportFileSize = 4 << 20
+	// maxImportRows 为一次导入的数据行上限
+	maxImportRows = 5000
+)
+
+// 导入文件的列：表头可用中文或英文，姓名与金额必填，备注与类型可省略（类型默认收入）。
+var importColumnAliases = map[string][]string{
+	"name":   {"姓名", "名字", "昵称", "name", "nickname"},
+	"remark": {"备注", "remark"},
+	"type":   {"类型", "收支", "type"},
+	"amount": {"金额", "礼金", "amount"},
+}
+
+// exportLedgerImportTable 是与导入相同格式的导出（layout=import）。
+var exportLedgerImportTable = sheet.Table{Key: "rows", Columns: []sheet.Column{
+	{Key: "name", Title: "姓名"}, {Key: "remark", Title: "备注"}, {Key: "type", Title: "类型"}, {Key: "amount", Title: "金额"},
+}}
+
+// ImportLedger 从 CSV / XLSX 批量导入礼金记录。默认只预览（解析结果、错误与重名），
+// commit=true 时在一个事务内全部写入；任何一行有错误都不会写入。
+func (h *LedgerHandlers) ImportLedger(ctx context.Context, c *app.RequestContext) {
+	uid, ok := middleware.UserID(c)
+	if !ok {
+		writeError(c, http.StatusUnauthorized, "unauthorized", "missing user")
+		return
+	}
+	id := strings.TrimSpace(c.Param("id"))
+	if id == "" {
+		writeError(c, http.StatusBadRequest, "bad_request", "id required")
+		return
+	}
+
+	format := strings.ToLower(strings.TrimSpace(string(c.Query("format"))))
+	if format != "" && format != sheet.FormatCSV && format != sheet.FormatXLSX {
+		writeError(c, http.StatusBadRequest, "bad_request", "format must be csv or xlsx")
+		return
+	}
+	separate := false
+	switch strings.TrimSpace(string(c.Query("mode"))) {
+	case "", "merge":
+	case "separate":
+		separate = true
+	default:
+		writeError(c, http.StatusBadRequest, "bad_request", "mode must be merge or separate")
+		return
+	}
+	commit := strings.TrimSpace(string(c.Query("commit"))) == "true"
+
+	data, filename, err := importFile(c)
+	if err != nil {
+		writeError(c, http.StatusBadRequest, "bad_request", err.Error())
+		return
+	}
+	if format == "" {
+		switch strings.ToLower(filepath.Ext(filename)) {
+		case ".csv":
+			format = sheet.FormatCSV
+		case ".xlsx":
+			format = sheet.FormatXLSX
+		}
+	}
+	cells, err := sheet.Read(format, data)
+	if err != nil {
+		writeError(c, http.StatusBadRequest, "bad_request", "cannot parse file")
+		return
+	}
+	rows, ok := parseLedgerImport(cells)
+	if !ok {
+		writeError(c, http.StatusBadRequest, "bad_request", "header row with name and amount columns required")
+		return
+	}
+	if len(rows) == 0 {
+		writeError(c, http.StatusBadRequest, "bad_request", "no rows")
+		return
+	}
+	if len(rows) > maxImportRows {
+		writeError(c, http.StatusBadRequest, "bad_request", "too many rows")
+		return
+	}
+
+	if commit {
+		plan, err := h.st.ImportLedgerRecords(ctx, id, uid, rows, separate)
+		if err != nil {
+			if err == store.ErrInvalidArgument {
+				c.JSON(http.StatusBadRequest, map[string]any{
+					"error": map[string]any{"code": "invalid_rows", "message": "some rows are invalid"},
+					"plan":  toImportPlanDTO(plan),
+				})
+				return
+			}
+			writeLedgerImportError(c, err)
+			return
+		}
+		h.broadcast(id, "records.imported", map[string]any{"records": plan.Records, "newMembers": plan.NewMembers})
+		c.JSON(http.StatusOK, map[string]any{"committed": true, "plan": toImportPlanDTO(plan)})
+		return
+	}
+
+	ledger, members, _, err := h.st.GetLedgerDetail(ctx, id, 0, 0)
+	if err != nil {
+		writeLedgerImportError(c, err)
+		return
+	}
+	if ledger.CreatedByUserID != uid {
+		writeLedgerImportError(c, store.ErrForbidden)
+		return
+	}
+	if ledger.Status != "recording" {
+		writeLedgerImportError(c, store.ErrScorebookEnded)
+		return
+	}
+	ownerMemberID := ""
+	for _, m := range members {
+		if m.Role == "owner" {
+			ownerMemberID = m.ID
+			break
+		}
+	}
+	plan := store.PlanLedgerImport(members, ownerMemberID, rows, separate)
+	c.JSON(http.StatusOK, map[string]any{"committed": false, "plan": toImportPlanDTO(plan)})
+}
+
+// importFile reads the upload: the multipart field "file", or the raw body.
+func importFile(c *app.RequestContext) ([]byte, string, error) {
+	if fh, err := c.FormFile("file"); err == nil {
+		if fh.Size > maxImportFileSize {
+			return nil, "", errImportTooLarge
+		}
+		f, err := fh.Open()
+		if err != nil {
+			return nil, "", errImportRead
+		}
+		defer f.Close()
+		data, err := io.ReadAll(io.LimitReader(f, maxImportFileSize+1))
+		if err != nil {
+			return nil, "", errImportRead
+		}
+		if len(data) > maxImportFileSize {
+			return nil, "", errImportTooLarge
+		}
+		return data, fh.Filename, nil
+	}
+	body, err := c.Body()
+	if err != nil {
+		return nil, "", errImportRead
+	}
+	if len(body) == 0 {
+		return nil, "", errImportEmpty
+	}
+	if len(body) > maxImportFileSize {
+		return nil, "", errImportTooLarge
+	}
+	return body, "", nil
+}
+
+type importError string
+
+func (e importError) Error() string { return string(e) }
+
+const (
+	errImportRead     = importError("read file failed")
+	errImportEmpty    = importError("file required")
+	errImportTooLarge = importError("file too large")
+)
+
+// parseLedgerImport finds the header row (within the first 10 rows) and parses the
+// data rows below it; blank rows are skipped. It reports false without a header.
+func parseLedgerImport(cells [][]string) ([]store.LedgerImportRow, bool) {
+	header := -1
+	cols := map[string]int{}
+	for i := 0; i < len(cells) && i < 10 && header < 0; i++ {
+		found := map[string]int{}
+		for j, cell := range cells[i] {
+			cell = strings.ToLower(strings.TrimSpace(cell))
+			for key, aliases := range importColumnAliases {
+				for _, a := range aliases {
+					if _, ok := found[key]; !ok && cell == a {
+						found[key] = j
+					}
+				}
+			}
+		}
+		if _, ok := found["name"]; !ok {
+			continue
+		}
+		if _, ok := found["amount"]; !ok {
+			continue
+		}
+		header, cols = i, found
+	}
+	if header < 0 {
+		return nil, false
+	}
+
+	cell := func(row []string, key string) string {
+		j, ok := cols[key]
+		if !ok || j >= len(row) {
+			return ""
+		}
+		return strings.TrimSpace(row[j])
+	}
+	var rows []store.LedgerImportRow
+	for i := header + 1; i < len(cells); i++ {
+		if strings.TrimSpace(strings.Join(cells[i], "")) == "" {
+			continue
+		}
+		r := store.LedgerImportRow{
+			Line:   i + 1,
+			Name:   cell(cells[i], "name"),
+			Remark: cell(cells[i], "remark"),
+		}
+		var ok bool
+		r.Type, ok = parseImportType(cell(cells[i], "type"))
+		amount, amountOK := parseImportAmount(cell(cells[i], "amount"))
+		r.Amount = amount
+		switch {
+		case r.Name == "":
+			r.Error = "name_required"
+		case !ok:
+			r.Error = "invalid_type"
+		case !amountOK:
+			r.Error = "invalid_amount"
+		}
+		rows = append(rows, r)
+	}
+	return rows, true
+}
+
+func parseImportType(v string) (string, bool) {
+	switch strings.ToLower(v) {
+	case "", "income", "收入", "收":
+		return "income", true
+	case "expense", "支出", "支", "出":
+		return "expense", true
+	default:
+		return "", false
+	}
+}
+
+// parseImportAmount accepts amounts like "888", "1,000.50" or "￥200元".
+func parseImportAmount(v string) (float64, bool) {
+	v = strings.NewReplacer(",", "", "，", "", "¥", "", "￥", "", "元", "", " ", "").Replace(v)
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		return 0, false
+	}
+	return store.NormalizeAmount(f)
+}
+
+func writeLedgerImportError(c *app.RequestContext, err error) {
+	switch err {
+	case store.ErrNotFound:
+		writeError(c, http.StatusNotFound, "not_found", "ledger not found")
+	case store.ErrForbidden:
+		writeError(c, http.StatusForbidden, "forbidden", "only owner can import")
+	case store.ErrScorebookEnded:
+		writeError(c, http.StatusBadRequest, "ended", "ledger ended")
+	default:
+		writeError(c, http.StatusInternalServerError, "internal", "db error", err)
+	}
+}
+
+func toImportPlanDTO(p store.LedgerImportPlan) map[string]any {
+	rows := make([]map[string]any, 0, len(p.Rows))
+	for _, r := range p.Rows {
+		warnings := r.Warnings
+		if warnings == nil {
+			warnings = []string{}
+		}
+		rows = append(rows, map[string]any{
+			"line":      r.Line,
+			"name":      r.Name,
+			"remark":    r.Remark,
+			"type":      r.Type,
+			"amount":    r.Amount,
+			"error":     r.Error,
+			"memberId":  r.MemberID,
+			"newMember": r.NewMember,
+			"warnings":  warnings,
+		})
+	}
+	dups := make([]map[string]any, 0, len(p.Duplicates))
+	for _, d := range p.Duplicates {
+		existing := d.ExistingMembers
+		if existing == nil {
+			existing = []string{}
+		}
+		dups = append(dups, map[string]any{
+			"name":              d.Name,
+			"lines":             d.Lines,
+			"existingMemberIds": existing,
+		})
+	}
+	return map[string]any{
+		"records":    p.Records,
+		"newMembers": p.NewMembers,
+		"errors":     p.Errors,
+		"income":     p.Income,
+		"expense":    p.Expense,
+		"rows":       rows,
+		"duplicates": dups,
+	}
+}
@@ -0,0 +1,133 @@
+package handlers_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"mime/multipart"
+	"testing"
+
+	"github.com/cloudwego/hertz/pkg/common/ut"
+
+	"scorehub/internal/sheet"
+)
+
+// upload posts a raw request body (a file or a multipart form) and decodes the JSON response.
+func (a *testAPI) upload(path, token, contentType string, body []byte) (int, map[string]any) {
+	a.t.Helper()
+	headers := []ut.Header{{Key: "Content-Type", Value: contentType}, {Key: "Authorization", Value: "Bearer " + token}}
+	resp := ut.PerformRequest(a.engine, "POST", path, &ut.Body{Body: bytes.NewReader(body), Len: len(body)}, headers...).Result()
+	out := map[string]any{}
+	if err := json.Unmarshal(resp.Body(), &out); err != nil {
+		a.t.Fatalf("POST %s: decode %q: %v", path, resp.Body(), err)
+	}
+	return resp.StatusCode(), out
+}
+
+func TestLedgerImport(t *testing.T) {
+	api := newTestAPI(t)
+	owner := api.login("owner", "店主")
+	guest := api.login("guest", "来宾")
+
+	resp := api.expect(200, "POST", "/api/v1/ledgers", owner, map[string]any{"name": "婚礼礼金"})
+	path := "/api/v1/ledgers/" + str(resp, "ledger", "id")
+	resp = api.expect(200, "POST", path+"/members", owner, map[string]any{"nickname": "张三", "remark": "大学同学"})
+	zhang := str(resp, "member", "id")
+
+	file := []byte("\xef\xbb\xbf姓名,备注,类型,金额\n" +
+		"张三,大学同学,收入,\"1,000\"\n" +
+		"李四,同事,,￥600元\n" +
+		",,,\n" +
+		"李四,同事,支出,200\n" +
+		"王五,,礼物,100\n")
+
+	// 预览：第 5 行（王五）类型无法识别；李四在文件中重复，张三与已有成员同名
+	code, resp := api.upload(path+"/import?format=csv", owner, "text/csv", file)
+	if code != 200 || boolean(resp, "committed") {
+		t.Fatalf("dry run: %d %v", code, resp)
+	}
+	plan := obj(resp, "plan")
+	if num(plan, "records") != 3 || num(plan, "errors") != 1 || num(plan, "newMembers") != 1 || num(plan, "income") != 1600 || num(plan, "expense") != 200 {
+		t.Fatalf("plan totals: %v", plan)
+	}
+	rows := list(plan, "rows")
+	if len(rows) != 4 || num(rows[0], "line") != 2 || str(rows[0], "memberId") != zhang || boolean(rows[0], "newMember") {
+		t.Fatalf("plan rows: %v", rows)
+	}
+	if str(rows[1], "type") != "income" || num(rows[1], "amount") != 600 || !boolean(rows[1], "newMember") {
+		t.Fatalf("li si row: %v", rows[1])
+	}
+	if num(rows[3], "line") != 6 || str(rows[3], "error") != "invalid_type" {
+		t.Fatalf("error row: %v", rows[3])
+	}
+	dups := list(plan, "duplicates")
+	if len(dups) != 2 || str(dups[0], "name") != "张三" || str(list(dups[0], "existingMemberIds")[0]) != zhang || len(list(dups[1], "lines")) != 2 {
+		t.Fatalf("duplicates: %v", dups)
+	}
+
+	// 有错误行时不能提交，账本保持不变
+	code, resp = api.upload(path+"/import?format=csv&commit=true", owner, "text/csv", file)
+	if code != 400 || str(obj(resp, "error"), "code") != "invalid_rows" || num(obj(resp, "plan"), "errors") != 1 {
+		t.Fatalf("commit with errors: %d %v", code, resp)
+	}
+	if len(list(api.expect(200, "GET", path, owner, nil), "members")) != 2 {
+		t.Fatalf("failed import changed ledger")
+	}
+
+	// XLSX 通过 multipart 上传，格式由文件名推断
+	var xlsx bytes.Buffer
+	w, _ := sheet.NewWriter(sheet.FormatXLSX, &xlsx)
+	_ = w.Begin(sheet.Table{Key: "rows", Title: "礼金", Columns: []sheet.Column{{Key: "name", Title: "姓名"}, {Key: "remark", Title: "备注"}, {Key: "type", Title: "类型"}, {Key: "amount", Title: "金额"}}})
+	_ = w.Row("张三", "大学同学", "收入", 1000)
+	_ = w.Row("李四", "同事", "income", 600)
+	_ = w.Row("李四", "同事", "支出", 200)
+	_ = w.Close()
+	var form bytes.Buffer
+	mw := multipart.NewWriter(&form)
+	part, _ := mw.CreateFormFile("file", "礼金.xlsx")
+	_, _ = part.Write(xlsx.Bytes())
+	_ = mw.Close()
+
+	code, resp = api.upload(path+"/import?commit=true", guest, mw.FormDataContentType(), form.Bytes())
+	if code != 403 {
+		t.Fatalf("guest import: %d %v", code, resp)
+	}
+	code, resp = api.upload(path+"/import?commit=true", owner, mw.FormDataContentType(), form.Bytes())
+	if code != 200 || !boolean(resp, "committed") || num(resp, "plan", "records") != 3 {
+		t.Fatalf("commit: %d %v", code, resp)
+	}
+
+	resp = api.expect(200, "GET", path, owner, nil)
+	members := list(resp, "members")
+	if len(members) != 3 || num(findBy(t, members, "id", zhang), "score") != -1000 {
+		t.Fatalf("members after import: %v", members)
+	}
+	li := findBy(t, members, "nickname", "李四")
+	if num(li, "score") != -400 || str(li, "remark") != "同事" {
+		t.Fatalf("new member: %v", li)
+	}
+
+	// layout=import 导出的文件可以原样再导入
+	res := api.download(path+"/export?layout=import", owner)
+	if res.StatusCode() != 200 {
+		t.Fatalf("import layout export: %d %s", res.StatusCode(), res.Body())
+	}
+	exported := append([]byte(nil), res.Body()...)
+	code, resp = api.upload(path+"/import?mode=separate", owner, "text/csv", exported)
+	plan = obj(resp, "plan")
+	if code != 200 || num(plan, "records") != 3 || num(plan, "errors") != 0 || num(plan, "newMembers") != 3 || num(plan, "income") != 1600 {
+		t.Fatalf("re-import: %d %v", code, resp)
+	}
+	if r := list(plan, "rows")[2]; str(r, "name") != "李四" || str(r, "type") != "expense" || num(r, "amount") != 200 {
+		t.Fatalf("re-import rows: %v", r)
+	}
+
+	code, _ = api.upload(path+"/import", owner, "text/csv", []byte("a,b\n1,2\n"))
+	if code != 400 {
+		t.Fatalf("missing header: %d", code)
+	}
+	api.expect(200, "POST", path+"/end", owner, nil)
+	code, resp = api.upload(path+"/import", owner, "text/csv", file)
+	if code != 400 || str(obj(resp, "error"), "code") != "ended" {
+		t.Fatalf("import into ended ledger: %d %v", code, resp)
+	}
+}
@@ -0,0 +1,210 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/hertz-contrib/websocket"
+
+	"scorehub/internal/http/middleware"
+	"scorehub/internal/store"
+)
+
+// 记账员：账本主人通过记账员邀请码邀请其他用户一起记账。记账员可以添加成员、
+// 修改成员信息与记一笔，查看备注；删除、修改记录、结束与删除账本仍只有主人可以。
+
+// ListLedgerRecorders 列出账本的记账员，主人额外拿到当前的记账员邀请码。
+func (h *LedgerHandlers) ListLedgerRecorders(ctx context.Context, c *app.RequestContext) {
+	uid, ok := middleware.UserID(c)
+	if !ok {
+		writeError(c, http.StatusUnauthorized, "unauthorized", "missing user")
+		return
+	}
+	id := strings.TrimSpace(c.Param("id"))
+	if id == "" {
+		writeError(c, http.StatusBadRequest, "bad_request", "id required")
+		return
+	}
+
+	recorders, code, err := h.st.ListLedgerRecorders(ctx, id, uid)
+	if err != nil {
+		writeLedgerRecorderError(c, err)
+		return
+	}
+	items := make([]map[string]any, 0, len(recorders))
+	for _, r := range recorders {
+		items = append(items, toLedgerRecorderDTO(r))
+	}
+	var inviteCode any
+	if code != "" {
+		inviteCode = code
+	}
+	c.JSON(http.StatusOK, map[string]any{"items": items, "inviteCode": inviteCode})
+}
+
+// ResetLedgerRecorderInvite 生成新的记账员邀请码，旧码随即失效；已加入的记账员不受影响。
+func (h *LedgerHandlers) ResetLedgerRecorderInvite(ctx context.Context, c *app.RequestContext) {
+	h.setLedgerRecorderInvite(ctx, c, true)
+}
+
+// DisableLedgerRecorderInvite 关闭记账员邀请码。
+func (h *LedgerHandlers) DisableLedgerRecorderInvite(ctx context.Context, c *app.RequestContext) {
+	h.setLedgerRecorderInvite(ctx, c, false)
+}
+
+func (h *LedgerHandlers) setLedgerRecorderInvite(ctx context.Context, c *app.RequestContext, enabled bool) {
+	uid, ok := middleware.UserID(c)
+	if !ok {
+		writeError(c, http.StatusUnauthorized, "unauthorized", "missing user")
+		return
+	}
+	id := strings.TrimSpace(c.Param("id"))
+	if id == "" {
+		writeError(c, http.StatusBadRequest, "bad_request", "id required")
+		return
+	}
+
+	code, err := h.st.ResetLedgerRecorderInvite(ctx, id, uid, enabled)
+	if err != nil {
+		writeLedgerRecorderError(c, err)
+		return
+	}
+	if !enabled {
+		c.JSON(http.StatusOK, map[string]any{"ok": true})
+		return
+	}
+	c.JSON(http.StatusOK, map[string]any{"inviteCode": code})
+}
+
+// GetLedgerRecorderInvite 查看记账员邀请码对应的账本，供接受前确认。
+func (h *LedgerHandlers) GetLedgerRecorderInvite(ctx context.Context, c *app.RequestContext) {
+	code := strings.TrimSpace(c.Param("code"))
+	if code == "" {
+		writeError(c, http.StatusBadRequest, "bad_request", "code required")
+		return
+	}
+
+	inv, err := h.st.GetLedgerRecorderInvite(ctx, code)
+	if err != nil {
+		writeLedgerRecorderError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, map[string]any{
+		"ledgerId":      inv.LedgerID,
+		"ledgerName":    inv.LedgerName,
+		"status":        inv.Status,
+		"ownerUserId":   inv.OwnerUserID,
+		"ownerNickname": inv.OwnerNickname,
+	})
+}
+
+// AcceptLedgerRecorderInvite 以记账员身份加入账本；重复接受直接返回已有身份。
+func (h *LedgerHandlers) AcceptLedgerRecorderInvite(ctx context.Context, c *app.RequestContext) {
+	uid, ok := middleware.UserID(c)
+	if !ok {
+		writeError(c, http.StatusUnauthorized, "unauthorized", "missing user")
+		return
+	}
+	code := strings.TrimSpace(c.Param("code"))
+	if code == "" {
+		writeError(c, http.StatusBadRequest, "bad_request", "code required")
+		return
+	}
+
+	r, err := h.st.AcceptLedgerRecorderInvite(ctx, code, uid)
+	if err != nil {
+		if err == store.ErrConflict {
+			writeError(c, http.StatusConflict, "conflict", "owner cannot be a recorder")
+			return
+		}
+		writeLedgerRecorderError(c, err)
+		return
+	}
+	h.broadcast(r.LedgerID, "recorder.joined", toLedgerRecorderDTO(r))
+	c.JSON(http.StatusOK, map[string]any{"ledgerId": r.LedgerID, "recorder": toLedgerRecorderDTO(r)})
+}
+
+// RemoveLedgerRecorder 移除记账员并断开其实时连接；记账员也可以退出（移除自己）。
+// 已记的记录保留，记录人不变。
+func (h *LedgerHandlers) RemoveLedgerRecorder(ctx context.Context, c *app.RequestContext) {
+	uid, ok := middleware.UserID(c)
+	if !ok {
+		writeError(c, http.StatusUnauthorized, "unauthorized", "missing user")
+		return
+	}
+	id := strings.TrimSpace(c.Param("id"))
+	if id == "" {
+		writeError(c, http.StatusBadRequest, "bad_request", "id required")
+		return
+	}
+	recorderID, err := strconv.ParseInt(strings.TrimSpace(c.Param("userId")), 10, 64)
+	if err != nil || recorderID <= 0 {
+		writeError(c, http.StatusBadRequest, "bad_request", "invalid userId")
+		return
+	}
+
+	if err := h.st.RemoveLedgerRecorder(ctx, id, uid, recorderID); err != nil {
+		writeLedgerRecorderError(c, err)
+		return
+	}
+	h.broadcast(id, "recorder.removed", map[string]any{"userId": recorderID})
+	if h.hub != nil {
+		h.hub.Disconnect(id, recorderID)
+	}
+	c.JSON(http.StatusOK, map[string]any{"ok": true})
+}
+
+// LedgerWS 推送账本的实时变更，只对主人和记账员开放。
+func (h *LedgerHandlers) LedgerWS(ctx context.Context, c *app.RequestContext) {
+	ledgerID := strings.TrimSpace(c.Param("id"))
+	if ledgerID == "" {
+		c.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+
+	uid, ok := wsUser(ctx, c, h.cfg, h.users)
+	if !ok {
+		return
+	}
+
+	role, err := h.st.GetLedgerRole(ctx, ledgerID, uid)
+	if err != nil || role == "" {
+		c.AbortWithStatus(http.StatusForbidden)
+		return
+	}
+
+	since, ok := parseSince(c)
+	if !ok {
+		return
+	}
+
+	h.upgrader.Upgrade(c, func(conn *websocket.Conn) {
+		h.hub.Serve(ctx, ledgerID, uid, conn, since)
+	})
+}
+
+func writeLedgerRecorderError(c *app.RequestContext, err error) {
+	switch err {
+	case store.ErrNotFound:
+		writeError(c, http.StatusNotFound, "not_found", "not found")
+	case store.ErrForbidden:
+		writeError(c, http.StatusForbidden, "forbidden", "no permission")
+	case store.ErrScorebookEnded:
+		writeError(c, http.StatusBadRequest, "ended", "ledger ended")
+	default:
+		writeError(c, http.StatusInternalServerError, "internal", "db error", err)
+	}
+}
+
+func toLedgerRecorderDTO(r store.LedgerRecorder) map[string]any {
+	return map[string]any{
+		"ledgerId":  r.LedgerID,
+		"userId":    r.UserID,
+		"nickname":  r.Nickname,
+		"avatarUrl": r.AvatarURL,
+		"createdAt": r.CreatedAt,
+	}
+}
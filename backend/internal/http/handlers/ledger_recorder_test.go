@@ -0,0 +1,174 @@
+package handlers_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/hertz-contrib/websocket"
+
+	"scorehub/internal/realtime"
+)
+
+func TestLedgerRecorders(t *testing.T) {
+	api := newTestAPI(t)
+	owner := api.login("owner", "店主")
+	helper := api.login("helper", "帮手")
+	guest := api.login("guest", "来宾")
+
+	resp := api.expect(200, "POST", "/api/v1/ledgers", owner, map[string]any{"name": "婚礼礼金"})
+	id := str(resp, "ledger", "id")
+	path := "/api/v1/ledgers/" + id
+	ownerM := str(resp, "member", "id")
+
+	// 记账员邀请码默认关闭，只有主人能生成
+	if resp := api.expect(200, "GET", path+"/recorders", owner, nil); field(resp, "inviteCode") != nil || len(list(resp, "items")) != 0 {
+		t.Fatalf("initial recorders: %v", resp)
+	}
+	api.expectError(403, "forbidden", "POST", path+"/recorder_invite", helper, nil)
+	api.expectError(403, "forbidden", "GET", path+"/recorders", helper, nil)
+	code := str(api.expect(200, "POST", path+"/recorder_invite", owner, nil), "inviteCode")
+	if code == "" {
+		t.Fatal("empty recorder invite code")
+	}
+	resp = api.expect(200, "GET", "/api/v1/ledger_recorder_invites/"+code, helper, nil)
+	if str(resp, "ledgerId") != id || str(resp, "ledgerName") != "婚礼礼金" || str(resp, "ownerNickname") != "店主" {
+		t.Fatalf("recorder invite: %v", resp)
+	}
+	api.expectError(409, "conflict", "POST", "/api/v1/ledger_recorder_invites/"+code+"/accept", owner, nil)
+	resp = api.expect(200, "POST", "/api/v1/ledger_recorder_invites/"+code+"/accept", helper, nil)
+	if str(resp, "ledgerId") != id || str(resp, "recorder", "nickname") != "帮手" {
+		t.Fatalf("accept recorder invite: %v", resp)
+	}
+	api.expect(200, "POST", "/api/v1/ledger_recorder_invites/"+code+"/accept", helper, nil)
+
+	resp = api.expect(200, "GET", path+"/recorders", owner, nil)
+	if items := list(resp, "items"); len(items) != 1 || str(resp, "inviteCode") != code {
+		t.Fatalf("recorders: %v", resp)
+	}
+	if resp := api.expect(200, "GET", path+"/recorders", helper, nil); field(resp, "inviteCode") != nil {
+		t.Fatalf("recorder sees invite code: %v", resp)
+	}
+	ledgers := list(api.expect(200, "GET", "/api/v1/ledgers", helper, nil), "items")
+	if len(ledgers) != 1 || str(ledgers[0], "role") != "recorder" {
+		t.Fatalf("recorder ledgers: %v", ledgers)
+	}
+	if l := list(api.expect(200, "GET", "/api/v1/ledgers", owner, nil), "items"); str(l[0], "role") != "owner" {
+		t.Fatalf("owner ledgers: %v", l)
+	}
+
+	// 记账员可以加成员、记一笔、改成员备注
+	zhang := str(api.expect(200, "POST", path+"/members", helper, map[string]any{"nickname": "张三", "remark": "同事"}), "member", "id")
+	api.expect(200, "PATCH", path+"/members/"+zhang, helper, map[string]any{"nickname": "张三", "remark": "老同事"})
+	resp = api.expect(200, "POST", path+"/records", helper, map[string]any{"memberId": zhang, "type": "income", "amount": 500, "note": "红包"})
+	rec := str(resp, "record", "id")
+	if str(resp, "record", "createdByNickname") != "帮手" || field(resp, "record", "createdByUserId") == nil {
+		t.Fatalf("record author: %v", resp)
+	}
+	api.expect(200, "POST", path+"/records", owner, map[string]any{"memberId": zhang, "type": "income", "amount": 100})
+	api.expect(200, "GET", path+"/categories", helper, nil)
+
+	// 删除、修改记录，结束账本，汇总仍只有主人可以
+	api.expectError(403, "forbidden", "DELETE", path+"/records/"+rec, helper, nil)
+	api.expectError(403, "forbidden", "PATCH", path+"/records/"+rec, helper, map[string]any{"amount": 1})
+	api.expectError(404, "not_found", "POST", path+"/end", helper, nil)
+	api.expectError(403, "forbidden", "GET", path+"/summary", helper, nil)
+	api.expectError(403, "forbidden", "POST", path+"/records", guest, map[string]any{"memberId": zhang, "type": "income", "amount": 1})
+	api.expectError(403, "forbidden", "POST", path+"/members", guest, map[string]any{"nickname": "王五"})
+
+	// 详情：记账员能看到备注，其他人看不到；记录都带记录人
+	resp = api.expect(200, "GET", path, helper, nil)
+	records := list(resp, "records")
+	if len(records) != 2 || str(findBy(t, records, "id", rec), "note") != "红包" ||
+		str(findBy(t, list(resp, "members"), "id", zhang), "remark") != "老同事" {
+		t.Fatalf("recorder detail: %v", resp)
+	}
+	for _, r := range records {
+		if str(r, "id") != rec && str(r, "createdByNickname") != "店主" {
+			t.Fatalf("owner record author: %v", r)
+		}
+	}
+	resp = api.expect(200, "GET", path, guest, nil)
+	if str(findBy(t, list(resp, "records"), "id", rec), "note") != "" {
+		t.Fatalf("guest sees note: %v", resp)
+	}
+	if s := num(findBy(t, list(resp, "members"), "id", ownerM), "score"); s != 600 {
+		t.Fatalf("owner score: %v", s)
+	}
+
+	// 重置后旧码失效，关闭后无法加入；已加入的记账员保留
+	newCode := str(api.expect(200, "POST", path+"/recorder_invite", owner, nil), "inviteCode")
+	if newCode == "" || newCode == code {
+		t.Fatalf("reset code: %q", newCode)
+	}
+	api.expectError(404, "not_found", "POST", "/api/v1/ledger_recorder_invites/"+code+"/accept", guest, nil)
+	api.expect(200, "DELETE", path+"/recorder_invite", owner, nil)
+	api.expectError(404, "not_found", "GET", "/api/v1/ledger_recorder_invites/"+newCode, guest, nil)
+	api.expect(200, "POST", path+"/records", helper, map[string]any{"memberId": zhang, "type": "expense", "amount": 50})
+
+	// 记账员退出后失去权限，已记的记录保留
+	guestID := int64(num(api.expect(200, "GET", "/api/v1/me", guest, nil), "user", "id"))
+	helperID := int64(num(api.expect(200, "GET", "/api/v1/me", helper, nil), "user", "id"))
+	api.expectError(403, "forbidden", "DELETE", fmt.Sprintf("%s/recorders/%d", path, guestID), helper, nil)
+	api.expect(200, "DELETE", fmt.Sprintf("%s/recorders/%d", path, helperID), helper, nil)
+	api.expectError(404, "not_found", "DELETE", fmt.Sprintf("%s/recorders/%d", path, helperID), owner, nil)
+	api.expectError(403, "forbidden", "POST", path+"/records", helper, map[string]any{"memberId": zhang, "type": "income", "amount": 1})
+	if l := list(api.expect(200, "GET", "/api/v1/ledgers", helper, nil), "items"); len(l) != 0 {
+		t.Fatalf("ledgers after leaving: %v", l)
+	}
+	if r := findBy(t, list(api.expect(200, "GET", path, owner, nil), "records"), "id", rec); str(r, "createdByNickname") != "帮手" {
+		t.Fatalf("record after leaving: %v", r)
+	}
+}
+
+func TestLedgerWS(t *testing.T) {
+	api := newTestAPI(t)
+	addr := api.serve()
+	owner := api.login("owner", "店主")
+	helper := api.login("helper", "帮手")
+	guest := api.login("guest", "来宾")
+
+	id := str(api.expect(200, "POST", "/api/v1/ledgers", owner, map[string]any{"name": "婚礼礼金"}), "ledger", "id")
+	path := "/api/v1/ledgers/" + id
+	code := str(api.expect(200, "POST", path+"/recorder_invite", owner, nil), "inviteCode")
+	api.expect(200, "POST", "/api/v1/ledger_recorder_invites/"+code+"/accept", helper, nil)
+	wsURL := func(token string) string {
+		return fmt.Sprintf("http://%s/ws/ledgers/%s?token=%s", addr, id, token)
+	}
+
+	// 只有主人和记账员能订阅；认领了成员的来宾也不能借记分本的房间旁听
+	guestM := str(api.expect(200, "POST", path+"/members", owner, map[string]any{"nickname": "来宾"}), "member", "id")
+	api.expect(200, "POST", path+"/bind", guest, map[string]any{"memberId": guestM})
+	if code, _ := api.do("GET", "/ws/ledgers/"+id+"?token="+guest, "", nil); code != 403 {
+		t.Fatalf("guest ws status = %d", code)
+	}
+	if code, _ := api.do("GET", "/ws/scorebooks/"+id+"?token="+guest, "", nil); code != 403 {
+		t.Fatalf("guest scorebook ws status = %d", code)
+	}
+
+	conn := dialWS(t, wsURL(helper))
+	zhang := str(api.expect(200, "POST", path+"/members", owner, map[string]any{"nickname": "张三"}), "member", "id")
+	if ev := readEvent(t, conn); ev.Type != "member.added" || ev.Data["id"] != zhang {
+		t.Fatalf("member event = %+v", ev)
+	}
+	api.expect(200, "POST", path+"/records", owner, map[string]any{"memberId": zhang, "type": "income", "amount": 200})
+	if ev := readEvent(t, conn); ev.Type != "record.created" || ev.Data["amount"] != float64(200) || ev.Data["createdByNickname"] != "店主" {
+		t.Fatalf("record event = %+v", ev)
+	}
+
+	helperID := int64(num(api.expect(200, "GET", "/api/v1/me", helper, nil), "user", "id"))
+	api.expect(200, "DELETE", fmt.Sprintf("%s/recorders/%d", path, helperID), owner, nil)
+
+	// 被移除的记账员连接随即以 4003 关闭
+	_ = conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	for {
+		_, _, err := conn.ReadMessage()
+		if err == nil {
+			continue
+		}
+		if !websocket.IsCloseError(err, realtime.CloseRemoved) {
+			t.Fatalf("read after remove: %v", err)
+		}
+		break
+	}
+}
@@ -0,0 +1,190 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/cloudwego/hertz/pkg/app"
+
+	"scorehub/internal/http/middleware"
+	"scorehub/internal/store"
+)
+
+// updateLedgerRecordRequest 只修改出现的字段；categoryId 传空字符串表示取消分类。
+type updateLedgerRecordRequest struct {
+	MemberID   *string  `json:"memberId"`
+	Type       *string  `json:"type"`
+	Amount     *float64 `json:"amount"`
+	Note       *string  `json:"note"`
+	CategoryID *string  `json:"categoryId"`
+}
+
+// UpdateLedgerRecord 修改一条记录，同一事务内重算相关成员余额并留下修改记录。
+func (h *LedgerHandlers) UpdateLedgerRecord(ctx context.Context, c *app.RequestContext) {
+	uid, ok := middleware.UserID(c)
+	if !ok {
+		writeError(c, http.StatusUnauthorized, "unauthorized", "missing user")
+		return
+	}
+	id := strings.TrimSpace(c.Param("id"))
+	recordID := strings.TrimSpace(c.Param("recordId"))
+	if id == "" || recordID == "" {
+		writeError(c, http.StatusBadRequest, "bad_request", "id required")
+		return
+	}
+
+	var req updateLedgerRecordRequest
+	body, err := c.Body()
+	if err != nil {
+		writeError(c, http.StatusBadRequest, "bad_request", "read body failed")
+		return
+	}
+	if err := json.Unmarshal(body, &req); err != nil {
+		writeError(c, http.StatusBadRequest, "bad_request", "invalid json")
+		return
+	}
+	if req.MemberID == nil && req.Type == nil && req.Amount == nil && req.Note == nil && req.CategoryID == nil {
+		writeError(c, http.StatusBadRequest, "bad_request", "nothing to update")
+		return
+	}
+	if req.Amount != nil && !validTwoDecimals(*req.Amount) {
+		writeError(c, http.StatusBadRequest, "bad_request", "amount must have at most 2 decimals")
+		return
+	}
+	if req.Type != nil {
+		t := strings.ToLower(strings.TrimSpace(*req.Type))
+		if t != "expense" && t != "income" {
+			writeError(c, http.StatusBadRequest, "bad_request", "invalid type")
+			return
+		}
+		req.Type = &t
+	}
+
+	r, err := h.st.UpdateLedgerRecord(ctx, id, uid, recordID, store.LedgerRecordUpdate{
+		MemberID:   req.MemberID,
+		Type:       req.Type,
+		Amount:     req.Amount,
+		Note:       req.Note,
+		CategoryID: req.CategoryID,
+	})
+	if err != nil {
+		writeLedgerRecordError(c, err)
+		return
+	}
+	h.broadcast(id, "record.updated", toLedgerRecordDTO(r))
+	c.JSON(http.StatusOK, map[string]any{"record": toLedgerRecordDTO(r)})
+}
+
+// DeleteLedgerRecord 删除一条记录并撤销它对余额的影响，删除前的内容保留在修改记录中。
+func (h *LedgerHandlers) DeleteLedgerRecord(ctx context.Context, c *app.RequestContext) {
+	uid, ok := middleware.UserID(c)
+	if !ok {
+		writeError(c, http.StatusUnauthorized, "unauthorized", "missing user")
+		return
+	}
+	id := strings.TrimSpace(c.Param("id"))
+	recordID := strings.TrimSpace(c.Param("recordId"))
+	if id == "" || recordID == "" {
+		writeError(c, http.StatusBadRequest, "bad_request", "id required")
+		return
+	}
+
+	r, err := h.st.DeleteLedgerRecord(ctx, id, uid, recordID)
+	if err != nil {
+		writeLedgerRecordError(c, err)
+		return
+	}
+	h.broadcast(id, "record.deleted", map[string]any{"id": r.ID})
+	c.JSON(http.StatusOK, map[string]any{"record": toLedgerRecordDTO(r)})
+}
+
+// ListRecordRevisions 列出账本记录的修改与删除历史（新的在前），可按 recordId 过滤。
+func (h *LedgerHandlers) ListRecordRevisions(ctx context.Context, c *app.RequestContext) {
+	uid, ok := middleware.UserID(c)
+	if !ok {
+		writeError(c, http.StatusUnauthorized, "unauthorized", "missing user")
+		return
+	}
+	id := strings.TrimSpace(c.Param("id"))
+	if id == "" {
+		writeError(c, http.StatusBadRequest, "bad_request", "id required")
+		return
+	}
+
+	limit := int32(20)
+	offset := int32(0)
+	if v := strings.TrimSpace(string(c.Query("limit"))); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 && n <= 200 {
+			limit = int32(n)
+		}
+	}
+	if v := strings.TrimSpace(string(c.Query("offset"))); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n >= 0 {
+			offset = int32(n)
+		}
+	}
+
+	revisions, err := h.st.ListRecordRevisions(ctx, id, uid, strings.TrimSpace(string(c.Query("recordId"))), limit, offset)
+	if err != nil {
+		writeLedgerRecordError(c, err)
+		return
+	}
+	items := make([]map[string]any, 0, len(revisions))
+	for _, rv := range revisions {
+		items = append(items, toRecordRevisionDTO(rv))
+	}
+	c.JSON(http.StatusOK, map[string]any{"items": items, "limit": limit, "offset": offset})
+}
+
+func writeLedgerRecordError(c *app.RequestContext, err error) {
+	switch err {
+	case store.ErrNotFound:
+		writeError(c, http.StatusNotFound, "not_found", "not found")
+	case store.ErrForbidden:
+		writeError(c, http.StatusForbidden, "forbidden", "no permission")
+	case store.ErrScorebookEnded:
+		writeError(c, http.StatusBadRequest, "ended", "ledger ended")
+	case store.ErrInvalidArgument:
+		writeError(c, http.StatusBadRequest, "bad_request", "invalid record")
+	default:
+		writeError(c, http.StatusInternalServerError, "internal", "db error", err)
+	}
+}
+
+func toRecordRevisionDTO(rv store.RecordRevision) map[string]any {
+	var changedBy any
+	if rv.ChangedByUserID != nil {
+		changedBy = *rv.ChangedByUserID
+	}
+	var after any
+	if rv.After != nil {
+		after = toRecordSnapshotDTO(*rv.After)
+	}
+	return map[string]any{
+		"id":                rv.ID,
+		"recordId":          rv.RecordID,
+		"action":            rv.Action,
+		"changedByUserId":   changedBy,
+		"changedByNickname": rv.ChangedByNickname,
+		"before":            toRecordSnapshotDTO(rv.Before),
+		"after":             after,
+		"createdAt":         rv.CreatedAt,
+	}
+}
+
+func toRecordSnapshotDTO(s store.LedgerRecordSnapshot) map[string]any {
+	var categoryID any
+	if s.CategoryID != "" {
+		categoryID = s.CategoryID
+	}
+	return map[string]any{
+		"memberId":   s.MemberID,
+		"type":       s.Type,
+		"amount":     s.Amount,
+		"note":       s.Note,
+		"categoryId": categoryID,
+	}
+}
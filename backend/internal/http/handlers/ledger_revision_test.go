@@ -0,0 +1,95 @@
+package handlers_test
+
+import "testing"
+
+func TestLedgerRecordEditAndDelete(t *testing.T) {
+	api := newTestAPI(t)
+	owner := api.login("owner", "店主")
+	guest := api.login("guest", "来宾")
+
+	resp := api.expect(200, "POST", "/api/v1/ledgers", owner, map[string]any{"name": "婚礼礼金"})
+	path := "/api/v1/ledgers/" + str(resp, "ledger", "id")
+	ownerM := str(resp, "member", "id")
+	zhang := str(api.expect(200, "POST", path+"/members", owner, map[string]any{"nickname": "张三"}), "member", "id")
+	li := str(api.expect(200, "POST", path+"/members", owner, map[string]any{"nickname": "李四"}), "member", "id")
+	gift := str(api.expect(200, "POST", path+"/categories", owner, map[string]any{"name": "礼金"}), "category", "id")
+
+	r1 := str(api.expect(200, "POST", path+"/records", owner, map[string]any{"memberId": zhang, "type": "income", "amount": 888}), "record", "id")
+	r2 := str(api.expect(200, "POST", path+"/records", owner, map[string]any{"memberId": zhang, "type": "expense", "amount": 100}), "record", "id")
+
+	scores := func() map[string]float64 {
+		t.Helper()
+		out := map[string]float64{}
+		for _, m := range list(api.expect(200, "GET", path, owner, nil), "members") {
+			out[str(m, "id")] = num(m, "score")
+		}
+		return out
+	}
+
+	// 改金额：张三 -888+100 → -666+100
+	resp = api.expect(200, "PATCH", path+"/records/"+r1, owner, map[string]any{"amount": 666})
+	if num(resp, "record", "amount") != 666 || str(resp, "record", "type") != "income" {
+		t.Fatalf("update amount: %v", resp)
+	}
+	if s := scores(); s[zhang] != -566 || s[ownerM] != 566 {
+		t.Fatalf("scores after amount edit: %v", s)
+	}
+
+	// 改成员、类型与分类：这笔变为给李四的支出
+	resp = api.expect(200, "PATCH", path+"/records/"+r1, owner, map[string]any{"memberId": li, "type": "expense", "categoryId": gift, "note": " 回礼 "})
+	rec := obj(resp, "record")
+	if str(rec, "memberId") != li || str(rec, "fromMemberId") != ownerM || str(rec, "toMemberId") != li || str(rec, "categoryId") != gift || str(rec, "note") != "回礼" {
+		t.Fatalf("update member/type: %v", rec)
+	}
+	if s := scores(); s[zhang] != 100 || s[li] != 666 || s[ownerM] != -766 {
+		t.Fatalf("scores after member edit: %v", s)
+	}
+	// 内容未变时不留修改记录
+	api.expect(200, "PATCH", path+"/records/"+r1, owner, map[string]any{"note": "回礼"})
+
+	api.expectError(400, "bad_request", "PATCH", path+"/records/"+r1, owner, map[string]any{})
+	api.expectError(400, "bad_request", "PATCH", path+"/records/"+r1, owner, map[string]any{"memberId": ownerM})
+	api.expectError(400, "bad_request", "PATCH", path+"/records/"+r1, owner, map[string]any{"amount": 0.001})
+	api.expectError(400, "bad_request", "PATCH", path+"/records/"+r1, owner, map[string]any{"type": "gift"})
+	api.expectError(400, "bad_request", "PATCH", path+"/records/"+r1, owner, map[string]any{"categoryId": "missing"})
+	api.expectError(404, "not_found", "PATCH", path+"/records/"+r1, owner, map[string]any{"memberId": "missing"})
+	api.expectError(404, "not_found", "PATCH", path+"/records/missing", owner, map[string]any{"amount": 1})
+	api.expectError(403, "forbidden", "PATCH", path+"/records/"+r1, guest, map[string]any{"amount": 1})
+	api.expectError(403, "forbidden", "DELETE", path+"/records/"+r2, guest, nil)
+
+	resp = api.expect(200, "DELETE", path+"/records/"+r2, owner, nil)
+	if str(resp, "record", "id") != r2 {
+		t.Fatalf("delete record: %v", resp)
+	}
+	if s := scores(); s[zhang] != 0 || s[ownerM] != -666 {
+		t.Fatalf("scores after delete: %v", s)
+	}
+	api.expectError(404, "not_found", "DELETE", path+"/records/"+r2, owner, nil)
+	if records := list(api.expect(200, "GET", path, owner, nil), "records"); len(records) != 1 || str(records[0], "id") != r1 {
+		t.Fatalf("records after delete: %v", records)
+	}
+
+	// 修改记录：新的在前，删除的 after 为 null
+	items := list(api.expect(200, "GET", path+"/revisions", owner, nil), "items")
+	if len(items) != 3 || str(items[0], "action") != "delete" || field(items[0], "after") != nil || num(items[0], "before", "amount") != 100 {
+		t.Fatalf("revisions: %v", items)
+	}
+	if str(items[0], "changedByNickname") != "店主" || str(items[0], "recordId") != r2 {
+		t.Fatalf("revision author: %v", items[0])
+	}
+	items = list(api.expect(200, "GET", path+"/revisions?recordId="+r1, owner, nil), "items")
+	if len(items) != 2 || num(items[1], "before", "amount") != 888 || num(items[1], "after", "amount") != 666 ||
+		str(items[0], "before", "memberId") != zhang || str(items[0], "after", "type") != "expense" || str(items[0], "after", "categoryId") != gift {
+		t.Fatalf("record revisions: %v", items)
+	}
+	api.expectError(403, "forbidden", "GET", path+"/revisions", guest, nil)
+
+	// 汇总随修改更新
+	if total := obj(api.expect(200, "GET", path+"/summary", owner, nil), "total"); num(total, "records") != 1 || num(total, "expense") != 666 {
+		t.Fatalf("summary after edits: %v", total)
+	}
+
+	api.expect(200, "POST", path+"/end", owner, nil)
+	api.expectError(400, "ended", "PATCH", path+"/records/"+r1, owner, map[string]any{"amount": 1})
+	api.expectError(400, "ended", "DELETE", path+"/records/"+r1, owner, nil)
+}
@@ -0,0 +1,96 @@
+package handlers_test
+
+import "testing"
+
+func TestLedgerFlow(t *testing.T) {
+	api := newTestAPI(t)
+	owner := api.login("owner", "店主")
+	guest := api.login("guest", "来宾")
+
+	resp := api.expect(200, "POST", "/api/v1/ledgers", owner, map[string]any{"name": "婚礼礼金"})
+	id := str(resp, "ledger", "id")
+	ownerM := str(resp, "member", "id")
+	if id == "" || str(resp, "member", "role") != "owner" {
+		t.Fatalf("create ledger: %v", resp)
+	}
+	path := "/api/v1/ledgers/" + id
+
+	resp = api.expect(200, "POST", path+"/members", owner, map[string]any{"nickname": "张三", "remark": "大学同学"})
+	zhang := str(resp, "member", "id")
+	api.expectError(403, "forbidden", "POST", path+"/members", guest, map[string]any{"nickname": "李四"})
+	api.expectError(400, "bad_request", "POST", path+"/members", owner, map[string]any{"nickname": " "})
+
+	resp = api.expect(200, "POST", path+"/records", owner, map[string]any{"memberId": zhang, "type": "income", "amount": 888, "note": "红包"})
+	if str(resp, "record", "type") != "income" || num(resp, "record", "amount") != 888 || str(resp, "record", "toMemberId") != ownerM {
+		t.Fatalf("income record: %v", resp)
+	}
+	resp = api.expect(200, "POST", path+"/records", owner, map[string]any{"memberId": zhang, "type": "expense", "amount": 100})
+	if str(resp, "record", "fromMemberId") != ownerM {
+		t.Fatalf("expense record: %v", resp)
+	}
+	api.expectError(400, "bad_request", "POST", path+"/records", owner, map[string]any{"memberId": zhang, "type": "gift", "amount": 1})
+	api.expectError(400, "bad_request", "POST", path+"/records", owner, map[string]any{"memberId": zhang, "type": "income", "amount": 0.001})
+	api.expectError(400, "bad_request", "POST", path+"/records", owner, map[string]any{"memberId": ownerM, "type": "income", "amount": 1})
+	api.expectError(404, "not_found", "POST", path+"/records", owner, map[string]any{"memberId": "missing", "type": "income", "amount": 1})
+	api.expectError(403, "forbidden", "POST", path+"/records", guest, map[string]any{"memberId": zhang, "type": "income", "amount": 1})
+
+	// 账本详情公开可读，但备注只对创建人可见
+	resp = api.expect(200, "GET", path, owner, nil)
+	if len(list(resp, "records")) != 2 {
+		t.Fatalf("owner detail: %v", resp)
+	}
+	if m := findBy(t, list(resp, "members"), "id", zhang); str(m, "remark") != "大学同学" || num(m, "score") != -788 {
+		t.Fatalf("owner view of member: %v", m)
+	}
+	resp = api.expect(200, "GET", path, "", nil)
+	if m := findBy(t, list(resp, "members"), "id", zhang); str(m, "remark") != "" {
+		t.Fatalf("anonymous view leaks remark: %v", m)
+	}
+	for _, r := range list(resp, "records") {
+		if str(r, "note") != "" {
+			t.Fatalf("anonymous view leaks note: %v", r)
+		}
+	}
+	api.expectError(404, "not_found", "GET", "/api/v1/ledgers/missing", "", nil)
+
+	resp = api.expect(200, "PATCH", path+"/members/"+zhang, owner, map[string]any{"nickname": "张三丰"})
+	if str(resp, "member", "nickname") != "张三丰" {
+		t.Fatalf("update member: %v", resp)
+	}
+
+	// 来宾认领成员
+	resp = api.expect(200, "POST", path+"/bind", guest, map[string]any{"memberId": zhang})
+	if num(resp, "member", "userId") == 0 {
+		t.Fatalf("bind: %v", resp)
+	}
+	api.expectError(409, "conflict", "POST", path+"/bind", guest, map[string]any{"memberId": zhang})
+
+	resp = api.expect(200, "GET", "/api/v1/ledgers", guest, nil)
+	if items := list(resp, "items"); len(items) != 1 || num(items[0], "recordCount") != 2 {
+		t.Fatalf("guest ledgers: %v", resp)
+	}
+
+	resp = api.expect(200, "PATCH", path, owner, map[string]any{"name": "婚宴", "shareDisabled": true})
+	if str(resp, "ledger", "name") != "婚宴" || !boolean(resp, "ledger", "shareDisabled") {
+		t.Fatalf("update ledger: %v", resp)
+	}
+	api.expectError(403, "forbidden", "PATCH", path, guest, map[string]any{"name": "x"})
+	api.expectError(400, "bad_request", "PATCH", path, owner, map[string]any{})
+
+	resp = api.expect(200, "POST", path+"/members", owner, map[string]any{"nickname": "李四"})
+	li := str(resp, "member", "id")
+	other := api.login("other", "路人")
+	api.expectError(403, "share_disabled", "POST", path+"/bind", other, map[string]any{"memberId": li})
+
+	api.expectError(400, "not_ended", "DELETE", path, owner, nil)
+	api.expectError(404, "not_found", "POST", path+"/end", guest, nil)
+	resp = api.expect(200, "POST", path+"/end", owner, nil)
+	if str(resp, "ledger", "status") != "ended" {
+		t.Fatalf("end ledger: %v", resp)
+	}
+	api.expectError(400, "ended", "POST", path+"/records", owner, map[string]any{"memberId": zhang, "type": "income", "amount": 1})
+
+	api.expectError(403, "forbidden", "DELETE", path, guest, nil)
+	api.expect(200, "DELETE", path, owner, nil)
+	api.expectError(404, "not_found", "GET", path, owner, nil)
+}
@@ -13,10 +13,10 @@ import (
 )
 
 type MeHandlers struct {
-	st *store.Store
+	st store.UserRepo
 }
 
-func NewMeHandlers(st *store.Store) *MeHandlers {
+func NewMeHandlers(st store.UserRepo) *MeHandlers {
 	return &MeHandlers{st: st}
 }
 
@@ -102,3 +102,52 @@ func (h *MeHandlers) UpdateMe(ctx context.Context, c *app.RequestContext) {
 		},
 	})
 }
+
+// ListSessions 列出当前用户的登录设备，current 标记发起请求的会话。
+func (h *MeHandlers) ListSessions(ctx context.Context, c *app.RequestContext) {
+	uid, ok := middleware.UserID(c)
+	if !ok {
+		writeError(c, http.StatusUnauthorized, "unauthorized", "missing user")
+		return
+	}
+	current, _ := middleware.SessionID(c)
+
+	sessions, err := h.st.ListSessions(ctx, uid)
+	if err != nil {
+		writeError(c, http.StatusInternalServerError, "internal", "db error", err)
+		return
+	}
+
+	items := make([]map[string]any, 0, len(sessions))
+	for _, ss := range sessions {
+		items = append(items, map[string]any{
+			"id":         ss.ID,
+			"userAgent":  ss.UserAgent,
+			"ip":         ss.IP,
+			"createdAt":  ss.CreatedAt,
+			"lastUsedAt": ss.LastUsedAt,
+			"expiresAt":  ss.ExpiresAt,
+			"current":    ss.ID == current,
+		})
+	}
+	c.JSON(http.StatusOK, map[string]any{"items": items})
+}
+
+// RevokeSession 注销指定会话（例如远程退出丢失的设备）。
+func (h *MeHandlers) RevokeSession(ctx context.Context, c *app.RequestContext) {
+	uid, ok := middleware.UserID(c)
+	if !ok {
+		writeError(c, http.StatusUnauthorized, "unauthorized", "missing user")
+		return
+	}
+
+	if err := h.st.RevokeSession(ctx, uid, c.Param("id")); err != nil {
+		if err == store.ErrNotFound {
+			writeError(c, http.StatusNotFound, "not_found", "session not found")
+			return
+		}
+		writeError(c, http.StatusInternalServerError, "internal", "db error", err)
+		return
+	}
+	c.JSON(http.StatusOK, map[string]any{"ok": true})
+}
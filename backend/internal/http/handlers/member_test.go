@@ -0,0 +1,111 @@
+package handlers_test
+
+import (
+	"strconv"
+	"testing"
+)
+
+func TestScorebookMemberRoles(t *testing.T) {
+	f := newScorebook(t)
+	api := f.api
+	dave := api.login("dave", "Dave")
+
+	// 只有房主可以设置角色
+	api.expectError(403, "forbidden", "PATCH", f.path("/members/"+f.carolM), f.bob, map[string]any{"role": "admin"})
+	api.expectError(400, "bad_request", "PATCH", f.path("/members/"+f.carolM), f.alice, map[string]any{"role": "owner"})
+	api.expectError(400, "bad_request", "PATCH", f.path("/members/"+f.aliceM), f.alice, map[string]any{"role": "member"})
+	api.expectError(404, "not_found", "PATCH", f.path("/members/missing"), f.alice, map[string]any{"role": "admin"})
+
+	resp := api.expect(200, "PATCH", f.path("/members/"+f.bobM), f.alice, map[string]any{"role": "admin"})
+	if str(resp, "member", "role") != "admin" {
+		t.Fatalf("set role: %v", resp)
+	}
+	resp = api.expect(200, "GET", f.path(""), f.bob, nil)
+	if str(resp, "me", "role") != "admin" || !boolean(resp, "me", "canManage") || boolean(resp, "me", "isOwner") {
+		t.Fatalf("detail for admin: %v", resp)
+	}
+
+	// 管理员可以改名，普通成员不可以
+	api.expect(200, "PATCH", f.path(""), f.bob, map[string]any{"name": "管理员改名"})
+	api.expectError(403, "forbidden", "PATCH", f.path(""), f.carol, map[string]any{"name": "x"})
+	api.expectError(403, "forbidden", "POST", f.path("/end"), f.carol, nil)
+	api.expectError(404, "not_found", "POST", f.path("/end"), dave, nil)
+
+	// 观众只能观看
+	resp = api.expect(200, "POST", f.path("/join"), dave, map[string]any{"role": "spectator", "inviteCode": f.code})
+	daveM := str(resp, "member", "id")
+	if str(resp, "member", "role") != "spectator" {
+		t.Fatalf("join as spectator: %v", resp)
+	}
+	api.expectError(400, "bad_request", "POST", "/api/v1/invites/"+f.code+"/join", api.login("erin", "Erin"), map[string]any{"role": "admin"})
+	api.expect(200, "GET", f.path("/records"), dave, nil)
+	api.expectError(403, "forbidden", "POST", f.path("/records"), dave, map[string]any{"toMemberId": f.aliceM, "delta": 1})
+	api.expectError(400, "bad_request", "POST", f.path("/records"), f.alice, map[string]any{"toMemberId": daveM, "delta": 1})
+	api.expectError(403, "forbidden", "POST", f.path("/rounds"), dave, map[string]any{
+		"deltas": []any{
+			map[string]any{"memberId": f.aliceM, "delta": 1},
+			map[string]any{"memberId": f.bobM, "delta": -1},
+		},
+	})
+
+	resp = api.expect(200, "POST", f.path("/end"), f.bob, nil)
+	if str(resp, "scorebook", "status") != "ended" {
+		t.Fatalf("admin end: %v", resp)
+	}
+	api.expectError(400, "ended", "POST", f.path("/end"), f.alice, nil)
+}
+
+func TestScorebookRemoveMember(t *testing.T) {
+	f := newScorebook(t)
+	api := f.api
+	dave := api.login("dave", "Dave")
+	resp := api.expect(200, "POST", f.path("/join"), dave, map[string]any{"inviteCode": f.code})
+	daveM := str(resp, "member", "id")
+
+	api.expect(200, "PATCH", f.path("/members/"+f.bobM), f.alice, map[string]any{"role": "admin"})
+	api.expect(200, "POST", f.path("/records"), f.carol, map[string]any{"toMemberId": f.aliceM, "delta": 8})
+
+	// 普通成员不能移出他人；管理员不能移出房主或其他管理员，也不能移出自己
+	api.expectError(403, "forbidden", "DELETE", f.path("/members/"+daveM), f.carol, nil)
+	api.expectError(403, "forbidden", "DELETE", f.path("/members/"+f.aliceM), f.bob, nil)
+	api.expectError(400, "bad_request", "DELETE", f.path("/members/"+f.bobM), f.bob, nil)
+	api.expectError(404, "not_found", "DELETE", f.path("/members/missing"), f.bob, nil)
+
+	// 没有记录的成员直接删除
+	resp = api.expect(200, "DELETE", f.path("/members/"+daveM), f.bob, nil)
+	if boolean(resp, "kept") {
+		t.Fatalf("remove dave: %v", resp)
+	}
+	api.expectError(404, "not_found", "GET", f.path(""), dave, nil)
+	api.expect(200, "POST", "/api/v1/invites/"+f.code+"/join", dave, map[string]any{})
+
+	// 有记录的成员保留记录，只解除与用户的关联；同时禁止重新加入
+	resp = api.expect(200, "DELETE", f.path("/members/"+f.carolM+"?ban=true"), f.bob, nil)
+	if !boolean(resp, "kept") || !boolean(resp, "banned") {
+		t.Fatalf("remove carol: %v", resp)
+	}
+	resp = api.expect(200, "GET", f.path(""), f.alice, nil)
+	carol := findBy(t, list(resp, "members"), "id", f.carolM)
+	if !boolean(carol, "removed") || num(carol, "score") != -8 {
+		t.Fatalf("kept member: %v", carol)
+	}
+	api.expectError(404, "not_found", "GET", f.path(""), f.carol, nil)
+	api.expectError(403, "banned", "POST", "/api/v1/invites/"+f.code+"/join", f.carol, map[string]any{})
+	api.expectError(403, "banned", "POST", f.path("/join"), f.carol, map[string]any{"inviteCode": f.code})
+
+	api.expectError(403, "forbidden", "GET", f.path("/bans"), dave, nil)
+	resp = api.expect(200, "GET", f.path("/bans"), f.bob, nil)
+	items := list(resp, "items")
+	if len(items) != 1 || str(items[0], "nickname") != "小C" {
+		t.Fatalf("bans: %v", resp)
+	}
+	carolUserID := num(items[0], "userId")
+
+	unban := f.path("/bans/" + strconv.FormatInt(int64(carolUserID), 10))
+	api.expect(200, "DELETE", unban, f.alice, nil)
+	api.expectError(404, "not_found", "DELETE", unban, f.alice, nil)
+	resp = api.expect(200, "POST", "/api/v1/invites/"+f.code+"/join", f.carol, map[string]any{})
+	if str(resp, "member", "id") == f.carolM {
+		t.Fatalf("rejoin reused removed member: %v", resp)
+	}
+}
@@ -0,0 +1,411 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/cloudwego/hertz/pkg/app"
+
+	"scorehub/internal/http/middleware"
+	"scorehub/internal/store"
+)
+
+const (
+	// maxPersonNameLen / maxPersonNoteLen 为联系人名称与备注的长度上限（字符数）。
+	maxPersonNameLen = 30
+	maxPersonNoteLen = 200
+)
+
+type PeopleHandlers struct {
+	st store.PeopleRepo
+}
+
+func NewPeopleHandlers(st store.PeopleRepo) *PeopleHandlers {
+	return &PeopleHandlers{st: st}
+}
+
+type createPersonRequest struct {
+	Name string `json:"name"`
+	Note string `json:"note"`
+}
+
+type updatePersonRequest struct {
+	Name *string `json:"name"`
+	Note *string `json:"note"`
+}
+
+type linkLedgerMemberRequest struct {
+	LedgerID string `json:"ledgerId"`
+	MemberID string `json:"memberId"`
+}
+
+type linkBirthdayRequest struct {
+	BirthdayID string `json:"birthdayId"`
+}
+
+func (h *PeopleHandlers) CreatePerson(ctx context.Context, c *app.RequestContext) {
+	uid, ok := middleware.UserID(c)
+	if !ok {
+		writeError(c, http.StatusUnauthorized, "unauthorized", "missing user")
+		return
+	}
+
+	var req createPersonRequest
+	if !readPersonJSON(c, &req) {
+		return
+	}
+	name := strings.TrimSpace(req.Name)
+	note := strings.TrimSpace(req.Note)
+	if name == "" || utf8.RuneCountInString(name) > maxPersonNameLen {
+		writeError(c, http.StatusBadRequest, "bad_request", "invalid name")
+		return
+	}
+	if utf8.RuneCountInString(note) > maxPersonNoteLen {
+		writeError(c, http.StatusBadRequest, "bad_request", "note too long")
+		return
+	}
+
+	p, err := h.st.CreatePerson(ctx, uid, name, note)
+	if err != nil {
+		writePersonError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, map[string]any{"person": toPersonDTO(p)})
+}
+
+// ListPeople 按名称列出联系人，q 为名称关键字。
+func (h *PeopleHandlers) ListPeople(ctx context.Context, c *app.RequestContext) {
+	uid, ok := middleware.UserID(c)
+	if !ok {
+		writeError(c, http.StatusUnauthorized, "unauthorized", "missing user")
+		return
+	}
+
+	limit := int32(20)
+	offset := int32(0)
+	if v := strings.TrimSpace(string(c.Query("limit"))); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 && n <= 200 {
+			limit = int32(n)
+		}
+	}
+	if v := strings.TrimSpace(string(c.Query("offset"))); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n >= 0 {
+			offset = int32(n)
+		}
+	}
+	q := strings.TrimSpace(string(c.Query("q")))
+
+	people, err := h.st.ListPeople(ctx, uid, q, limit, offset)
+	if err != nil {
+		writeError(c, http.StatusInternalServerError, "internal", "db error", err)
+		return
+	}
+	items := make([]map[string]any, 0, len(people))
+	for _, p := range people {
+		items = append(items, toPersonDTO(p))
+	}
+	c.JSON(http.StatusOK, map[string]any{"items": items, "limit": limit, "offset": offset})
+}
+
+// GetPerson 返回联系人及其关联的账本成员与生日联系人。
+func (h *PeopleHandlers) GetPerson(ctx context.Context, c *app.RequestContext) {
+	uid, ok := middleware.UserID(c)
+	if !ok {
+		writeError(c, http.StatusUnauthorized, "unauthorized", "missing user")
+		return
+	}
+	id := strings.TrimSpace(c.Param("id"))
+	if id == "" {
+		writeError(c, http.StatusBadRequest, "bad_request", "id required")
+		return
+	}
+
+	p, links, err := h.st.GetPerson(ctx, uid, id)
+	if err != nil {
+		writePersonError(c, err)
+		return
+	}
+	members := make([]map[string]any, 0, len(links.LedgerMembers))
+	for _, m := range links.LedgerMembers {
+		members = append(members, map[string]any{
+			"ledgerId":     m.LedgerID,
+			"ledgerName":   m.LedgerName,
+			"ledgerStatus": m.LedgerStatus,
+			"memberId":     m.MemberID,
+			"nickname":     m.Nickname,
+			"remark":       m.Remark,
+		})
+	}
+	birthdays := make([]map[string]any, 0, len(links.BirthdayContacts))
+	for _, b := range links.BirthdayContacts {
+		birthdays = append(birthdays, map[string]any{"id": b.ID, "name": b.Name, "relation": b.Relation})
+	}
+	c.JSON(http.StatusOK, map[string]any{
+		"person":        toPersonDTO(p),
+		"ledgerMembers": members,
+		"birthdays":     birthdays,
+	})
+}
+
+func (h *PeopleHandlers) UpdatePerson(ctx context.Context, c *app.RequestContext) {
+	uid, ok := middleware.UserID(c)
+	if !ok {
+		writeError(c, http.StatusUnauthorized, "unauthorized", "missing user")
+		return
+	}
+	id := strings.TrimSpace(c.Param("id"))
+	if id == "" {
+		writeError(c, http.StatusBadRequest, "bad_request", "id required")
+		return
+	}
+
+	var req updatePersonRequest
+	if !readPersonJSON(c, &req) {
+		return
+	}
+	if req.Name != nil {
+		name := strings.TrimSpace(*req.Name)
+		if name == "" || utf8.RuneCountInString(name) > maxPersonNameLen {
+			writeError(c, http.StatusBadRequest, "bad_request", "invalid name")
+			return
+		}
+		req.Name = &name
+	}
+	if req.Note != nil {
+		note := strings.TrimSpace(*req.Note)
+		if utf8.RuneCountInString(note) > maxPersonNoteLen {
+			writeError(c, http.StatusBadRequest, "bad_request", "note too long")
+			return
+		}
+		req.Note = &note
+	}
+
+	p, err := h.st.UpdatePerson(ctx, uid, id, req.Name, req.Note)
+	if err != nil {
+		writePersonError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, map[string]any{"person": toPersonDTO(p)})
+}
+
+// DeletePerson 删除联系人，关联的账本成员与生日联系人仅解除关联。
+func (h *PeopleHandlers) DeletePerson(ctx context.Context, c *app.RequestContext) {
+	uid, ok := middleware.UserID(c)
+	if !ok {
+		writeError(c, http.StatusUnauthorized, "unauthorized", "missing user")
+		return
+	}
+	id := strings.TrimSpace(c.Param("id"))
+	if id == "" {
+		writeError(c, http.StatusBadRequest, "bad_request", "id required")
+		return
+	}
+
+	if err := h.st.DeletePerson(ctx, uid, id); err != nil {
+		writePersonError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, map[string]any{"ok": true})
+}
+
+// LinkLedgerMember 将自己账本中的成员关联到联系人（成员原有关联会被替换）。
+func (h *PeopleHandlers) LinkLedgerMember(ctx context.Context, c *app.RequestContext) {
+	uid, ok := middleware.UserID(c)
+	if !ok {
+		writeError(c, http.StatusUnauthorized, "unauthorized", "missing user")
+		return
+	}
+	id := strings.TrimSpace(c.Param("id"))
+	if id == "" {
+		writeError(c, http.StatusBadRequest, "bad_request", "id required")
+		return
+	}
+
+	var req linkLedgerMemberRequest
+	if !readPersonJSON(c, &req) {
+		return
+	}
+	ledgerID := strings.TrimSpace(req.LedgerID)
+	memberID := strings.TrimSpace(req.MemberID)
+	if ledgerID == "" || memberID == "" {
+		writeError(c, http.StatusBadRequest, "bad_request", "ledgerId and memberId required")
+		return
+	}
+
+	if err := h.st.LinkLedgerMember(ctx, uid, id, ledgerID, memberID); err != nil {
+		if err == store.ErrInvalidArgument {
+			writeError(c, http.StatusBadRequest, "bad_request", "cannot link ledger owner")
+			return
+		}
+		writePersonError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, map[string]any{"ok": true})
+}
+
+func (h *PeopleHandlers) UnlinkLedgerMember(ctx context.Context, c *app.RequestContext) {
+	uid, ok := middleware.UserID(c)
+	if !ok {
+		writeError(c, http.StatusUnauthorized, "unauthorized", "missing user")
+		return
+	}
+	id := strings.TrimSpace(c.Param("id"))
+	memberID := strings.TrimSpace(c.Param("memberId"))
+	if id == "" || memberID == "" {
+		writeError(c, http.StatusBadRequest, "bad_request", "id required")
+		return
+	}
+
+	if err := h.st.UnlinkLedgerMember(ctx, uid, id, memberID); err != nil {
+		writePersonError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, map[string]any{"ok": true})
+}
+
+// LinkBirthday 将自己的生日联系人关联到联系人（原有关联会被替换）。
+func (h *PeopleHandlers) LinkBirthday(ctx context.Context, c *app.RequestContext) {
+	uid, ok := middleware.UserID(c)
+	if !ok {
+		writeError(c, http.StatusUnauthorized, "unauthorized", "missing user")
+		return
+	}
+	id := strings.TrimSpace(c.Param("id"))
+	if id == "" {
+		writeError(c, http.StatusBadRequest, "bad_request", "id required")
+		return
+	}
+
+	var req linkBirthdayRequest
+	if !readPersonJSON(c, &req) {
+		return
+	}
+	birthdayID := strings.TrimSpace(req.BirthdayID)
+	if birthdayID == "" {
+		writeError(c, http.StatusBadRequest, "bad_request", "birthdayId required")
+		return
+	}
+
+	if err := h.st.LinkBirthdayContact(ctx, uid, id, birthdayID); err != nil {
+		writePersonError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, map[string]any{"ok": true})
+}
+
+func (h *PeopleHandlers) UnlinkBirthday(ctx context.Context, c *app.RequestContext) {
+	uid, ok := middleware.UserID(c)
+	if !ok {
+		writeError(c, http.StatusUnauthorized, "unauthorized", "missing user")
+		return
+	}
+	id := strings.TrimSpace(c.Param("id"))
+	birthdayID := strings.TrimSpace(c.Param("birthdayId"))
+	if id == "" || birthdayID == "" {
+		writeError(c, http.StatusBadRequest, "bad_request", "id required")
+		return
+	}
+
+	if err := h.st.UnlinkBirthdayContact(ctx, uid, id, birthdayID); err != nil {
+		writePersonError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, map[string]any{"ok": true})
+}
+
+// GetPersonHistory 返回与联系人的人情往来：在自己所有账本中收到（收入）与送出（支出）
+// 的记录，按时间倒序，附合计与最近一次收/送。
+func (h *PeopleHandlers) GetPersonHistory(ctx context.Context, c *app.RequestContext) {
+	uid, ok := middleware.UserID(c)
+	if !ok {
+		writeError(c, http.StatusUnauthorized, "unauthorized", "missing user")
+		return
+	}
+	id := strings.TrimSpace(c.Param("id"))
+	if id == "" {
+		writeError(c, http.StatusBadRequest, "bad_request", "id required")
+		return
+	}
+
+	hist, err := h.st.GetPersonHistory(ctx, uid, id)
+	if err != nil {
+		writePersonError(c, err)
+		return
+	}
+
+	entries := make([]map[string]any, 0, len(hist.Entries))
+	var lastReceived, lastGiven any
+	for _, e := range hist.Entries {
+		dto := map[string]any{
+			"ledgerId":   e.LedgerID,
+			"ledgerName": e.LedgerName,
+			"recordId":   e.RecordID,
+			"memberId":   e.MemberID,
+			"direction":  e.Direction,
+			"amount":     e.Amount,
+			"note":       e.Note,
+			"createdAt":  e.CreatedAt,
+		}
+		entries = append(entries, dto)
+		if e.Direction == "given" && lastGiven == nil {
+			lastGiven = dto
+		}
+		if e.Direction == "received" && lastReceived == nil {
+			lastReceived = dto
+		}
+	}
+	c.JSON(http.StatusOK, map[string]any{
+		"person": toPersonDTO(hist.Person),
+		"summary": map[string]any{
+			"received":      hist.Received,
+			"given":         hist.Given,
+			"balance":       math.Round((hist.Received-hist.Given)*100) / 100,
+			"receivedCount": hist.ReceivedCount,
+			"givenCount":    hist.GivenCount,
+		},
+		"lastReceived": lastReceived,
+		"lastGiven":    lastGiven,
+		"entries":      entries,
+	})
+}
+
+func readPersonJSON(c *app.RequestContext, v any) bool {
+	body, err := c.Body()
+	if err != nil {
+		writeError(c, http.StatusBadRequest, "bad_request", "read body failed")
+		return false
+	}
+	if err := json.Unmarshal(body, v); err != nil {
+		writeError(c, http.StatusBadRequest, "bad_request", "invalid json")
+		return false
+	}
+	return true
+}
+
+func writePersonError(c *app.RequestContext, err error) {
+	switch err {
+	case store.ErrNotFound:
+		writeError(c, http.StatusNotFound, "not_found", "not found")
+	case store.ErrForbidden:
+		writeError(c, http.StatusForbidden, "forbidden", "no permission")
+	case store.ErrInvalidArgument:
+		writeError(c, http.StatusBadRequest, "bad_request", "invalid argument")
+	default:
+		writeError(c, http.StatusInternalServerError, "internal", "db error", err)
+	}
+}
+
+func toPersonDTO(p store.Person) map[string]any {
+	return map[string]any{
+		"id":        p.ID,
+		"name":      p.Name,
+		"note":      p.Note,
+		"createdAt": p.CreatedAt,
+		"updatedAt": p.UpdatedAt,
+	}
+}
@@ -0,0 +1,112 @@
+package handlers_test
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPeopleAndHistory(t *testing.T) {
+	api := newTestAPI(t)
+	clock := time.Date(2026, 5, 1, 9, 0, 0, 0, time.UTC)
+	api.st.Now = func() time.Time {
+		clock = clock.Add(time.Minute)
+		return clock
+	}
+	alice := api.login("alice", "Alice")
+	bob := api.login("bob", "Bob")
+
+	resp := api.expect(200, "POST", "/api/v1/people", alice, map[string]any{"name": " 张三 ", "note": "大学同学"})
+	zhang := str(resp, "person", "id")
+	if str(resp, "person", "name") != "张三" || str(resp, "person", "note") != "大学同学" {
+		t.Fatalf("create person: %v", resp)
+	}
+	api.expect(200, "POST", "/api/v1/people", alice, map[string]any{"name": "李四"})
+	api.expectError(400, "bad_request", "POST", "/api/v1/people", alice, map[string]any{"name": " "})
+	items := list(api.expect(200, "GET", "/api/v1/people?q=张", alice, nil), "items")
+	if len(items) != 1 || str(items[0], "id") != zhang {
+		t.Fatalf("search people: %v", items)
+	}
+	if items := list(api.expect(200, "GET", "/api/v1/people", bob, nil), "items"); len(items) != 0 {
+		t.Fatalf("people of another user: %v", items)
+	}
+	api.expectError(404, "not_found", "GET", "/api/v1/people/"+zhang, bob, nil)
+	resp = api.expect(200, "PATCH", "/api/v1/people/"+zhang, alice, map[string]any{"note": "同学"})
+	if str(resp, "person", "name") != "张三" || str(resp, "person", "note") != "同学" {
+		t.Fatalf("update person: %v", resp)
+	}
+
+	// 两个账本里的张三：自己婚礼收礼，对方婚礼送礼
+	resp = api.expect(200, "POST", "/api/v1/ledgers", alice, map[string]any{"name": "我的婚礼"})
+	wedding := str(resp, "ledger", "id")
+	owner := str(resp, "member", "id")
+	weddingPath := "/api/v1/ledgers/" + wedding
+	zhangA := str(api.expect(200, "POST", weddingPath+"/members", alice, map[string]any{"nickname": "张三"}), "member", "id")
+	api.expect(200, "POST", weddingPath+"/records", alice, map[string]any{"memberId": zhangA, "type": "income", "amount": 600})
+	api.expect(200, "POST", weddingPath+"/records", alice, map[string]any{"memberId": zhangA, "type": "income", "amount": 200.5, "note": "红包"})
+
+	resp = api.expect(200, "POST", "/api/v1/ledgers", alice, map[string]any{"name": "人情往来"})
+	gifts := str(resp, "ledger", "id")
+	giftsPath := "/api/v1/ledgers/" + gifts
+	zhangB := str(api.expect(200, "POST", giftsPath+"/members", alice, map[string]any{"nickname": "张三结婚"}), "member", "id")
+	api.expect(200, "POST", giftsPath+"/records", alice, map[string]any{"memberId": zhangB, "type": "expense", "amount": 1000, "note": "婚礼"})
+
+	link := "/api/v1/people/" + zhang + "/ledger_members"
+	api.expect(200, "POST", link, alice, map[string]any{"ledgerId": wedding, "memberId": zhangA})
+	api.expect(200, "POST", link, alice, map[string]any{"ledgerId": gifts, "memberId": zhangB})
+	api.expectError(400, "bad_request", "POST", link, alice, map[string]any{"ledgerId": wedding, "memberId": owner})
+	api.expectError(404, "not_found", "POST", link, alice, map[string]any{"ledgerId": wedding, "memberId": zhangB})
+	bobPerson := str(api.expect(200, "POST", "/api/v1/people", bob, map[string]any{"name": "张三"}), "person", "id")
+	api.expectError(403, "forbidden", "POST", "/api/v1/people/"+bobPerson+"/ledger_members", bob, map[string]any{"ledgerId": wedding, "memberId": zhangA})
+
+	birthday := str(api.expect(200, "POST", "/api/v1/birthdays", alice, map[string]any{
+		"name": "张三", "relation": "同学", "solarBirthday": "1995-08-01", "primaryType": "solar",
+	}), "birthday", "id")
+	api.expect(200, "POST", "/api/v1/people/"+zhang+"/birthdays", alice, map[string]any{"birthdayId": birthday})
+	api.expectError(404, "not_found", "POST", "/api/v1/people/"+bobPerson+"/birthdays", bob, map[string]any{"birthdayId": birthday})
+
+	resp = api.expect(200, "GET", "/api/v1/people/"+zhang, alice, nil)
+	members := list(resp, "ledgerMembers")
+	if len(members) != 2 || str(findBy(t, members, "memberId", zhangA), "ledgerName") != "我的婚礼" {
+		t.Fatalf("linked members: %v", members)
+	}
+	if b := list(resp, "birthdays"); len(b) != 1 || str(b[0], "id") != birthday || str(b[0], "relation") != "同学" {
+		t.Fatalf("linked birthdays: %v", b)
+	}
+
+	resp = api.expect(200, "GET", "/api/v1/people/"+zhang+"/history", alice, nil)
+	sum := obj(resp, "summary")
+	if num(sum, "received") != 800.5 || num(sum, "given") != 1000 || num(sum, "balance") != -199.5 ||
+		num(sum, "receivedCount") != 2 || num(sum, "givenCount") != 1 {
+		t.Fatalf("history summary: %v", sum)
+	}
+	entries := list(resp, "entries")
+	if len(entries) != 3 || str(entries[0], "direction") != "given" || str(entries[0], "ledgerId") != gifts ||
+		num(entries[0], "amount") != 1000 || str(entries[1], "note") != "红包" {
+		t.Fatalf("history entries: %v", entries)
+	}
+	if num(obj(resp, "lastReceived"), "amount") != 200.5 || str(obj(resp, "lastGiven"), "note") != "婚礼" {
+		t.Fatalf("history last: %v", resp)
+	}
+	api.expectError(404, "not_found", "GET", "/api/v1/people/"+zhang+"/history", bob, nil)
+
+	// 解除关联、删除账本后不再计入
+	api.expect(200, "DELETE", link+"/"+zhangB, alice, nil)
+	api.expectError(404, "not_found", "DELETE", link+"/"+zhangB, alice, nil)
+	sum = obj(api.expect(200, "GET", "/api/v1/people/"+zhang+"/history", alice, nil), "summary")
+	if num(sum, "given") != 0 || num(sum, "received") != 800.5 {
+		t.Fatalf("history after unlink: %v", sum)
+	}
+	api.expect(200, "POST", weddingPath+"/end", alice, nil)
+	api.expect(200, "DELETE", weddingPath, alice, nil)
+	resp = api.expect(200, "GET", "/api/v1/people/"+zhang+"/history", alice, nil)
+	if len(list(resp, "entries")) != 0 || field(resp, "lastReceived") != nil {
+		t.Fatalf("history after ledger delete: %v", resp)
+	}
+
+	api.expect(200, "DELETE", "/api/v1/people/"+zhang+"/birthdays/"+birthday, alice, nil)
+	api.expect(200, "POST", "/api/v1/people/"+zhang+"/birthdays", alice, map[string]any{"birthdayId": birthday})
+	api.expect(200, "DELETE", "/api/v1/people/"+zhang, alice, nil)
+	api.expectError(404, "not_found", "GET", "/api/v1/people/"+zhang, alice, nil)
+	// 生日联系人本身保留
+	api.expect(200, "GET", "/api/v1/birthdays/"+birthday, alice, nil)
+}
@@ -0,0 +1,213 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math"
+	"net/http"
+	"strings"
+
+	"github.com/cloudwego/hertz/pkg/app"
+
+	"scorehub/internal/http/middleware"
+	"scorehub/internal/store"
+)
+
+type updatePointRateRequest struct {
+	PointValue *float64 `json:"pointValue"`
+	Currency   *string  `json:"currency"`
+}
+
+// UpdatePointRate 修改积分单价或币种（仅掌柜或管理员，记录中的得分簿）。
+// 修改只影响之后的记录，之前的记录仍按原单价折算金额；有记录后不能再改币种。
+func (h *ScorebookHandlers) UpdatePointRate(ctx context.Context, c *app.RequestContext) {
+	uid, ok := middleware.UserID(c)
+	if !ok {
+		writeError(c, http.StatusUnauthorized, "unauthorized", "missing user")
+		return
+	}
+	id := strings.TrimSpace(c.Param("id"))
+	if id == "" {
+		writeError(c, http.StatusBadRequest, "bad_request", "id required")
+		return
+	}
+
+	var req updatePointRateRequest
+	body, err := c.Body()
+	if err != nil {
+		writeError(c, http.StatusBadRequest, "bad_request", "read body failed")
+		return
+	}
+	if err := json.Unmarshal(body, &req); err != nil {
+		writeError(c, http.StatusBadRequest, "bad_request", "invalid json")
+		return
+	}
+	if req.PointValue == nil && req.Currency == nil {
+		writeError(c, http.StatusBadRequest, "bad_request", "pointValue or currency required")
+		return
+	}
+
+	in := store.PointRateUpdate{PointValue: req.PointValue}
+	if req.PointValue != nil && !validPointValue(*req.PointValue) {
+		writeError(c, http.StatusBadRequest, "bad_request", "pointValue must be positive with at most 4 decimals")
+		return
+	}
+	if req.Currency != nil {
+		currency, ok := normalizeCurrencyCode(*req.Currency)
+		if !ok {
+			writeError(c, http.StatusBadRequest, "bad_request", "currency must be a 3-letter code")
+			return
+		}
+		in.Currency = &currency
+	}
+
+	rate, changed, err := h.st.UpdatePointRate(ctx, id, uid, in)
+	if err != nil {
+		switch err {
+		case store.ErrNotFound:
+			writeError(c, http.StatusNotFound, "not_found", "scorebook not found")
+		case store.ErrForbidden:
+			writeError(c, http.StatusForbidden, "forbidden", "only owner or admin can change point value")
+		case store.ErrScorebookEnded:
+			writeError(c, http.StatusBadRequest, "ended", "scorebook ended")
+		case store.ErrCurrencyLocked:
+			writeError(c, http.StatusConflict, "currency_locked", "currency cannot change once the scorebook has records")
+		default:
+			writeError(c, http.StatusInternalServerError, "internal", "db error", err)
+		}
+		return
+	}
+
+	if changed {
+		h.hub.Broadcast(id, map[string]any{
+			"type": "scorebook.point_rate_changed",
+			"data": map[string]any{"pointRate": toPointRateDTO(rate)},
+		})
+	}
+	c.JSON(http.StatusOK, map[string]any{"pointRate": toPointRateDTO(rate), "changed": changed})
+}
+
+// ListPointRates 返回积分单价的修改历史（最早的在前），任何成员可查看。
+func (h *ScorebookHandlers) ListPointRates(ctx context.Context, c *app.RequestContext) {
+	uid, ok := middleware.UserID(c)
+	if !ok {
+		writeError(c, http.StatusUnauthorized, "unauthorized", "missing user")
+		return
+	}
+	id := strings.TrimSpace(c.Param("id"))
+	if id == "" {
+		writeError(c, http.StatusBadRequest, "bad_request", "id required")
+		return
+	}
+
+	rates, err := h.st.ListPointRates(ctx, id, uid)
+	if err != nil {
+		if err == store.ErrNotFound {
+			writeError(c, http.StatusNotFound, "not_found", "scorebook not found")
+			return
+		}
+		writeError(c, http.StatusInternalServerError, "internal", "db error", err)
+		return
+	}
+	items := make([]map[string]any, 0, len(rates))
+	for _, r := range rates {
+		items = append(items, toPointRateDTO(r))
+	}
+	c.JSON(http.StatusOK, map[string]any{"items": items})
+}
+
+func toPointRateDTO(r store.PointRate) map[string]any {
+	out := map[string]any{
+		"id":          r.ID,
+		"pointValue":  r.PointValue,
+		"currency":    r.Currency,
+		"effectiveAt": r.EffectiveAt,
+	}
+	// 初始单价没有修改人
+	if r.ChangedByUserID != 0 {
+		out["changedByUserId"] = r.ChangedByUserID
+	}
+	return out
+}
+
+// EndedMoney 汇总结束时的金额信息：当前单价与币种、每位成员的积分与金额，以及按金额
+// 计算的结算方案。单价中途修改过时金额与积分不成比例，所以金额方案单独计算。
+// 手动结束与自动结束共用，uid 是有权查看单价的成员（自动结束时为掌柜）。
+// 出错时仍返回能算出的部分，错误不影响结束流程，由调用方记录。
+func EndedMoney(ctx context.Context, st store.ScorebookRepo, scorebookID string, uid int64) (map[string]any, error) {
+	var errs []error
+	out := map[string]any{"pointValue": 1.0, "currency": store.DefaultCurrency, "rateChanges": 0}
+	if rates, err := st.ListPointRates(ctx, scorebookID, uid); err == nil && len(rates) > 0 {
+		cur := rates[len(rates)-1]
+		out["pointValue"] = cur.PointValue
+		out["currency"] = cur.Currency
+		out["rateChanges"] = len(rates) - 1
+	} else if err != nil {
+		errs = append(errs, err)
+	}
+
+	balances := []any{}
+	settlement := []any{}
+	if members, err := st.ListMemberBalances(ctx, scorebookID); err == nil {
+		nicknames := make(map[string]string, len(members))
+		byMoney := make([]store.MemberWithScore, 0, len(members))
+		for _, m := range members {
+			nicknames[m.ID] = m.Nickname
+			if m.Score != 0 || m.Money != 0 {
+				balances = append(balances, toBalanceDTO(m))
+			}
+			m.Score = m.Money
+			byMoney = append(byMoney, m)
+		}
+		if plan, err := store.PlanSettlement(byMoney); err == nil {
+			for _, t := range plan {
+				settlement = append(settlement, map[string]any{
+					"fromMemberId": t.FromMemberID,
+					"fromNickname": nicknames[t.FromMemberID],
+					"toMemberId":   t.ToMemberID,
+					"toNickname":   nicknames[t.ToMemberID],
+					"money":        t.Amount,
+				})
+			}
+		} else {
+			errs = append(errs, err)
+		}
+	} else {
+		errs = append(errs, err)
+	}
+	out["balances"] = balances
+	out["settlement"] = settlement
+	return out, errors.Join(errs...)
+}
+
+// toBalanceDTO 是结束时每位成员的积分与折算金额。
+func toBalanceDTO(m store.MemberWithScore) map[string]any {
+	return map[string]any{
+		"memberId": m.ID,
+		"nickname": m.Nickname,
+		"score":    m.Score,
+		"money":    m.Money,
+	}
+}
+
+func validPointValue(v float64) bool {
+	if v <= 0 || v >= 1e8 || math.IsNaN(v) || math.IsInf(v, 0) {
+		return false
+	}
+	return math.Abs(v*1e4-math.Round(v*1e4)) < 1e-6
+}
+
+// normalizeCurrencyCode 接受 3 位字母的币种代码（如 CNY、USD），统一为大写。
+func normalizeCurrencyCode(v string) (string, bool) {
+	v = strings.ToUpper(strings.TrimSpace(v))
+	if len(v) != 3 {
+		return "", false
+	}
+	for _, r := range v {
+		if r < 'A' || r > 'Z' {
+			return "", false
+		}
+	}
+	return v, true
+}
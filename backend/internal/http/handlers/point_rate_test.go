@@ -0,0 +1,113 @@
+package handlers_test
+
+import (
+	"testing"
+	"time"
+)
+
+func TestScorebookPointRate(t *testing.T) {
+	f := newScorebook(t)
+	api := f.api
+	now := time.Now()
+	api.st.Now = func() time.Time { return now }
+
+	resp := api.expect(200, "GET", f.path(""), f.alice, nil)
+	if num(resp, "scorebook", "game", "pointValue") != 1 || str(resp, "scorebook", "game", "currency") != "CNY" {
+		t.Fatalf("default point rate: %v", resp)
+	}
+
+	// 记分前改为美元，按 1 分 = 1 美元记一笔，之后改为 1 分 = 0.5 美元
+	resp = api.expect(200, "PATCH", f.path("/point_rate"), f.alice, map[string]any{"currency": "usd"})
+	if !boolean(resp, "changed") || num(resp, "pointRate", "pointValue") != 1 || str(resp, "pointRate", "currency") != "USD" {
+		t.Fatalf("set currency: %v", resp)
+	}
+	now = now.Add(time.Minute)
+	resp = api.expect(200, "POST", f.path("/records"), f.bob, map[string]any{"toMemberId": f.carolM, "delta": 10})
+	first := str(resp, "record", "id")
+
+	api.expectError(403, "forbidden", "PATCH", f.path("/point_rate"), f.bob, map[string]any{"pointValue": 0.5})
+	api.expectError(400, "bad_request", "PATCH", f.path("/point_rate"), f.alice, map[string]any{})
+	api.expectError(400, "bad_request", "PATCH", f.path("/point_rate"), f.alice, map[string]any{"pointValue": 0})
+	api.expectError(400, "bad_request", "PATCH", f.path("/point_rate"), f.alice, map[string]any{"pointValue": 0.12345})
+	api.expectError(400, "bad_request", "PATCH", f.path("/point_rate"), f.alice, map[string]any{"currency": "RMB1"})
+
+	now = now.Add(time.Minute)
+	resp = api.expect(200, "PATCH", f.path("/point_rate"), f.alice, map[string]any{"pointValue": 0.5, "currency": " usd "})
+	if !boolean(resp, "changed") || num(resp, "pointRate", "pointValue") != 0.5 || str(resp, "pointRate", "currency") != "USD" {
+		t.Fatalf("update point rate: %v", resp)
+	}
+	resp = api.expect(200, "PATCH", f.path("/point_rate"), f.alice, map[string]any{"pointValue": 0.5})
+	if boolean(resp, "changed") {
+		t.Fatalf("unchanged point rate: %v", resp)
+	}
+	// 有记录后不能再换币种
+	api.expectError(409, "currency_locked", "PATCH", f.path("/point_rate"), f.alice, map[string]any{"currency": "EUR"})
+
+	now = now.Add(time.Minute)
+	api.expect(200, "POST", f.path("/records"), f.bob, map[string]any{"toMemberId": f.carolM, "delta": 10})
+
+	resp = api.expect(200, "GET", f.path(""), f.carol, nil)
+	carol := findBy(t, list(resp, "members"), "id", f.carolM)
+	if num(carol, "score") != 20 || num(carol, "money") != 15 {
+		t.Fatalf("money by rate history: %v", carol)
+	}
+	if num(resp, "scorebook", "game", "pointValue") != 0.5 || str(resp, "scorebook", "game", "currency") != "USD" {
+		t.Fatalf("detail point rate: %v", resp)
+	}
+
+	// 作废按原记录的单价冲正
+	api.expect(200, "POST", f.path("/records/"+first+"/void"), f.bob, nil)
+	resp = api.expect(200, "GET", f.path(""), f.carol, nil)
+	carol = findBy(t, list(resp, "members"), "id", f.carolM)
+	bob := findBy(t, list(resp, "members"), "id", f.bobM)
+	if num(carol, "score") != 10 || num(carol, "money") != 5 || num(bob, "money") != -5 {
+		t.Fatalf("money after void: %v", resp)
+	}
+
+	resp = api.expect(200, "GET", f.path("/point_rates"), f.carol, nil)
+	items := list(resp, "items")
+	if len(items) != 3 || num(items[0], "pointValue") != 1 || field(items[0], "changedByUserId") != nil ||
+		str(items[1], "currency") != "USD" || num(items[2], "pointValue") != 0.5 || field(items[2], "changedByUserId") == nil {
+		t.Fatalf("point rate history: %v", resp)
+	}
+
+	resp = api.expect(200, "POST", f.path("/end"), f.alice, nil)
+	if num(resp, "money", "pointValue") != 0.5 || str(resp, "money", "currency") != "USD" || num(resp, "money", "rateChanges") != 2 {
+		t.Fatalf("ended money: %v", resp)
+	}
+	if len(list(resp, "money", "balances")) != 2 {
+		t.Fatalf("ended balances: %v", resp)
+	}
+	plan := list(resp, "money", "settlement")
+	if len(plan) != 1 || str(plan[0], "fromMemberId") != f.bobM || str(plan[0], "toMemberId") != f.carolM || num(plan[0], "money") != 5 {
+		t.Fatalf("money settlement: %v", resp)
+	}
+	if champion := obj(resp, "winners", "champion"); num(champion, "money") != 5 {
+		t.Fatalf("champion money: %v", resp)
+	}
+
+	api.expectError(400, "ended", "PATCH", f.path("/point_rate"), f.alice, map[string]any{"pointValue": 1})
+}
+
+func TestScorebookMoneyRoundsPerRecord(t *testing.T) {
+	f := newScorebook(t)
+	api := f.api
+
+	// 每笔 0.125 元：按笔取整到分，成员金额之和才为零
+	api.expect(200, "PATCH", f.path("/point_rate"), f.alice, map[string]any{"pointValue": 0.125})
+	api.expect(200, "POST", f.path("/records"), f.alice, map[string]any{"toMemberId": f.bobM, "delta": 1})
+	api.expect(200, "POST", f.path("/records"), f.carol, map[string]any{"toMemberId": f.bobM, "delta": 1})
+
+	resp := api.expect(200, "GET", f.path(""), f.alice, nil)
+	members := list(resp, "members")
+	if num(findBy(t, members, "id", f.bobM), "money") != 0.26 || num(findBy(t, members, "id", f.aliceM), "money") != -0.13 ||
+		num(findBy(t, members, "id", f.carolM), "money") != -0.13 {
+		t.Fatalf("money per record: %v", members)
+	}
+
+	resp = api.expect(200, "POST", f.path("/end"), f.alice, nil)
+	plan := list(resp, "money", "settlement")
+	if len(plan) != 2 || num(plan[0], "money") != 0.13 || num(plan[1], "money") != 0.13 {
+		t.Fatalf("money settlement: %v", resp)
+	}
+}
@@ -0,0 +1,166 @@
+package handlers
+
+import (
+	"github.com/cloudwego/hertz/pkg/route"
+
+	appconfig "scorehub/internal/config"
+	"scorehub/internal/http/middleware"
+	"scorehub/internal/realtime"
+	"scorehub/internal/store"
+)
+
+// Repos groups the repositories the API handlers depend on. In production every
+// field is the same *store.Store.
+type Repos struct {
+	Users      store.UserRepo
+	Scorebooks store.ScorebookRepo
+	Ledgers    store.LedgerRepo
+	Templates  store.TemplateRepo
+	Birthdays  store.BirthdayRepo
+	Deposits   store.DepositRepo
+	People     store.PeopleRepo
+}
+
+// RegisterRoutes mounts the /api/v1 and /ws routes on r.
+func RegisterRoutes(r route.IRouter, cfg appconfig.Config, repos Repos, hub *realtime.Hub) {
+	authHandlers := NewAuthHandlers(cfg, repos.Users)
+	meHandlers := NewMeHandlers(repos.Users)
+	statsHandlers := NewStatsHandlers(repos.Scorebooks)
+	scorebookHandlers := NewScorebookHandlers(cfg, repos.Users, repos.Scorebooks, hub)
+	ledgerHandlers := NewLedgerHandlers(cfg, repos.Users, repos.Ledgers, hub)
+	scorebookTransfers := NewOwnershipTransferHandlers(repos.Scorebooks, "scorebook", hub)
+	ledgerTransfers := NewOwnershipTransferHandlers(repos.Ledgers, "ledger", hub)
+	templateHandlers := NewTemplateHandlers(repos.Templates)
+	birthdayHandlers := NewBirthdayHandlers(repos.Birthdays)
+	depositHandlers := NewDepositHandlers(repos.Deposits)
+	peopleHandlers := NewPeopleHandlers(repos.People)
+	locationHandlers := NewLocationHandlers(cfg)
+
+	api := r.Group("/api/v1")
+	auth := api.Group("/auth")
+	auth.POST("/dev_login", authHandlers.DevLogin)
+	auth.POST("/wechat_login", authHandlers.WechatLogin)
+	auth.POST("/refresh", authHandlers.Refresh)
+
+	authed := api.Group("", middleware.AuthRequired(cfg, repos.Users))
+	authed.GET("/me", meHandlers.GetMe)
+	authed.PATCH("/me", meHandlers.UpdateMe)
+	authed.GET("/me/sessions", meHandlers.ListSessions)
+	authed.DELETE("/me/sessions/:id", meHandlers.RevokeSession)
+	authed.GET("/me/stats", statsHandlers.GetMyStats)
+	authed.GET("/me/frequent_players", scorebookHandlers.ListFrequentPlayers)
+	authed.GET("/me/invitations", scorebookHandlers.ListMyInvitations)
+	authed.POST("/me/invitations/:id/accept", scorebookHandlers.AcceptInvitation)
+	authed.POST("/me/invitations/:id/decline", scorebookHandlers.DeclineInvitation)
+	authed.POST("/auth/logout", authHandlers.Logout)
+	authed.POST("/scorebooks", scorebookHandlers.CreateScorebook)
+	authed.GET("/scorebooks", scorebookHandlers.ListMyScorebooks)
+	authed.GET("/scorebooks/:id", scorebookHandlers.GetScorebookDetail)
+	authed.PATCH("/scorebooks/:id", scorebookHandlers.UpdateScorebook)
+	authed.DELETE("/scorebooks/:id", scorebookHandlers.DeleteScorebook)
+	authed.POST("/scorebooks/:id/end", scorebookHandlers.EndScorebook)
+	authed.GET("/scorebooks/:id/settlement", scorebookHandlers.GetSettlement)
+	authed.PATCH("/scorebooks/:id/settlement/:transferId", scorebookHandlers.UpdateSettlementTransfer)
+	authed.GET("/scorebooks/:id/online", scorebookHandlers.GetOnline)
+	authed.POST("/scorebooks/:id/join", scorebookHandlers.JoinScorebook)
+	authed.PATCH("/scorebooks/:id/members/me", scorebookHandlers.UpdateMyProfile)
+	authed.PATCH("/scorebooks/:id/members/:memberId", scorebookHandlers.UpdateMember)
+	authed.DELETE("/scorebooks/:id/members/:memberId", scorebookHandlers.RemoveMember)
+	authed.GET("/scorebooks/:id/bans", scorebookHandlers.ListBans)
+	authed.DELETE("/scorebooks/:id/bans/:userId", scorebookHandlers.Unban)
+	authed.POST("/scorebooks/:id/transfer", scorebookTransfers.Request)
+	authed.GET("/scorebooks/:id/transfer", scorebookTransfers.Get)
+	authed.DELETE("/scorebooks/:id/transfer", scorebookTransfers.Cancel)
+	authed.POST("/scorebooks/:id/transfer/accept", scorebookTransfers.Accept)
+	authed.POST("/scorebooks/:id/transfer/decline", scorebookTransfers.Decline)
+	authed.GET("/scorebooks/:id/invite", scorebookHandlers.GetInvite)
+	authed.PATCH("/scorebooks/:id/invite", scorebookHandlers.UpdateInvite)
+	authed.POST("/scorebooks/:id/invite/regenerate", scorebookHandlers.RegenerateInvite)
+	authed.GET("/scorebooks/:id/invite/uses", scorebookHandlers.ListInviteUses)
+	authed.GET("/scorebooks/:id/invite_qrcode", scorebookHandlers.GetInviteQRCode)
+	authed.GET("/scorebooks/:id/join_requests", scorebookHandlers.ListJoinRequests)
+	authed.POST("/scorebooks/:id/invitations", scorebookHandlers.InviteUsers)
+	authed.POST("/scorebooks/:id/join_requests/:requestId/approve", scorebookHandlers.ApproveJoinRequest)
+	authed.POST("/scorebooks/:id/join_requests/:requestId/reject", scorebookHandlers.RejectJoinRequest)
+	authed.POST("/scorebooks/:id/records", scorebookHandlers.CreateRecord)
+	authed.GET("/scorebooks/:id/records", scorebookHandlers.ListRecords)
+	authed.GET("/scorebooks/:id/timeline", scorebookHandlers.GetScoreTimeline)
+	authed.GET("/scorebooks/:id/export", scorebookHandlers.ExportScorebook)
+	authed.POST("/scorebooks/:id/records/:recordId/void", scorebookHandlers.VoidRecord)
+	authed.POST("/scorebooks/:id/rounds", scorebookHandlers.CreateRound)
+	authed.PATCH("/scorebooks/:id/point_rate", scorebookHandlers.UpdatePointRate)
+	authed.GET("/scorebooks/:id/point_rates", scorebookHandlers.ListPointRates)
+	authed.POST("/invites/:code/join", scorebookHandlers.JoinByInviteCode)
+	authed.POST("/scorebook_templates", templateHandlers.CreateTemplate)
+	authed.GET("/scorebook_templates", templateHandlers.ListTemplates)
+	authed.GET("/scorebook_templates/:id", templateHandlers.GetTemplate)
+	authed.PATCH("/scorebook_templates/:id", templateHandlers.UpdateTemplate)
+	authed.DELETE("/scorebook_templates/:id", templateHandlers.DeleteTemplate)
+	authed.POST("/ledgers", ledgerHandlers.CreateLedger)
+	authed.GET("/ledgers", ledgerHandlers.ListLedgers)
+	authed.PATCH("/ledgers/:id", ledgerHandlers.UpdateLedger)
+	authed.DELETE("/ledgers/:id", ledgerHandlers.DeleteLedger)
+	authed.GET("/ledgers/:id/invite_qrcode", ledgerHandlers.GetInviteQRCode)
+	authed.POST("/ledgers/:id/bind", ledgerHandlers.BindLedgerMember)
+	authed.POST("/ledgers/:id/members", ledgerHandlers.AddLedgerMember)
+	authed.PATCH("/ledgers/:id/members/:memberId", ledgerHandlers.UpdateLedgerMember)
+	authed.POST("/ledgers/:id/records", ledgerHandlers.AddLedgerRecord)
+	authed.PATCH("/ledgers/:id/records/:recordId", ledgerHandlers.UpdateLedgerRecord)
+	authed.DELETE("/ledgers/:id/records/:recordId", ledgerHandlers.DeleteLedgerRecord)
+	authed.GET("/ledgers/:id/revisions", ledgerHandlers.ListRecordRevisions)
+	authed.GET("/ledgers/:id/categories", ledgerHandlers.ListLedgerCategories)
+	authed.POST("/ledgers/:id/categories", ledgerHandlers.CreateLedgerCategory)
+	authed.PATCH("/ledgers/:id/categories/:categoryId", ledgerHandlers.UpdateLedgerCategory)
+	authed.DELETE("/ledgers/:id/categories/:categoryId", ledgerHandlers.DeleteLedgerCategory)
+	authed.GET("/ledgers/:id/summary", ledgerHandlers.GetLedgerSummary)
+	authed.GET("/ledgers/:id/export", ledgerHandlers.ExportLedger)
+	authed.POST("/ledgers/:id/import", ledgerHandlers.ImportLedger)
+	authed.POST("/ledgers/:id/end", ledgerHandlers.EndLedger)
+	authed.GET("/ledgers/:id/recorders", ledgerHandlers.ListLedgerRecorders)
+	authed.DELETE("/ledgers/:id/recorders/:userId", ledgerHandlers.RemoveLedgerRecorder)
+	authed.POST("/ledgers/:id/recorder_invite", ledgerHandlers.ResetLedgerRecorderInvite)
+	authed.DELETE("/ledgers/:id/recorder_invite", ledgerHandlers.DisableLedgerRecorderInvite)
+	authed.GET("/ledger_recorder_invites/:code", ledgerHandlers.GetLedgerRecorderInvite)
+	authed.POST("/ledger_recorder_invites/:code/accept", ledgerHandlers.AcceptLedgerRecorderInvite)
+	authed.POST("/ledgers/:id/transfer", ledgerTransfers.Request)
+	authed.GET("/ledgers/:id/transfer", ledgerTransfers.Get)
+	authed.DELETE("/ledgers/:id/transfer", ledgerTransfers.Cancel)
+	authed.POST("/ledgers/:id/transfer/accept", ledgerTransfers.Accept)
+	authed.POST("/ledgers/:id/transfer/decline", ledgerTransfers.Decline)
+	authed.POST("/birthdays", birthdayHandlers.CreateBirthday)
+	authed.GET("/birthdays", birthdayHandlers.ListBirthdays)
+	authed.GET("/birthdays/:id", birthdayHandlers.GetBirthday)
+	authed.PATCH("/birthdays/:id", birthdayHandlers.UpdateBirthday)
+	authed.DELETE("/birthdays/:id", birthdayHandlers.DeleteBirthday)
+	authed.POST("/deposits/accounts", depositHandlers.CreateDepositAccount)
+	authed.GET("/deposits/accounts", depositHandlers.ListDepositAccounts)
+	authed.GET("/deposits/accounts/:id", depositHandlers.GetDepositAccount)
+	authed.PATCH("/deposits/accounts/:id", depositHandlers.UpdateDepositAccount)
+	authed.DELETE("/deposits/accounts/:id", depositHandlers.DeleteDepositAccount)
+	authed.POST("/deposits/accounts/:id/records", depositHandlers.CreateDepositRecord)
+	authed.GET("/deposits/accounts/:id/records", depositHandlers.ListDepositAccountRecords)
+	authed.GET("/deposits/records", depositHandlers.ListDepositRecords)
+	authed.GET("/deposits/records/:id", depositHandlers.GetDepositRecord)
+	authed.PATCH("/deposits/records/:id", depositHandlers.UpdateDepositRecord)
+	authed.DELETE("/deposits/records/:id", depositHandlers.DeleteDepositRecord)
+	authed.GET("/deposits/tags", depositHandlers.ListDepositTags)
+	authed.GET("/deposits/stats", depositHandlers.GetDepositStats)
+	authed.POST("/people", peopleHandlers.CreatePerson)
+	authed.GET("/people", peopleHandlers.ListPeople)
+	authed.GET("/people/:id", peopleHandlers.GetPerson)
+	authed.PATCH("/people/:id", peopleHandlers.UpdatePerson)
+	authed.DELETE("/people/:id", peopleHandlers.DeletePerson)
+	authed.GET("/people/:id/history", peopleHandlers.GetPersonHistory)
+	authed.POST("/people/:id/ledger_members", peopleHandlers.LinkLedgerMember)
+	authed.DELETE("/people/:id/ledger_members/:memberId", peopleHandlers.UnlinkLedgerMember)
+	authed.POST("/people/:id/birthdays", peopleHandlers.LinkBirthday)
+	authed.DELETE("/people/:id/birthdays/:birthdayId", peopleHandlers.UnlinkBirthday)
+
+	// Public: allow location & invite info lookup without login.
+	api.GET("/location/reverse_geocode", locationHandlers.ReverseGeocode)
+	api.GET("/invites/:code", middleware.AuthOptional(cfg, repos.Users), scorebookHandlers.GetInviteInfo)
+	api.GET("/ledgers/:id", ledgerHandlers.GetLedgerDetail)
+
+	r.GET("/ws/scorebooks/:id", scorebookHandlers.ScorebookWS)
+	r.GET("/ws/ledgers/:id", ledgerHandlers.LedgerWS)
+}
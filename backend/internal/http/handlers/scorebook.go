@@ -13,7 +13,6 @@ import (
 	"github.com/cloudwego/hertz/pkg/app"
 	"github.com/hertz-contrib/websocket"
 
-	appauth "scorehub/internal/auth"
 	appconfig "scorehub/internal/config"
 	"scorehub/internal/http/middleware"
 	"scorehub/internal/realtime"
@@ -21,18 +20,20 @@ import (
 )
 
 type ScorebookHandlers struct {
-	cfg appconfig.Config
-	st  *store.Store
-	hub *realtime.Hub
+	cfg   appconfig.Config
+	users store.UserRepo
+	st    store.ScorebookRepo
+	hub   *realtime.Hub
 
 	upgrader websocket.HertzUpgrader
 }
 
-func NewScorebookHandlers(cfg appconfig.Config, st *store.Store, hub *realtime.Hub) *ScorebookHandlers {
+func NewScorebookHandlers(cfg appconfig.Config, users store.UserRepo, st store.ScorebookRepo, hub *realtime.Hub) *ScorebookHandlers {
 	return &ScorebookHandlers{
-		cfg: cfg,
-		st:  st,
-		hub: hub,
+		cfg:   cfg,
+		users: users,
+		st:    st,
+		hub:   hub,
 		upgrader: websocket.HertzUpgrader{
 			CheckOrigin: func(ctx *app.RequestContext) bool { return true },
 		},
@@ -43,6 +44,10 @@ type createScorebookRequest struct {
 	Name         string `json:"name"`
 	LocationText string `json:"locationText"`
 	BookType     string `json:"bookType"`
+	// TemplateID 为自己的模板时，复制模板的玩法设置并添加占位成员
+	TemplateID string `json:"templateId"`
+	// InviteUserIDs 为常一起玩的用户，创建后收到站内邀请
+	InviteUserIDs []int64 `json:"inviteUserIds"`
 }
 
 func (h *ScorebookHandlers) CreateScorebook(ctx context.Context, c *app.RequestContext) {
@@ -75,14 +80,30 @@ func (h *ScorebookHandlers) CreateScorebook(ctx context.Context, c *app.RequestC
 		}
 	}
 
-	user, err := h.st.GetUserByID(ctx, uid)
+	if len(req.InviteUserIDs) > maxInvitees {
+		writeError(c, http.StatusBadRequest, "bad_request", "too many invitees")
+		return
+	}
+
+	user, err := h.users.GetUserByID(ctx, uid)
 	if err != nil {
 		writeError(c, http.StatusUnauthorized, "unauthorized", "user not found")
 		return
 	}
 
-	sb, owner, err := h.st.CreateScorebook(ctx, user, name, locationText, bookType)
+	sb, owner, err := h.st.CreateScorebook(ctx, user, name, locationText, bookType, strings.TrimSpace(req.TemplateID), req.InviteUserIDs)
 	if err != nil {
+		switch err {
+		case store.ErrNotFound:
+			writeError(c, http.StatusNotFound, "not_found", "template not found")
+			return
+		case store.ErrInvalidArgument:
+			writeError(c, http.StatusBadRequest, "bad_request", "can only invite users you have played with")
+			return
+		case store.ErrBanned:
+			writeError(c, http.StatusForbidden, "banned", "user is banned from this scorebook")
+			return
+		}
 		writeError(c, http.StatusInternalServerError, "internal", "db error", err)
 		return
 	}
@@ -136,7 +157,8 @@ func (h *ScorebookHandlers) ListMyScorebooks(ctx context.Context, c *app.Request
 			"bookType":     it.BookType,
 			"endedAt":      it.EndedAt,
 			"inviteCode":   it.InviteCode,
-			"isOwner":      it.MyRole == "owner",
+			"isOwner":      it.MyRole == store.RoleOwner,
+			"myRole":       it.MyRole,
 			"memberCount":  it.MemberCount,
 		})
 	}
@@ -168,14 +190,18 @@ func (h *ScorebookHandlers) GetScorebookDetail(ctx context.Context, c *app.Reque
 
 	var memOut []any
 	for _, m := range members {
-		memOut = append(memOut, toMemberDTO(m.Member, m.Score, myMemberID))
+		dto := toMemberDTO(m.Member, m.Score, myMemberID)
+		dto["money"] = m.Money
+		memOut = append(memOut, dto)
 	}
 
 	c.JSON(http.StatusOK, map[string]any{
 		"scorebook": toScorebookDTO(sb),
 		"me": map[string]any{
-			"memberId": myMemberID,
-			"isOwner":  myRole == "owner",
+			"memberId":  myMemberID,
+			"role":      myRole,
+			"isOwner":   myRole == store.RoleOwner,
+			"canManage": store.CanManage(myRole),
 		},
 		"members": memOut,
 	})
@@ -215,8 +241,12 @@ func (h *ScorebookHandlers) UpdateScorebook(ctx context.Context, c *app.RequestC
 
 	sb, err := h.st.UpdateScorebookName(ctx, id, uid, name)
 	if err != nil {
-		if err == store.ErrForbidden {
-			writeError(c, http.StatusForbidden, "forbidden", "only owner can update")
+		switch err {
+		case store.ErrNotFound:
+			writeError(c, http.StatusNotFound, "not_found", "scorebook not found")
+			return
+		case store.ErrForbidden:
+			writeError(c, http.StatusForbidden, "forbidden", "only owner or admin can update")
 			return
 		}
 		writeError(c, http.StatusInternalServerError, "internal", "db error", err)
@@ -245,58 +275,143 @@ func (h *ScorebookHandlers) EndScorebook(ctx context.Context, c *app.RequestCont
 
 	sb, err := h.st.EndScorebook(ctx, id, uid)
 	if err != nil {
-		if err == store.ErrForbidden {
-			writeError(c, http.StatusForbidden, "forbidden", "only owner can end")
+		switch err {
+		case store.ErrNotFound:
+			writeError(c, http.StatusNotFound, "not_found", "scorebook not found")
+			return
+		case store.ErrForbidden:
+			writeError(c, http.StatusForbidden, "forbidden", "only owner or admin can end")
+			return
+		case store.ErrScorebookEnded:
+			writeError(c, http.StatusBadRequest, "ended", "scorebook ended")
 			return
 		}
 		writeError(c, http.StatusInternalServerError, "internal", "db error", err)
 		return
 	}
 
-	var champion any
-	var runnerUp any
-	var third any
-	if tops, err := h.st.GetTopWinners(ctx, sb.ID); err == nil {
-		if len(tops) >= 1 {
-			champion = map[string]any{
-				"memberId":  tops[0].ID,
-				"nickname":  tops[0].Nickname,
-				"avatarUrl": tops[0].AvatarURL,
-				"score":     tops[0].Score,
-			}
-		}
-		if len(tops) >= 2 {
-			runnerUp = map[string]any{
-				"memberId":  tops[1].ID,
-				"nickname":  tops[1].Nickname,
-				"avatarUrl": tops[1].AvatarURL,
-				"score":     tops[1].Score,
-			}
-		}
-		if len(tops) >= 3 {
-			third = map[string]any{
-				"memberId":  tops[2].ID,
-				"nickname":  tops[2].Nickname,
-				"avatarUrl": tops[2].AvatarURL,
-				"score":     tops[2].Score,
-			}
+	tops, err := h.st.GetTopWinners(ctx, sb.ID)
+	if err != nil {
+		// 不影响结束流程，但记录内部错误方便排查
+		c.Error(err)
+	}
+	winners := WinnersDTO(tops)
+
+	settlement := []any{}
+	if transfers, err := h.st.EnsureSettlement(ctx, sb.ID); err == nil {
+		for _, t := range transfers {
+			settlement = append(settlement, SettlementTransferDTO(t))
 		}
 	} else {
-		// 不影响结束流程，但记录内部错误方便排查
 		c.Error(err)
 	}
-	winners := map[string]any{
-		"champion": champion,
-		"runnerUp": runnerUp,
-		"third":    third,
+
+	money, err := EndedMoney(ctx, h.st, sb.ID, uid)
+	if err != nil {
+		c.Error(err)
 	}
 
 	h.hub.Broadcast(sb.ID, map[string]any{
 		"type": "scorebook.ended",
-		"data": map[string]any{"id": sb.ID, "endedAt": sb.EndedAt, "updatedAt": sb.UpdatedAt, "winners": winners},
+		"data": map[string]any{"id": sb.ID, "endedAt": sb.EndedAt, "updatedAt": sb.UpdatedAt, "winners": winners, "settlement": settlement, "money": money},
+	})
+
+	c.JSON(http.StatusOK, map[string]any{"scorebook": toScorebookDTO(sb), "winners": winners, "settlement": settlement, "money": money})
+}
+
+func (h *ScorebookHandlers) GetSettlement(ctx context.Context, c *app.RequestContext) {
+	uid, ok := middleware.UserID(c)
+	if !ok {
+		writeError(c, http.StatusUnauthorized, "unauthorized", "missing user")
+		return
+	}
+	scorebookID := strings.TrimSpace(c.Param("id"))
+	if scorebookID == "" {
+		writeError(c, http.StatusBadRequest, "bad_request", "id required")
+		return
+	}
+
+	transfers, final, err := h.st.GetSettlement(ctx, scorebookID, uid)
+	if err != nil {
+		switch err {
+		case store.ErrNotFound:
+			writeError(c, http.StatusNotFound, "not_found", "scorebook not found")
+			return
+		case store.ErrForbidden:
+			writeError(c, http.StatusForbidden, "forbidden", "not a member")
+			return
+		default:
+			writeError(c, http.StatusInternalServerError, "internal", "db error", err)
+			return
+		}
+	}
+
+	out := []any{}
+	for _, t := range transfers {
+		out = append(out, SettlementTransferDTO(t))
+	}
+
+	c.JSON(http.StatusOK, map[string]any{"scorebookId": scorebookID, "final": final, "transfers": out})
+}
+
+type updateSettlementTransferRequest struct {
+	Paid *bool `json:"paid"`
+}
+
+func (h *ScorebookHandlers) UpdateSettlementTransfer(ctx context.Context, c *app.RequestContext) {
+	uid, ok := middleware.UserID(c)
+	if !ok {
+		writeError(c, http.StatusUnauthorized, "unauthorized", "missing user")
+		return
+	}
+	scorebookID := strings.TrimSpace(c.Param("id"))
+	transferID := strings.TrimSpace(c.Param("transferId"))
+	if scorebookID == "" || transferID == "" {
+		writeError(c, http.StatusBadRequest, "bad_request", "id required")
+		return
+	}
+
+	var req updateSettlementTransferRequest
+	body, err := c.Body()
+	if err != nil {
+		writeError(c, http.StatusBadRequest, "bad_request", "read body failed")
+		return
+	}
+	if err := json.Unmarshal(body, &req); err != nil {
+		writeError(c, http.StatusBadRequest, "bad_request", "invalid json")
+		return
+	}
+	if req.Paid == nil {
+		writeError(c, http.StatusBadRequest, "bad_request", "paid required")
+		return
+	}
+
+	t, err := h.st.MarkSettlementTransferPaid(ctx, scorebookID, uid, transferID, *req.Paid)
+	if err != nil {
+		switch err {
+		case store.ErrNotFound:
+			writeError(c, http.StatusNotFound, "not_found", "transfer not found")
+			return
+		case store.ErrForbidden:
+			writeError(c, http.StatusForbidden, "forbidden", "only payer, payee or owner can update")
+			return
+		case store.ErrInvalidArgument:
+			writeError(c, http.StatusBadRequest, "bad_request", "invalid transfer")
+			return
+		default:
+			writeError(c, http.StatusInternalServerError, "internal", "db error", err)
+			return
+		}
+	}
+
+	h.hub.Broadcast(scorebookID, map[string]any{
+		"type": "settlement.updated",
+		"data": map[string]any{
+			"transfer": SettlementTransferDTO(t),
+		},
 	})
 
-	c.JSON(http.StatusOK, map[string]any{"scorebook": toScorebookDTO(sb), "winners": winners})
+	c.JSON(http.StatusOK, map[string]any{"transfer": SettlementTransferDTO(t)})
 }
 
 func (h *ScorebookHandlers) DeleteScorebook(ctx context.Context, c *app.RequestContext) {
@@ -334,6 +449,10 @@ func (h *ScorebookHandlers) DeleteScorebook(ctx context.Context, c *app.RequestC
 type joinScorebookRequest struct {
 	Nickname  string `json:"nickname"`
 	AvatarURL string `json:"avatarUrl"`
+	// Role 为 spectator 时以观众身份加入（只能观看不能记分），默认 member
+	Role string `json:"role"`
+	// InviteCode 按 id 加入时必填（已是成员除外），须为当前有效的邀请码
+	InviteCode string `json:"inviteCode"`
 }
 
 func (h *ScorebookHandlers) JoinScorebook(ctx context.Context, c *app.RequestContext) {
@@ -353,40 +472,52 @@ func (h *ScorebookHandlers) JoinScorebook(ctx context.Context, c *app.RequestCon
 		_ = json.Unmarshal(body, &req)
 	}
 
-	user, err := h.st.GetUserByID(ctx, uid)
+	user, err := h.users.GetUserByID(ctx, uid)
 	if err != nil {
 		writeError(c, http.StatusUnauthorized, "unauthorized", "user not found")
 		return
 	}
 
-	m, err := h.st.JoinScorebook(ctx, scorebookID, user, strings.TrimSpace(req.Nickname), strings.TrimSpace(req.AvatarURL))
+	m, joinReq, err := h.st.JoinScorebook(ctx, scorebookID, strings.TrimSpace(req.InviteCode), user, strings.TrimSpace(req.Nickname), strings.TrimSpace(req.AvatarURL), strings.TrimSpace(req.Role))
 	if err != nil {
 		switch err {
 		case store.ErrNotFound:
 			writeError(c, http.StatusNotFound, "not_found", "scorebook not found")
 			return
+		case store.ErrInviteRequired:
+			writeError(c, http.StatusForbidden, "invite_required", "a current invite code is required")
+			return
+		case store.ErrInviteRegenerated:
+			writeError(c, http.StatusGone, "invite_regenerated", "invite code has been regenerated")
+			return
+		case store.ErrInviteExpired:
+			writeError(c, http.StatusGone, "invite_expired", "invite code expired")
+			return
+		case store.ErrInviteExhausted:
+			writeError(c, http.StatusGone, "invite_exhausted", "invite code usage limit reached")
+			return
 		case store.ErrScorebookEnded:
 			writeError(c, http.StatusBadRequest, "ended", "scorebook ended")
 			return
+		case store.ErrInvalidArgument:
+			writeError(c, http.StatusBadRequest, "bad_request", "role must be member or spectator")
+			return
+		case store.ErrBanned:
+			writeError(c, http.StatusForbidden, "banned", "banned from this scorebook")
+			return
 		default:
 			writeError(c, http.StatusInternalServerError, "internal", "db error", err)
 			return
 		}
 	}
 
-	h.hub.Broadcast(scorebookID, map[string]any{
-		"type": "member.joined",
-		"data": map[string]any{
-			"member": map[string]any{
-				"id":        m.ID,
-				"nickname":  m.Nickname,
-				"avatarUrl": m.AvatarURL,
-				"role":      m.Role,
-				"joinedAt":  m.JoinedAt,
-			},
-		},
-	})
+	if joinReq != nil {
+		h.notifyJoinRequested(ctx, *joinReq)
+		c.JSON(http.StatusAccepted, map[string]any{"joinRequest": toJoinRequestDTO(*joinReq)})
+		return
+	}
 
+	h.broadcastMemberJoined(m)
 	c.JSON(http.StatusOK, map[string]any{"member": toMemberDTO(m, 0, m.ID)})
 }
 
@@ -443,6 +574,196 @@ func (h *ScorebookHandlers) UpdateMyProfile(ctx context.Context, c *app.RequestC
 	c.JSON(http.StatusOK, map[string]any{"member": toMemberDTO(m, 0, m.ID)})
 }
 
+type updateMemberRequest struct {
+	Role string `json:"role"`
+}
+
+// UpdateMember 由房主设置成员角色：admin、member 或 spectator。
+func (h *ScorebookHandlers) UpdateMember(ctx context.Context, c *app.RequestContext) {
+	uid, ok := middleware.UserID(c)
+	if !ok {
+		writeError(c, http.StatusUnauthorized, "unauthorized", "missing user")
+		return
+	}
+	scorebookID := strings.TrimSpace(c.Param("id"))
+	memberID := strings.TrimSpace(c.Param("memberId"))
+	if scorebookID == "" || memberID == "" {
+		writeError(c, http.StatusBadRequest, "bad_request", "id required")
+		return
+	}
+
+	var req updateMemberRequest
+	body, err := c.Body()
+	if err != nil {
+		writeError(c, http.StatusBadRequest, "bad_request", "read body failed")
+		return
+	}
+	if err := json.Unmarshal(body, &req); err != nil {
+		writeError(c, http.StatusBadRequest, "bad_request", "invalid json")
+		return
+	}
+	role := strings.TrimSpace(req.Role)
+	if role != store.RoleAdmin && role != store.RoleMember && role != store.RoleSpectator {
+		writeError(c, http.StatusBadRequest, "bad_request", "role must be admin, member or spectator")
+		return
+	}
+
+	m, err := h.st.SetMemberRole(ctx, scorebookID, uid, memberID, role)
+	if err != nil {
+		switch err {
+		case store.ErrNotFound:
+			writeError(c, http.StatusNotFound, "not_found", "member not found")
+			return
+		case store.ErrForbidden:
+			writeError(c, http.StatusForbidden, "forbidden", "only owner can change roles")
+			return
+		case store.ErrInvalidArgument:
+			writeError(c, http.StatusBadRequest, "bad_request", "cannot change owner role")
+			return
+		default:
+			writeError(c, http.StatusInternalServerError, "internal", "db error", err)
+			return
+		}
+	}
+
+	h.hub.Broadcast(scorebookID, map[string]any{
+		"type": "member.updated",
+		"data": map[string]any{
+			"member": map[string]any{
+				"id":        m.ID,
+				"nickname":  m.Nickname,
+				"avatarUrl": m.AvatarURL,
+				"role":      m.Role,
+				"updatedAt": m.UpdatedAt,
+			},
+		},
+	})
+
+	c.JSON(http.StatusOK, map[string]any{"member": toMemberDTO(m, 0, "")})
+}
+
+// RemoveMember 移出成员，?ban=true 同时禁止其通过邀请码重新加入。
+// 已有记录的成员不会被删除，只解除与用户的关联，记录仍归属于该成员。
+func (h *ScorebookHandlers) RemoveMember(ctx context.Context, c *app.RequestContext) {
+	uid, ok := middleware.UserID(c)
+	if !ok {
+		writeError(c, http.StatusUnauthorized, "unauthorized", "missing user")
+		return
+	}
+	scorebookID := strings.TrimSpace(c.Param("id"))
+	memberID := strings.TrimSpace(c.Param("memberId"))
+	if scorebookID == "" || memberID == "" {
+		writeError(c, http.StatusBadRequest, "bad_request", "id required")
+		return
+	}
+	ban := false
+	if v := strings.TrimSpace(string(c.Query("ban"))); v != "" {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			writeError(c, http.StatusBadRequest, "bad_request", "invalid ban")
+			return
+		}
+		ban = b
+	}
+
+	m, kept, err := h.st.RemoveMember(ctx, scorebookID, uid, memberID, ban)
+	if err != nil {
+		switch err {
+		case store.ErrNotFound:
+			writeError(c, http.StatusNotFound, "not_found", "member not found")
+			return
+		case store.ErrForbidden:
+			writeError(c, http.StatusForbidden, "forbidden", "not allowed to remove this member")
+			return
+		case store.ErrInvalidArgument:
+			writeError(c, http.StatusBadRequest, "bad_request", "cannot remove yourself")
+			return
+		default:
+			writeError(c, http.StatusInternalServerError, "internal", "db error", err)
+			return
+		}
+	}
+
+	h.hub.Broadcast(scorebookID, map[string]any{
+		"type": "member.removed",
+		"data": map[string]any{"memberId": m.ID, "kept": kept, "banned": ban},
+	})
+	if m.UserID != 0 {
+		h.hub.Disconnect(scorebookID, m.UserID)
+	}
+
+	c.JSON(http.StatusOK, map[string]any{"ok": true, "kept": kept, "banned": ban})
+}
+
+// ListBans 列出被禁止重新加入的用户。
+func (h *ScorebookHandlers) ListBans(ctx context.Context, c *app.RequestContext) {
+	uid, ok := middleware.UserID(c)
+	if !ok {
+		writeError(c, http.StatusUnauthorized, "unauthorized", "missing user")
+		return
+	}
+	scorebookID := strings.TrimSpace(c.Param("id"))
+	if scorebookID == "" {
+		writeError(c, http.StatusBadRequest, "bad_request", "id required")
+		return
+	}
+
+	bans, err := h.st.ListBans(ctx, scorebookID, uid)
+	if err != nil {
+		switch err {
+		case store.ErrNotFound:
+			writeError(c, http.StatusNotFound, "not_found", "scorebook not found")
+			return
+		case store.ErrForbidden:
+			writeError(c, http.StatusForbidden, "forbidden", "only owner or admin can view bans")
+			return
+		default:
+			writeError(c, http.StatusInternalServerError, "internal", "db error", err)
+			return
+		}
+	}
+
+	items := make([]map[string]any, 0, len(bans))
+	for _, b := range bans {
+		items = append(items, map[string]any{
+			"userId":   b.UserID,
+			"nickname": b.Nickname,
+			"bannedAt": b.CreatedAt,
+		})
+	}
+	c.JSON(http.StatusOK, map[string]any{"items": items})
+}
+
+// Unban 解除禁止，用户可再次通过邀请码加入。
+func (h *ScorebookHandlers) Unban(ctx context.Context, c *app.RequestContext) {
+	uid, ok := middleware.UserID(c)
+	if !ok {
+		writeError(c, http.StatusUnauthorized, "unauthorized", "missing user")
+		return
+	}
+	scorebookID := strings.TrimSpace(c.Param("id"))
+	bannedUserID, err := strconv.ParseInt(strings.TrimSpace(c.Param("userId")), 10, 64)
+	if scorebookID == "" || err != nil || bannedUserID <= 0 {
+		writeError(c, http.StatusBadRequest, "bad_request", "invalid id")
+		return
+	}
+
+	if err := h.st.Unban(ctx, scorebookID, uid, bannedUserID); err != nil {
+		switch err {
+		case store.ErrNotFound:
+			writeError(c, http.StatusNotFound, "not_found", "ban not found")
+			return
+		case store.ErrForbidden:
+			writeError(c, http.StatusForbidden, "forbidden", "only owner or admin can unban")
+			return
+		default:
+			writeError(c, http.StatusInternalServerError, "internal", "db error", err)
+			return
+		}
+	}
+	c.JSON(http.StatusOK, map[string]any{"ok": true})
+}
+
 type createRecordRequest struct {
 	ToMemberID string  `json:"toMemberId"`
 	Delta      float64 `json:"delta"`
@@ -489,7 +810,7 @@ func (h *ScorebookHandlers) CreateRecord(ctx context.Context, c *app.RequestCont
 			writeError(c, http.StatusNotFound, "not_found", "not found")
 			return
 		case store.ErrForbidden:
-			writeError(c, http.StatusForbidden, "forbidden", "not a member")
+			writeError(c, http.StatusForbidden, "forbidden", "not a member or spectator")
 			return
 		case store.ErrInvalidArgument:
 			writeError(c, http.StatusBadRequest, "bad_request", "invalid member")
@@ -497,6 +818,9 @@ func (h *ScorebookHandlers) CreateRecord(ctx context.Context, c *app.RequestCont
 		case store.ErrInvalidDelta:
 			writeError(c, http.StatusBadRequest, "bad_request", "delta must be positive")
 			return
+		case store.ErrDeltaNotAllowed:
+			writeError(c, http.StatusBadRequest, "delta_not_allowed", "delta must be one of the scorebook's delta presets")
+			return
 		case store.ErrScorebookEnded:
 			writeError(c, http.StatusBadRequest, "ended", "scorebook ended")
 			return
@@ -509,25 +833,144 @@ func (h *ScorebookHandlers) CreateRecord(ctx context.Context, c *app.RequestCont
 	h.hub.Broadcast(scorebookID, map[string]any{
 		"type": "record.created",
 		"data": map[string]any{
-			"record": map[string]any{
-				"id":           r.ID,
-				"fromMemberId": r.FromMemberID,
-				"toMemberId":   r.ToMemberID,
-				"delta":        r.Delta,
-				"note":         r.Note,
-				"createdAt":    r.CreatedAt,
-			},
+			"record": toRecordDTO(r),
 		},
 	})
 
-	c.JSON(http.StatusOK, map[string]any{"record": map[string]any{
-		"id":           r.ID,
-		"fromMemberId": r.FromMemberID,
-		"toMemberId":   r.ToMemberID,
-		"delta":        r.Delta,
-		"note":         r.Note,
-		"createdAt":    r.CreatedAt,
-	}})
+	c.JSON(http.StatusOK, map[string]any{"record": toRecordDTO(r)})
+}
+
+type createRoundRequest struct {
+	Deltas []struct {
+		MemberID string  `json:"memberId"`
+		Delta    float64 `json:"delta"`
+	} `json:"deltas"`
+	Note string `json:"note"`
+}
+
+func (h *ScorebookHandlers) CreateRound(ctx context.Context, c *app.RequestContext) {
+	uid, ok := middleware.UserID(c)
+	if !ok {
+		writeError(c, http.StatusUnauthorized, "unauthorized", "missing user")
+		return
+	}
+	scorebookID := strings.TrimSpace(c.Param("id"))
+	if scorebookID == "" {
+		writeError(c, http.StatusBadRequest, "bad_request", "id required")
+		return
+	}
+
+	var req createRoundRequest
+	body, err := c.Body()
+	if err != nil {
+		writeError(c, http.StatusBadRequest, "bad_request", "read body failed")
+		return
+	}
+	if err := json.Unmarshal(body, &req); err != nil {
+		writeError(c, http.StatusBadRequest, "bad_request", "invalid json")
+		return
+	}
+	if len(req.Deltas) < 2 {
+		writeError(c, http.StatusBadRequest, "bad_request", "at least 2 deltas required")
+		return
+	}
+	deltas := make([]store.RoundDelta, 0, len(req.Deltas))
+	for _, d := range req.Deltas {
+		memberID := strings.TrimSpace(d.MemberID)
+		if memberID == "" {
+			writeError(c, http.StatusBadRequest, "bad_request", "memberId required")
+			return
+		}
+		if d.Delta != 0 && !validTwoDecimals(math.Abs(d.Delta)) {
+			writeError(c, http.StatusBadRequest, "bad_request", "delta must have at most 2 decimals")
+			return
+		}
+		deltas = append(deltas, store.RoundDelta{MemberID: memberID, Delta: d.Delta})
+	}
+
+	round, err := h.st.CreateRound(ctx, scorebookID, uid, deltas, strings.TrimSpace(req.Note))
+	if err != nil {
+		switch err {
+		case store.ErrNotFound:
+			writeError(c, http.StatusNotFound, "not_found", "scorebook not found")
+			return
+		case store.ErrForbidden:
+			writeError(c, http.StatusForbidden, "forbidden", "not a member or spectator")
+			return
+		case store.ErrInvalidArgument:
+			writeError(c, http.StatusBadRequest, "bad_request", "invalid or duplicate member")
+			return
+		case store.ErrInvalidDelta:
+			writeError(c, http.StatusBadRequest, "not_zero_sum", "deltas must sum to zero")
+			return
+		case store.ErrScorebookEnded:
+			writeError(c, http.StatusBadRequest, "ended", "scorebook ended")
+			return
+		default:
+			writeError(c, http.StatusInternalServerError, "internal", "db error", err)
+			return
+		}
+	}
+
+	h.hub.Broadcast(scorebookID, map[string]any{
+		"type": "round.created",
+		"data": map[string]any{
+			"round": toRoundDTO(round),
+		},
+	})
+
+	c.JSON(http.StatusOK, map[string]any{"round": toRoundDTO(round)})
+}
+
+func (h *ScorebookHandlers) VoidRecord(ctx context.Context, c *app.RequestContext) {
+	uid, ok := middleware.UserID(c)
+	if !ok {
+		writeError(c, http.StatusUnauthorized, "unauthorized", "missing user")
+		return
+	}
+	scorebookID := strings.TrimSpace(c.Param("id"))
+	recordID := strings.TrimSpace(c.Param("recordId"))
+	if scorebookID == "" || recordID == "" {
+		writeError(c, http.StatusBadRequest, "bad_request", "id required")
+		return
+	}
+
+	r, rev, err := h.st.VoidRecord(ctx, scorebookID, uid, recordID, h.cfg.RecordVoidWindow)
+	if err != nil {
+		switch err {
+		case store.ErrNotFound:
+			writeError(c, http.StatusNotFound, "not_found", "record not found")
+			return
+		case store.ErrForbidden:
+			writeError(c, http.StatusForbidden, "forbidden", "only author, owner or admin can void")
+			return
+		case store.ErrInvalidArgument:
+			writeError(c, http.StatusBadRequest, "bad_request", "invalid record")
+			return
+		case store.ErrRecordVoided:
+			writeError(c, http.StatusConflict, "voided", "record already voided")
+			return
+		case store.ErrVoidWindowClosed:
+			writeError(c, http.StatusBadRequest, "void_window_closed", "void window closed")
+			return
+		case store.ErrScorebookEnded:
+			writeError(c, http.StatusBadRequest, "ended", "scorebook ended")
+			return
+		default:
+			writeError(c, http.StatusInternalServerError, "internal", "db error", err)
+			return
+		}
+	}
+
+	h.hub.Broadcast(scorebookID, map[string]any{
+		"type": "record.voided",
+		"data": map[string]any{
+			"record":   toRecordDTO(r),
+			"reversal": toRecordDTO(rev),
+		},
+	})
+
+	c.JSON(http.StatusOK, map[string]any{"record": toRecordDTO(r), "reversal": toRecordDTO(rev)})
 }
 
 func (h *ScorebookHandlers) ListRecords(ctx context.Context, c *app.RequestContext) {
@@ -571,14 +1014,7 @@ func (h *ScorebookHandlers) ListRecords(ctx context.Context, c *app.RequestConte
 
 	var out []any
 	for _, r := range items {
-		out = append(out, map[string]any{
-			"id":           r.ID,
-			"fromMemberId": r.FromMemberID,
-			"toMemberId":   r.ToMemberID,
-			"delta":        r.Delta,
-			"note":         r.Note,
-			"createdAt":    r.CreatedAt,
-		})
+		out = append(out, toRecordDTO(r))
 	}
 
 	c.JSON(http.StatusOK, map[string]any{"items": out, "limit": limit, "offset": offset})
@@ -655,9 +1091,13 @@ func (h *ScorebookHandlers) GetInviteInfo(ctx context.Context, c *app.RequestCon
 
 	info, err := h.st.GetInviteInfo(ctx, code)
 	if err != nil {
-		if err == store.ErrNotFound {
+		switch err {
+		case store.ErrNotFound:
 			writeError(c, http.StatusNotFound, "not_found", "invite not found")
 			return
+		case store.ErrInviteRegenerated:
+			writeError(c, http.StatusGone, "invite_regenerated", "invite code has been regenerated")
+			return
 		}
 		writeError(c, http.StatusInternalServerError, "internal", "db error", err)
 		return
@@ -673,22 +1113,37 @@ func (h *ScorebookHandlers) GetInviteInfo(ctx context.Context, c *app.RequestCon
 		return
 	}
 
+	var myJoinRequest map[string]any
+	if uid, ok := middleware.UserID(c); ok && bookType == "scorebook" {
+		r, err := h.st.GetMyJoinRequest(ctx, bookID, uid)
+		switch err {
+		case nil:
+			myJoinRequest = toJoinRequestDTO(r)
+		case store.ErrNotFound:
+		default:
+			writeError(c, http.StatusInternalServerError, "internal", "db error", err)
+			return
+		}
+	}
+
 	c.JSON(http.StatusOK, map[string]any{
 		"invite": map[string]any{
 			"code":      code,
 			"bookType":  bookType,
-			"bookId":    bookID,
 			"name":      info.Name,
 			"status":    info.Status,
 			"shareDisabled": info.ShareDisabled,
 			"updatedAt": info.UpdatedAt,
-			// backwards compatibility for existing clients
-			"scorebookId": func() string {
-				if bookType == "scorebook" {
-					return bookID
-				}
-				return ""
-			}(),
+			"expiresAt": info.ExpiresAt,
+			"maxUses":   info.MaxUses,
+			"uses":      info.Uses,
+			// usable 为 false 时 reason 说明原因：ended / expired / exhausted
+			"usable": info.UnusableReason == "",
+			"reason": info.UnusableReason,
+			"joinApproval": info.JoinApproval,
+			// 已登录时返回自己最近一次的加入申请，未申请过为 null
+			"myJoinRequest": myJoinRequest,
+			// 得分簿不返回 id，只能凭邀请码加入；记账簿分享仍需要 id 打开
 			"ledgerId": func() string {
 				if bookType == "ledger" {
 					return bookID
@@ -711,54 +1166,59 @@ func (h *ScorebookHandlers) JoinByInviteCode(ctx context.Context, c *app.Request
 		return
 	}
 
-	scorebookID, err := h.st.ScorebookIDByInviteCode(ctx, code)
-	if err != nil {
-		if err == store.ErrNotFound {
-			writeError(c, http.StatusNotFound, "not_found", "invite not found")
-			return
-		}
-		writeError(c, http.StatusInternalServerError, "internal", "db error", err)
-		return
-	}
-
 	var req joinScorebookRequest
 	if body, err := c.Body(); err == nil && len(body) > 0 {
 		_ = json.Unmarshal(body, &req)
 	}
 
-	user, err := h.st.GetUserByID(ctx, uid)
+	user, err := h.users.GetUserByID(ctx, uid)
 	if err != nil {
 		writeError(c, http.StatusUnauthorized, "unauthorized", "user not found")
 		return
 	}
 
-	m, err := h.st.JoinScorebook(ctx, scorebookID, user, strings.TrimSpace(req.Nickname), strings.TrimSpace(req.AvatarURL))
+	m, joinReq, err := h.st.JoinByInviteCode(ctx, code, user, strings.TrimSpace(req.Nickname), strings.TrimSpace(req.AvatarURL), strings.TrimSpace(req.Role))
 	if err != nil {
 		switch err {
 		case store.ErrNotFound:
-			writeError(c, http.StatusNotFound, "not_found", "scorebook not found")
+			writeError(c, http.StatusNotFound, "not_found", "invite not found")
+			return
+		case store.ErrInviteRegenerated:
+			writeError(c, http.StatusGone, "invite_regenerated", "invite code has been regenerated")
+			return
+		case store.ErrInviteExpired:
+			writeError(c, http.StatusGone, "invite_expired", "invite code expired")
+			return
+		case store.ErrInviteExhausted:
+			writeError(c, http.StatusGone, "invite_exhausted", "invite code usage limit reached")
 			return
 		case store.ErrScorebookEnded:
 			writeError(c, http.StatusBadRequest, "ended", "scorebook ended")
 			return
+		case store.ErrInvalidArgument:
+			writeError(c, http.StatusBadRequest, "bad_request", "role must be member or spectator")
+			return
+		case store.ErrBanned:
+			writeError(c, http.StatusForbidden, "banned", "banned from this scorebook")
+			return
 		default:
 			writeError(c, http.StatusInternalServerError, "internal", "db error", err)
 			return
 		}
 	}
 
-	h.hub.Broadcast(scorebookID, map[string]any{
-		"type": "member.joined",
-		"data": map[string]any{
-			"member": map[string]any{
-				"id":        m.ID,
-				"nickname":  m.Nickname,
-				"avatarUrl": m.AvatarURL,
-				"role":      m.Role,
-				"joinedAt":  m.JoinedAt,
-			},
-		},
-	})
+	// 需要审核时只提交申请，等待掌柜或管理员处理。
+	if joinReq != nil {
+		h.notifyJoinRequested(ctx, *joinReq)
+		c.JSON(http.StatusAccepted, map[string]any{
+			"scorebookId": joinReq.ScorebookID,
+			"joinRequest": toJoinRequestDTO(*joinReq),
+		})
+		return
+	}
+
+	scorebookID := m.ScorebookID
+	h.broadcastMemberJoined(m)
 
 	c.JSON(http.StatusOK, map[string]any{
 		"scorebookId": scorebookID,
@@ -773,19 +1233,9 @@ func (h *ScorebookHandlers) ScorebookWS(ctx context.Context, c *app.RequestConte
 		return
 	}
 
-	uid, ok := middleware.UserID(c)
+	uid, ok := wsUser(ctx, c, h.cfg, h.users)
 	if !ok {
-		token := extractTokenForWS(c)
-		if token == "" {
-			c.AbortWithStatus(http.StatusUnauthorized)
-			return
-		}
-		id, err := appauth.ParseToken([]byte(h.cfg.TokenSecret), token)
-		if err != nil {
-			c.AbortWithStatus(http.StatusUnauthorized)
-			return
-		}
-		uid = id
+		return
 	}
 
 	isMember, err := h.st.IsMember(ctx, scorebookID, uid)
@@ -794,19 +1244,83 @@ func (h *ScorebookHandlers) ScorebookWS(ctx context.Context, c *app.RequestConte
 		return
 	}
 
+	since, ok := parseSince(c)
+	if !ok {
+		return
+	}
+
 	h.upgrader.Upgrade(c, func(conn *websocket.Conn) {
-		h.hub.Join(scorebookID, conn)
-		defer h.hub.Leave(scorebookID, conn)
-		defer conn.Close()
+		h.hub.Serve(ctx, scorebookID, uid, conn, since)
+	})
+}
 
-		for {
-			if _, _, err := conn.ReadMessage(); err != nil {
-				return
-			}
-		}
+// GetOnline 返回当前实例上连接到该得分簿的 WebSocket 数。
+func (h *ScorebookHandlers) GetOnline(ctx context.Context, c *app.RequestContext) {
+	uid, ok := middleware.UserID(c)
+	if !ok {
+		writeError(c, http.StatusUnauthorized, "unauthorized", "missing user")
+		return
+	}
+	id := strings.TrimSpace(c.Param("id"))
+	if id == "" {
+		writeError(c, http.StatusBadRequest, "bad_request", "id required")
+		return
+	}
+
+	isMember, err := h.st.IsMember(ctx, id, uid)
+	if err != nil {
+		writeError(c, http.StatusInternalServerError, "internal", "db error", err)
+		return
+	}
+	if !isMember {
+		writeError(c, http.StatusForbidden, "forbidden", "not a member")
+		return
+	}
+
+	c.JSON(http.StatusOK, map[string]any{
+		"scorebookId": id,
+		"connections": h.hub.Connections(id),
 	})
 }
 
+// wsUser 认证 WebSocket 握手：优先用中间件解析出的用户，否则读 query 里的 token。
+// 失败时已写好 401/500，调用方直接返回即可。
+func wsUser(ctx context.Context, c *app.RequestContext, cfg appconfig.Config, users store.UserRepo) (int64, bool) {
+	if uid, ok := middleware.UserID(c); ok {
+		return uid, true
+	}
+	token := extractTokenForWS(c)
+	if token == "" {
+		c.AbortWithStatus(http.StatusUnauthorized)
+		return 0, false
+	}
+	claims, err := middleware.Authenticate(ctx, cfg, users, token)
+	if err != nil {
+		if !middleware.IsAuthError(err) {
+			_ = c.Error(err)
+			c.AbortWithStatus(http.StatusInternalServerError)
+			return 0, false
+		}
+		c.AbortWithStatus(http.StatusUnauthorized)
+		return 0, false
+	}
+	return claims.UserID, true
+}
+
+// parseSince 读取 since=<seq>：补发该序号之后错过的事件；不带时返回 -1，只推送实时事件。
+func parseSince(c *app.RequestContext) (int64, bool) {
+	v := strings.TrimSpace(string(c.Query("since")))
+	if v == "" {
+		return -1, true
+	}
+	n, err := strconv.ParseInt(v, 10, 64)
+	if err != nil || n < 0 {
+		c.AbortWithStatus(http.StatusBadRequest)
+		return 0, false
+	}
+	return n, true
+}
+
 func extractTokenForWS(c *app.RequestContext) string {
 	if v := strings.TrimSpace(string(c.Query("token"))); v != "" {
 		return v
@@ -815,7 +1329,7 @@ func extractTokenForWS(c *app.RequestContext) string {
 }
 
 func toScorebookDTO(sb store.Scorebook) map[string]any {
-	return map[string]any{
+	out := map[string]any{
 		"id":           sb.ID,
 		"name":         sb.Name,
 		"locationText": sb.LocationText,
@@ -826,6 +1340,98 @@ func toScorebookDTO(sb store.Scorebook) map[string]any {
 		"endedAt":      sb.EndedAt,
 		"inviteCode":   sb.InviteCode,
 	}
+	if sb.Game != nil {
+		out["game"] = map[string]any{
+			"templateId":      sb.Game.TemplateID,
+			"gameType":        sb.Game.GameType,
+			"stakeMultiplier": sb.Game.StakeMultiplier,
+			"deltaPresets":    sb.Game.DeltaPresets,
+			"pointValue":      sb.Game.PointValue,
+			"currency":        sb.Game.Currency,
+		}
+	}
+	return out
+}
+
+func toRecordDTO(r store.ScoreRecord) map[string]any {
+	out := map[string]any{
+		"id":           r.ID,
+		"fromMemberId": r.FromMemberID,
+		"toMemberId":   r.ToMemberID,
+		"delta":        r.Delta,
+		"note":         r.Note,
+		"createdAt":    r.CreatedAt,
+		"voided":       r.VoidedAt != nil,
+		"voidedAt":     r.VoidedAt,
+	}
+	if r.VoidedByMemberID != "" {
+		out["voidedByMemberId"] = r.VoidedByMemberID
+	}
+	if r.ReversesRecordID != "" {
+		out["reversesRecordId"] = r.ReversesRecordID
+	}
+	if r.RoundID != "" {
+		out["roundId"] = r.RoundID
+	}
+	return out
+}
+
+func toRoundDTO(r store.ScoreRound) map[string]any {
+	deltas := make([]any, 0, len(r.Deltas))
+	for _, d := range r.Deltas {
+		deltas = append(deltas, map[string]any{"memberId": d.MemberID, "delta": d.Delta})
+	}
+	records := make([]any, 0, len(r.Records))
+	for _, rec := range r.Records {
+		records = append(records, toRecordDTO(rec))
+	}
+	return map[string]any{
+		"id":                r.ID,
+		"roundNo":           r.RoundNo,
+		"createdByMemberId": r.CreatedByMemberID,
+		"note":              r.Note,
+		"createdAt":         r.CreatedAt,
+		"deltas":            deltas,
+		"records":           records,
+	}
+}
+
+// WinnersDTO 是结束时的前三名（不足三人时为 null），手动结束与自动结束共用。
+func WinnersDTO(tops []store.MemberWithScore) map[string]any {
+	out := map[string]any{"champion": nil, "runnerUp": nil, "third": nil}
+	for i, key := range []string{"champion", "runnerUp", "third"} {
+		if i >= len(tops) {
+			break
+		}
+		out[key] = map[string]any{
+			"memberId":  tops[i].ID,
+			"nickname":  tops[i].Nickname,
+			"avatarUrl": tops[i].AvatarURL,
+			"score":     tops[i].Score,
+			"money":     tops[i].Money,
+		}
+	}
+	return out
+}
+
+// SettlementTransferDTO 是结算转账的 JSON 结构，接口返回与 scorebook.ended 事件（含自动结束）共用。
+func SettlementTransferDTO(t store.SettlementTransfer) map[string]any {
+	out := map[string]any{
+		"fromMemberId": t.FromMemberID,
+		"fromNickname": t.FromNickname,
+		"toMemberId":   t.ToMemberID,
+		"toNickname":   t.ToNickname,
+		"amount":       t.Amount,
+		"paid":         t.PaidAt != nil,
+		"paidAt":       t.PaidAt,
+	}
+	if t.ID != "" {
+		out["id"] = t.ID
+	}
+	if t.PaidByMemberID != "" {
+		out["paidByMemberId"] = t.PaidByMemberID
+	}
+	return out
 }
 
 func normalizeBookType(v string) string {
@@ -855,6 +1461,10 @@ func toMemberDTO(m store.Member, score float64, myMemberID string) map[string]an
 		"updatedAt": m.UpdatedAt,
 		"score":     score,
 		"isMe":      m.ID == myMemberID,
-		"isOwner":   m.Role == "owner",
+		"isOwner":   m.Role == store.RoleOwner,
+		// 已被移出但保留了记录的成员
+		"removed": m.UserID == 0 && !m.Placeholder,
+		// 模板创建的占位成员，没有绑定用户
+		"placeholder": m.Placeholder,
 	}
 }
@@ -0,0 +1,283 @@
+package handlers_test
+
+import (
+	"testing"
+	"time"
+)
+
+type scorebookFixture struct {
+	api                  *testAPI
+	id, code             string
+	alice, bob, carol    string // tokens
+	aliceM, bobM, carolM string // member ids
+}
+
+// newScorebook creates a scorebook owned by alice that bob joined by invite code
+// and carol joined by id.
+func newScorebook(t *testing.T) *scorebookFixture {
+	t.Helper()
+	api := newTestAPI(t)
+	f := &scorebookFixture{
+		api:   api,
+		alice: api.login("alice", "Alice"),
+		bob:   api.login("bob", "Bob"),
+		carol: api.login("carol", "Carol"),
+	}
+
+	resp := api.expect(200, "POST", "/api/v1/scorebooks", f.alice, map[string]any{"name": "周五麻将", "locationText": "老地方"})
+	f.id = str(resp, "scorebook", "id")
+	f.code = str(resp, "scorebook", "inviteCode")
+	f.aliceM = str(resp, "me", "id")
+	if f.id == "" || f.code == "" || f.aliceM == "" {
+		t.Fatalf("create scorebook: %v", resp)
+	}
+
+	resp = api.expect(200, "POST", "/api/v1/invites/"+f.code+"/join", f.bob, map[string]any{})
+	if str(resp, "scorebookId") != f.id {
+		t.Fatalf("join by invite: %v", resp)
+	}
+	f.bobM = str(resp, "member", "id")
+	resp = api.expect(200, "POST", "/api/v1/scorebooks/"+f.id+"/join", f.carol, map[string]any{"nickname": "小C", "inviteCode": f.code})
+	f.carolM = str(resp, "member", "id")
+	return f
+}
+
+func (f *scorebookFixture) path(suffix string) string {
+	return "/api/v1/scorebooks/" + f.id + suffix
+}
+
+func (f *scorebookFixture) scores(t *testing.T) map[string]float64 {
+	t.Helper()
+	resp := f.api.expect(200, "GET", f.path(""), f.alice, nil)
+	out := map[string]float64{}
+	for _, m := range list(resp, "members") {
+		out[str(m, "id")] = num(m, "score")
+	}
+	return out
+}
+
+func TestScorebookLifecycle(t *testing.T) {
+	f := newScorebook(t)
+	api := f.api
+	dave := api.login("dave", "Dave")
+
+	resp := api.expect(200, "GET", "/api/v1/invites/"+f.code, "", nil)
+	if str(resp, "invite", "bookType") != "scorebook" || str(resp, "invite", "name") != "周五麻将" {
+		t.Fatalf("invite info: %v", resp)
+	}
+	api.expectError(404, "not_found", "GET", "/api/v1/invites/NOPE", "", nil)
+
+	resp = api.expect(200, "GET", f.path(""), f.bob, nil)
+	if len(list(resp, "members")) != 3 || boolean(resp, "me", "isOwner") {
+		t.Fatalf("detail for bob: %v", resp)
+	}
+	if nick := str(findBy(t, list(resp, "members"), "id", f.carolM), "nickname"); nick != "小C" {
+		t.Fatalf("carol nickname = %q", nick)
+	}
+	api.expectError(404, "not_found", "GET", f.path(""), dave, nil)
+
+	// 重复加入保持幂等
+	resp = api.expect(200, "POST", f.path("/join"), f.bob, map[string]any{})
+	if str(resp, "member", "id") != f.bobM {
+		t.Fatalf("rejoin returned a new member: %v", resp)
+	}
+
+	resp = api.expect(200, "PATCH", f.path("/members/me"), f.bob, map[string]any{"nickname": "老B"})
+	if str(resp, "member", "nickname") != "老B" {
+		t.Fatalf("update my profile: %v", resp)
+	}
+
+	resp = api.expect(200, "PATCH", f.path(""), f.alice, map[string]any{"name": "周六麻将"})
+	if str(resp, "scorebook", "name") != "周六麻将" {
+		t.Fatalf("rename: %v", resp)
+	}
+
+	resp = api.expect(200, "GET", "/api/v1/scorebooks", f.carol, nil)
+	items := list(resp, "items")
+	if len(items) != 1 || num(items[0], "memberCount") != 3 || boolean(items[0], "isOwner") {
+		t.Fatalf("list for carol: %v", resp)
+	}
+	resp = api.expect(200, "GET", "/api/v1/scorebooks", dave, nil)
+	if len(list(resp, "items")) != 0 {
+		t.Fatalf("list for outsider: %v", resp)
+	}
+
+	api.expectError(400, "not_ended", "DELETE", f.path(""), f.alice, nil)
+
+	resp = api.expect(200, "POST", f.path("/end"), f.alice, nil)
+	if str(resp, "scorebook", "status") != "ended" {
+		t.Fatalf("end: %v", resp)
+	}
+	api.expectError(400, "ended", "POST", f.path("/join"), dave, map[string]any{})
+	api.expectError(400, "ended", "POST", f.path("/records"), f.bob, map[string]any{"toMemberId": f.aliceM, "delta": 1})
+
+	api.expectError(403, "forbidden", "DELETE", f.path(""), f.bob, nil)
+	api.expect(200, "DELETE", f.path(""), f.alice, nil)
+	api.expectError(404, "not_found", "GET", f.path(""), f.alice, nil)
+	api.expectError(404, "not_found", "GET", "/api/v1/invites/"+f.code, "", nil)
+}
+
+func TestScorebookRecords(t *testing.T) {
+	f := newScorebook(t)
+	api := f.api
+	dave := api.login("dave", "Dave")
+
+	resp := api.expect(200, "POST", f.path("/records"), f.bob, map[string]any{"toMemberId": f.aliceM, "delta": 12.5, "note": "自摸"})
+	if str(resp, "record", "fromMemberId") != f.bobM || num(resp, "record", "delta") != 12.5 {
+		t.Fatalf("create record: %v", resp)
+	}
+	if s := f.scores(t); s[f.aliceM] != 12.5 || s[f.bobM] != -12.5 {
+		t.Fatalf("scores after record: %v", s)
+	}
+
+	api.expectError(400, "bad_request", "POST", f.path("/records"), f.bob, map[string]any{"toMemberId": f.aliceM, "delta": -1})
+	api.expectError(400, "bad_request", "POST", f.path("/records"), f.bob, map[string]any{"toMemberId": f.aliceM, "delta": 1.234})
+	api.expectError(400, "bad_request", "POST", f.path("/records"), f.bob, map[string]any{"toMemberId": f.bobM, "delta": 1})
+	api.expectError(404, "not_found", "POST", f.path("/records"), f.bob, map[string]any{"toMemberId": "missing", "delta": 1})
+	api.expectError(403, "forbidden", "POST", f.path("/records"), dave, map[string]any{"toMemberId": f.aliceM, "delta": 1})
+
+	resp = api.expect(200, "GET", f.path("/records"), f.carol, nil)
+	if items := list(resp, "items"); len(items) != 1 || str(items[0], "note") != "自摸" {
+		t.Fatalf("list records: %v", resp)
+	}
+	api.expectError(403, "forbidden", "GET", f.path("/records"), dave, nil)
+}
+
+func TestScorebookVoidRecord(t *testing.T) {
+	f := newScorebook(t)
+	api := f.api
+
+	resp := api.expect(200, "POST", f.path("/records"), f.bob, map[string]any{"toMemberId": f.carolM, "delta": 5})
+	recordID := str(resp, "record", "id")
+	voidPath := f.path("/records/" + recordID + "/void")
+
+	// 只有记录人或房主可以作废
+	api.expectError(403, "forbidden", "POST", voidPath, f.carol, nil)
+
+	resp = api.expect(200, "POST", voidPath, f.bob, nil)
+	if !boolean(resp, "record", "voided") || str(resp, "record", "voidedByMemberId") != f.bobM {
+		t.Fatalf("voided record: %v", resp)
+	}
+	if str(resp, "reversal", "reversesRecordId") != recordID || str(resp, "reversal", "fromMemberId") != f.carolM {
+		t.Fatalf("reversal: %v", resp)
+	}
+	if s := f.scores(t); s[f.bobM] != 0 || s[f.carolM] != 0 {
+		t.Fatalf("scores after void: %v", s)
+	}
+	api.expectError(409, "voided", "POST", voidPath, f.alice, nil)
+
+	// 冲正记录不单独出现在流水中
+	resp = api.expect(200, "GET", f.path("/records"), f.bob, nil)
+	if items := list(resp, "items"); len(items) != 1 || !boolean(items[0], "voided") {
+		t.Fatalf("records after void: %v", resp)
+	}
+
+	resp = api.expect(200, "POST", f.path("/records"), f.bob, map[string]any{"toMemberId": f.carolM, "delta": 5})
+	late := str(resp, "record", "id")
+	api.st.Now = func() time.Time { return time.Now().Add(time.Hour) }
+	api.expectError(400, "void_window_closed", "POST", f.path("/records/"+late+"/void"), f.alice, nil)
+
+	api.expectError(404, "not_found", "POST", f.path("/records/missing/void"), f.alice, nil)
+}
+
+func TestScorebookRounds(t *testing.T) {
+	f := newScorebook(t)
+	api := f.api
+
+	resp := api.expect(200, "POST", f.path("/rounds"), f.carol, map[string]any{
+		"deltas": []any{
+			map[string]any{"memberId": f.aliceM, "delta": 30},
+			map[string]any{"memberId": f.bobM, "delta": -10},
+			map[string]any{"memberId": f.carolM, "delta": -20},
+		},
+		"note": "第一把",
+	})
+	round := obj(resp, "round")
+	if num(round, "roundNo") != 1 || str(round, "createdByMemberId") != f.carolM || len(list(round, "records")) != 2 {
+		t.Fatalf("create round: %v", resp)
+	}
+	if s := f.scores(t); s[f.aliceM] != 30 || s[f.bobM] != -10 || s[f.carolM] != -20 {
+		t.Fatalf("scores after round: %v", s)
+	}
+
+	roundRecord := str(list(round, "records")[0], "id")
+	api.expectError(400, "bad_request", "POST", f.path("/records/"+roundRecord+"/void"), f.alice, nil)
+
+	api.expectError(400, "not_zero_sum", "POST", f.path("/rounds"), f.alice, map[string]any{
+		"deltas": []any{
+			map[string]any{"memberId": f.aliceM, "delta": 10},
+			map[string]any{"memberId": f.bobM, "delta": -5},
+		},
+	})
+	api.expectError(400, "bad_request", "POST", f.path("/rounds"), f.alice, map[string]any{
+		"deltas": []any{
+			map[string]any{"memberId": f.aliceM, "delta": 10},
+			map[string]any{"memberId": "missing", "delta": -10},
+		},
+	})
+
+	resp = api.expect(200, "POST", f.path("/rounds"), f.alice, map[string]any{
+		"deltas": []any{
+			map[string]any{"memberId": f.aliceM, "delta": -1},
+			map[string]any{"memberId": f.bobM, "delta": 1},
+			map[string]any{"memberId": f.carolM, "delta": 0},
+		},
+	})
+	if num(resp, "round", "roundNo") != 2 || len(list(resp, "round", "deltas")) != 2 {
+		t.Fatalf("second round: %v", resp)
+	}
+}
+
+func TestScorebookSettlement(t *testing.T) {
+	f := newScorebook(t)
+	api := f.api
+	dave := api.login("dave", "Dave")
+
+	api.expect(200, "POST", f.path("/rounds"), f.alice, map[string]any{
+		"deltas": []any{
+			map[string]any{"memberId": f.aliceM, "delta": 30},
+			map[string]any{"memberId": f.bobM, "delta": -10},
+			map[string]any{"memberId": f.carolM, "delta": -20},
+		},
+	})
+
+	resp := api.expect(200, "GET", f.path("/settlement"), f.bob, nil)
+	if boolean(resp, "final") || len(list(resp, "transfers")) != 2 {
+		t.Fatalf("settlement preview: %v", resp)
+	}
+	api.expectError(403, "forbidden", "GET", f.path("/settlement"), dave, nil)
+
+	resp = api.expect(200, "POST", f.path("/end"), f.alice, nil)
+	if str(resp, "winners", "champion", "memberId") != f.aliceM {
+		t.Fatalf("winners: %v", resp)
+	}
+	if len(list(resp, "settlement")) != 2 {
+		t.Fatalf("settlement on end: %v", resp)
+	}
+
+	resp = api.expect(200, "GET", f.path("/settlement"), f.carol, nil)
+	transfers := list(resp, "transfers")
+	if !boolean(resp, "final") || len(transfers) != 2 {
+		t.Fatalf("final settlement: %v", resp)
+	}
+	// 金额大的排在前面
+	if num(transfers[0], "amount") != 20 || str(transfers[0], "fromMemberId") != f.carolM || str(transfers[0], "toNickname") != "Alice" {
+		t.Fatalf("first transfer: %v", transfers[0])
+	}
+	bobTransfer := findBy(t, transfers, "fromMemberId", f.bobM)
+	transferPath := f.path("/settlement/" + str(bobTransfer, "id"))
+
+	api.expectError(403, "forbidden", "PATCH", transferPath, f.carol, map[string]any{"paid": true})
+	api.expectError(403, "forbidden", "PATCH", transferPath, dave, map[string]any{"paid": true})
+	api.expectError(404, "not_found", "PATCH", f.path("/settlement/missing"), f.bob, map[string]any{"paid": true})
+	api.expectError(400, "bad_request", "PATCH", transferPath, f.bob, map[string]any{})
+
+	resp = api.expect(200, "PATCH", transferPath, f.bob, map[string]any{"paid": true})
+	if !boolean(resp, "transfer", "paid") || str(resp, "transfer", "paidByMemberId") != f.bobM {
+		t.Fatalf("mark paid: %v", resp)
+	}
+	resp = api.expect(200, "PATCH", transferPath, f.alice, map[string]any{"paid": false})
+	if boolean(resp, "transfer", "paid") {
+		t.Fatalf("unmark paid: %v", resp)
+	}
+}
@@ -0,0 +1,119 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cloudwego/hertz/pkg/app"
+
+	"scorehub/internal/http/middleware"
+	"scorehub/internal/store"
+)
+
+type StatsHandlers struct {
+	st store.ScorebookRepo
+}
+
+func NewStatsHandlers(st store.ScorebookRepo) *StatsHandlers {
+	return &StatsHandlers{st: st}
+}
+
+// GetMyStats 汇总我参与过的已结束得分簿的成绩：局数、胜率、净分、最好/最差一局、连胜连败，
+// 以及与常见对手的交锋记录。可按开始日期（from/to，含当天）与地点过滤。
+func (h *StatsHandlers) GetMyStats(ctx context.Context, c *app.RequestContext) {
+	uid, ok := middleware.UserID(c)
+	if !ok {
+		writeError(c, http.StatusUnauthorized, "unauthorized", "missing user")
+		return
+	}
+
+	var filter store.GameResultFilter
+	if v := strings.TrimSpace(c.Query("from")); v != "" {
+		t, err := parseDateRequired(v)
+		if err != nil {
+			writeError(c, http.StatusBadRequest, "bad_request", "invalid from")
+			return
+		}
+		filter.From = &t
+	}
+	if v := strings.TrimSpace(c.Query("to")); v != "" {
+		t, err := parseDateRequired(v)
+		if err != nil {
+			writeError(c, http.StatusBadRequest, "bad_request", "invalid to")
+			return
+		}
+		t = t.Add(24 * time.Hour)
+		filter.To = &t
+	}
+	if filter.From != nil && filter.To != nil && !filter.From.Before(*filter.To) {
+		writeError(c, http.StatusBadRequest, "bad_request", "from must not be after to")
+		return
+	}
+	filter.Location = strings.TrimSpace(c.Query("location"))
+	opponents := 10
+	if v := strings.TrimSpace(c.Query("opponents")); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n >= 0 && n <= 50 {
+			opponents = n
+		}
+	}
+
+	games, err := h.st.ListGameResults(ctx, uid, filter)
+	if err != nil {
+		writeError(c, http.StatusInternalServerError, "internal", "db error", err)
+		return
+	}
+	st := store.SummarizeGames(games, opponents)
+
+	h2h := make([]map[string]any, 0, len(st.HeadToHead))
+	for _, r := range st.HeadToHead {
+		h2h = append(h2h, map[string]any{
+			"userId":           r.UserID,
+			"nickname":         r.Nickname,
+			"avatarUrl":        r.AvatarURL,
+			"games":            r.Games,
+			"wins":             r.Wins,
+			"losses":           r.Losses,
+			"draws":            r.Draws,
+			"netScore":         r.NetScore,
+			"opponentNetScore": r.OpponentNetScore,
+			"lastPlayedAt":     r.LastPlayedAt,
+		})
+	}
+	c.JSON(http.StatusOK, map[string]any{
+		"stats": map[string]any{
+			"games":    st.Games,
+			"wins":     st.Wins,
+			"losses":   st.Losses,
+			"draws":    st.Draws,
+			"winRate":  st.WinRate,
+			"netScore": st.NetScore,
+			"best":     toGameResultDTO(st.Best),
+			"worst":    toGameResultDTO(st.Worst),
+			"streaks": map[string]any{
+				"longestWin":     st.LongestWinStreak,
+				"longestLoss":    st.LongestLossStreak,
+				"current":        st.CurrentStreak,
+				"currentOutcome": st.CurrentStreakOutcome,
+			},
+			"headToHead": h2h,
+		},
+	})
+}
+
+func toGameResultDTO(g *store.GameResult) any {
+	if g == nil {
+		return nil
+	}
+	return map[string]any{
+		"scorebookId":  g.ScorebookID,
+		"name":         g.Name,
+		"locationText": g.LocationText,
+		"startTime":    g.StartTime,
+		"endedAt":      g.EndedAt,
+		"score":        g.Score,
+		"rank":         g.Rank,
+	}
+}
@@ -0,0 +1,95 @@
+package handlers_test
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMyStats(t *testing.T) {
+	api := newTestAPI(t)
+	alice := api.login("alice", "Alice")
+	bob := api.login("bob", "Bob")
+	carol := api.login("carol", "Carol")
+
+	now := time.Date(2026, 5, 1, 20, 0, 0, 0, time.UTC)
+	api.st.Now = func() time.Time { return now }
+
+	// play 在 day 由 alice 建一局：players 加入后按 transfers 记分（from 付给 to），end 时结束
+	type transfer struct {
+		from, to string
+		delta    float64
+	}
+	play := func(day time.Time, location string, players []string, transfers []transfer, end bool) {
+		t.Helper()
+		now = day
+		resp := api.expect(200, "POST", "/api/v1/scorebooks", alice, map[string]any{"name": "牌局", "locationText": location})
+		id := str(resp, "scorebook", "id")
+		code := str(resp, "scorebook", "inviteCode")
+		members := map[string]string{alice: str(resp, "me", "id")}
+		for _, p := range players {
+			resp = api.expect(200, "POST", "/api/v1/scorebooks/"+id+"/join", p, map[string]any{"inviteCode": code})
+			members[p] = str(resp, "member", "id")
+		}
+		for _, tr := range transfers {
+			now = now.Add(time.Minute)
+			api.expect(200, "POST", "/api/v1/scorebooks/"+id+"/records", tr.from, map[string]any{"toMemberId": members[tr.to], "delta": tr.delta})
+		}
+		if end {
+			now = now.Add(time.Hour)
+			api.expect(200, "POST", "/api/v1/scorebooks/"+id+"/end", alice, nil)
+		}
+	}
+	play(time.Date(2026, 5, 1, 20, 0, 0, 0, time.UTC), "老地方", []string{bob, carol}, []transfer{{bob, alice, 10}}, true)
+	play(time.Date(2026, 5, 10, 20, 0, 0, 0, time.UTC), "公司", []string{bob}, []transfer{{alice, bob, 5}}, true)
+	play(time.Date(2026, 6, 1, 20, 0, 0, 0, time.UTC), "老地方二楼", []string{bob, carol}, []transfer{{bob, alice, 3}, {carol, alice, 2}}, true)
+	// 进行中的得分簿不计入
+	play(time.Date(2026, 6, 2, 20, 0, 0, 0, time.UTC), "老地方", []string{bob}, []transfer{{bob, alice, 100}}, false)
+
+	resp := api.expect(200, "GET", "/api/v1/me/stats", alice, nil)
+	if num(resp, "stats", "games") != 3 || num(resp, "stats", "wins") != 2 || num(resp, "stats", "losses") != 1 ||
+		num(resp, "stats", "winRate") != 0.6667 || num(resp, "stats", "netScore") != 10 {
+		t.Fatalf("stats: %v", resp)
+	}
+	if num(resp, "stats", "best", "score") != 10 || num(resp, "stats", "best", "rank") != 1 || num(resp, "stats", "worst", "score") != -5 {
+		t.Fatalf("best/worst: %v", resp)
+	}
+	if num(resp, "stats", "streaks", "longestWin") != 1 || num(resp, "stats", "streaks", "current") != 1 ||
+		str(resp, "stats", "streaks", "currentOutcome") != "win" {
+		t.Fatalf("streaks: %v", resp)
+	}
+	h2h := list(resp, "stats", "headToHead")
+	if len(h2h) != 2 || str(h2h[0], "nickname") != "Bob" || num(h2h[0], "games") != 3 || num(h2h[0], "wins") != 2 ||
+		num(h2h[0], "losses") != 1 || num(h2h[0], "opponentNetScore") != -8 {
+		t.Fatalf("head to head: %v", resp)
+	}
+	if num(h2h[1], "games") != 2 || num(h2h[1], "wins") != 2 {
+		t.Fatalf("head to head carol: %v", resp)
+	}
+
+	resp = api.expect(200, "GET", "/api/v1/me/stats?location=%E8%80%81%E5%9C%B0%E6%96%B9", alice, nil)
+	if num(resp, "stats", "games") != 2 || num(resp, "stats", "streaks", "longestWin") != 2 {
+		t.Fatalf("location filter: %v", resp)
+	}
+	resp = api.expect(200, "GET", "/api/v1/me/stats?from=2026-05-10&to=2026-05-10", alice, nil)
+	if num(resp, "stats", "games") != 1 || num(resp, "stats", "losses") != 1 || str(resp, "stats", "streaks", "currentOutcome") != "loss" {
+		t.Fatalf("date filter: %v", resp)
+	}
+	resp = api.expect(200, "GET", "/api/v1/me/stats?opponents=1", alice, nil)
+	if len(list(resp, "stats", "headToHead")) != 1 {
+		t.Fatalf("opponents limit: %v", resp)
+	}
+
+	// 卡罗尔的视角：第 1 局平，第 3 局负
+	resp = api.expect(200, "GET", "/api/v1/me/stats", carol, nil)
+	if num(resp, "stats", "games") != 2 || num(resp, "stats", "draws") != 1 || num(resp, "stats", "losses") != 1 {
+		t.Fatalf("carol stats: %v", resp)
+	}
+
+	api.expectError(400, "bad_request", "GET", "/api/v1/me/stats?from=2026-13-01", alice, nil)
+	api.expectError(400, "bad_request", "GET", "/api/v1/me/stats?from=2026-06-01&to=2026-05-01", alice, nil)
+	dave := api.login("dave", "Dave")
+	resp = api.expect(200, "GET", "/api/v1/me/stats", dave, nil)
+	if num(resp, "stats", "games") != 0 || field(resp, "stats", "best") != nil || len(list(resp, "stats", "headToHead")) != 0 {
+		t.Fatalf("empty stats: %v", resp)
+	}
+}
@@ -0,0 +1,297 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/cloudwego/hertz/pkg/app"
+
+	"scorehub/internal/http/middleware"
+	"scorehub/internal/store"
+)
+
+// 模板的上限，避免一次创建出过多占位成员或按钮。
+const (
+	maxTemplateDeltaPresets = 12
+	maxTemplateMembers      = 20
+	maxTemplateNameLen      = 50
+)
+
+type TemplateHandlers struct {
+	st store.TemplateRepo
+}
+
+func NewTemplateHandlers(st store.TemplateRepo) *TemplateHandlers {
+	return &TemplateHandlers{st: st}
+}
+
+type templateRequest struct {
+	Name            *string    `json:"name"`
+	GameType        *string    `json:"gameType"`
+	StakeMultiplier *float64   `json:"stakeMultiplier"`
+	DeltaPresets    *[]float64 `json:"deltaPresets"`
+	// Members 为默认名单（占位成员昵称）
+	Members *[]string `json:"members"`
+}
+
+// normalize 校验并整理请求中出现的字段，返回错误信息；未出现的字段保持 nil。
+func (req *templateRequest) normalize() string {
+	if req.Name != nil {
+		v := strings.TrimSpace(*req.Name)
+		if v == "" || utf8.RuneCountInString(v) > maxTemplateNameLen {
+			return "invalid name"
+		}
+		req.Name = &v
+	}
+	if req.GameType != nil {
+		v := strings.TrimSpace(*req.GameType)
+		if utf8.RuneCountInString(v) > maxTemplateNameLen {
+			return "invalid gameType"
+		}
+		req.GameType = &v
+	}
+	if req.StakeMultiplier != nil {
+		v, ok := store.NormalizeAmount(*req.StakeMultiplier)
+		if !ok {
+			return "stakeMultiplier must be positive with at most 2 decimals"
+		}
+		req.StakeMultiplier = &v
+	}
+	if req.DeltaPresets != nil {
+		if len(*req.DeltaPresets) > maxTemplateDeltaPresets {
+			return "too many deltaPresets"
+		}
+		presets := make([]float64, 0, len(*req.DeltaPresets))
+		seen := map[float64]bool{}
+		for _, d := range *req.DeltaPresets {
+			v, ok := store.NormalizeAmount(d)
+			if !ok {
+				return "deltaPresets must be positive with at most 2 decimals"
+			}
+			if !seen[v] {
+				seen[v] = true
+				presets = append(presets, v)
+			}
+		}
+		req.DeltaPresets = &presets
+	}
+	if req.Members != nil {
+		if len(*req.Members) > maxTemplateMembers {
+			return "too many members"
+		}
+		names := make([]string, 0, len(*req.Members))
+		seen := map[string]bool{}
+		for _, n := range *req.Members {
+			n = strings.TrimSpace(n)
+			if n == "" || utf8.RuneCountInString(n) > maxTemplateNameLen {
+				return "invalid member name"
+			}
+			if seen[n] {
+				return "duplicate member name"
+			}
+			seen[n] = true
+			names = append(names, n)
+		}
+		req.Members = &names
+	}
+	return ""
+}
+
+func (h *TemplateHandlers) CreateTemplate(ctx context.Context, c *app.RequestContext) {
+	uid, ok := middleware.UserID(c)
+	if !ok {
+		writeError(c, http.StatusUnauthorized, "unauthorized", "missing user")
+		return
+	}
+
+	var req templateRequest
+	body, err := c.Body()
+	if err != nil {
+		writeError(c, http.StatusBadRequest, "bad_request", "read body failed")
+		return
+	}
+	if err := json.Unmarshal(body, &req); err != nil {
+		writeError(c, http.StatusBadRequest, "bad_request", "invalid json")
+		return
+	}
+	if req.Name == nil {
+		writeError(c, http.StatusBadRequest, "bad_request", "name required")
+		return
+	}
+	if msg := req.normalize(); msg != "" {
+		writeError(c, http.StatusBadRequest, "bad_request", msg)
+		return
+	}
+
+	in := store.ScorebookTemplateInput{Name: *req.Name, StakeMultiplier: 1}
+	if req.GameType != nil {
+		in.GameType = *req.GameType
+	}
+	if req.StakeMultiplier != nil {
+		in.StakeMultiplier = *req.StakeMultiplier
+	}
+	if req.DeltaPresets != nil {
+		in.DeltaPresets = *req.DeltaPresets
+	}
+	if req.Members != nil {
+		in.MemberNames = *req.Members
+	}
+
+	t, err := h.st.CreateScorebookTemplate(ctx, uid, in)
+	if err != nil {
+		if err == store.ErrInvalidArgument {
+			writeError(c, http.StatusBadRequest, "bad_request", "name required")
+			return
+		}
+		writeError(c, http.StatusInternalServerError, "internal", "db error", err)
+		return
+	}
+	c.JSON(http.StatusOK, map[string]any{"template": toTemplateDTO(t)})
+}
+
+func (h *TemplateHandlers) ListTemplates(ctx context.Context, c *app.RequestContext) {
+	uid, ok := middleware.UserID(c)
+	if !ok {
+		writeError(c, http.StatusUnauthorized, "unauthorized", "missing user")
+		return
+	}
+
+	limit := int32(20)
+	offset := int32(0)
+	if v := strings.TrimSpace(string(c.Query("limit"))); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 && n <= 200 {
+			limit = int32(n)
+		}
+	}
+	if v := strings.TrimSpace(string(c.Query("offset"))); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n >= 0 {
+			offset = int32(n)
+		}
+	}
+
+	list, err := h.st.ListScorebookTemplates(ctx, uid, limit, offset)
+	if err != nil {
+		writeError(c, http.StatusInternalServerError, "internal", "db error", err)
+		return
+	}
+	items := make([]map[string]any, 0, len(list))
+	for _, t := range list {
+		items = append(items, toTemplateDTO(t))
+	}
+	c.JSON(http.StatusOK, map[string]any{"items": items, "limit": limit, "offset": offset})
+}
+
+func (h *TemplateHandlers) GetTemplate(ctx context.Context, c *app.RequestContext) {
+	uid, ok := middleware.UserID(c)
+	if !ok {
+		writeError(c, http.StatusUnauthorized, "unauthorized", "missing user")
+		return
+	}
+	id := strings.TrimSpace(c.Param("id"))
+	if id == "" {
+		writeError(c, http.StatusBadRequest, "bad_request", "id required")
+		return
+	}
+
+	t, err := h.st.GetScorebookTemplate(ctx, uid, id)
+	if err != nil {
+		if err == store.ErrNotFound {
+			writeError(c, http.StatusNotFound, "not_found", "template not found")
+			return
+		}
+		writeError(c, http.StatusInternalServerError, "internal", "db error", err)
+		return
+	}
+	c.JSON(http.StatusOK, map[string]any{"template": toTemplateDTO(t)})
+}
+
+func (h *TemplateHandlers) UpdateTemplate(ctx context.Context, c *app.RequestContext) {
+	uid, ok := middleware.UserID(c)
+	if !ok {
+		writeError(c, http.StatusUnauthorized, "unauthorized", "missing user")
+		return
+	}
+	id := strings.TrimSpace(c.Param("id"))
+	if id == "" {
+		writeError(c, http.StatusBadRequest, "bad_request", "id required")
+		return
+	}
+
+	var req templateRequest
+	body, err := c.Body()
+	if err != nil {
+		writeError(c, http.StatusBadRequest, "bad_request", "read body failed")
+		return
+	}
+	if err := json.Unmarshal(body, &req); err != nil {
+		writeError(c, http.StatusBadRequest, "bad_request", "invalid json")
+		return
+	}
+	if msg := req.normalize(); msg != "" {
+		writeError(c, http.StatusBadRequest, "bad_request", msg)
+		return
+	}
+
+	t, err := h.st.UpdateScorebookTemplate(ctx, uid, id, store.ScorebookTemplateUpdate{
+		Name:            req.Name,
+		GameType:        req.GameType,
+		StakeMultiplier: req.StakeMultiplier,
+		DeltaPresets:    req.DeltaPresets,
+		MemberNames:     req.Members,
+	})
+	if err != nil {
+		switch err {
+		case store.ErrNotFound:
+			writeError(c, http.StatusNotFound, "not_found", "template not found")
+			return
+		case store.ErrInvalidArgument:
+			writeError(c, http.StatusBadRequest, "bad_request", "invalid name")
+			return
+		default:
+			writeError(c, http.StatusInternalServerError, "internal", "db error", err)
+			return
+		}
+	}
+	c.JSON(http.StatusOK, map[string]any{"template": toTemplateDTO(t)})
+}
+
+// DeleteTemplate 删除模板；用它创建的得分簿保留各自的设置。
+func (h *TemplateHandlers) DeleteTemplate(ctx context.Context, c *app.RequestContext) {
+	uid, ok := middleware.UserID(c)
+	if !ok {
+		writeError(c, http.StatusUnauthorized, "unauthorized", "missing user")
+		return
+	}
+	id := strings.TrimSpace(c.Param("id"))
+	if id == "" {
+		writeError(c, http.StatusBadRequest, "bad_request", "id required")
+		return
+	}
+
+	if err := h.st.DeleteScorebookTemplate(ctx, uid, id); err != nil {
+		if err == store.ErrNotFound {
+			writeError(c, http.StatusNotFound, "not_found", "template not found")
+			return
+		}
+		writeError(c, http.StatusInternalServerError, "internal", "db error", err)
+		return
+	}
+	c.JSON(http.StatusOK, map[string]any{"ok": true})
+}
+
+func toTemplateDTO(t store.ScorebookTemplate) map[string]any {
+	return map[string]any{
+		"id":              t.ID,
+		"name":            t.Name,
+		"gameType":        t.GameType,
+		"stakeMultiplier": t.StakeMultiplier,
+		"deltaPresets":    t.DeltaPresets,
+		"members":         t.MemberNames,
+		"createdAt":       t.CreatedAt,
+		"updatedAt":       t.UpdatedAt,
+	}
+}
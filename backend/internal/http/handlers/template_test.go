@@ -0,0 +1,98 @@
+package handlers_test
+
+import "testing"
+
+func TestScorebookTemplates(t *testing.T) {
+	api := newTestAPI(t)
+	alice := api.login("alice", "Alice")
+	bob := api.login("bob", "Bob")
+
+	api.expectError(400, "bad_request", "POST", "/api/v1/scorebook_templates", alice, map[string]any{"gameType": "麻将"})
+	api.expectError(400, "bad_request", "POST", "/api/v1/scorebook_templates", alice, map[string]any{"name": "周五", "stakeMultiplier": 0})
+	api.expectError(400, "bad_request", "POST", "/api/v1/scorebook_templates", alice, map[string]any{"name": "周五", "deltaPresets": []any{1, -2}})
+	api.expectError(400, "bad_request", "POST", "/api/v1/scorebook_templates", alice, map[string]any{"name": "周五", "members": []any{"老王", " 老王 "}})
+
+	resp := api.expect(200, "POST", "/api/v1/scorebook_templates", alice, map[string]any{
+		"name":            " 周五麻将 ",
+		"gameType":        "麻将",
+		"stakeMultiplier": 2.5,
+		"deltaPresets":    []any{1, 2, 2, 5},
+		"members":         []any{"老王", "老李"},
+	})
+	tplID := str(resp, "template", "id")
+	if str(resp, "template", "name") != "周五麻将" || num(resp, "template", "stakeMultiplier") != 2.5 ||
+		len(list(resp, "template", "deltaPresets")) != 3 || len(list(resp, "template", "members")) != 2 {
+		t.Fatalf("create template: %v", resp)
+	}
+	resp = api.expect(200, "POST", "/api/v1/scorebook_templates", alice, map[string]any{"name": "斗地主"})
+	if num(resp, "template", "stakeMultiplier") != 1 || len(list(resp, "template", "members")) != 0 {
+		t.Fatalf("template defaults: %v", resp)
+	}
+	otherID := str(resp, "template", "id")
+
+	// 模板按用户隔离
+	api.expectError(404, "not_found", "GET", "/api/v1/scorebook_templates/"+tplID, bob, nil)
+	api.expectError(404, "not_found", "POST", "/api/v1/scorebooks", bob, map[string]any{"templateId": tplID})
+	resp = api.expect(200, "GET", "/api/v1/scorebook_templates", bob, nil)
+	if len(list(resp, "items")) != 0 {
+		t.Fatalf("bob templates: %v", resp)
+	}
+
+	resp = api.expect(200, "PATCH", "/api/v1/scorebook_templates/"+otherID, alice, map[string]any{"deltaPresets": []any{10}})
+	if len(list(resp, "template", "deltaPresets")) != 1 || str(resp, "template", "name") != "斗地主" {
+		t.Fatalf("update template: %v", resp)
+	}
+	resp = api.expect(200, "GET", "/api/v1/scorebook_templates", alice, nil)
+	if items := list(resp, "items"); len(items) != 2 || str(items[0], "id") != otherID {
+		t.Fatalf("list templates: %v", resp)
+	}
+
+	// 用模板创建：复制玩法设置，名单成为占位成员
+	resp = api.expect(200, "POST", "/api/v1/scorebooks", alice, map[string]any{"name": "本周", "templateId": tplID})
+	id := str(resp, "scorebook", "id")
+	if str(resp, "scorebook", "game", "templateId") != tplID || str(resp, "scorebook", "game", "gameType") != "麻将" ||
+		num(resp, "scorebook", "game", "stakeMultiplier") != 2.5 || len(list(resp, "scorebook", "game", "deltaPresets")) != 3 ||
+		num(resp, "scorebook", "game", "pointValue") != 2.5 {
+		t.Fatalf("create from template: %v", resp)
+	}
+	resp = api.expect(200, "GET", "/api/v1/scorebooks/"+id, alice, nil)
+	members := list(resp, "members")
+	if len(members) != 3 {
+		t.Fatalf("members: %v", resp)
+	}
+	wang := findBy(t, members, "nickname", "老王")
+	li := findBy(t, members, "nickname", "老李")
+	if !boolean(wang, "placeholder") || boolean(wang, "removed") {
+		t.Fatalf("placeholder member: %v", wang)
+	}
+	aliceM := str(resp, "me", "memberId")
+
+	// 单条记分只能使用预设分值；占位成员可以被记分，也可以被移出
+	api.expectError(400, "delta_not_allowed", "POST", "/api/v1/scorebooks/"+id+"/records", alice, map[string]any{"toMemberId": str(wang, "id"), "delta": 3})
+	api.expect(200, "POST", "/api/v1/scorebooks/"+id+"/records", alice, map[string]any{"toMemberId": str(wang, "id"), "delta": 5})
+	api.expect(200, "POST", "/api/v1/scorebooks/"+id+"/rounds", alice, map[string]any{"deltas": []any{
+		map[string]any{"memberId": aliceM, "delta": 3},
+		map[string]any{"memberId": str(li, "id"), "delta": -3},
+	}})
+	resp = api.expect(200, "DELETE", "/api/v1/scorebooks/"+id+"/members/"+str(wang, "id"), alice, nil)
+	if !boolean(resp, "kept") {
+		t.Fatalf("remove placeholder: %v", resp)
+	}
+	resp = api.expect(200, "GET", "/api/v1/scorebooks/"+id, alice, nil)
+	wang = findBy(t, list(resp, "members"), "nickname", "老王")
+	if boolean(wang, "placeholder") || !boolean(wang, "removed") || num(wang, "score") != 5 || num(wang, "money") != 12.5 {
+		t.Fatalf("removed placeholder: %v", wang)
+	}
+
+	// 不带模板时为默认设置；删除模板不影响已创建的得分簿
+	resp = api.expect(200, "POST", "/api/v1/scorebooks", alice, map[string]any{"name": "随便"})
+	if num(resp, "scorebook", "game", "stakeMultiplier") != 1 || str(resp, "scorebook", "game", "templateId") != "" {
+		t.Fatalf("create without template: %v", resp)
+	}
+	api.expect(200, "DELETE", "/api/v1/scorebook_templates/"+tplID, alice, nil)
+	api.expectError(404, "not_found", "DELETE", "/api/v1/scorebook_templates/"+tplID, alice, nil)
+	resp = api.expect(200, "GET", "/api/v1/scorebooks/"+id, alice, nil)
+	if str(resp, "scorebook", "game", "templateId") != "" || str(resp, "scorebook", "game", "gameType") != "麻将" {
+		t.Fatalf("scorebook after template deleted: %v", resp)
+	}
+}
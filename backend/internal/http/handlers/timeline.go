@@ -0,0 +1,91 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cloudwego/hertz/pkg/app"
+
+	"scorehub/internal/http/middleware"
+	"scorehub/internal/store"
+)
+
+// GetScoreTimeline 返回每个成员随记录变化的累计分数，用于画折线图。
+// 默认每条记录一个点；records=N 每 N 条记录一个点，interval=5m 按时间分桶（与 records 互斥）。
+func (h *ScorebookHandlers) GetScoreTimeline(ctx context.Context, c *app.RequestContext) {
+	uid, ok := middleware.UserID(c)
+	if !ok {
+		writeError(c, http.StatusUnauthorized, "unauthorized", "missing user")
+		return
+	}
+	id := strings.TrimSpace(c.Param("id"))
+	if id == "" {
+		writeError(c, http.StatusBadRequest, "bad_request", "id required")
+		return
+	}
+
+	var bucket store.TimelineBucket
+	records := strings.TrimSpace(string(c.Query("records")))
+	interval := strings.TrimSpace(string(c.Query("interval")))
+	if records != "" && interval != "" {
+		writeError(c, http.StatusBadRequest, "bad_request", "records and interval are exclusive")
+		return
+	}
+	if records != "" {
+		n, err := strconv.Atoi(records)
+		if err != nil || n <= 0 || n > 10000 {
+			writeError(c, http.StatusBadRequest, "bad_request", "invalid records")
+			return
+		}
+		bucket.Records = n
+	}
+	if interval != "" {
+		d, err := time.ParseDuration(interval)
+		if err != nil || d < time.Minute || d > 30*24*time.Hour || d%time.Second != 0 {
+			writeError(c, http.StatusBadRequest, "bad_request", "invalid interval")
+			return
+		}
+		bucket.Interval = d
+	}
+	if bucket.Records == 0 && bucket.Interval == 0 {
+		bucket.Records = 1
+	}
+
+	members, points, err := h.st.ScoreTimeline(ctx, id, uid, bucket)
+	if err != nil {
+		if err == store.ErrNotFound {
+			writeError(c, http.StatusNotFound, "not_found", "scorebook not found")
+			return
+		}
+		writeError(c, http.StatusInternalServerError, "internal", "db error", err)
+		return
+	}
+
+	outMembers := make([]map[string]any, 0, len(members))
+	for _, m := range members {
+		outMembers = append(outMembers, map[string]any{
+			"id":          m.ID,
+			"nickname":    m.Nickname,
+			"avatarUrl":   m.AvatarURL,
+			"removed":     m.UserID == 0 && !m.Placeholder,
+			"placeholder": m.Placeholder,
+		})
+	}
+	outPoints := make([]map[string]any, 0, len(points))
+	for _, p := range points {
+		outPoints = append(outPoints, map[string]any{
+			"records": p.Records,
+			"startAt": p.StartAt,
+			"endAt":   p.EndAt,
+			"scores":  p.Scores,
+		})
+	}
+	outBucket := map[string]any{"records": bucket.Records}
+	if bucket.Interval > 0 {
+		outBucket = map[string]any{"intervalSeconds": int64(bucket.Interval / time.Second)}
+	}
+	c.JSON(http.StatusOK, map[string]any{"members": outMembers, "points": outPoints, "bucket": outBucket})
+}
@@ -0,0 +1,69 @@
+package handlers_test
+
+import (
+	"testing"
+	"time"
+)
+
+func TestScoreTimeline(t *testing.T) {
+	f := newScorebook(t)
+	api := f.api
+	dave := api.login("dave", "Dave")
+
+	t0 := time.Date(2026, 3, 1, 20, 0, 0, 0, time.UTC)
+	at := func(d time.Duration) { api.st.Now = func() time.Time { return t0.Add(d) } }
+
+	resp := api.expect(200, "GET", f.path("/timeline"), f.alice, nil)
+	if len(list(resp, "points")) != 0 || len(list(resp, "members")) != 3 {
+		t.Fatalf("empty timeline: %v", resp)
+	}
+
+	at(time.Minute)
+	api.expect(200, "POST", f.path("/records"), f.alice, map[string]any{"toMemberId": f.bobM, "delta": 10})
+	at(2 * time.Minute)
+	resp = api.expect(200, "POST", f.path("/records"), f.bob, map[string]any{"toMemberId": f.carolM, "delta": 5})
+	voidPath := f.path("/records/" + str(resp, "record", "id") + "/void")
+	at(3 * time.Minute)
+	api.expect(200, "POST", voidPath, f.bob, nil)
+	at(12 * time.Minute)
+	api.expect(200, "POST", f.path("/records"), f.carol, map[string]any{"toMemberId": f.aliceM, "delta": 3})
+
+	// 每条记录一个点（冲正记录也算），分数为累计值
+	resp = api.expect(200, "GET", f.path("/timeline"), f.alice, nil)
+	points := list(resp, "points")
+	if len(points) != 4 || num(resp, "bucket", "records") != 1 {
+		t.Fatalf("timeline: %v", resp)
+	}
+	want := [][3]float64{{-10, 10, 0}, {-10, 5, 5}, {-10, 10, 0}, {-7, 10, -3}}
+	for i, w := range want {
+		got := [3]float64{num(points[i], "scores", f.aliceM), num(points[i], "scores", f.bobM), num(points[i], "scores", f.carolM)}
+		if got != w || num(points[i], "records") != float64(i+1) {
+			t.Fatalf("point %d: %v", i, points[i])
+		}
+	}
+
+	// 每 3 条记录一个点
+	resp = api.expect(200, "GET", f.path("/timeline?records=3"), f.alice, nil)
+	points = list(resp, "points")
+	if len(points) != 2 || num(points[0], "records") != 3 || num(points[0], "scores", f.carolM) != 0 || num(points[1], "scores", f.bobM) != 10 {
+		t.Fatalf("records bucket: %v", resp)
+	}
+
+	// 按 10 分钟分桶：20:00 与 20:10 两个桶
+	resp = api.expect(200, "GET", f.path("/timeline?interval=10m"), f.bob, nil)
+	points = list(resp, "points")
+	if len(points) != 2 || num(resp, "bucket", "intervalSeconds") != 600 {
+		t.Fatalf("interval bucket: %v", resp)
+	}
+	if str(points[0], "startAt") != "2026-03-01T20:00:00Z" || str(points[1], "startAt") != "2026-03-01T20:10:00Z" || num(points[1], "records") != 4 {
+		t.Fatalf("interval points: %v", points)
+	}
+	if num(points[0], "scores", f.bobM) != 10 || num(points[0], "records") != 3 || num(points[1], "scores", f.aliceM) != -7 {
+		t.Fatalf("interval scores: %v", points)
+	}
+
+	api.expectError(400, "bad_request", "GET", f.path("/timeline?records=2&interval=10m"), f.alice, nil)
+	api.expectError(400, "bad_request", "GET", f.path("/timeline?interval=10s"), f.alice, nil)
+	api.expectError(400, "bad_request", "GET", f.path("/timeline?records=0"), f.alice, nil)
+	api.expectError(404, "not_found", "GET", f.path("/timeline"), dave, nil)
+}
@@ -0,0 +1,210 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/cloudwego/hertz/pkg/app"
+
+	"scorehub/internal/http/middleware"
+	"scorehub/internal/realtime"
+	"scorehub/internal/store"
+)
+
+// OwnershipTransferHandlers 是得分簿与账本共用的所有权转让接口，挂在 /scorebooks/:id/transfer
+// 与 /ledgers/:id/transfer 下。hub 为 nil 时不广播。
+type OwnershipTransferHandlers struct {
+	st       store.OwnershipRepo
+	bookType string
+	hub      *realtime.Hub
+}
+
+func NewOwnershipTransferHandlers(st store.OwnershipRepo, bookType string, hub *realtime.Hub) *OwnershipTransferHandlers {
+	return &OwnershipTransferHandlers{st: st, bookType: bookType, hub: hub}
+}
+
+type requestTransferRequest struct {
+	MemberID string `json:"memberId"`
+}
+
+// Request 由掌柜提名新的掌柜，覆盖之前未处理的提名。
+func (h *OwnershipTransferHandlers) Request(ctx context.Context, c *app.RequestContext) {
+	uid, ok := middleware.UserID(c)
+	if !ok {
+		writeError(c, http.StatusUnauthorized, "unauthorized", "missing user")
+		return
+	}
+	id := strings.TrimSpace(c.Param("id"))
+	if id == "" {
+		writeError(c, http.StatusBadRequest, "bad_request", "id required")
+		return
+	}
+
+	var req requestTransferRequest
+	body, err := c.Body()
+	if err != nil {
+		writeError(c, http.StatusBadRequest, "bad_request", "read body failed")
+		return
+	}
+	if err := json.Unmarshal(body, &req); err != nil {
+		writeError(c, http.StatusBadRequest, "bad_request", "invalid json")
+		return
+	}
+	memberID := strings.TrimSpace(req.MemberID)
+	if memberID == "" {
+		writeError(c, http.StatusBadRequest, "bad_request", "memberId required")
+		return
+	}
+
+	t, err := h.st.RequestOwnershipTransfer(ctx, h.bookType, id, uid, memberID)
+	if err != nil {
+		switch err {
+		case store.ErrNotFound:
+			writeError(c, http.StatusNotFound, "not_found", "member not found")
+			return
+		case store.ErrForbidden:
+			writeError(c, http.StatusForbidden, "forbidden", "only owner can transfer ownership")
+			return
+		case store.ErrInvalidArgument:
+			writeError(c, http.StatusBadRequest, "bad_request", "cannot transfer to yourself or a spectator")
+			return
+		default:
+			writeError(c, http.StatusInternalServerError, "internal", "db error", err)
+			return
+		}
+	}
+
+	h.broadcast(id, "transfer.requested", map[string]any{"transfer": toTransferDTO(t)})
+	c.JSON(http.StatusOK, map[string]any{"transfer": toTransferDTO(t)})
+}
+
+// Get 返回待处理的转让（成员可见）。
+func (h *OwnershipTransferHandlers) Get(ctx context.Context, c *app.RequestContext) {
+	uid, ok := middleware.UserID(c)
+	if !ok {
+		writeError(c, http.StatusUnauthorized, "unauthorized", "missing user")
+		return
+	}
+	id := strings.TrimSpace(c.Param("id"))
+	if id == "" {
+		writeError(c, http.StatusBadRequest, "bad_request", "id required")
+		return
+	}
+
+	t, err := h.st.GetOwnershipTransfer(ctx, h.bookType, id, uid)
+	if err != nil {
+		if err == store.ErrNotFound {
+			writeError(c, http.StatusNotFound, "not_found", "no pending transfer")
+			return
+		}
+		writeError(c, http.StatusInternalServerError, "internal", "db error", err)
+		return
+	}
+	c.JSON(http.StatusOK, map[string]any{"transfer": toTransferDTO(t)})
+}
+
+// Accept 由被提名人接受转让，立即成为掌柜。
+func (h *OwnershipTransferHandlers) Accept(ctx context.Context, c *app.RequestContext) {
+	h.respond(ctx, c, true)
+}
+
+// Decline 由被提名人拒绝转让。
+func (h *OwnershipTransferHandlers) Decline(ctx context.Context, c *app.RequestContext) {
+	h.respond(ctx, c, false)
+}
+
+func (h *OwnershipTransferHandlers) respond(ctx context.Context, c *app.RequestContext, accept bool) {
+	uid, ok := middleware.UserID(c)
+	if !ok {
+		writeError(c, http.StatusUnauthorized, "unauthorized", "missing user")
+		return
+	}
+	id := strings.TrimSpace(c.Param("id"))
+	if id == "" {
+		writeError(c, http.StatusBadRequest, "bad_request", "id required")
+		return
+	}
+
+	t, err := h.st.RespondOwnershipTransfer(ctx, h.bookType, id, uid, accept)
+	if err != nil {
+		switch err {
+		case store.ErrNotFound:
+			writeError(c, http.StatusNotFound, "not_found", "no pending transfer for you")
+			return
+		case store.ErrConflict:
+			writeError(c, http.StatusConflict, "conflict", "owner has changed")
+			return
+		default:
+			writeError(c, http.StatusInternalServerError, "internal", "db error", err)
+			return
+		}
+	}
+
+	if accept {
+		h.broadcast(id, h.bookType+".owner_changed", map[string]any{
+			"transferId":            t.ID,
+			"ownerUserId":           t.ToUserID,
+			"ownerMemberId":         t.ToMemberID,
+			"previousOwnerUserId":   t.FromUserID,
+			"previousOwnerMemberId": t.FromMemberID,
+		})
+	} else {
+		h.broadcast(id, "transfer.declined", map[string]any{"transfer": toTransferDTO(t)})
+	}
+	c.JSON(http.StatusOK, map[string]any{"transfer": toTransferDTO(t)})
+}
+
+// Cancel 由掌柜撤回待处理的提名。
+func (h *OwnershipTransferHandlers) Cancel(ctx context.Context, c *app.RequestContext) {
+	uid, ok := middleware.UserID(c)
+	if !ok {
+		writeError(c, http.StatusUnauthorized, "unauthorized", "missing user")
+		return
+	}
+	id := strings.TrimSpace(c.Param("id"))
+	if id == "" {
+		writeError(c, http.StatusBadRequest, "bad_request", "id required")
+		return
+	}
+
+	if err := h.st.CancelOwnershipTransfer(ctx, h.bookType, id, uid); err != nil {
+		switch err {
+		case store.ErrNotFound:
+			writeError(c, http.StatusNotFound, "not_found", "no pending transfer")
+			return
+		case store.ErrForbidden:
+			writeError(c, http.StatusForbidden, "forbidden", "only owner can cancel")
+			return
+		default:
+			writeError(c, http.StatusInternalServerError, "internal", "db error", err)
+			return
+		}
+	}
+
+	h.broadcast(id, "transfer.cancelled", map[string]any{})
+	c.JSON(http.StatusOK, map[string]any{"ok": true})
+}
+
+func (h *OwnershipTransferHandlers) broadcast(bookID, typ string, data map[string]any) {
+	if h.hub == nil {
+		return
+	}
+	h.hub.Broadcast(bookID, map[string]any{"type": typ, "data": data})
+}
+
+func toTransferDTO(t store.OwnershipTransfer) map[string]any {
+	return map[string]any{
+		"id":           t.ID,
+		"bookId":       t.ScorebookID,
+		"status":       t.Status,
+		"fromUserId":   t.FromUserID,
+		"fromMemberId": t.FromMemberID,
+		"toUserId":     t.ToUserID,
+		"toMemberId":   t.ToMemberID,
+		"toNickname":   t.ToNickname,
+		"createdAt":    t.CreatedAt,
+		"resolvedAt":   t.ResolvedAt,
+	}
+}
@@ -0,0 +1,113 @@
+package handlers_test
+
+import "testing"
+
+func TestScorebookOwnershipTransfer(t *testing.T) {
+	f := newScorebook(t)
+	api := f.api
+	dave := api.login("dave", "Dave")
+	resp := api.expect(200, "POST", f.path("/join"), dave, map[string]any{"role": "spectator", "inviteCode": f.code})
+	daveM := str(resp, "member", "id")
+	api.expect(200, "PATCH", f.path("/members/"+f.bobM), f.alice, map[string]any{"role": "admin"})
+
+	api.expectError(404, "not_found", "GET", f.path("/transfer"), f.bob, nil)
+	api.expectError(403, "forbidden", "POST", f.path("/transfer"), f.bob, map[string]any{"memberId": f.carolM})
+	api.expectError(400, "bad_request", "POST", f.path("/transfer"), f.alice, map[string]any{"memberId": f.aliceM})
+	api.expectError(400, "bad_request", "POST", f.path("/transfer"), f.alice, map[string]any{"memberId": daveM})
+	api.expectError(404, "not_found", "POST", f.path("/transfer"), f.alice, map[string]any{"memberId": "missing"})
+
+	// 重新提名会取消之前的提名
+	api.expect(200, "POST", f.path("/transfer"), f.alice, map[string]any{"memberId": f.carolM})
+	resp = api.expect(200, "POST", f.path("/transfer"), f.alice, map[string]any{"memberId": f.bobM})
+	if str(resp, "transfer", "status") != "pending" || str(resp, "transfer", "toMemberId") != f.bobM {
+		t.Fatalf("request transfer: %v", resp)
+	}
+	resp = api.expect(200, "GET", f.path("/transfer"), f.carol, nil)
+	if str(resp, "transfer", "toNickname") != "Bob" {
+		t.Fatalf("pending transfer: %v", resp)
+	}
+	api.expectError(404, "not_found", "POST", f.path("/transfer/accept"), f.carol, nil)
+
+	// 撤回后无法接受
+	api.expectError(403, "forbidden", "DELETE", f.path("/transfer"), f.bob, nil)
+	api.expect(200, "DELETE", f.path("/transfer"), f.alice, nil)
+	api.expectError(404, "not_found", "POST", f.path("/transfer/accept"), f.bob, nil)
+
+	api.expect(200, "POST", f.path("/transfer"), f.alice, map[string]any{"memberId": f.bobM})
+	resp = api.expect(200, "POST", f.path("/transfer/decline"), f.bob, nil)
+	if str(resp, "transfer", "status") != "declined" {
+		t.Fatalf("decline: %v", resp)
+	}
+
+	api.expect(200, "POST", f.path("/transfer"), f.alice, map[string]any{"memberId": f.bobM})
+	resp = api.expect(200, "POST", f.path("/transfer/accept"), f.bob, nil)
+	if str(resp, "transfer", "status") != "accepted" {
+		t.Fatalf("accept: %v", resp)
+	}
+	api.expectError(404, "not_found", "GET", f.path("/transfer"), f.bob, nil)
+
+	// 角色互换：bob 成为掌柜，alice 接过 bob 原来的管理员角色
+	resp = api.expect(200, "GET", f.path(""), f.alice, nil)
+	if str(resp, "me", "role") != "admin" || boolean(resp, "me", "isOwner") {
+		t.Fatalf("previous owner: %v", resp)
+	}
+	if str(findBy(t, list(resp, "members"), "id", f.bobM), "role") != "owner" {
+		t.Fatalf("new owner: %v", resp)
+	}
+	api.expectError(403, "forbidden", "PATCH", f.path("/members/"+f.carolM), f.alice, map[string]any{"role": "admin"})
+	api.expect(200, "PATCH", f.path("/members/"+f.aliceM), f.bob, map[string]any{"role": "member"})
+
+	api.expect(200, "POST", f.path("/end"), f.bob, nil)
+	api.expectError(403, "forbidden", "DELETE", f.path(""), f.alice, nil)
+	api.expect(200, "DELETE", f.path(""), f.bob, nil)
+}
+
+func TestLedgerOwnershipTransfer(t *testing.T) {
+	api := newTestAPI(t)
+	owner := api.login("owner", "店主")
+	guest := api.login("guest", "来宾")
+
+	resp := api.expect(200, "POST", "/api/v1/ledgers", owner, map[string]any{"name": "婚礼礼金"})
+	id := str(resp, "ledger", "id")
+	path := "/api/v1/ledgers/" + id
+	resp = api.expect(200, "POST", path+"/members", owner, map[string]any{"nickname": "张三"})
+	zhang := str(resp, "member", "id")
+	resp = api.expect(200, "POST", path+"/members", owner, map[string]any{"nickname": "李四"})
+	li := str(resp, "member", "id")
+
+	// 转让前张三送过礼
+	gift := str(api.expect(200, "POST", path+"/records", owner, map[string]any{"memberId": zhang, "type": "income", "amount": 200}), "record", "id")
+
+	// 只能转给已认领的成员
+	api.expectError(404, "not_found", "POST", path+"/transfer", owner, map[string]any{"memberId": zhang})
+	api.expect(200, "POST", path+"/bind", guest, map[string]any{"memberId": zhang})
+	api.expectError(403, "forbidden", "POST", path+"/transfer", guest, map[string]any{"memberId": zhang})
+	api.expect(200, "POST", path+"/transfer", owner, map[string]any{"memberId": zhang})
+	api.expect(200, "POST", path+"/transfer/accept", guest, nil)
+
+	resp = api.expect(200, "PATCH", path, guest, map[string]any{"name": "婚宴"})
+	if str(resp, "ledger", "name") != "婚宴" {
+		t.Fatalf("new owner update: %v", resp)
+	}
+	api.expectError(403, "forbidden", "PATCH", path, owner, map[string]any{"name": "x"})
+	api.expectError(403, "forbidden", "POST", path+"/members", owner, map[string]any{"nickname": "王五"})
+	api.expect(200, "POST", path+"/records", guest, map[string]any{"memberId": li, "type": "income", "amount": 100})
+
+	// 转让不改变历史记录的归属：张三的礼金仍记在张三名下，修改后也一样
+	resp = api.expect(200, "GET", path, guest, nil)
+	if r := findBy(t, list(resp, "records"), "id", gift); str(r, "memberId") != zhang || str(r, "type") != "income" {
+		t.Fatalf("gift after transfer: %v", r)
+	}
+	resp = api.expect(200, "PATCH", path+"/records/"+gift, guest, map[string]any{"amount": 300})
+	if str(resp, "record", "memberId") != zhang || str(resp, "record", "type") != "income" || num(resp, "record", "amount") != 300 {
+		t.Fatalf("edit gift after transfer: %v", resp)
+	}
+	resp = api.expect(200, "GET", path+"/summary", guest, nil)
+	byMember := list(resp, "byMember")
+	if m := findBy(t, byMember, "memberId", zhang); num(m, "net") != 300 || len(byMember) != 2 {
+		t.Fatalf("summary by member after transfer: %v", byMember)
+	}
+
+	api.expect(200, "POST", path+"/end", guest, nil)
+	api.expect(200, "DELETE", path, guest, nil)
+}
@@ -0,0 +1,230 @@
+package handlers_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/cloudwego/hertz/pkg/app/client"
+	"github.com/cloudwego/hertz/pkg/app/server"
+	"github.com/cloudwego/hertz/pkg/network/standard"
+	"github.com/cloudwego/hertz/pkg/protocol"
+	"github.com/hertz-contrib/websocket"
+
+	"scorehub/internal/realtime"
+)
+
+// serve starts a real HTTP server sharing the test store and hub, for WebSocket tests.
+func (a *testAPI) serve() string {
+	a.t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		a.t.Fatal(err)
+	}
+	addr := l.Addr().String()
+	_ = l.Close()
+
+	h := server.New(server.WithHostPorts(addr), server.WithTransport(standard.NewTransporter))
+	h.NoHijackConnPool = true
+	a.register(h)
+	go h.Spin()
+	a.t.Cleanup(func() { _ = h.Shutdown(context.Background()) })
+
+	for i := 0; i < 50; i++ {
+		if c, err := net.Dial("tcp", addr); err == nil {
+			_ = c.Close()
+			return addr
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	a.t.Fatalf("server on %s did not start", addr)
+	return ""
+}
+
+func dialWS(t *testing.T, url string) *websocket.Conn {
+	t.Helper()
+	c, err := client.NewClient(client.WithDialer(standard.NewDialer()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req, resp := protocol.AcquireRequest(), protocol.AcquireResponse()
+	req.SetRequestURI(url)
+	req.SetMethod("GET")
+	u := &websocket.ClientUpgrader{}
+	u.PrepareRequest(req)
+	if err := c.Do(context.Background(), req, resp); err != nil {
+		t.Fatal(err)
+	}
+	conn, err := u.UpgradeResponse(req, resp)
+	if err != nil {
+		t.Fatalf("upgrade %s: %v (status %d)", url, err, resp.StatusCode())
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	return conn
+}
+
+type wsEvent struct {
+	Type string         `json:"type"`
+	Seq  int64          `json:"seq"`
+	Data map[string]any `json:"data"`
+}
+
+func readEvent(t *testing.T, conn *websocket.Conn) wsEvent {
+	t.Helper()
+	_ = conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	_, raw, err := conn.ReadMessage()
+	if err != nil {
+		t.Fatalf("read event: %v", err)
+	}
+	var ev wsEvent
+	if err := json.Unmarshal(raw, &ev); err != nil {
+		t.Fatalf("decode %s: %v", raw, err)
+	}
+	return ev
+}
+
+func TestScorebookWSResume(t *testing.T) {
+	api := newTestAPI(t)
+	addr := api.serve()
+	alice := api.login("alice", "Alice")
+	bob := api.login("bob", "Bob")
+
+	resp := api.expect(200, "POST", "/api/v1/scorebooks", alice, map[string]any{"name": "test"})
+	id := str(resp, "scorebook", "id")
+	aliceM := str(resp, "me", "id")
+	api.expect(200, "POST", "/api/v1/scorebooks/"+id+"/join", bob, map[string]any{"inviteCode": str(resp, "scorebook", "inviteCode")})
+
+	wsURL := func(token, query string) string {
+		return fmt.Sprintf("http://%s/ws/scorebooks/%s?token=%s%s", addr, id, token, query)
+	}
+
+	// since=0：先补发创建、加入事件，再推送实时事件
+	conn := dialWS(t, wsURL(alice, "&since=0"))
+	for i, want := range []string{"scorebook.created", "member.joined"} {
+		if ev := readEvent(t, conn); ev.Type != want || ev.Seq != int64(i+1) {
+			t.Fatalf("replayed event %d = %+v", i, ev)
+		}
+	}
+	api.expect(200, "POST", "/api/v1/scorebooks/"+id+"/records", bob, map[string]any{"toMemberId": aliceM, "delta": 5})
+	if ev := readEvent(t, conn); ev.Type != "record.created" || ev.Seq != 3 {
+		t.Fatalf("live event = %+v", ev)
+	}
+
+	resp = api.expect(200, "GET", "/api/v1/scorebooks/"+id+"/online", bob, nil)
+	if num(resp, "connections") != 1 {
+		t.Fatalf("online: %v", resp)
+	}
+	api.expectError(403, "forbidden", "GET", "/api/v1/scorebooks/"+id+"/online", api.login("carol", "Carol"), nil)
+
+	resumed := dialWS(t, wsURL(bob, "&since=2"))
+	if ev := readEvent(t, resumed); ev.Type != "record.created" || ev.Seq != 3 {
+		t.Fatalf("resumed event = %+v", ev)
+	}
+
+	ahead := dialWS(t, wsURL(bob, "&since=99"))
+	if ev := readEvent(t, ahead); ev.Type != "resync.required" || ev.Data["seq"] != float64(3) {
+		t.Fatalf("resync event = %+v", ev)
+	}
+}
+
+func TestScorebookWSRemovedMemberDisconnected(t *testing.T) {
+	api := newTestAPI(t)
+	addr := api.serve()
+	alice := api.login("alice", "Alice")
+	bob := api.login("bob", "Bob")
+
+	resp := api.expect(200, "POST", "/api/v1/scorebooks", alice, map[string]any{"name": "test"})
+	id := str(resp, "scorebook", "id")
+	resp = api.expect(200, "POST", "/api/v1/scorebooks/"+id+"/join", bob, map[string]any{"inviteCode": str(resp, "scorebook", "inviteCode")})
+	bobM := str(resp, "member", "id")
+
+	conn := dialWS(t, fmt.Sprintf("http://%s/ws/scorebooks/%s?token=%s", addr, id, bob))
+	resp = api.expect(200, "GET", "/api/v1/scorebooks/"+id+"/online", alice, nil)
+	if num(resp, "connections") != 1 {
+		t.Fatalf("online: %v", resp)
+	}
+
+	api.expect(200, "DELETE", "/api/v1/scorebooks/"+id+"/members/"+bobM, alice, nil)
+
+	// 被移出的成员连接随即以 4003 关闭
+	_ = conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	for {
+		_, _, err := conn.ReadMessage()
+		if err == nil {
+			continue
+		}
+		if !websocket.IsCloseError(err, realtime.CloseRemoved) {
+			t.Fatalf("read after remove: %v", err)
+		}
+		break
+	}
+	resp = api.expect(200, "GET", "/api/v1/scorebooks/"+id+"/online", alice, nil)
+	if num(resp, "connections") != 0 {
+		t.Fatalf("online after remove: %v", resp)
+	}
+}
+
+func TestScorebookWSJoinRequested(t *testing.T) {
+	api := newTestAPI(t)
+	addr := api.serve()
+	alice := api.login("alice", "Alice")
+	bob := api.login("bob", "Bob")
+	carol := api.login("carol", "Carol")
+
+	resp := api.expect(200, "POST", "/api/v1/scorebooks", alice, map[string]any{"name": "test"})
+	id := str(resp, "scorebook", "id")
+	code := str(resp, "scorebook", "inviteCode")
+	api.expect(200, "POST", "/api/v1/scorebooks/"+id+"/join", carol, map[string]any{"inviteCode": code})
+	api.expect(200, "PATCH", "/api/v1/scorebooks/"+id+"/invite", alice, map[string]any{"joinApproval": true})
+
+	conn := dialWS(t, fmt.Sprintf("http://%s/ws/scorebooks/%s?token=%s", addr, id, alice))
+	carolConn := dialWS(t, fmt.Sprintf("http://%s/ws/scorebooks/%s?token=%s", addr, id, carol))
+	resp = api.expect(202, "POST", "/api/v1/scorebooks/"+id+"/join", bob, map[string]any{"inviteCode": code})
+	reqID := str(resp, "joinRequest", "id")
+
+	// 申请只推送给掌柜与管理员，不分配序号
+	ev := readEvent(t, conn)
+	if ev.Type != "member.join_requested" || str(ev.Data, "joinRequest", "id") != reqID || ev.Seq != 0 {
+		t.Fatalf("join requested event: %+v", ev)
+	}
+
+	api.expect(200, "POST", "/api/v1/scorebooks/"+id+"/join_requests/"+reqID+"/approve", alice, nil)
+	if ev := readEvent(t, conn); ev.Type != "member.joined" || str(ev.Data, "member", "nickname") != "Bob" {
+		t.Fatalf("member joined event: %+v", ev)
+	}
+	if ev := readEvent(t, conn); ev.Type != "join_request.resolved" || str(ev.Data, "joinRequest", "status") != "approved" {
+		t.Fatalf("join request resolved event: %+v", ev)
+	}
+
+	// 普通成员只看到 member.joined
+	if ev := readEvent(t, carolConn); ev.Type != "member.joined" {
+		t.Fatalf("member saw join request: %+v", ev)
+	}
+	_ = carolConn.SetReadDeadline(time.Now().Add(200 * time.Millisecond))
+	if _, raw, err := carolConn.ReadMessage(); err == nil {
+		t.Fatalf("member received %s", raw)
+	}
+
+	// 也不会出现在补发的事件里
+	resumed := dialWS(t, fmt.Sprintf("http://%s/ws/scorebooks/%s?token=%s&since=0", addr, id, carol))
+	var replayed []string
+	for {
+		_ = resumed.SetReadDeadline(time.Now().Add(200 * time.Millisecond))
+		_, raw, err := resumed.ReadMessage()
+		if err != nil {
+			break
+		}
+		var ev wsEvent
+		_ = json.Unmarshal(raw, &ev)
+		replayed = append(replayed, ev.Type)
+		if ev.Type == "member.join_requested" || ev.Type == "join_request.resolved" {
+			t.Fatalf("replayed %s: %s", ev.Type, raw)
+		}
+	}
+	if len(replayed) == 0 || replayed[len(replayed)-1] != "member.joined" {
+		t.Fatalf("replayed events: %v", replayed)
+	}
+}
@@ -2,6 +2,8 @@ package middleware
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"strings"
 
 	"github.com/cloudwego/hertz/pkg/app"
@@ -11,10 +13,39 @@ import (
 	"scorehub/internal/store"
 )
 
-const ctxUserIDKey = "scorehub.userID"
+const (
+	ctxUserIDKey    = "scorehub.userID"
+	ctxSessionIDKey = "scorehub.sessionID"
+)
+
+var (
+	ErrInvalidToken   = errors.New("invalid token")
+	ErrSessionRevoked = errors.New("session revoked")
+)
+
+// Authenticate 校验访问令牌并确认其会话未被注销。签名、过期等令牌问题返回 ErrInvalidToken，
+// 会话已注销返回 ErrSessionRevoked；查询会话失败等其他错误原样返回，调用方应按服务端错误处理。
+func Authenticate(ctx context.Context, cfg appconfig.Config, st store.UserRepo, token string) (auth.Claims, error) {
+	claims, err := auth.ParseToken(cfg.VerifyKeys(), token)
+	if err != nil {
+		return auth.Claims{}, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+	active, err := st.SessionActive(ctx, claims.UserID, claims.SessionID)
+	if err != nil {
+		return auth.Claims{}, err
+	}
+	if !active {
+		return auth.Claims{}, ErrSessionRevoked
+	}
+	return claims, nil
+}
+
+// IsAuthError 判断 Authenticate 的错误是否是令牌本身无效（应返回 401）。
+func IsAuthError(err error) bool {
+	return errors.Is(err, ErrInvalidToken) || errors.Is(err, ErrSessionRevoked)
+}
 
-func AuthRequired(cfg appconfig.Config, st *store.Store) app.HandlerFunc {
-	secret := []byte(cfg.TokenSecret)
+func AuthRequired(cfg appconfig.Config, st store.UserRepo) app.HandlerFunc {
 	return func(ctx context.Context, c *app.RequestContext) {
 		token := extractBearerToken(string(c.GetHeader("Authorization")))
 		if token == "" && cfg.DevAuth {
@@ -35,18 +66,62 @@ func AuthRequired(cfg appconfig.Config, st *store.Store) app.HandlerFunc {
 			return
 		}
 
-		uid, err := auth.ParseToken(secret, token)
+		claims, err := Authenticate(ctx, cfg, st, token)
+		if err != nil && !IsAuthError(err) {
+			abortInternal(c, err)
+			return
+		}
 		if err != nil {
+			msg := "invalid token"
+			if errors.Is(err, ErrSessionRevoked) {
+				msg = "session revoked"
+			}
 			c.AbortWithStatusJSON(401, map[string]any{
-				"error": map[string]any{"code": "unauthorized", "message": "invalid token"},
+				"error": map[string]any{"code": "unauthorized", "message": msg},
 			})
 			return
 		}
-		c.Set(ctxUserIDKey, uid)
+		c.Set(ctxUserIDKey, claims.UserID)
+		c.Set(ctxSessionIDKey, claims.SessionID)
 		c.Next(ctx)
 	}
 }
 
+// AuthOptional 在带有有效令牌时记录当前用户，否则按未登录继续处理，用于公开但可感知登录态的接口。
+func AuthOptional(cfg appconfig.Config, st store.UserRepo) app.HandlerFunc {
+	return func(ctx context.Context, c *app.RequestContext) {
+		token := extractBearerToken(string(c.GetHeader("Authorization")))
+		if token == "" && cfg.DevAuth {
+			if openid := strings.TrimSpace(string(c.GetHeader("X-Dev-OpenID"))); openid != "" {
+				if u, err := st.UpsertUserByOpenID(ctx, openid, "", ""); err == nil {
+					c.Set(ctxUserIDKey, u.ID)
+				}
+			}
+			c.Next(ctx)
+			return
+		}
+		if token != "" {
+			claims, err := Authenticate(ctx, cfg, st, token)
+			switch {
+			case err == nil:
+				c.Set(ctxUserIDKey, claims.UserID)
+				c.Set(ctxSessionIDKey, claims.SessionID)
+			case !IsAuthError(err):
+				abortInternal(c, err)
+				return
+			}
+		}
+		c.Next(ctx)
+	}
+}
+
+func abortInternal(c *app.RequestContext, err error) {
+	_ = c.Error(err)
+	c.AbortWithStatusJSON(500, map[string]any{
+		"error": map[string]any{"code": "internal", "message": "auth check failed"},
+	})
+}
+
 func UserID(c *app.RequestContext) (int64, bool) {
 	v, ok := c.Get(ctxUserIDKey)
 	if !ok {
@@ -56,6 +131,16 @@ func UserID(c *app.RequestContext) (int64, bool) {
 	return id, ok
 }
 
+// SessionID 返回当前请求令牌所属的会话；X-Dev-OpenID 开发登录没有会话。
+func SessionID(c *app.RequestContext) (string, bool) {
+	v, ok := c.Get(ctxSessionIDKey)
+	if !ok {
+		return "", false
+	}
+	id, ok := v.(string)
+	return id, ok
+}
+
 func extractBearerToken(authHeader string) string {
 	authHeader = strings.TrimSpace(authHeader)
 	if authHeader == "" {
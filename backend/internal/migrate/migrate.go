@@ -0,0 +1,309 @@
+package migrate
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// lockKey is the Postgres advisory lock key held while migrating, so concurrent
+// replicas starting with --migrate don't race each other.
+const lockKey int64 = 0x73636f7265687562 // "scorehub"
+
+var ErrSchemaBehind = errors.New("database schema is behind")
+
+type Migration struct {
+	Version  int64
+	Name     string
+	UpSQL    string
+	DownSQL  string
+	Checksum string
+}
+
+type Status struct {
+	Migration
+	Applied          bool
+	AppliedAt        *time.Time
+	AppliedChecksum  string
+	ChecksumMismatch bool
+}
+
+type Runner struct {
+	pool       *pgxpool.Pool
+	migrations []Migration
+}
+
+// Load reads migrations from fsys: NNNN_name.sql files in the root are the up
+// scripts and down/NNNN_name.sql the matching (optional) rollback scripts.
+func Load(fsys fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, err
+	}
+
+	var out []Migration
+	seen := map[int64]string{}
+	for _, e := range entries {
+		if e.IsDir() || path.Ext(e.Name()) != ".sql" {
+			continue
+		}
+		version, name, err := parseFilename(e.Name())
+		if err != nil {
+			return nil, err
+		}
+		if prev, ok := seen[version]; ok {
+			return nil, fmt.Errorf("duplicate migration version %d: %s, %s", version, prev, e.Name())
+		}
+		seen[version] = e.Name()
+
+		up, err := fs.ReadFile(fsys, e.Name())
+		if err != nil {
+			return nil, err
+		}
+		down, err := fs.ReadFile(fsys, path.Join("down", e.Name()))
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return nil, err
+		}
+		sum := sha256.Sum256(up)
+		out = append(out, Migration{
+			Version:  version,
+			Name:     name,
+			UpSQL:    string(up),
+			DownSQL:  string(down),
+			Checksum: hex.EncodeToString(sum[:]),
+		})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Version < out[j].Version })
+	return out, nil
+}
+
+func parseFilename(filename string) (int64, string, error) {
+	base := strings.TrimSuffix(filename, ".sql")
+	v, name, ok := strings.Cut(base, "_")
+	if !ok || name == "" {
+		return 0, "", fmt.Errorf("invalid migration filename %q (want NNNN_name.sql)", filename)
+	}
+	version, err := strconv.ParseInt(v, 10, 64)
+	if err != nil || version <= 0 {
+		return 0, "", fmt.Errorf("invalid migration version in %q", filename)
+	}
+	return version, name, nil
+}
+
+func New(pool *pgxpool.Pool, fsys fs.FS) (*Runner, error) {
+	migrations, err := Load(fsys)
+	if err != nil {
+		return nil, err
+	}
+	return &Runner{pool: pool, migrations: migrations}, nil
+}
+
+// Up applies all pending migrations in order, each in its own transaction, and
+// returns the ones applied by this call.
+func (r *Runner) Up(ctx context.Context) ([]Migration, error) {
+	var applied []Migration
+	err := r.withLock(ctx, func(conn *pgxpool.Conn) error {
+		if err := ensureTable(ctx, conn); err != nil {
+			return err
+		}
+		done, err := appliedVersions(ctx, conn)
+		if err != nil {
+			return err
+		}
+		for _, m := range r.migrations {
+			if _, ok := done[m.Version]; ok {
+				continue
+			}
+			if err := pgx.BeginFunc(ctx, conn, func(tx pgx.Tx) error {
+				if _, err := tx.Exec(ctx, m.UpSQL); err != nil {
+					return err
+				}
+				_, err := tx.Exec(ctx, `
+INSERT INTO schema_migrations (version, name, checksum, applied_at)
+VALUES ($1, $2, $3, NOW())
+`, m.Version, m.Name, m.Checksum)
+				return err
+			}); err != nil {
+				return fmt.Errorf("apply %04d_%s: %w", m.Version, m.Name, err)
+			}
+			applied = append(applied, m)
+		}
+		return nil
+	})
+	return applied, err
+}
+
+// Down rolls back the last steps applied migrations (newest first) and returns
+// the ones rolled back by this call.
+func (r *Runner) Down(ctx context.Context, steps int) ([]Migration, error) {
+	if steps <= 0 {
+		return nil, nil
+	}
+	byVersion := make(map[int64]Migration, len(r.migrations))
+	for _, m := range r.migrations {
+		byVersion[m.Version] = m
+	}
+
+	var reverted []Migration
+	err := r.withLock(ctx, func(conn *pgxpool.Conn) error {
+		if err := ensureTable(ctx, conn); err != nil {
+			return err
+		}
+		rows, err := conn.Query(ctx, `SELECT version FROM schema_migrations ORDER BY version DESC LIMIT $1`, steps)
+		if err != nil {
+			return err
+		}
+		versions, err := pgx.CollectRows(rows, pgx.RowTo[int64])
+		if err != nil {
+			return err
+		}
+		for _, v := range versions {
+			m, ok := byVersion[v]
+			if !ok {
+				return fmt.Errorf("migration %04d is applied but unknown to this binary", v)
+			}
+			if strings.TrimSpace(m.DownSQL) == "" {
+				return fmt.Errorf("migration %04d_%s has no down script", m.Version, m.Name)
+			}
+			if err := pgx.BeginFunc(ctx, conn, func(tx pgx.Tx) error {
+				if _, err := tx.Exec(ctx, m.DownSQL); err != nil {
+					return err
+				}
+				_, err := tx.Exec(ctx, `DELETE FROM schema_migrations WHERE version = $1`, m.Version)
+				return err
+			}); err != nil {
+				return fmt.Errorf("revert %04d_%s: %w", m.Version, m.Name, err)
+			}
+			reverted = append(reverted, m)
+		}
+		return nil
+	})
+	return reverted, err
+}
+
+// Status reports every known migration together with its applied state.
+func (r *Runner) Status(ctx context.Context) ([]Status, error) {
+	type appliedRow struct {
+		checksum  string
+		appliedAt time.Time
+	}
+	applied := map[int64]appliedRow{}
+
+	var exists bool
+	if err := r.pool.QueryRow(ctx, `SELECT to_regclass('schema_migrations') IS NOT NULL`).Scan(&exists); err != nil {
+		return nil, err
+	}
+	if exists {
+		rows, err := r.pool.Query(ctx, `SELECT version, checksum, applied_at FROM schema_migrations`)
+		if err != nil {
+			return nil, err
+		}
+		defer rows.Close()
+		for rows.Next() {
+			var v int64
+			var row appliedRow
+			if err := rows.Scan(&v, &row.checksum, &row.appliedAt); err != nil {
+				return nil, err
+			}
+			applied[v] = row
+		}
+		if err := rows.Err(); err != nil {
+			return nil, err
+		}
+	}
+
+	out := make([]Status, 0, len(r.migrations))
+	for _, m := range r.migrations {
+		st := Status{Migration: m}
+		if row, ok := applied[m.Version]; ok {
+			at := row.appliedAt
+			st.Applied = true
+			st.AppliedAt = &at
+			st.AppliedChecksum = row.checksum
+			st.ChecksumMismatch = row.checksum != m.Checksum
+		}
+		out = append(out, st)
+	}
+	return out, nil
+}
+
+// Check returns ErrSchemaBehind (wrapped with the pending versions) when the
+// database hasn't applied every embedded migration, or an error when an applied
+// migration's checksum no longer matches the embedded file.
+func (r *Runner) Check(ctx context.Context) error {
+	statuses, err := r.Status(ctx)
+	if err != nil {
+		return err
+	}
+	var pending []string
+	for _, st := range statuses {
+		name := fmt.Sprintf("%04d_%s", st.Version, st.Name)
+		if st.ChecksumMismatch {
+			return fmt.Errorf("migration %s was modified after being applied", name)
+		}
+		if !st.Applied {
+			pending = append(pending, name)
+		}
+	}
+	if len(pending) > 0 {
+		return fmt.Errorf("%w: pending %s", ErrSchemaBehind, strings.Join(pending, ", "))
+	}
+	return nil
+}
+
+func (r *Runner) withLock(ctx context.Context, fn func(conn *pgxpool.Conn) error) error {
+	conn, err := r.pool.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Release()
+
+	if _, err := conn.Exec(ctx, `SELECT pg_advisory_lock($1)`, lockKey); err != nil {
+		return fmt.Errorf("acquire migration lock: %w", err)
+	}
+	defer func() {
+		// 使用独立 context，避免调用方 ctx 已取消时锁无法释放
+		_, _ = conn.Exec(context.Background(), `SELECT pg_advisory_unlock($1)`, lockKey)
+	}()
+
+	return fn(conn)
+}
+
+func ensureTable(ctx context.Context, conn *pgxpool.Conn) error {
+	_, err := conn.Exec(ctx, `
+CREATE TABLE IF NOT EXISTS schema_migrations (
+  version    BIGINT PRIMARY KEY,
+  name       TEXT NOT NULL,
+  checksum   TEXT NOT NULL,
+  applied_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+)
+`)
+	return err
+}
+
+func appliedVersions(ctx context.Context, conn *pgxpool.Conn) (map[int64]struct{}, error) {
+	rows, err := conn.Query(ctx, `SELECT version FROM schema_migrations`)
+	if err != nil {
+		return nil, err
+	}
+	versions, err := pgx.CollectRows(rows, pgx.RowTo[int64])
+	if err != nil {
+		return nil, err
+	}
+	out := make(map[int64]struct{}, len(versions))
+	for _, v := range versions {
+		out[v] = struct{}{}
+	}
+	return out, nil
+}
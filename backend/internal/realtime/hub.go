@@ -1,64 +1,485 @@
 package realtime
 
 import (
+	"context"
 	"encoding/json"
+	"log"
+	"slices"
 	"sync"
+	"time"
 
 	"github.com/hertz-contrib/websocket"
+
+	"scorehub/internal/store"
+)
+
+// MaxReplay 是断线重连时最多补发的事件数，缺口更大时要求客户端全量刷新。
+const MaxReplay = 500
+
+const (
+	// 单次写超时
+	writeWait = 10 * time.Second
+	// 超过该时长没有收到任何消息（含 pong）视为连接已断开
+	pongWait = 60 * time.Second
+	// ping 间隔，须小于 pongWait
+	pingPeriod = pongWait * 9 / 10
+	// 客户端只发心跳，不需要大消息
+	maxMessageSize = 4096
+	// 每个连接的发送队列长度，能容纳一次完整补发；队列满说明客户端太慢，直接断开
+	sendQueueSize = MaxReplay + 128
 )
 
+// CloseRemoved 是成员被移出房间时发给客户端的 WebSocket 关闭码，客户端收到后不应重连。
+const CloseRemoved = 4003
+
+// disconnectType 是节点之间转发断开指令的内部消息类型，不会推送给客户端。
+const disconnectType = "hub.disconnect"
+
+// notifyType 是节点之间转发定向推送的内部消息类型，data.event 才是推送给客户端的内容。
+const notifyType = "hub.notify"
+
 type Hub struct {
-	mu    sync.RWMutex
-	rooms map[string]map[*websocket.Conn]struct{}
+	mu      sync.RWMutex
+	rooms   map[string]map[*websocket.Conn]*client
+	backend Backend
+	events  store.EventLog
+
+	// 同一房间的广播、定向推送与断开按提交顺序在该房间的后台协程中执行：
+	// 写事件日志可能较慢，不能阻塞发起广播的请求，但序号与推送顺序必须一致
+	qmu     sync.Mutex
+	queues  map[string][]func()
+	drained *sync.Cond
+}
+
+// Backend 把广播扩散到其他 API 实例。未挂载 Backend 时 Hub 只向本进程内的连接推送。
+type Backend interface {
+	// Publish 发送一条已在本地推送过的广播，实现方需自行过滤掉本节点发出的消息。
+	Publish(room string, raw []byte)
+	// Run 接收其他节点的广播并交给 deliver，直到 ctx 结束。
+	Run(ctx context.Context, deliver func(room string, raw []byte))
+}
+
+type message struct {
+	seq int64
+	raw []byte
+}
+
+// client 是房间内的一个连接。所有写操作由 writePump 串行完成；
+// 补发历史事件期间 paused 为 true，实时事件先暂存在 pending。
+type client struct {
+	conn      *websocket.Conn
+	userID    int64
+	out       chan []byte
+	done      chan struct{}
+	closeOnce sync.Once
+
+	mu       sync.Mutex
+	paused   bool
+	pending  []message
+	replayed int64
 }
 
 func NewHub() *Hub {
-	return &Hub{
-		rooms: make(map[string]map[*websocket.Conn]struct{}),
+	h := &Hub{
+		rooms:  make(map[string]map[*websocket.Conn]*client),
+		queues: make(map[string][]func()),
 	}
+	h.drained = sync.NewCond(&h.qmu)
+	return h
 }
 
-func (h *Hub) Join(room string, conn *websocket.Conn) {
+// Attach 挂载跨实例广播后端并在后台运行。
+func (h *Hub) Attach(ctx context.Context, b Backend) {
+	h.mu.Lock()
+	h.backend = b
+	h.mu.Unlock()
+	go b.Run(ctx, h.deliverRemote)
+}
+
+// UseEventLog 为每个广播分配持久化的序号（消息中的 seq 字段），支持断线补发。
+func (h *Hub) UseEventLog(l store.EventLog) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.events = l
+}
+
+// Serve 把用户 userID 的连接加入房间并阻塞到连接断开。since >= 0 时先补发该序号之后的事件，
+// 无法补发（事件已清理、缺口过大或未启用事件日志）时发送 resync.required，
+// 客户端应重新拉取完整数据，之后从 data.seq 继续；since < 0 时只推送实时事件。
+func (h *Hub) Serve(ctx context.Context, room string, userID int64, conn *websocket.Conn, since int64) {
+	c := h.join(room, conn, userID, since >= 0)
+	defer h.leave(room, c)
+	go c.writePump()
+
+	if since >= 0 && !c.resume(h.replay(ctx, room, since)) {
+		return
+	}
+
+	conn.SetReadLimit(maxMessageSize)
+	_ = conn.SetReadDeadline(time.Now().Add(pongWait))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(pongWait))
+	})
+	for {
+		if _, _, err := conn.ReadMessage(); err != nil {
+			return
+		}
+		_ = conn.SetReadDeadline(time.Now().Add(pongWait))
+	}
+}
+
+// Connections 返回当前实例上某个房间的连接数。
+func (h *Hub) Connections(room string) int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return len(h.rooms[room])
+}
+
+// Disconnect 断开某用户在房间内的所有连接（包括其他实例上的），用于移出成员。
+// 断开排在此前提交的广播之后，被移出的用户仍能先收到这些事件。
+func (h *Hub) Disconnect(room string, userID int64) {
+	h.enqueue(room, func() {
+		h.disconnectLocal(room, userID)
+
+		h.mu.RLock()
+		b := h.backend
+		h.mu.RUnlock()
+		if b != nil {
+			raw, _ := json.Marshal(map[string]any{
+				"type": disconnectType,
+				"data": map[string]any{"userId": userID},
+			})
+			b.Publish(room, raw)
+		}
+	})
+}
+
+func (h *Hub) disconnectLocal(room string, userID int64) {
+	h.mu.RLock()
+	var targets []*client
+	for _, c := range h.rooms[room] {
+		if c.userID == userID {
+			targets = append(targets, c)
+		}
+	}
+	h.mu.RUnlock()
+
+	msg := websocket.FormatCloseMessage(CloseRemoved, "removed from room")
+	for _, c := range targets {
+		_ = c.conn.WriteControl(websocket.CloseMessage, msg, time.Now().Add(writeWait))
+		h.leave(room, c)
+	}
+}
+
+// replay 查询 since 之后的事件，返回待补发的消息及其覆盖到的最新序号。
+func (h *Hub) replay(ctx context.Context, room string, since int64) ([]message, int64) {
+	h.mu.RLock()
+	l := h.events
+	h.mu.RUnlock()
+
+	var (
+		events []store.ScorebookEvent
+		latest int64
+		ok     bool
+	)
+	if l != nil {
+		qctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+		var err error
+		events, latest, ok, err = l.ListScorebookEventsSince(qctx, room, since, MaxReplay)
+		cancel()
+		if err != nil {
+			log.Printf("realtime: replay room %s since %d: %v", room, since, err)
+			ok = false
+		}
+	}
+
+	if !ok {
+		raw, _ := json.Marshal(map[string]any{
+			"type": "resync.required",
+			"data": map[string]any{"seq": latest},
+		})
+		return []message{{raw: raw}}, latest
+	}
+	out := make([]message, 0, len(events))
+	for _, e := range events {
+		out = append(out, message{seq: e.Seq, raw: withSeq(e.Payload, e.Seq)})
+	}
+	return out, latest
+}
+
+func (h *Hub) join(room string, conn *websocket.Conn, userID int64, paused bool) *client {
+	c := &client{
+		conn:   conn,
+		userID: userID,
+		out:    make(chan []byte, sendQueueSize),
+		done:   make(chan struct{}),
+		paused: paused,
+	}
 	h.mu.Lock()
 	defer h.mu.Unlock()
 	if h.rooms[room] == nil {
-		h.rooms[room] = make(map[*websocket.Conn]struct{})
+		h.rooms[room] = make(map[*websocket.Conn]*client)
 	}
-	h.rooms[room][conn] = struct{}{}
+	h.rooms[room][conn] = c
+	return c
 }
 
-func (h *Hub) Leave(room string, conn *websocket.Conn) {
+func (h *Hub) leave(room string, c *client) {
+	c.close()
 	h.mu.Lock()
 	defer h.mu.Unlock()
 	conns := h.rooms[room]
-	if conns == nil {
+	if conns == nil || conns[c.conn] != c {
 		return
 	}
-	delete(conns, conn)
+	delete(conns, c.conn)
 	if len(conns) == 0 {
 		delete(h.rooms, room)
 	}
 }
 
+// Broadcast 向房间内所有连接（包括其他实例上的）推送 v。写事件日志与推送在房间的后台协程中
+// 按提交顺序完成，调用方不会等待。
 func (h *Hub) Broadcast(room string, v any) {
 	raw, err := json.Marshal(v)
 	if err != nil {
 		return
 	}
+	h.enqueue(room, func() { h.broadcast(room, raw) })
+}
 
+// broadcast 为消息分配序号并推送，只在房间的后台协程中调用。
+func (h *Hub) broadcast(room string, raw []byte) {
+	h.mu.RLock()
+	l := h.events
+	b := h.backend
+	h.mu.RUnlock()
+
+	msg := message{raw: raw}
+	if l != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		seq, err := l.AppendScorebookEvent(ctx, room, raw)
+		cancel()
+		if err != nil {
+			log.Printf("realtime: append event for room %s: %v", room, err)
+		} else {
+			msg = message{seq: seq, raw: withSeq(raw, seq)}
+		}
+	}
+
+	h.deliver(room, msg, nil)
+	if b != nil {
+		b.Publish(room, msg.raw)
+	}
+}
+
+// SendTo 只向房间内属于 userIDs 的连接（包括其他实例上的）推送 v，用于不应让全体成员看到的通知。
+// 定向推送不分配序号、不写入事件日志，断线重连时不会补发。
+func (h *Hub) SendTo(room string, userIDs []int64, v any) {
+	if len(userIDs) == 0 {
+		return
+	}
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return
+	}
+
+	h.enqueue(room, func() {
+		h.deliver(room, message{raw: raw}, userIDs)
+
+		h.mu.RLock()
+		b := h.backend
+		h.mu.RUnlock()
+		if b != nil {
+			env, _ := json.Marshal(map[string]any{
+				"type": notifyType,
+				"data": map[string]any{"userIds": userIDs, "event": json.RawMessage(raw)},
+			})
+			b.Publish(room, env)
+		}
+	})
+}
+
+// Wait 阻塞到已提交的广播、定向推送与断开全部执行完，用于退出前把事件写完。
+func (h *Hub) Wait() {
+	h.qmu.Lock()
+	defer h.qmu.Unlock()
+	for len(h.queues) > 0 {
+		h.drained.Wait()
+	}
+}
+
+// enqueue 把任务排到房间队列末尾；房间没有后台协程时启动一个，队列清空后协程退出。
+func (h *Hub) enqueue(room string, task func()) {
+	h.qmu.Lock()
+	defer h.qmu.Unlock()
+	q, running := h.queues[room]
+	h.queues[room] = append(q, task)
+	if !running {
+		go h.drain(room)
+	}
+}
+
+func (h *Hub) drain(room string) {
+	for {
+		h.qmu.Lock()
+		q := h.queues[room]
+		if len(q) == 0 {
+			delete(h.queues, room)
+			h.drained.Broadcast()
+			h.qmu.Unlock()
+			return
+		}
+		task := q[0]
+		q[0] = nil
+		h.queues[room] = q[1:]
+		h.qmu.Unlock()
+
+		task()
+	}
+}
+
+// deliverRemote 推送其他实例转发过来的广播或定向推送，或执行其转发的断开指令。
+func (h *Hub) deliverRemote(room string, raw []byte) {
+	var head struct {
+		Seq  int64  `json:"seq"`
+		Type string `json:"type"`
+		Data struct {
+			UserID  int64           `json:"userId"`
+			UserIDs []int64         `json:"userIds"`
+			Event   json.RawMessage `json:"event"`
+		} `json:"data"`
+	}
+	_ = json.Unmarshal(raw, &head)
+	switch head.Type {
+	case disconnectType:
+		h.disconnectLocal(room, head.Data.UserID)
+	case notifyType:
+		if len(head.Data.UserIDs) > 0 && len(head.Data.Event) > 0 {
+			h.deliver(room, message{raw: head.Data.Event}, head.Data.UserIDs)
+		}
+	default:
+		h.deliver(room, message{seq: head.Seq, raw: raw}, nil)
+	}
+}
+
+// deliver 把消息放入房间内连接的发送队列（to 不为 nil 时只发给这些用户），不会阻塞在慢连接上。
+func (h *Hub) deliver(room string, msg message, to []int64) {
 	h.mu.RLock()
 	conns := h.rooms[room]
-	var targets []*websocket.Conn
-	for c := range conns {
-		targets = append(targets, c)
+	targets := make([]*client, 0, len(conns))
+	for _, c := range conns {
+		if to == nil || slices.Contains(to, c.userID) {
+			targets = append(targets, c)
+		}
 	}
 	h.mu.RUnlock()
 
 	for _, c := range targets {
-		if err := c.WriteMessage(websocket.TextMessage, raw); err != nil {
-			_ = c.Close()
-			h.Leave(room, c)
+		if !c.send(msg) {
+			log.Printf("realtime: evicting slow connection in room %s", room)
+			h.leave(room, c)
+		}
+	}
+}
+
+// send 把消息放入发送队列；补发期间先暂存。返回 false 表示队列已满或连接已关闭。
+func (c *client) send(msg message) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.paused {
+		if len(c.pending) >= sendQueueSize {
+			return false
+		}
+		c.pending = append(c.pending, msg)
+		return true
+	}
+	return c.enqueue(msg)
+}
+
+// resume 放入补发的消息，再放入补发期间暂存的、序号在 upTo 之后的实时事件。
+func (c *client) resume(replay []message, upTo int64) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, m := range replay {
+		if !c.enqueue(m) {
+			return false
+		}
+	}
+	c.replayed = upTo
+	for _, m := range c.pending {
+		if !c.enqueue(m) {
+			return false
+		}
+	}
+	c.pending = nil
+	c.paused = false
+	return true
+}
+
+func (c *client) enqueue(msg message) bool {
+	// 已在补发中送达的事件不再重复推送
+	if msg.seq > 0 && msg.seq <= c.replayed {
+		return true
+	}
+	select {
+	case <-c.done:
+		return false
+	default:
+	}
+	select {
+	case c.out <- msg.raw:
+		return true
+	default:
+		return false
+	}
+}
+
+// writePump 是连接唯一的写协程：发送队列中的消息并定期 ping。
+func (c *client) writePump() {
+	ticker := time.NewTicker(pingPeriod)
+	defer ticker.Stop()
+	defer c.close()
+
+	for {
+		select {
+		case <-c.done:
+			return
+		case raw := <-c.out:
+			_ = c.conn.SetWriteDeadline(time.Now().Add(writeWait))
+			if err := c.conn.WriteMessage(websocket.TextMessage, raw); err != nil {
+				return
+			}
+		case <-ticker.C:
+			_ = c.conn.SetWriteDeadline(time.Now().Add(writeWait))
+			if err := c.conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				return
+			}
 		}
 	}
 }
 
+// close 关闭连接；读循环随之退出并离开房间。
+func (c *client) close() {
+	c.closeOnce.Do(func() {
+		close(c.done)
+		// hijack 后的连接 Close 不会立即关闭底层连接（由 handler 返回后关闭），
+		// 设置已过期的读超时让 Serve 的读循环马上退出
+		_ = c.conn.SetReadDeadline(time.Now())
+		_ = c.conn.Close()
+	})
+}
+
+// withSeq 在事件 JSON 对象上加上 seq 字段。
+func withSeq(raw []byte, seq int64) []byte {
+	var obj map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &obj); err != nil {
+		return raw
+	}
+	obj["seq"], _ = json.Marshal(seq)
+	out, err := json.Marshal(obj)
+	if err != nil {
+		return raw
+	}
+	return out
+}
@@ -0,0 +1,52 @@
+package realtime_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"scorehub/internal/realtime"
+	"scorehub/internal/store/memstore"
+)
+
+func TestHubBroadcastAssignsSequence(t *testing.T) {
+	ctx := context.Background()
+	st := memstore.New()
+	user, _ := st.UpsertUserByOpenID(ctx, "alice", "Alice", "")
+	sb, _, err := st.CreateScorebook(ctx, user, "test", "", "scorebook", "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	hub := realtime.NewHub()
+	hub.UseEventLog(st)
+	for i := 0; i < 3; i++ {
+		hub.Broadcast(sb.ID, map[string]any{"type": "record.created", "data": map[string]any{"n": i}})
+	}
+	hub.Wait()
+
+	events, latest, ok, err := st.ListScorebookEventsSince(ctx, sb.ID, 1, realtime.MaxReplay)
+	if err != nil || !ok || latest != 3 || len(events) != 2 || events[0].Seq != 2 {
+		t.Fatalf("events since 1 = %v latest=%d ok=%v err=%v", events, latest, ok, err)
+	}
+	var msg struct {
+		Type string `json:"type"`
+		Data struct {
+			N int `json:"n"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(events[0].Payload, &msg); err != nil || msg.Type != "record.created" || msg.Data.N != 1 {
+		t.Fatalf("payload = %s", events[0].Payload)
+	}
+
+	// since 超出当前序号时无法补发
+	if _, _, ok, _ := st.ListScorebookEventsSince(ctx, sb.ID, 9, realtime.MaxReplay); ok {
+		t.Fatal("expected resync for since ahead of the log")
+	}
+	if events, _, ok, _ := st.ListScorebookEventsSince(ctx, sb.ID, 3, realtime.MaxReplay); !ok || len(events) != 0 {
+		t.Fatal("expected nothing to replay when up to date")
+	}
+	if _, _, ok, _ := st.ListScorebookEventsSince(ctx, sb.ID, 0, 2); ok {
+		t.Fatal("expected resync when the gap exceeds the limit")
+	}
+}
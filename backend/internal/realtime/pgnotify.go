@@ -0,0 +1,138 @@
+package realtime
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"log"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// PGChannel 是跨实例广播使用的 LISTEN/NOTIFY 通道名。
+const PGChannel = "scorehub_realtime"
+
+// NOTIFY 的 payload 上限为 8000 字节，超过的广播只在本地推送。
+const maxNotifyPayload = 7900
+
+const publishQueueSize = 256
+
+type envelope struct {
+	Node    string          `json:"n"`
+	Room    string          `json:"r"`
+	Payload json.RawMessage `json:"p"`
+}
+
+// PGBackend 通过 Postgres LISTEN/NOTIFY 在多个 API 实例之间转发广播。
+// 每个实例有随机的节点 ID，收到自己发出的通知时直接丢弃（本地已推送过）。
+// 监听连接断开时自动重连，期间 Hub 退化为仅本地推送。
+type PGBackend struct {
+	pool  *pgxpool.Pool
+	node  string
+	queue chan envelope
+}
+
+func NewPGBackend(pool *pgxpool.Pool) *PGBackend {
+	return &PGBackend{
+		pool:  pool,
+		node:  newNodeID(),
+		queue: make(chan envelope, publishQueueSize),
+	}
+}
+
+// Node 返回当前实例的节点 ID。
+func (b *PGBackend) Node() string { return b.node }
+
+func (b *PGBackend) Publish(room string, raw []byte) {
+	select {
+	case b.queue <- envelope{Node: b.node, Room: room, Payload: raw}:
+	default:
+		log.Printf("realtime: publish queue full, dropping broadcast for room %s", room)
+	}
+}
+
+func (b *PGBackend) Run(ctx context.Context, deliver func(room string, raw []byte)) {
+	go b.publishLoop(ctx)
+
+	backoff := time.Second
+	for {
+		err := b.listen(ctx, deliver)
+		if ctx.Err() != nil {
+			return
+		}
+		log.Printf("realtime: listen %s failed: %v (retry in %s)", PGChannel, err, backoff)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, 30*time.Second)
+	}
+}
+
+func (b *PGBackend) listen(ctx context.Context, deliver func(room string, raw []byte)) error {
+	conn, err := b.pool.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Release()
+
+	if _, err := conn.Exec(ctx, "LISTEN "+PGChannel); err != nil {
+		return err
+	}
+	log.Printf("realtime: listening on %s as node %s", PGChannel, b.node)
+
+	for {
+		n, err := conn.Conn().WaitForNotification(ctx)
+		if err != nil {
+			// 连接状态未知，关闭后由连接池丢弃
+			_ = conn.Conn().Close(context.Background())
+			return err
+		}
+		b.handle(n.Payload, deliver)
+	}
+}
+
+// handle 解析一条通知，跳过本节点发出的消息。
+func (b *PGBackend) handle(payload string, deliver func(room string, raw []byte)) {
+	var env envelope
+	if err := json.Unmarshal([]byte(payload), &env); err != nil || env.Room == "" {
+		return
+	}
+	if env.Node == b.node {
+		return
+	}
+	deliver(env.Room, env.Payload)
+}
+
+func (b *PGBackend) publishLoop(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case env := <-b.queue:
+			raw, err := json.Marshal(env)
+			if err != nil {
+				continue
+			}
+			if len(raw) > maxNotifyPayload {
+				log.Printf("realtime: broadcast for room %s too large (%d bytes), delivered locally only", env.Room, len(raw))
+				continue
+			}
+			pctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+			_, err = b.pool.Exec(pctx, "SELECT pg_notify($1, $2)", PGChannel, string(raw))
+			cancel()
+			if err != nil {
+				log.Printf("realtime: notify room %s: %v", env.Room, err)
+			}
+		}
+	}
+}
+
+func newNodeID() string {
+	var b [8]byte
+	_, _ = rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
@@ -0,0 +1,26 @@
+package realtime
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestPGBackendHandleSkipsOwnNode(t *testing.T) {
+	b := &PGBackend{node: "self"}
+	var got []string
+	deliver := func(room string, raw []byte) { got = append(got, room+":"+string(raw)) }
+
+	for _, env := range []envelope{
+		{Node: "self", Room: "a", Payload: json.RawMessage(`{"type":"x"}`)},
+		{Node: "other", Room: "b", Payload: json.RawMessage(`{"type":"y"}`)},
+		{Node: "other", Room: "", Payload: json.RawMessage(`{}`)},
+	} {
+		raw, _ := json.Marshal(env)
+		b.handle(string(raw), deliver)
+	}
+	b.handle("not json", deliver)
+
+	if len(got) != 1 || got[0] != `b:{"type":"y"}` {
+		t.Fatalf("delivered = %v", got)
+	}
+}
@@ -0,0 +1,70 @@
+package sheet
+
+import (
+	"encoding/csv"
+	"io"
+)
+
+// csvWriter writes every table as a section: a title line (omitted for an empty
+// Title), the header and the rows, with a blank line between sections. A UTF-8 BOM lets Excel detect the
+// encoding of Chinese text.
+type csvWriter struct {
+	w      *csv.Writer
+	tables int
+}
+
+func newCSVWriter(w io.Writer) *csvWriter {
+	return &csvWriter{w: csv.NewWriter(&bomWriter{w: w})}
+}
+
+func (cw *csvWriter) Begin(t Table) error {
+	if cw.tables > 0 {
+		if err := cw.w.Write(nil); err != nil {
+			return err
+		}
+	}
+	cw.tables++
+	if t.Title != "" {
+		if err := cw.w.Write([]string{t.Title}); err != nil {
+			return err
+		}
+	}
+	header := make([]string, len(t.Columns))
+	for i, c := range t.Columns {
+		header[i] = c.Title
+	}
+	if err := cw.w.Write(header); err != nil {
+		return err
+	}
+	cw.w.Flush()
+	return cw.w.Error()
+}
+
+func (cw *csvWriter) Row(values ...any) error {
+	record := make([]string, len(values))
+	for i, v := range values {
+		record[i] = text(v)
+	}
+	return cw.w.Write(record)
+}
+
+func (cw *csvWriter) Close() error {
+	cw.w.Flush()
+	return cw.w.Error()
+}
+
+// bomWriter writes the UTF-8 BOM before the first byte.
+type bomWriter struct {
+	w     io.Writer
+	wrote bool
+}
+
+func (b *bomWriter) Write(p []byte) (int, error) {
+	if !b.wrote {
+		b.wrote = true
+		if _, err := b.w.Write([]byte("\xef\xbb\xbf")); err != nil {
+			return 0, err
+		}
+	}
+	return b.w.Write(p)
+}
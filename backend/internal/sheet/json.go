@@ -0,0 +1,96 @@
+package sheet
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"time"
+)
+
+// jsonWriter writes one object whose fields are the tables: arrays of row objects,
+// or a single object for Single tables.
+type jsonWriter struct {
+	w      *bufio.Writer
+	table  Table
+	rows   int
+	tables int
+}
+
+func newJSONWriter(w io.Writer) *jsonWriter {
+	return &jsonWriter{w: bufio.NewWriter(w)}
+}
+
+func (jw *jsonWriter) Begin(t Table) error {
+	jw.endTable()
+	if jw.tables == 0 {
+		jw.w.WriteByte('{')
+	} else {
+		jw.w.WriteByte(',')
+	}
+	jw.tables++
+	jw.table = t
+	jw.rows = 0
+	key, _ := json.Marshal(t.Key)
+	jw.w.Write(key)
+	jw.w.WriteByte(':')
+	if !t.Single {
+		jw.w.WriteByte('[')
+	}
+	return jw.w.Flush()
+}
+
+func (jw *jsonWriter) Row(values ...any) error {
+	if jw.rows > 0 {
+		jw.w.WriteByte(',')
+	}
+	jw.rows++
+	jw.w.WriteByte('{')
+	for i, c := range jw.table.Columns {
+		if i > 0 {
+			jw.w.WriteByte(',')
+		}
+		var v any
+		if i < len(values) {
+			v = values[i]
+		}
+		if t, ok := v.(time.Time); ok {
+			v = t.Format(time.RFC3339)
+		}
+		if t, ok := v.(*time.Time); ok && t != nil {
+			v = t.Format(time.RFC3339)
+		}
+		key, _ := json.Marshal(c.Key)
+		raw, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		jw.w.Write(key)
+		jw.w.WriteByte(':')
+		jw.w.Write(raw)
+	}
+	jw.w.WriteByte('}')
+	if jw.w.Buffered() >= 4096 {
+		return jw.w.Flush()
+	}
+	return nil
+}
+
+func (jw *jsonWriter) endTable() {
+	if jw.tables == 0 {
+		return
+	}
+	if !jw.table.Single {
+		jw.w.WriteByte(']')
+	} else if jw.rows == 0 {
+		jw.w.WriteString("null")
+	}
+}
+
+func (jw *jsonWriter) Close() error {
+	jw.endTable()
+	if jw.tables == 0 {
+		jw.w.WriteByte('{')
+	}
+	jw.w.WriteByte('}')
+	return jw.w.Flush()
+}
@@ -0,0 +1,243 @@
+package sheet
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"encoding/xml"
+	"errors"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// ErrInvalidFile is returned when an uploaded file cannot be parsed.
+var ErrInvalidFile = errors.New("invalid file")
+
+// maxXLSXPart caps the uncompressed size of a single part read from an XLSX file.
+const maxXLSXPart = 64 << 20
+
+// Read parses a CSV file, or the first worksheet of an XLSX file, into rows of
+// cells; rows[i] is line (or sheet row) i+1. The format is sniffed when empty: XLSX files are zip
+// archives.
+func Read(format string, data []byte) ([][]string, error) {
+	if format == "" {
+		format = FormatCSV
+		if bytes.HasPrefix(data, []byte("PK\x03\x04")) {
+			format = FormatXLSX
+		}
+	}
+	switch format {
+	case FormatCSV:
+		return readCSV(data)
+	case FormatXLSX:
+		return readXLSX(data)
+	default:
+		return nil, ErrUnknownFormat
+	}
+}
+
+func readCSV(data []byte) ([][]string, error) {
+	r := csv.NewReader(bytes.NewReader(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))))
+	r.FieldsPerRecord = -1
+	r.LazyQuotes = true
+	var rows [][]string
+	for {
+		rec, err := r.Read()
+		if err == io.EOF {
+			return rows, nil
+		}
+		if err != nil {
+			return nil, ErrInvalidFile
+		}
+		// csv.Reader 跳过空行；按行号补齐，保证 rows[i] 对应第 i+1 行
+		line, _ := r.FieldPos(0)
+		for len(rows) < line-1 {
+			rows = append(rows, nil)
+		}
+		rows = append(rows, rec)
+	}
+}
+
+type xlsxText struct {
+	T    string `xml:"t"`
+	Runs []struct {
+		T string `xml:"t"`
+	} `xml:"r"`
+}
+
+func (t xlsxText) String() string {
+	if len(t.Runs) == 0 {
+		return t.T
+	}
+	var b strings.Builder
+	for _, r := range t.Runs {
+		b.WriteString(r.T)
+	}
+	return b.String()
+}
+
+type xlsxCell struct {
+	Ref    string   `xml:"r,attr"`
+	Type   string   `xml:"t,attr"`
+	Value  string   `xml:"v"`
+	Inline xlsxText `xml:"is"`
+}
+
+type xlsxRow struct {
+	Num   int        `xml:"r,attr"`
+	Cells []xlsxCell `xml:"c"`
+}
+
+// readXLSX reads the first worksheet of the workbook, resolving shared strings.
+func readXLSX(data []byte) ([][]string, error) {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, ErrInvalidFile
+	}
+	files := map[string]*zip.File{}
+	for _, f := range zr.File {
+		files[f.Name] = f
+	}
+
+	var shared []string
+	if f := files["xl/sharedStrings.xml"]; f != nil {
+		var sst struct {
+			Items []xlsxText `xml:"si"`
+		}
+		if err := decodePart(f, &sst); err != nil {
+			return nil, err
+		}
+		for _, it := range sst.Items {
+			shared = append(shared, it.String())
+		}
+	}
+
+	f := files[firstSheetPath(files)]
+	if f == nil {
+		return nil, ErrInvalidFile
+	}
+	var ws struct {
+		Rows []xlsxRow `xml:"sheetData>row"`
+	}
+	if err := decodePart(f, &ws); err != nil {
+		return nil, err
+	}
+
+	var rows [][]string
+	for _, r := range ws.Rows {
+		// 空行在文件中被省略，按行号补齐（最多到 Excel 的行数上限）
+		if r.Num > len(rows)+1 && r.Num <= 1<<20 {
+			for len(rows) < r.Num-1 {
+				rows = append(rows, nil)
+			}
+		}
+		var cells []string
+		for i, c := range r.Cells {
+			col := i
+			if c.Ref != "" {
+				col = columnIndex(c.Ref)
+			}
+			if col < 0 || col >= 1<<14 {
+				return nil, ErrInvalidFile
+			}
+			for len(cells) < col {
+				cells = append(cells, "")
+			}
+			var v string
+			switch c.Type {
+			case "s":
+				n, err := strconv.Atoi(c.Value)
+				if err != nil || n < 0 || n >= len(shared) {
+					return nil, ErrInvalidFile
+				}
+				v = shared[n]
+			case "inlineStr":
+				v = c.Inline.String()
+			case "b":
+				v = "false"
+				if c.Value == "1" {
+					v = "true"
+				}
+			default:
+				v = c.Value
+			}
+			if col < len(cells) {
+				cells[col] = v
+			} else {
+				cells = append(cells, v)
+			}
+		}
+		rows = append(rows, cells)
+	}
+	return rows, nil
+}
+
+// firstSheetPath resolves the first sheet listed in the workbook to its part name.
+func firstSheetPath(files map[string]*zip.File) string {
+	const fallback = "xl/worksheets/sheet1.xml"
+	wb, rels := files["xl/workbook.xml"], files["xl/_rels/workbook.xml.rels"]
+	if wb == nil || rels == nil {
+		return fallback
+	}
+	var workbook struct {
+		Sheets []struct {
+			RID string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+		} `xml:"sheets>sheet"`
+	}
+	var relationships struct {
+		Items []struct {
+			ID     string `xml:"Id,attr"`
+			Target string `xml:"Target,attr"`
+		} `xml:"Relationship"`
+	}
+	if decodePart(wb, &workbook) != nil || decodePart(rels, &relationships) != nil || len(workbook.Sheets) == 0 {
+		return fallback
+	}
+	for _, rel := range relationships.Items {
+		if rel.ID != workbook.Sheets[0].RID {
+			continue
+		}
+		if strings.HasPrefix(rel.Target, "/") {
+			return strings.TrimPrefix(rel.Target, "/")
+		}
+		return path.Join("xl", rel.Target)
+	}
+	return fallback
+}
+
+func decodePart(f *zip.File, v any) error {
+	if f.UncompressedSize64 > maxXLSXPart {
+		return ErrInvalidFile
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return ErrInvalidFile
+	}
+	defer rc.Close()
+	if err := xml.NewDecoder(io.LimitReader(rc, maxXLSXPart)).Decode(v); err != nil {
+		return ErrInvalidFile
+	}
+	return nil
+}
+
+// columnIndex returns the zero-based column of an A1-style reference, or -1.
+func columnIndex(ref string) int {
+	col := 0
+	n := 0
+	for _, r := range ref {
+		if r < 'A' || r > 'Z' {
+			break
+		}
+		col = col*26 + int(r-'A'+1)
+		n++
+		if n > 3 {
+			return -1
+		}
+	}
+	if n == 0 {
+		return -1
+	}
+	return col - 1
+}
@@ -0,0 +1,97 @@
+// Package sheet writes tabular exports as CSV, XLSX or JSON. Rows are written to
+// the underlying io.Writer as they come, so large exports never sit in memory.
+package sheet
+
+import (
+	"errors"
+	"io"
+	"strconv"
+	"time"
+)
+
+const (
+	FormatCSV  = "csv"
+	FormatXLSX = "xlsx"
+	FormatJSON = "json"
+)
+
+var ErrUnknownFormat = errors.New("unknown format")
+
+// Column is one column of a table: Key names the field in JSON, Title is the
+// header in CSV/XLSX.
+type Column struct {
+	Key   string
+	Title string
+}
+
+// Table describes a table (an XLSX sheet, a CSV section or a JSON field).
+// A Single table has exactly one row and is written as an object in JSON.
+type Table struct {
+	Key     string
+	Title   string
+	Columns []Column
+	Single  bool
+}
+
+// Writer writes tables one after another: Begin a table, write its rows, then
+// Begin the next one. Close finishes the file; it does not close the io.Writer.
+//
+// Row values may be string, bool, int, int64, float64, time.Time, *time.Time or nil.
+type Writer interface {
+	Begin(t Table) error
+	Row(values ...any) error
+	Close() error
+}
+
+// NewWriter returns a Writer for format (csv, xlsx or json).
+func NewWriter(format string, w io.Writer) (Writer, error) {
+	switch format {
+	case FormatCSV:
+		return newCSVWriter(w), nil
+	case FormatXLSX:
+		return newXLSXWriter(w), nil
+	case FormatJSON:
+		return newJSONWriter(w), nil
+	default:
+		return nil, ErrUnknownFormat
+	}
+}
+
+// ContentType returns the MIME type of format.
+func ContentType(format string) string {
+	switch format {
+	case FormatCSV:
+		return "text/csv; charset=utf-8"
+	case FormatXLSX:
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	default:
+		return "application/json; charset=utf-8"
+	}
+}
+
+// text formats a cell value for CSV and XLSX string cells.
+func text(v any) string {
+	switch x := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return x
+	case bool:
+		return strconv.FormatBool(x)
+	case int:
+		return strconv.Itoa(x)
+	case int64:
+		return strconv.FormatInt(x, 10)
+	case float64:
+		return strconv.FormatFloat(x, 'f', -1, 64)
+	case time.Time:
+		return x.Format(time.RFC3339)
+	case *time.Time:
+		if x == nil {
+			return ""
+		}
+		return x.Format(time.RFC3339)
+	default:
+		return ""
+	}
+}
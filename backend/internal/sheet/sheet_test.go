@@ -0,0 +1,170 @@
+package sheet
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+var (
+	testInfo = Table{Key: "info", Title: "概况", Single: true, Columns: []Column{{"name", "名称"}, {"endedAt", "结束时间"}}}
+	testRows = Table{Key: "rows", Title: "记录", Columns: []Column{{"who", "成员"}, {"delta", "分数"}, {"voided", "作废"}}}
+	testTime = time.Date(2026, 3, 1, 20, 0, 0, 0, time.UTC)
+)
+
+func writeTest(t *testing.T, format string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w, err := NewWriter(format, &buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	steps := []error{
+		w.Begin(testInfo),
+		w.Row("周五<麻将>", testTime),
+		w.Begin(testRows),
+		w.Row("张三", 10.5, false),
+		w.Row("李四, \"小四\"", int64(-3), true),
+		w.Close(),
+	}
+	for _, err := range steps {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	return buf.Bytes()
+}
+
+func TestCSV(t *testing.T) {
+	got := string(writeTest(t, FormatCSV))
+	want := "\xef\xbb\xbf概况\n名称,结束时间\n周五<麻将>,2026-03-01T20:00:00Z\n\n记录\n成员,分数,作废\n张三,10.5,false\n\"李四, \"\"小四\"\"\",-3,true\n"
+	if got != want {
+		t.Fatalf("csv:\n%q\nwant\n%q", got, want)
+	}
+}
+
+func TestJSON(t *testing.T) {
+	raw := writeTest(t, FormatJSON)
+	var out struct {
+		Info map[string]any   `json:"info"`
+		Rows []map[string]any `json:"rows"`
+	}
+	if err := json.Unmarshal(raw, &out); err != nil {
+		t.Fatalf("decode %s: %v", raw, err)
+	}
+	if out.Info["endedAt"] != "2026-03-01T20:00:00Z" || len(out.Rows) != 2 || out.Rows[1]["delta"] != float64(-3) || out.Rows[1]["voided"] != true {
+		t.Fatalf("json: %s", raw)
+	}
+}
+
+func TestXLSX(t *testing.T) {
+	raw := writeTest(t, FormatXLSX)
+	zr, err := zip.NewReader(bytes.NewReader(raw), int64(len(raw)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]string{}
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, _ := io.ReadAll(rc)
+		rc.Close()
+		files[f.Name] = string(b)
+	}
+	for _, name := range []string{"[Content_Types].xml", "_rels/.rels", "xl/workbook.xml", "xl/_rels/workbook.xml.rels", "xl/worksheets/sheet1.xml", "xl/worksheets/sheet2.xml"} {
+		body, ok := files[name]
+		if !ok {
+			t.Fatalf("missing %s", name)
+		}
+		dec := xml.NewDecoder(strings.NewReader(body))
+		for {
+			if _, err := dec.Token(); err == io.EOF {
+				break
+			} else if err != nil {
+				t.Fatalf("%s: %v", name, err)
+			}
+		}
+	}
+	if !strings.Contains(files["xl/workbook.xml"], `<sheet name="记录" sheetId="2" r:id="rId2"/>`) {
+		t.Fatalf("workbook: %s", files["xl/workbook.xml"])
+	}
+	sheet := files["xl/worksheets/sheet2.xml"]
+	for _, want := range []string{`<c r="B2"><v>10.5</v></c>`, `<c r="C3" t="b"><v>1</v></c>`, `<t xml:space="preserve">李四, &#34;小四&#34;</t>`} {
+		if !strings.Contains(sheet, want) {
+			t.Fatalf("sheet2 missing %s: %s", want, sheet)
+		}
+	}
+	if !strings.Contains(files["xl/worksheets/sheet1.xml"], "周五&lt;麻将&gt;") {
+		t.Fatalf("sheet1: %s", files["xl/worksheets/sheet1.xml"])
+	}
+}
+
+func TestCellRef(t *testing.T) {
+	for col, want := range map[int]string{0: "A1", 25: "Z1", 26: "AA1", 701: "ZZ1", 702: "AAA1"} {
+		if got := cellRef(col, 1); got != want {
+			t.Fatalf("cellRef(%d) = %s, want %s", col, got, want)
+		}
+	}
+}
+
+func TestReadRoundTrip(t *testing.T) {
+	for _, format := range []string{FormatCSV, FormatXLSX} {
+		var buf bytes.Buffer
+		w, _ := NewWriter(format, &buf)
+		w.Begin(Table{Columns: testRows.Columns})
+		w.Row("张三", 10.5, false)
+		w.Row(nil, nil, nil)
+		w.Row("李四, \"小四\"", int64(-3), true)
+		if err := w.Close(); err != nil {
+			t.Fatal(err)
+		}
+
+		rows, err := Read("", buf.Bytes())
+		if err != nil {
+			t.Fatalf("%s: %v", format, err)
+		}
+		if len(rows) != 4 || rows[0][1] != "分数" || rows[1][1] != "10.5" || strings.Join(rows[2], "") != "" || rows[3][0] != "李四, \"小四\"" || rows[3][2] != "true" {
+			t.Fatalf("%s rows: %q", format, rows)
+		}
+	}
+}
+
+func TestReadXLSXSharedStrings(t *testing.T) {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	parts := map[string]string{
+		"xl/workbook.xml":            `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets><sheet name="名单" sheetId="1" r:id="rId7"/></sheets></workbook>`,
+		"xl/_rels/workbook.xml.rels": `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId7" Target="worksheets/data.xml"/></Relationships>`,
+		"xl/sharedStrings.xml":       `<sst><si><t>姓名</t></si><si><r><t>张</t></r><r><t>三</t></r></si></sst>`,
+		"xl/worksheets/data.xml":     `<worksheet><sheetData><row r="1"><c r="A1" t="s"><v>0</v></c><c r="C1" t="str"><v>金额</v></c></row><row r="3"><c r="A3" t="s"><v>1</v></c><c r="C3"><v>888</v></c></row></sheetData></worksheet>`,
+	}
+	for name, body := range parts {
+		w, _ := zw.Create(name)
+		io.WriteString(w, body)
+	}
+	zw.Close()
+
+	rows, err := Read(FormatXLSX, buf.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := [][]string{{"姓名", "", "金额"}, nil, {"张三", "", "888"}}
+	if len(rows) != len(want) {
+		t.Fatalf("rows: %q", rows)
+	}
+	for i := range want {
+		if strings.Join(rows[i], "|") != strings.Join(want[i], "|") {
+			t.Fatalf("row %d: %q, want %q", i+1, rows[i], want[i])
+		}
+	}
+	if _, err := Read(FormatXLSX, []byte("PK\x03\x04 broken")); err != ErrInvalidFile {
+		t.Fatalf("broken xlsx: %v", err)
+	}
+}
@@ -0,0 +1,166 @@
+package sheet
+
+import (
+	"archive/zip"
+	"bufio"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// xlsxWriter writes a minimal Office Open XML workbook: one worksheet per table,
+// strings stored inline (no shared string table) so every sheet can be streamed
+// straight into its zip entry. The first row of each sheet is the header.
+type xlsxWriter struct {
+	zw     *zip.Writer
+	w      *bufio.Writer
+	sheets []string
+	row    int
+}
+
+func newXLSXWriter(w io.Writer) *xlsxWriter {
+	return &xlsxWriter{zw: zip.NewWriter(w)}
+}
+
+func (xw *xlsxWriter) Begin(t Table) error {
+	if err := xw.endSheet(); err != nil {
+		return err
+	}
+	xw.sheets = append(xw.sheets, sheetName(t.Title, len(xw.sheets)+1))
+	entry, err := xw.zw.Create(fmt.Sprintf("xl/worksheets/sheet%d.xml", len(xw.sheets)))
+	if err != nil {
+		return err
+	}
+	xw.w = bufio.NewWriter(entry)
+	xw.row = 0
+	xw.w.WriteString(xml.Header)
+	xw.w.WriteString(`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`)
+
+	header := make([]any, len(t.Columns))
+	for i, c := range t.Columns {
+		header[i] = c.Title
+	}
+	return xw.Row(header...)
+}
+
+func (xw *xlsxWriter) Row(values ...any) error {
+	xw.row++
+	fmt.Fprintf(xw.w, `<row r="%d">`, xw.row)
+	for i, v := range values {
+		ref := cellRef(i, xw.row)
+		switch x := v.(type) {
+		case nil:
+			continue
+		case int, int64, float64:
+			fmt.Fprintf(xw.w, `<c r="%s"><v>%s</v></c>`, ref, text(x))
+		case bool:
+			b := "0"
+			if x {
+				b = "1"
+			}
+			fmt.Fprintf(xw.w, `<c r="%s" t="b"><v>%s</v></c>`, ref, b)
+		default:
+			s := text(x)
+			if s == "" {
+				continue
+			}
+			fmt.Fprintf(xw.w, `<c r="%s" t="inlineStr"><is><t xml:space="preserve">`, ref)
+			if err := xml.EscapeText(xw.w, []byte(s)); err != nil {
+				return err
+			}
+			xw.w.WriteString(`</t></is></c>`)
+		}
+	}
+	xw.w.WriteString(`</row>`)
+	if xw.w.Buffered() >= 4096 {
+		return xw.w.Flush()
+	}
+	return nil
+}
+
+func (xw *xlsxWriter) endSheet() error {
+	if xw.w == nil {
+		return nil
+	}
+	xw.w.WriteString(`</sheetData></worksheet>`)
+	err := xw.w.Flush()
+	xw.w = nil
+	return err
+}
+
+func (xw *xlsxWriter) Close() error {
+	if len(xw.sheets) == 0 {
+		if err := xw.Begin(Table{Title: "Sheet1"}); err != nil {
+			return err
+		}
+	}
+	if err := xw.endSheet(); err != nil {
+		return err
+	}
+
+	var types, sheets, rels strings.Builder
+	for i, name := range xw.sheets {
+		n := i + 1
+		fmt.Fprintf(&types, `<Override PartName="/xl/worksheets/sheet%d.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>`, n)
+		fmt.Fprintf(&sheets, `<sheet name="%s" sheetId="%d" r:id="rId%d"/>`, escapeAttr(name), n, n)
+		fmt.Fprintf(&rels, `<Relationship Id="rId%d" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet%d.xml"/>`, n, n)
+	}
+	parts := []struct{ name, body string }{
+		{"[Content_Types].xml", `<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+			`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+			`<Default Extension="xml" ContentType="application/xml"/>` +
+			`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+			types.String() + `</Types>`},
+		{"_rels/.rels", `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+			`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+			`</Relationships>`},
+		{"xl/workbook.xml", `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+			`<sheets>` + sheets.String() + `</sheets></workbook>`},
+		{"xl/_rels/workbook.xml.rels", `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+			rels.String() + `</Relationships>`},
+	}
+	for _, p := range parts {
+		w, err := xw.zw.Create(p.name)
+		if err != nil {
+			return err
+		}
+		if _, err := io.WriteString(w, xml.Header+p.body); err != nil {
+			return err
+		}
+	}
+	return xw.zw.Close()
+}
+
+// cellRef returns the A1-style reference of a zero-based column and one-based row.
+func cellRef(col, row int) string {
+	name := ""
+	for col++; col > 0; col = (col - 1) / 26 {
+		name = string(rune('A'+(col-1)%26)) + name
+	}
+	return name + strconv.Itoa(row)
+}
+
+// sheetName makes title a valid sheet name: at most 31 characters, none of []:*?/\.
+func sheetName(title string, n int) string {
+	title = strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`[]:*?/\`, r) {
+			return '_'
+		}
+		return r
+	}, strings.TrimSpace(title))
+	if r := []rune(title); len(r) > 31 {
+		title = string(r[:31])
+	}
+	if title == "" {
+		title = "Sheet" + strconv.Itoa(n)
+	}
+	return title
+}
+
+func escapeAttr(s string) string {
+	var b strings.Builder
+	_ = xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
@@ -10,4 +10,14 @@ var (
 	ErrScorebookNotEnded = errors.New("scorebook not ended")
 	ErrInvalidArgument = errors.New("invalid argument")
 	ErrInvalidDelta    = errors.New("invalid delta")
+	ErrDeltaNotAllowed = errors.New("delta not in presets")
+	ErrRecordVoided    = errors.New("record voided")
+	ErrVoidWindowClosed = errors.New("void window closed")
+	ErrSessionRevoked  = errors.New("session revoked")
+	ErrBanned          = errors.New("banned")
+	ErrInviteExpired   = errors.New("invite expired")
+	ErrInviteExhausted = errors.New("invite exhausted")
+	ErrInviteRegenerated = errors.New("invite regenerated")
+	ErrInviteRequired  = errors.New("invite required")
+	ErrCurrencyLocked  = errors.New("currency locked")
 )
@@ -0,0 +1,163 @@
+package memstore
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"scorehub/internal/store"
+)
+
+func (s *Store) CreateBirthdayContact(ctx context.Context, userID int64, in store.BirthdayContactInput) (store.BirthdayContact, error) {
+	name := strings.TrimSpace(in.Name)
+	if name == "" {
+		return store.BirthdayContact{}, store.ErrInvalidArgument
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	c := &store.BirthdayContact{
+		ID:            newID(),
+		UserID:        userID,
+		Name:          name,
+		Gender:        in.Gender,
+		Phone:         in.Phone,
+		Relation:      in.Relation,
+		Note:          in.Note,
+		AvatarURL:     in.AvatarURL,
+		SolarBirthday: in.SolarBirthday,
+		LunarBirthday: in.LunarBirthday,
+		PrimaryType:   in.PrimaryType,
+		PrimaryMonth:  in.PrimaryMonth,
+		PrimaryDay:    in.PrimaryDay,
+		PrimaryYear:   in.PrimaryYear,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+	s.birthdays = append(s.birthdays, c)
+	return *c, nil
+}
+
+func (s *Store) GetBirthdayContact(ctx context.Context, userID int64, id string) (store.BirthdayContact, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c := s.birthday(userID, id)
+	if c == nil {
+		return store.BirthdayContact{}, store.ErrNotFound
+	}
+	return *c, nil
+}
+
+func (s *Store) ListBirthdayContacts(ctx context.Context, userID int64, limit, offset int32) ([]store.BirthdayContactWithDays, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+
+	var out []store.BirthdayContactWithDays
+	for _, c := range s.birthdays {
+		if c.UserID != userID {
+			continue
+		}
+		next := birthdayIn(today.Year(), c.PrimaryMonth, c.PrimaryDay)
+		if next.Before(today) {
+			next = birthdayIn(today.Year()+1, c.PrimaryMonth, c.PrimaryDay)
+		}
+		out = append(out, store.BirthdayContactWithDays{
+			BirthdayContact: *c,
+			NextBirthday:    next,
+			DaysLeft:        int(next.Sub(today).Hours() / 24),
+		})
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		if out[i].DaysLeft != out[j].DaysLeft {
+			return out[i].DaysLeft < out[j].DaysLeft
+		}
+		return out[i].Name < out[j].Name
+	})
+	from, to := page(len(out), limit, offset)
+	return out[from:to], nil
+}
+
+func (s *Store) UpdateBirthdayContact(ctx context.Context, userID int64, id string, in store.BirthdayContactUpdate) (store.BirthdayContact, error) {
+	if in.Name == nil && in.Gender == nil && in.Phone == nil && in.Relation == nil && in.Note == nil &&
+		in.AvatarURL == nil && in.SolarBirthday == nil && !in.SolarSetNull && in.LunarBirthday == nil &&
+		in.PrimaryType == nil && in.PrimaryMonth == nil && in.PrimaryDay == nil && in.PrimaryYear == nil {
+		return store.BirthdayContact{}, store.ErrInvalidArgument
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c := s.birthday(userID, id)
+	if c == nil {
+		return store.BirthdayContact{}, store.ErrNotFound
+	}
+	setTrimmed(&c.Name, in.Name)
+	setTrimmed(&c.Gender, in.Gender)
+	setTrimmed(&c.Phone, in.Phone)
+	setTrimmed(&c.Relation, in.Relation)
+	setTrimmed(&c.Note, in.Note)
+	setTrimmed(&c.AvatarURL, in.AvatarURL)
+	if in.SolarSetNull {
+		c.SolarBirthday = nil
+	} else if in.SolarBirthday != nil {
+		c.SolarBirthday = timePtr(*in.SolarBirthday)
+	}
+	setTrimmed(&c.LunarBirthday, in.LunarBirthday)
+	setTrimmed(&c.PrimaryType, in.PrimaryType)
+	if in.PrimaryMonth != nil {
+		c.PrimaryMonth = *in.PrimaryMonth
+	}
+	if in.PrimaryDay != nil {
+		c.PrimaryDay = *in.PrimaryDay
+	}
+	if in.PrimaryYear != nil {
+		c.PrimaryYear = *in.PrimaryYear
+	}
+	c.UpdatedAt = s.now()
+	return *c, nil
+}
+
+func (s *Store) DeleteBirthdayContact(ctx context.Context, userID int64, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, c := range s.birthdays {
+		if c.ID == id && c.UserID == userID {
+			s.birthdays = append(s.birthdays[:i], s.birthdays[i+1:]...)
+			delete(s.birthdayPeople, id)
+			return nil
+		}
+	}
+	return store.ErrNotFound
+}
+
+func (s *Store) birthday(userID int64, id string) *store.BirthdayContact {
+	for _, c := range s.birthdays {
+		if c.ID == id && c.UserID == userID {
+			return c
+		}
+	}
+	return nil
+}
+
+// birthdayIn returns the birthday date in the given year, clamping month to 1..12
+// and day to the last day of that month (e.g. Feb 29 -> Feb 28).
+func birthdayIn(year, month, day int) time.Time {
+	month = min(max(month, 1), 12)
+	day = max(day, 1)
+	lastDay := time.Date(year, time.Month(month)+1, 0, 0, 0, 0, 0, time.UTC).Day()
+	return time.Date(year, time.Month(month), min(day, lastDay), 0, 0, 0, 0, time.UTC)
+}
+
+func setTrimmed(dst *string, v *string) {
+	if v != nil {
+		*dst = strings.TrimSpace(*v)
+	}
+}
@@ -0,0 +1,373 @@
+package memstore
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"strings"
+
+	"scorehub/internal/store"
+)
+
+func (s *Store) CreateDepositAccount(ctx context.Context, userID int64, in store.DepositAccountInput) (store.DepositAccount, error) {
+	bank := strings.TrimSpace(in.Bank)
+	if bank == "" {
+		return store.DepositAccount{}, store.ErrInvalidArgument
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	a := &store.DepositAccount{
+		ID:        newID(),
+		UserID:    userID,
+		Bank:      bank,
+		Branch:    in.Branch,
+		AccountNo: in.AccountNo,
+		Holder:    in.Holder,
+		AvatarURL: in.AvatarURL,
+		Note:      in.Note,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	s.depositAccounts = append(s.depositAccounts, a)
+	return *a, nil
+}
+
+func (s *Store) ListDepositAccounts(ctx context.Context, userID int64, limit, offset int32) ([]store.DepositAccount, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var out []store.DepositAccount
+	for i := len(s.depositAccounts) - 1; i >= 0; i-- {
+		a := s.depositAccounts[i]
+		if a.UserID == userID && a.DeletedAt == nil {
+			out = append(out, *a)
+		}
+	}
+	from, to := page(len(out), limit, offset)
+	return out[from:to], nil
+}
+
+func (s *Store) GetDepositAccount(ctx context.Context, userID int64, id string) (store.DepositAccount, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	a := s.depositAccount(userID, id)
+	if a == nil {
+		return store.DepositAccount{}, store.ErrNotFound
+	}
+	return *a, nil
+}
+
+func (s *Store) UpdateDepositAccount(ctx context.Context, userID int64, id string, in store.DepositAccountUpdate) (store.DepositAccount, error) {
+	if in.Bank == nil && in.Branch == nil && in.AccountNo == nil && in.Holder == nil && in.AvatarURL == nil && in.Note == nil {
+		return store.DepositAccount{}, store.ErrInvalidArgument
+	}
+	if in.Bank != nil && strings.TrimSpace(*in.Bank) == "" {
+		return store.DepositAccount{}, store.ErrInvalidArgument
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	a := s.depositAccount(userID, id)
+	if a == nil {
+		return store.DepositAccount{}, store.ErrNotFound
+	}
+	setTrimmed(&a.Bank, in.Bank)
+	setTrimmed(&a.Branch, in.Branch)
+	setTrimmed(&a.AccountNo, in.AccountNo)
+	setTrimmed(&a.Holder, in.Holder)
+	setTrimmed(&a.AvatarURL, in.AvatarURL)
+	setTrimmed(&a.Note, in.Note)
+	a.UpdatedAt = s.now()
+	return *a, nil
+}
+
+func (s *Store) DeleteDepositAccount(ctx context.Context, userID int64, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	a := s.depositAccount(userID, id)
+	if a == nil {
+		return store.ErrNotFound
+	}
+	now := s.now()
+	a.DeletedAt = timePtr(now)
+	a.UpdatedAt = now
+	for _, r := range s.depositRecords {
+		if r.AccountID == id && r.UserID == userID && r.DeletedAt == nil {
+			r.DeletedAt = timePtr(now)
+			r.UpdatedAt = now
+		}
+	}
+	return nil
+}
+
+func (s *Store) CreateDepositRecord(ctx context.Context, userID int64, in store.DepositRecordInput) (store.DepositRecord, error) {
+	if strings.TrimSpace(in.AccountID) == "" {
+		return store.DepositRecord{}, store.ErrInvalidArgument
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.depositAccount(userID, in.AccountID) == nil {
+		return store.DepositRecord{}, store.ErrNotFound
+	}
+	tags := slices.Clone(in.Tags)
+	if tags == nil {
+		tags = []string{}
+	}
+	now := s.now()
+	r := &store.DepositRecord{
+		ID:          newID(),
+		UserID:      userID,
+		AccountID:   in.AccountID,
+		Currency:    in.Currency,
+		Amount:      in.Amount,
+		AmountUpper: in.AmountUpper,
+		TermValue:   in.TermValue,
+		TermUnit:    in.TermUnit,
+		Rate:        in.Rate,
+		StartDate:   in.StartDate,
+		EndDate:     in.EndDate,
+		Interest:    in.Interest,
+		ReceiptNo:   in.ReceiptNo,
+		Status:      in.Status,
+		WithdrawnAt: in.WithdrawnAt,
+		Tags:        tags,
+		Attachments: slices.Clone(in.Attachments),
+		Note:        in.Note,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	s.depositRecords = append(s.depositRecords, r)
+	return copyDepositRecord(r), nil
+}
+
+func (s *Store) ListDepositRecords(ctx context.Context, userID int64, accountID string, status string, tags []string, limit, offset int32) ([]store.DepositRecord, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var out []store.DepositRecord
+	for _, r := range s.filterDepositRecords(userID, accountID, status, tags) {
+		out = append(out, copyDepositRecord(r))
+	}
+	statusRank := func(status string) int {
+		switch status {
+		case "未到期":
+			return 0
+		case "已到期":
+			return 1
+		}
+		return 2
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		ri, rj := statusRank(out[i].Status), statusRank(out[j].Status)
+		if ri != rj {
+			return ri < rj
+		}
+		ti, tj := out[i].EndDate, out[j].EndDate
+		if out[i].WithdrawnAt != nil {
+			ti = *out[i].WithdrawnAt
+		}
+		if out[j].WithdrawnAt != nil {
+			tj = *out[j].WithdrawnAt
+		}
+		return ti.Before(tj)
+	})
+	from, to := page(len(out), limit, offset)
+	return out[from:to], nil
+}
+
+func (s *Store) GetDepositRecord(ctx context.Context, userID int64, id string) (store.DepositRecord, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	r := s.depositRecord(userID, id)
+	if r == nil {
+		return store.DepositRecord{}, store.ErrNotFound
+	}
+	return copyDepositRecord(r), nil
+}
+
+func (s *Store) UpdateDepositRecord(ctx context.Context, userID int64, id string, in store.DepositRecordUpdate) (store.DepositRecord, error) {
+	if in.Currency == nil && in.Amount == nil && in.AmountUpper == nil && in.TermValue == nil && in.TermUnit == nil &&
+		in.Rate == nil && in.StartDate == nil && in.EndDate == nil && in.Interest == nil && in.ReceiptNo == nil &&
+		in.Status == nil && in.WithdrawnAt == nil && !in.WithdrawnSetNull && in.Tags == nil && in.Attachments == nil && in.Note == nil {
+		return store.DepositRecord{}, store.ErrInvalidArgument
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	r := s.depositRecord(userID, id)
+	if r == nil {
+		return store.DepositRecord{}, store.ErrNotFound
+	}
+	setTrimmed(&r.Currency, in.Currency)
+	if in.Amount != nil {
+		r.Amount = *in.Amount
+	}
+	setTrimmed(&r.AmountUpper, in.AmountUpper)
+	if in.TermValue != nil {
+		r.TermValue = *in.TermValue
+	}
+	setTrimmed(&r.TermUnit, in.TermUnit)
+	if in.Rate != nil {
+		r.Rate = *in.Rate
+	}
+	if in.StartDate != nil {
+		r.StartDate = *in.StartDate
+	}
+	if in.EndDate != nil {
+		r.EndDate = *in.EndDate
+	}
+	if in.Interest != nil {
+		r.Interest = *in.Interest
+	}
+	setTrimmed(&r.ReceiptNo, in.ReceiptNo)
+	setTrimmed(&r.Status, in.Status)
+	if in.WithdrawnSetNull {
+		r.WithdrawnAt = nil
+	} else if in.WithdrawnAt != nil {
+		r.WithdrawnAt = timePtr(*in.WithdrawnAt)
+	}
+	if in.Tags != nil {
+		r.Tags = slices.Clone(*in.Tags)
+	}
+	if in.Attachments != nil {
+		r.Attachments = slices.Clone(*in.Attachments)
+	}
+	setTrimmed(&r.Note, in.Note)
+	r.UpdatedAt = s.now()
+	return copyDepositRecord(r), nil
+}
+
+func (s *Store) DeleteDepositRecord(ctx context.Context, userID int64, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	r := s.depositRecord(userID, id)
+	if r == nil {
+		return store.ErrNotFound
+	}
+	now := s.now()
+	r.DeletedAt = timePtr(now)
+	r.UpdatedAt = now
+	return nil
+}
+
+func (s *Store) ListDepositTags(ctx context.Context, userID int64, accountID string, status string) ([]store.DepositTagCount, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	counts := map[string]int{}
+	for _, r := range s.filterDepositRecords(userID, accountID, status, nil) {
+		for _, tag := range r.Tags {
+			if tag != "" {
+				counts[tag]++
+			}
+		}
+	}
+	var out []store.DepositTagCount
+	for tag, n := range counts {
+		out = append(out, store.DepositTagCount{Tag: tag, Count: n})
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Count != out[j].Count {
+			return out[i].Count > out[j].Count
+		}
+		return out[i].Tag < out[j].Tag
+	})
+	return out, nil
+}
+
+func (s *Store) GetDepositStats(ctx context.Context, userID int64, accountID string, status string, tags []string) (store.DepositStats, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	type accountKey struct{ accountID, currency string }
+	totals := map[string]float64{}
+	yields := map[string]float64{}
+	accounts := map[accountKey]float64{}
+	year := s.now().Year()
+	for _, r := range s.filterDepositRecords(userID, accountID, status, tags) {
+		totals[r.Currency] += r.Amount
+		if r.EndDate.Year() == year {
+			yields[r.Currency] += r.Interest
+		}
+		accounts[accountKey{r.AccountID, r.Currency}] += r.Amount
+	}
+
+	var stats store.DepositStats
+	for currency, v := range totals {
+		stats.Totals = append(stats.Totals, store.DepositCurrencyStat{Currency: currency, Amount: v})
+	}
+	for currency, v := range yields {
+		stats.AnnualYields = append(stats.AnnualYields, store.DepositCurrencyStat{Currency: currency, Amount: v})
+	}
+	for k, v := range accounts {
+		stats.AccountTotals = append(stats.AccountTotals, store.DepositAccountStat{AccountID: k.accountID, Currency: k.currency, Amount: v})
+	}
+	sort.Slice(stats.Totals, func(i, j int) bool { return stats.Totals[i].Currency < stats.Totals[j].Currency })
+	sort.Slice(stats.AnnualYields, func(i, j int) bool { return stats.AnnualYields[i].Currency < stats.AnnualYields[j].Currency })
+	sort.Slice(stats.AccountTotals, func(i, j int) bool {
+		a, b := stats.AccountTotals[i], stats.AccountTotals[j]
+		if a.AccountID != b.AccountID {
+			return a.AccountID < b.AccountID
+		}
+		return a.Currency < b.Currency
+	})
+	return stats, nil
+}
+
+func (s *Store) depositAccount(userID int64, id string) *store.DepositAccount {
+	for _, a := range s.depositAccounts {
+		if a.ID == id && a.UserID == userID && a.DeletedAt == nil {
+			return a
+		}
+	}
+	return nil
+}
+
+func (s *Store) depositRecord(userID int64, id string) *store.DepositRecord {
+	for _, r := range s.depositRecords {
+		if r.ID == id && r.UserID == userID && r.DeletedAt == nil {
+			return r
+		}
+	}
+	return nil
+}
+
+// filterDepositRecords applies the account / status / tag-overlap filters shared by
+// the deposit list, tag and stats queries.
+func (s *Store) filterDepositRecords(userID int64, accountID, status string, tags []string) []*store.DepositRecord {
+	var out []*store.DepositRecord
+	for _, r := range s.depositRecords {
+		if r.UserID != userID || r.DeletedAt != nil {
+			continue
+		}
+		if accountID != "" && r.AccountID != accountID {
+			continue
+		}
+		if status != "" && r.Status != status {
+			continue
+		}
+		if len(tags) > 0 && !slices.ContainsFunc(r.Tags, func(t string) bool { return slices.Contains(tags, t) }) {
+			continue
+		}
+		out = append(out, r)
+	}
+	return out
+}
+
+func copyDepositRecord(r *store.DepositRecord) store.DepositRecord {
+	out := *r
+	out.Tags = slices.Clone(r.Tags)
+	out.Attachments = slices.Clone(r.Attachments)
+	return out
+}
@@ -0,0 +1,55 @@
+package memstore
+
+import (
+	"context"
+
+	"scorehub/internal/store"
+)
+
+func (s *Store) AppendScorebookEvent(ctx context.Context, scorebookID string, payload []byte) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.findBook(scorebookID) == nil {
+		return 0, store.ErrNotFound
+	}
+	seq := s.eventSeq[scorebookID] + 1
+	s.eventSeq[scorebookID] = seq
+	events := append(s.events[scorebookID], store.ScorebookEvent{
+		Seq:       seq,
+		Payload:   append([]byte(nil), payload...),
+		CreatedAt: s.now(),
+	})
+	if n := len(events) - store.ScorebookEventRetention; n > 0 {
+		events = events[n:]
+	}
+	s.events[scorebookID] = events
+	return seq, nil
+}
+
+func (s *Store) ListScorebookEventsSince(ctx context.Context, scorebookID string, since int64, limit int32) ([]store.ScorebookEvent, int64, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.findBook(scorebookID) == nil {
+		return nil, 0, false, store.ErrNotFound
+	}
+	latest := s.eventSeq[scorebookID]
+	if since > latest || since < 0 {
+		return nil, latest, false, nil
+	}
+	if since == latest {
+		return nil, latest, true, nil
+	}
+
+	var out []store.ScorebookEvent
+	for _, e := range s.events[scorebookID] {
+		if e.Seq > since && len(out) <= int(limit) {
+			out = append(out, e)
+		}
+	}
+	if !store.Replayable(out, since, limit) {
+		return nil, latest, false, nil
+	}
+	return out, latest, true, nil
+}
@@ -0,0 +1,49 @@
+package memstore
+
+import (
+	"context"
+	"sort"
+
+	"scorehub/internal/store"
+)
+
+// EachRecord copies the matching records under the lock and calls fn after
+// releasing it, so fn may call back into the store.
+func (s *Store) EachRecord(ctx context.Context, scorebookID string, fn func(store.ScoreRecord) error) error {
+	s.mu.Lock()
+	var out []store.ScoreRecord
+	for _, r := range s.records {
+		if r.ScorebookID == scorebookID && r.ReversesRecordID == "" {
+			out = append(out, *r)
+		}
+	}
+	s.mu.Unlock()
+
+	sort.SliceStable(out, func(i, j int) bool { return out[i].CreatedAt.Before(out[j].CreatedAt) })
+	for _, r := range out {
+		if err := fn(r); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Store) EachLedgerRecord(ctx context.Context, ledgerID string, fn func(store.LedgerRecord) error) error {
+	s.mu.Lock()
+	ownerID := s.ledgerOwnerMemberID(ledgerID)
+	var out []store.LedgerRecord
+	for _, r := range s.records {
+		if r.ScorebookID == ledgerID {
+			out = append(out, toLedgerRecord(r, ownerID))
+		}
+	}
+	s.mu.Unlock()
+
+	sort.SliceStable(out, func(i, j int) bool { return out[i].CreatedAt.Before(out[j].CreatedAt) })
+	for _, r := range out {
+		if err := fn(r); err != nil {
+			return err
+		}
+	}
+	return nil
+}
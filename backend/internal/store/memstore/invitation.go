@@ -0,0 +1,229 @@
+package memstore
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"scorehub/internal/store"
+)
+
+func (s *Store) ListFrequentPlayers(ctx context.Context, userID int64, limit int32) ([]store.FrequentPlayer, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	byUser := map[int64]*store.FrequentPlayer{}
+	for _, b := range s.books {
+		if b.BookType != "scorebook" || b.DeletedAt != nil || s.memberByUser(b.ID, userID) == nil {
+			continue
+		}
+		for _, m := range s.membersOf(b.ID) {
+			if m.UserID == nil || *m.UserID == userID {
+				continue
+			}
+			p := byUser[*m.UserID]
+			if p == nil {
+				p = &store.FrequentPlayer{UserID: *m.UserID}
+				byUser[*m.UserID] = p
+			}
+			p.SharedScorebooks++
+			if !b.UpdatedAt.Before(p.LastPlayedAt) {
+				p.Nickname = m.Nickname
+				p.AvatarURL = m.AvatarURL
+				p.LastPlayedAt = b.UpdatedAt
+			}
+		}
+	}
+	var out []store.FrequentPlayer
+	for _, p := range byUser {
+		out = append(out, *p)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].SharedScorebooks != out[j].SharedScorebooks {
+			return out[i].SharedScorebooks > out[j].SharedScorebooks
+		}
+		if !out[i].LastPlayedAt.Equal(out[j].LastPlayedAt) {
+			return out[i].LastPlayedAt.After(out[j].LastPlayedAt)
+		}
+		return out[i].UserID < out[j].UserID
+	})
+	from, to := page(len(out), limit, 0)
+	return out[from:to], nil
+}
+
+func (s *Store) InviteUsers(ctx context.Context, scorebookID string, userID int64, inviteeIDs []int64) ([]store.ScorebookInvitation, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	b, err := s.manageableBook(scorebookID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if b.Status != "recording" {
+		return nil, store.ErrScorebookEnded
+	}
+	if err := s.checkInvitees(b.ID, userID, inviteeIDs); err != nil {
+		return nil, err
+	}
+	return s.inviteUsers(b.ID, userID, inviteeIDs), nil
+}
+
+func (s *Store) ListMyInvitations(ctx context.Context, userID int64, status string, limit, offset int32) ([]store.ScorebookInvitation, error) {
+	switch status {
+	case "", store.InvitationPending, store.InvitationAccepted, store.InvitationDeclined:
+	default:
+		return nil, store.ErrInvalidArgument
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var out []store.ScorebookInvitation
+	for _, inv := range s.invitations {
+		if inv.UserID != userID || (status != "" && inv.Status != status) {
+			continue
+		}
+		if b := s.findBook(inv.ScorebookID); b == nil || b.DeletedAt != nil {
+			continue
+		}
+		out = append(out, s.invitationOut(inv))
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].CreatedAt.After(out[j].CreatedAt) })
+	from, to := page(len(out), limit, offset)
+	return out[from:to], nil
+}
+
+func (s *Store) RespondInvitation(ctx context.Context, invitationID string, user store.User, accept bool) (store.ScorebookInvitation, *store.Member, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var inv *store.ScorebookInvitation
+	for _, it := range s.invitations {
+		if it.ID == invitationID && it.UserID == user.ID {
+			inv = it
+		}
+	}
+	if inv == nil {
+		return store.ScorebookInvitation{}, nil, store.ErrNotFound
+	}
+	b := s.findBook(inv.ScorebookID)
+	if b == nil || b.DeletedAt != nil {
+		return store.ScorebookInvitation{}, nil, store.ErrNotFound
+	}
+	if inv.Status != store.InvitationPending {
+		return store.ScorebookInvitation{}, nil, store.ErrConflict
+	}
+
+	if !accept {
+		inv.Status = store.InvitationDeclined
+		inv.ResolvedAt = timePtr(s.now())
+		return s.invitationOut(inv), nil, nil
+	}
+
+	if existing := s.memberByUser(b.ID, user.ID); existing != nil {
+		inv.Status = store.InvitationAccepted
+		inv.MemberID = existing.ID
+		inv.ResolvedAt = timePtr(s.now())
+		m := toMember(existing)
+		return s.invitationOut(inv), &m, nil
+	}
+	if b.Status == "ended" {
+		return store.ScorebookInvitation{}, nil, store.ErrScorebookEnded
+	}
+	if s.banned(b.ID, user.ID) {
+		return store.ScorebookInvitation{}, nil, store.ErrBanned
+	}
+	nickname := strings.TrimSpace(user.WeChatNickname)
+	if nickname == "" {
+		nickname = "成员"
+	}
+	inviter := inv.InvitedByUserID
+	m := s.insertJoinedMember(b.ID, user.ID, nickname, user.WeChatAvatarURL, store.RoleMember, "", &inviter)
+	return s.invitationOut(inv), &m, nil
+}
+
+// checkInvitees mirrors the checks of inviteUsersTx; bookID is empty while the
+// scorebook is being created.
+func (s *Store) checkInvitees(bookID string, inviterID int64, inviteeIDs []int64) error {
+	for _, id := range inviteeIDs {
+		if id == inviterID {
+			return store.ErrInvalidArgument
+		}
+		if bookID != "" && s.memberByUser(bookID, id) != nil {
+			continue
+		}
+		if !s.playedTogether(inviterID, id) {
+			return store.ErrInvalidArgument
+		}
+		if bookID != "" && s.banned(bookID, id) {
+			return store.ErrBanned
+		}
+	}
+	return nil
+}
+
+// inviteUsers writes pending invitations for the (already checked) invitees,
+// skipping members and refreshing existing pending invitations.
+func (s *Store) inviteUsers(bookID string, inviterID int64, inviteeIDs []int64) []store.ScorebookInvitation {
+	var out []store.ScorebookInvitation
+	seen := map[int64]bool{}
+	for _, id := range inviteeIDs {
+		if seen[id] || s.memberByUser(bookID, id) != nil {
+			continue
+		}
+		seen[id] = true
+		inv := s.pendingInvitation(bookID, id)
+		if inv == nil {
+			inv = &store.ScorebookInvitation{
+				ID:          newID(),
+				ScorebookID: bookID,
+				UserID:      id,
+				Status:      store.InvitationPending,
+			}
+			s.invitations = append(s.invitations, inv)
+		}
+		inv.InvitedByUserID = inviterID
+		inv.CreatedAt = s.now()
+		out = append(out, s.invitationOut(inv))
+	}
+	return out
+}
+
+func (s *Store) pendingInvitation(bookID string, userID int64) *store.ScorebookInvitation {
+	for _, inv := range s.invitations {
+		if inv.ScorebookID == bookID && inv.UserID == userID && inv.Status == store.InvitationPending {
+			return inv
+		}
+	}
+	return nil
+}
+
+// playedTogether reports whether both users are members of a non-deleted scorebook.
+func (s *Store) playedTogether(a, b int64) bool {
+	for _, bk := range s.books {
+		if bk.BookType == "scorebook" && bk.DeletedAt == nil && s.memberByUser(bk.ID, a) != nil && s.memberByUser(bk.ID, b) != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// invitationOut fills the joined columns: scorebook name/status and the inviter's
+// nickname in the scorebook (or their profile nickname).
+func (s *Store) invitationOut(inv *store.ScorebookInvitation) store.ScorebookInvitation {
+	out := *inv
+	if b := s.findBook(inv.ScorebookID); b != nil {
+		out.ScorebookName = b.Name
+		out.ScorebookStatus = b.Status
+	}
+	if m := s.memberByUser(inv.ScorebookID, inv.InvitedByUserID); m != nil {
+		out.InvitedByNickname = m.Nickname
+	} else if u, ok := s.users[inv.InvitedByUserID]; ok {
+		out.InvitedByNickname = u.WeChatNickname
+	}
+	if out.ResolvedAt != nil {
+		t := *out.ResolvedAt
+		out.ResolvedAt = &t
+	}
+	return out
+}
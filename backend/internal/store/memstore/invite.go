@@ -0,0 +1,232 @@
+package memstore
+
+import (
+	"context"
+	"sort"
+
+	"scorehub/internal/store"
+)
+
+func (s *Store) GetInviteInfo(ctx context.Context, code string) (store.InviteInfo, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, b := range s.books {
+		if b.InviteCode == code && b.DeletedAt == nil {
+			uses := s.inviteUseCount(b.ID, code)
+			return store.InviteInfo{
+				BookID:         b.ID,
+				BookType:       b.BookType,
+				Name:           b.Name,
+				Status:         b.Status,
+				ShareDisabled:  b.ShareDisabled,
+				UpdatedAt:      b.UpdatedAt,
+				ExpiresAt:      b.InviteExpiresAt,
+				MaxUses:        b.InviteMaxUses,
+				Uses:           uses,
+				UnusableReason: store.InviteUnusableReason(b.Status, b.InviteExpiresAt, b.InviteMaxUses, uses, s.now()),
+				JoinApproval:   b.JoinApproval,
+			}, nil
+		}
+	}
+	return store.InviteInfo{}, s.unknownInvite(code)
+}
+
+func (s *Store) unknownInvite(code string) error {
+	if id, ok := s.retiredInvites[code]; ok {
+		if b := s.findBook(id); b != nil && b.DeletedAt == nil {
+			return store.ErrInviteRegenerated
+		}
+	}
+	return store.ErrNotFound
+}
+
+func (s *Store) JoinByInviteCode(ctx context.Context, code string, user store.User, nickname, avatarURL, role string) (store.Member, *store.JoinRequest, error) {
+	if role == "" {
+		role = store.RoleMember
+	}
+	if role != store.RoleMember && role != store.RoleSpectator {
+		return store.Member{}, nil, store.ErrInvalidArgument
+	}
+	if nickname == "" {
+		nickname = user.WeChatNickname
+	}
+	if nickname == "" {
+		nickname = "成员"
+	}
+	if avatarURL == "" {
+		avatarURL = user.WeChatAvatarURL
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var target *book
+	for _, b := range s.books {
+		if b.InviteCode == code && b.BookType == "scorebook" && b.DeletedAt == nil {
+			target = b
+			break
+		}
+	}
+	if target == nil {
+		return store.Member{}, nil, s.unknownInvite(code)
+	}
+
+	return s.joinScorebook(target.ID, user, nickname, avatarURL, role, code, func(b *book) error {
+		return s.checkInviteUsable(b, code)
+	})
+}
+
+// checkInviteUsable mirrors store.checkInviteUsable; the caller holds s.mu.
+func (s *Store) checkInviteUsable(b *book, code string) error {
+	if code == "" {
+		return store.ErrInviteRequired
+	}
+	if code != b.InviteCode {
+		if s.retiredInvites[code] == b.ID {
+			return store.ErrInviteRegenerated
+		}
+		return store.ErrInviteRequired
+	}
+	switch store.InviteUnusableReason(b.Status, b.InviteExpiresAt, b.InviteMaxUses, s.inviteUseCount(b.ID, code), s.now()) {
+	case "expired":
+		return store.ErrInviteExpired
+	case "exhausted":
+		return store.ErrInviteExhausted
+	}
+	return nil
+}
+
+func (s *Store) GetInviteSettings(ctx context.Context, scorebookID string, userID int64) (store.InviteSettings, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	b, err := s.manageableBook(scorebookID, userID)
+	if err != nil {
+		return store.InviteSettings{}, err
+	}
+	return s.inviteSettings(b), nil
+}
+
+func (s *Store) UpdateInviteSettings(ctx context.Context, scorebookID string, userID int64, in store.InviteSettingsUpdate) (store.InviteSettings, error) {
+	if in.MaxUses != nil && *in.MaxUses < 0 {
+		return store.InviteSettings{}, store.ErrInvalidArgument
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	b, err := s.manageableBook(scorebookID, userID)
+	if err != nil {
+		return store.InviteSettings{}, err
+	}
+	if in.ExpiresSetNull {
+		b.InviteExpiresAt = nil
+	} else if in.ExpiresAt != nil {
+		b.InviteExpiresAt = timePtr(*in.ExpiresAt)
+	}
+	if in.MaxUses != nil {
+		b.InviteMaxUses = *in.MaxUses
+	}
+	if in.JoinApproval != nil {
+		b.JoinApproval = *in.JoinApproval
+	}
+	s.touch(b.ID)
+	return s.inviteSettings(b), nil
+}
+
+func (s *Store) RegenerateInviteCode(ctx context.Context, scorebookID string, userID int64) (store.InviteSettings, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	b, err := s.manageableBook(scorebookID, userID)
+	if err != nil {
+		return store.InviteSettings{}, err
+	}
+	old := b.InviteCode
+	for {
+		b.InviteCode = randomInviteCode(8)
+		if _, retired := s.retiredInvites[b.InviteCode]; !retired && !s.inviteCodeTaken(b) {
+			break
+		}
+	}
+	if _, ok := s.retiredInvites[old]; !ok {
+		s.retiredInvites[old] = b.ID
+	}
+	s.touch(b.ID)
+	return s.inviteSettings(b), nil
+}
+
+func (s *Store) ListInviteUses(ctx context.Context, scorebookID string, userID int64, limit, offset int32) ([]store.InviteUse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	b, err := s.manageableBook(scorebookID, userID)
+	if err != nil {
+		return nil, err
+	}
+	// 倒序遍历，与 SQL 中 created_at 相同时按 id 倒序一致
+	var all []store.InviteUse
+	for i := len(s.inviteUses) - 1; i >= 0; i-- {
+		u := s.inviteUses[i]
+		if u.BookID != b.ID {
+			continue
+		}
+		out := u.InviteUse
+		if m := s.memberByID(b.ID, u.MemberID); m != nil {
+			out.Nickname = m.Nickname
+		} else {
+			out.MemberID = ""
+			if usr := s.users[u.UserID]; usr != nil {
+				out.Nickname = usr.WeChatNickname
+			}
+		}
+		all = append(all, out)
+	}
+	sort.SliceStable(all, func(i, j int) bool { return all[i].CreatedAt.After(all[j].CreatedAt) })
+	from, to := page(len(all), limit, offset)
+	return all[from:to], nil
+}
+
+// manageableBook returns the scorebook if the user is its owner or admin.
+func (s *Store) manageableBook(scorebookID string, userID int64) (*book, error) {
+	b, me := s.bookMember(scorebookID, userID)
+	if me == nil {
+		return nil, store.ErrNotFound
+	}
+	if !store.CanManage(me.Role) {
+		return nil, store.ErrForbidden
+	}
+	return b, nil
+}
+
+func (s *Store) inviteSettings(b *book) store.InviteSettings {
+	return store.InviteSettings{
+		ScorebookID: b.ID,
+		Code:        b.InviteCode,
+		ExpiresAt:   b.InviteExpiresAt,
+		MaxUses:     b.InviteMaxUses,
+		Uses:        s.inviteUseCount(b.ID, b.InviteCode),
+
+		JoinApproval: b.JoinApproval,
+	}
+}
+
+func (s *Store) inviteUseCount(bookID, code string) int64 {
+	var n int64
+	for _, u := range s.inviteUses {
+		if u.BookID == bookID && u.Code == code {
+			n++
+		}
+	}
+	return n
+}
+
+func (s *Store) inviteCodeTaken(b *book) bool {
+	for _, other := range s.books {
+		if other != b && other.InviteCode == b.InviteCode {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,164 @@
+package memstore
+
+import (
+	"context"
+
+	"scorehub/internal/store"
+)
+
+// requestJoin mirrors store.requestJoinTx; the caller holds s.mu.
+func (s *Store) requestJoin(bookID string, userID int64, nickname, avatarURL, role, inviteCode string) store.JoinRequest {
+	for _, r := range s.joinReqs {
+		if r.ScorebookID == bookID && r.UserID == userID && r.Status == store.JoinRequestPending {
+			r.Nickname, r.AvatarURL, r.Role, r.InviteCode = nickname, avatarURL, role, inviteCode
+			return *r
+		}
+	}
+	r := &store.JoinRequest{
+		ID:          newID(),
+		ScorebookID: bookID,
+		UserID:      userID,
+		Nickname:    nickname,
+		AvatarURL:   avatarURL,
+		Role:        role,
+		InviteCode:  inviteCode,
+		Status:      store.JoinRequestPending,
+		CreatedAt:   s.now(),
+	}
+	s.joinReqs = append(s.joinReqs, r)
+	return *r
+}
+
+// insertJoinedMember mirrors store.insertJoinedMember; the caller holds s.mu.
+func (s *Store) insertJoinedMember(bookID string, userID int64, nickname, avatarURL, role, inviteCode string, resolvedBy *int64) store.Member {
+	m := s.insertMember(bookID, int64Ptr(userID), role, nickname, avatarURL, "")
+	if inviteCode != "" {
+		s.inviteUses = append(s.inviteUses, &inviteUse{
+			BookID: bookID,
+			InviteUse: store.InviteUse{
+				Code:      inviteCode,
+				UserID:    userID,
+				MemberID:  m.ID,
+				CreatedAt: s.now(),
+			},
+		})
+	}
+	for _, r := range s.joinReqs {
+		if r.ScorebookID == bookID && r.UserID == userID && r.Status == store.JoinRequestPending {
+			r.Status = store.JoinRequestApproved
+			r.MemberID = m.ID
+			r.ResolvedByUserID = resolvedBy
+			r.ResolvedAt = timePtr(s.now())
+		}
+	}
+	for _, inv := range s.invitations {
+		if inv.ScorebookID == bookID && inv.UserID == userID && inv.Status == store.InvitationPending {
+			inv.Status = store.InvitationAccepted
+			inv.MemberID = m.ID
+			inv.ResolvedAt = timePtr(s.now())
+		}
+	}
+	s.touch(bookID)
+	return toMember(m)
+}
+
+func (s *Store) ListJoinRequests(ctx context.Context, scorebookID string, userID int64, status string, limit, offset int32) ([]store.JoinRequest, error) {
+	switch status {
+	case "", store.JoinRequestPending, store.JoinRequestApproved, store.JoinRequestRejected:
+	default:
+		return nil, store.ErrInvalidArgument
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	b, err := s.manageableBook(scorebookID, userID)
+	if err != nil {
+		return nil, err
+	}
+	var all []store.JoinRequest
+	for i := len(s.joinReqs) - 1; i >= 0; i-- {
+		r := s.joinReqs[i]
+		if r.ScorebookID == b.ID && (status == "" || r.Status == status) {
+			all = append(all, s.joinRequestOut(r))
+		}
+	}
+	from, to := page(len(all), limit, offset)
+	return all[from:to], nil
+}
+
+func (s *Store) GetMyJoinRequest(ctx context.Context, scorebookID string, userID int64) (store.JoinRequest, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i := len(s.joinReqs) - 1; i >= 0; i-- {
+		if r := s.joinReqs[i]; r.ScorebookID == scorebookID && r.UserID == userID {
+			return s.joinRequestOut(r), nil
+		}
+	}
+	return store.JoinRequest{}, store.ErrNotFound
+}
+
+func (s *Store) ApproveJoinRequest(ctx context.Context, scorebookID string, userID int64, requestID string) (store.JoinRequest, store.Member, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	b, r, err := s.pendingJoinRequest(scorebookID, userID, requestID)
+	if err != nil {
+		return store.JoinRequest{}, store.Member{}, err
+	}
+	if b.Status == "ended" {
+		return store.JoinRequest{}, store.Member{}, store.ErrScorebookEnded
+	}
+	if s.banned(b.ID, r.UserID) {
+		return store.JoinRequest{}, store.Member{}, store.ErrBanned
+	}
+	if r.InviteCode != "" {
+		if err := s.checkInviteUsable(b, r.InviteCode); err != nil {
+			return store.JoinRequest{}, store.Member{}, err
+		}
+	}
+	m := s.insertJoinedMember(b.ID, r.UserID, r.Nickname, r.AvatarURL, r.Role, r.InviteCode, int64Ptr(userID))
+	return s.joinRequestOut(r), m, nil
+}
+
+func (s *Store) RejectJoinRequest(ctx context.Context, scorebookID string, userID int64, requestID string) (store.JoinRequest, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, r, err := s.pendingJoinRequest(scorebookID, userID, requestID)
+	if err != nil {
+		return store.JoinRequest{}, err
+	}
+	r.Status = store.JoinRequestRejected
+	r.ResolvedByUserID = int64Ptr(userID)
+	r.ResolvedAt = timePtr(s.now())
+	return s.joinRequestOut(r), nil
+}
+
+// pendingJoinRequest mirrors store.lockPendingJoinRequest.
+func (s *Store) pendingJoinRequest(scorebookID string, userID int64, requestID string) (*book, *store.JoinRequest, error) {
+	b, err := s.manageableBook(scorebookID, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, r := range s.joinReqs {
+		if r.ID == requestID && r.ScorebookID == b.ID {
+			if r.Status != store.JoinRequestPending {
+				return nil, nil, store.ErrConflict
+			}
+			return b, r, nil
+		}
+	}
+	return nil, nil, store.ErrNotFound
+}
+
+// joinRequestOut copies r; a member that was removed since approval reads as no
+// member, like the ON DELETE SET NULL on member_id.
+func (s *Store) joinRequestOut(r *store.JoinRequest) store.JoinRequest {
+	out := *r
+	if out.MemberID != "" && s.memberByID(r.ScorebookID, out.MemberID) == nil {
+		out.MemberID = ""
+	}
+	return out
+}
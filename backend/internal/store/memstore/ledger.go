@@ -0,0 +1,412 @@
+package memstore
+
+import (
+	"context"
+	"math"
+	"sort"
+	"strings"
+
+	"scorehub/internal/store"
+)
+
+func (s *Store) GetLedger(ctx context.Context, ledgerID string) (store.Scorebook, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	b := s.activeBook(ledgerID, "ledger")
+	if b == nil {
+		return store.Scorebook{}, store.ErrNotFound
+	}
+	return b.Scorebook, nil
+}
+
+func (s *Store) CreateLedger(ctx context.Context, user store.User, name string) (store.Scorebook, store.LedgerMember, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	b := s.insertBook(user.ID, name, "", "ledger")
+	owner := s.insertMember(b.ID, int64Ptr(user.ID), "owner", defaultNickname(user.WeChatNickname, "我"), strings.TrimSpace(user.WeChatAvatarURL), "")
+	return b.Scorebook, toLedgerMember(owner), nil
+}
+
+func (s *Store) ListLedgersForUser(ctx context.Context, userID int64, limit, offset int32) ([]store.LedgerListItem, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var out []store.LedgerListItem
+	for _, b := range s.books {
+		if b.BookType != "ledger" || b.DeletedAt != nil {
+			continue
+		}
+		role := s.ledgerRole(b, userID)
+		if role == "" {
+			if s.memberByUser(b.ID, userID) == nil {
+				continue
+			}
+			role = "member"
+		}
+		var recordCount int64
+		for _, r := range s.records {
+			if r.ScorebookID == b.ID {
+				recordCount++
+			}
+		}
+		out = append(out, store.LedgerListItem{
+			LedgerID:    b.ID,
+			Name:        b.Name,
+			StartTime:   b.StartTime,
+			UpdatedAt:   b.UpdatedAt,
+			Status:      b.Status,
+			EndedAt:     b.EndedAt,
+			MemberCount: int64(len(s.membersOf(b.ID))),
+			RecordCount: recordCount,
+			Role:        role,
+		})
+	}
+	statusRank := func(status string) int {
+		switch status {
+		case "recording":
+			return 0
+		case "ended":
+			return 1
+		}
+		return 2
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		ri, rj := statusRank(out[i].Status), statusRank(out[j].Status)
+		if ri != rj {
+			return ri < rj
+		}
+		return out[i].UpdatedAt.After(out[j].UpdatedAt)
+	})
+	from, to := page(len(out), limit, offset)
+	return out[from:to], nil
+}
+
+func (s *Store) GetLedgerDetail(ctx context.Context, ledgerID string, limit, offset int32) (store.Scorebook, []store.LedgerMember, []store.LedgerRecord, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	b := s.activeBook(ledgerID, "ledger")
+	if b == nil {
+		return store.Scorebook{}, nil, nil, store.ErrNotFound
+	}
+
+	var members []store.LedgerMember
+	ownerID := ""
+	for _, m := range s.membersOf(b.ID) {
+		lm := toLedgerMember(m)
+		if ownerID == "" && lm.Role == "owner" {
+			ownerID = lm.ID
+		}
+		members = append(members, lm)
+	}
+	if ownerID == "" && len(members) > 0 {
+		ownerID = members[0].ID
+		members[0].Role = "owner"
+	}
+
+	var all []*store.ScoreRecord
+	for i := len(s.records) - 1; i >= 0; i-- {
+		if s.records[i].ScorebookID == b.ID {
+			all = append(all, s.records[i])
+		}
+	}
+	from, to := page(len(all), limit, offset)
+
+	var records []store.LedgerRecord
+	for _, sr := range all[from:to] {
+		records = append(records, s.withAuthor(toLedgerRecord(sr, ownerID)))
+	}
+	return b.Scorebook, members, records, nil
+}
+
+func (s *Store) UpdateLedger(ctx context.Context, ledgerID string, userID int64, name *string, shareDisabled *bool) (store.Scorebook, error) {
+	if name == nil && shareDisabled == nil {
+		return store.Scorebook{}, store.ErrInvalidArgument
+	}
+	var newName string
+	if name != nil {
+		newName = strings.TrimSpace(*name)
+		if newName == "" {
+			return store.Scorebook{}, store.ErrInvalidArgument
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	b := s.activeBook(ledgerID, "ledger")
+	if b == nil || b.CreatedByUserID != userID {
+		return store.Scorebook{}, store.ErrForbidden
+	}
+	if newName != "" {
+		b.Name = newName
+	}
+	if shareDisabled != nil {
+		b.ShareDisabled = *shareDisabled
+	}
+	b.UpdatedAt = s.now()
+	return b.Scorebook, nil
+}
+
+func (s *Store) AddLedgerMember(ctx context.Context, ledgerID string, userID int64, nickname, avatarURL, remark string) (store.LedgerMember, error) {
+	if strings.TrimSpace(nickname) == "" {
+		return store.LedgerMember{}, store.ErrInvalidArgument
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	b := s.activeBook(ledgerID, "ledger")
+	if b == nil {
+		return store.LedgerMember{}, store.ErrNotFound
+	}
+	if s.ledgerRole(b, userID) == "" {
+		return store.LedgerMember{}, store.ErrForbidden
+	}
+	if b.Status != "recording" {
+		return store.LedgerMember{}, store.ErrScorebookEnded
+	}
+	m := s.insertMember(b.ID, nil, "member", nickname, avatarURL, remark)
+	s.touch(b.ID)
+	return toLedgerMember(m), nil
+}
+
+func (s *Store) UpdateLedgerMember(ctx context.Context, ledgerID string, userID int64, memberID string, nickname, avatarURL, remark string) (store.LedgerMember, error) {
+	if strings.TrimSpace(memberID) == "" {
+		return store.LedgerMember{}, store.ErrInvalidArgument
+	}
+	if strings.TrimSpace(nickname) == "" {
+		return store.LedgerMember{}, store.ErrInvalidArgument
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	b := s.activeBook(ledgerID, "ledger")
+	if b == nil {
+		return store.LedgerMember{}, store.ErrNotFound
+	}
+	if b.Status != "recording" {
+		return store.LedgerMember{}, store.ErrScorebookEnded
+	}
+	m := s.memberByID(b.ID, memberID)
+	if m == nil {
+		return store.LedgerMember{}, store.ErrNotFound
+	}
+	if s.ledgerRole(b, userID) == "" && (m.UserID == nil || *m.UserID != userID) {
+		return store.LedgerMember{}, store.ErrForbidden
+	}
+	m.Nickname = nickname
+	m.AvatarURL = avatarURL
+	m.Remark = remark
+	m.UpdatedAt = s.now()
+	s.touch(b.ID)
+	return toLedgerMember(m), nil
+}
+
+func (s *Store) BindLedgerMember(ctx context.Context, ledgerID string, userID int64, memberID string, nickname, avatarURL string) (store.LedgerMember, error) {
+	if strings.TrimSpace(memberID) == "" {
+		return store.LedgerMember{}, store.ErrInvalidArgument
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	b := s.activeBook(ledgerID, "ledger")
+	if b == nil {
+		return store.LedgerMember{}, store.ErrNotFound
+	}
+	if b.Status != "recording" {
+		return store.LedgerMember{}, store.ErrScorebookEnded
+	}
+	if b.ShareDisabled {
+		return store.LedgerMember{}, store.ErrForbidden
+	}
+	if s.memberByUser(b.ID, userID) != nil {
+		return store.LedgerMember{}, store.ErrConflict
+	}
+	m := s.memberByID(b.ID, memberID)
+	if m == nil {
+		return store.LedgerMember{}, store.ErrNotFound
+	}
+	if m.UserID != nil {
+		return store.LedgerMember{}, store.ErrConflict
+	}
+	m.UserID = int64Ptr(userID)
+	if nickname != "" {
+		m.Nickname = nickname
+	}
+	if avatarURL != "" {
+		m.AvatarURL = avatarURL
+	}
+	m.UpdatedAt = s.now()
+	s.touch(b.ID)
+	return toLedgerMember(m), nil
+}
+
+func (s *Store) AddLedgerRecord(ctx context.Context, ledgerID string, userID int64, memberID string, recordType string, amount float64, note, categoryID string) (store.LedgerRecord, error) {
+	if strings.TrimSpace(memberID) == "" {
+		return store.LedgerRecord{}, store.ErrInvalidArgument
+	}
+	if recordType != "income" && recordType != "expense" {
+		return store.LedgerRecord{}, store.ErrInvalidArgument
+	}
+	amount, ok := store.NormalizeAmount(amount)
+	if !ok {
+		return store.LedgerRecord{}, store.ErrInvalidArgument
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	b := s.activeBook(ledgerID, "ledger")
+	if b == nil {
+		return store.LedgerRecord{}, store.ErrNotFound
+	}
+	if s.ledgerRole(b, userID) == "" {
+		return store.LedgerRecord{}, store.ErrForbidden
+	}
+	if b.Status != "recording" {
+		return store.LedgerRecord{}, store.ErrScorebookEnded
+	}
+
+	owner := s.memberByID(b.ID, s.ledgerOwnerMemberID(b.ID))
+	if owner == nil {
+		return store.LedgerRecord{}, store.ErrNotFound
+	}
+	if owner.ID == memberID {
+		return store.LedgerRecord{}, store.ErrInvalidArgument
+	}
+	target := s.memberByID(b.ID, memberID)
+	if target == nil {
+		return store.LedgerRecord{}, store.ErrNotFound
+	}
+	if categoryID != "" && s.ledgerCategory(b.ID, categoryID) == nil {
+		return store.LedgerRecord{}, store.ErrInvalidArgument
+	}
+
+	from, to := target, owner
+	delta := amount
+	if recordType == "expense" {
+		delta = -amount
+		from, to = owner, target
+	}
+	r := s.insertRecord(store.ScoreRecord{
+		ScorebookID:     b.ID,
+		FromMemberID:    from.ID,
+		ToMemberID:      to.ID,
+		Delta:           delta,
+		Note:            note,
+		CategoryID:      categoryID,
+		CreatedByUserID: int64Ptr(userID),
+	})
+	s.addScore(to, cents(amount))
+	s.addScore(from, -cents(amount))
+	s.touch(b.ID)
+
+	return s.withAuthor(store.LedgerRecord{
+		ID:              r.ID,
+		LedgerID:        b.ID,
+		FromMemberID:    from.ID,
+		ToMemberID:      to.ID,
+		MemberID:        memberID,
+		Type:            recordType,
+		Amount:          amount,
+		Note:            note,
+		CategoryID:      categoryID,
+		CreatedByUserID: r.CreatedByUserID,
+		CreatedAt:       r.CreatedAt,
+	}), nil
+}
+
+func (s *Store) EndLedger(ctx context.Context, ledgerID string, userID int64) (store.Scorebook, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	b := s.activeBook(ledgerID, "ledger")
+	if b == nil || b.Status != "recording" || b.CreatedByUserID != userID {
+		return store.Scorebook{}, store.ErrNotFound
+	}
+	s.endBook(b)
+	return b.Scorebook, nil
+}
+
+func (s *Store) DeleteLedger(ctx context.Context, ledgerID string, userID int64) (store.Scorebook, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	b := s.findBook(ledgerID)
+	if b == nil || b.BookType != "ledger" {
+		return store.Scorebook{}, store.ErrNotFound
+	}
+	if b.CreatedByUserID != userID {
+		return store.Scorebook{}, store.ErrForbidden
+	}
+	if b.Status != "ended" {
+		return store.Scorebook{}, store.ErrScorebookNotEnded
+	}
+	if b.DeletedAt != nil {
+		return store.Scorebook{}, store.ErrNotFound
+	}
+	now := s.now()
+	b.DeletedAt = timePtr(now)
+	b.UpdatedAt = now
+	return b.Scorebook, nil
+}
+
+func toLedgerMember(m *member) store.LedgerMember {
+	out := store.LedgerMember{
+		ID:        m.ID,
+		LedgerID:  m.BookID,
+		Role:      m.Role,
+		Nickname:  m.Nickname,
+		AvatarURL: m.AvatarURL,
+		Remark:    m.Remark,
+		Score:     amount(m.Cents),
+		CreatedAt: m.JoinedAt,
+		UpdatedAt: m.UpdatedAt,
+	}
+	if m.UserID != nil {
+		out.UserID = int64Ptr(*m.UserID)
+	}
+	return out
+}
+
+// toLedgerRecord mirrors store.setLedgerRecordType.
+func toLedgerRecord(sr *store.ScoreRecord, ownerID string) store.LedgerRecord {
+	r := store.LedgerRecord{
+		ID:              sr.ID,
+		LedgerID:        sr.ScorebookID,
+		FromMemberID:    sr.FromMemberID,
+		ToMemberID:      sr.ToMemberID,
+		Note:            sr.Note,
+		CategoryID:      sr.CategoryID,
+		CreatedByUserID: sr.CreatedByUserID,
+		CreatedAt:       sr.CreatedAt,
+	}
+	if math.Abs(sr.Delta) < 1e-9 {
+		r.Type = "remark"
+		r.MemberID = r.ToMemberID
+		return r
+	}
+	if sr.Delta < 0 {
+		r.Amount = -sr.Delta
+		r.Type = "expense"
+	} else {
+		r.Amount = sr.Delta
+		r.Type = "income"
+	}
+	if ownerID != "" {
+		if r.FromMemberID == ownerID {
+			r.MemberID = r.ToMemberID
+		} else if r.ToMemberID == ownerID {
+			r.MemberID = r.FromMemberID
+		}
+	}
+	if r.MemberID == "" {
+		r.MemberID = r.ToMemberID
+	}
+	return r
+}
@@ -0,0 +1,184 @@
+package memstore
+
+import (
+	"context"
+	"strings"
+
+	"scorehub/internal/store"
+)
+
+func (s *Store) ListLedgerCategories(ctx context.Context, ledgerID string, userID int64) ([]store.LedgerCategory, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	b := s.activeBook(ledgerID, "ledger")
+	if b == nil {
+		return nil, store.ErrNotFound
+	}
+	if s.ledgerRole(b, userID) == "" {
+		return nil, store.ErrForbidden
+	}
+	return s.categoriesOf(b.ID), nil
+}
+
+func (s *Store) CreateLedgerCategory(ctx context.Context, ledgerID string, userID int64, name string) (store.LedgerCategory, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return store.LedgerCategory{}, store.ErrInvalidArgument
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	b, err := s.ownedLedger(ledgerID, userID)
+	if err != nil {
+		return store.LedgerCategory{}, err
+	}
+	if b.Status != "recording" {
+		return store.LedgerCategory{}, store.ErrScorebookEnded
+	}
+	if s.categoryNamed(b.ID, name, "") {
+		return store.LedgerCategory{}, store.ErrConflict
+	}
+	now := s.now()
+	c := &store.LedgerCategory{ID: newID(), LedgerID: b.ID, Name: name, CreatedAt: now, UpdatedAt: now}
+	s.categories = append(s.categories, c)
+	return *c, nil
+}
+
+func (s *Store) UpdateLedgerCategory(ctx context.Context, ledgerID string, userID int64, categoryID, name string) (store.LedgerCategory, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return store.LedgerCategory{}, store.ErrInvalidArgument
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	b, err := s.ownedLedger(ledgerID, userID)
+	if err != nil {
+		return store.LedgerCategory{}, err
+	}
+	if b.Status != "recording" {
+		return store.LedgerCategory{}, store.ErrScorebookEnded
+	}
+	c := s.ledgerCategory(b.ID, categoryID)
+	if c == nil {
+		return store.LedgerCategory{}, store.ErrNotFound
+	}
+	if s.categoryNamed(b.ID, name, c.ID) {
+		return store.LedgerCategory{}, store.ErrConflict
+	}
+	c.Name = name
+	c.UpdatedAt = s.now()
+	return *c, nil
+}
+
+func (s *Store) DeleteLedgerCategory(ctx context.Context, ledgerID string, userID int64, categoryID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	b, err := s.ownedLedger(ledgerID, userID)
+	if err != nil {
+		return err
+	}
+	if b.Status != "recording" {
+		return store.ErrScorebookEnded
+	}
+	for i, c := range s.categories {
+		if c.LedgerID == b.ID && c.ID == categoryID {
+			s.categories = append(s.categories[:i], s.categories[i+1:]...)
+			// ON DELETE SET NULL
+			for _, r := range s.records {
+				if r.CategoryID == categoryID {
+					r.CategoryID = ""
+				}
+			}
+			return nil
+		}
+	}
+	return store.ErrNotFound
+}
+
+func (s *Store) GetLedgerSummary(ctx context.Context, ledgerID string, userID int64) (store.LedgerSummary, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	b, err := s.ownedLedger(ledgerID, userID)
+	if err != nil {
+		return store.LedgerSummary{}, err
+	}
+	var ledgerMembers []store.LedgerMember
+	for _, m := range s.membersOf(b.ID) {
+		ledgerMembers = append(ledgerMembers, toLedgerMember(m))
+	}
+	ownerID := s.ledgerOwnerMemberID(b.ID)
+
+	type key struct{ category, member, typ string }
+	groups := map[key]*store.LedgerSummaryRow{}
+	var order []key
+	for _, sr := range s.records {
+		if sr.ScorebookID != b.ID {
+			continue
+		}
+		r := toLedgerRecord(sr, ownerID)
+		if r.Type == "remark" {
+			continue
+		}
+		k := key{r.CategoryID, r.MemberID, r.Type}
+		g := groups[k]
+		if g == nil {
+			g = &store.LedgerSummaryRow{CategoryID: r.CategoryID, MemberID: r.MemberID, Type: r.Type}
+			groups[k] = g
+			order = append(order, k)
+		}
+		g.Records++
+		g.Amount = amount(cents(g.Amount) + cents(r.Amount))
+	}
+	rows := make([]store.LedgerSummaryRow, 0, len(order))
+	for _, k := range order {
+		rows = append(rows, *groups[k])
+	}
+	return store.SummarizeLedger(s.categoriesOf(b.ID), ledgerMembers, rows), nil
+}
+
+// ownedLedger returns the non-deleted ledger if userID owns it.
+func (s *Store) ownedLedger(ledgerID string, userID int64) (*book, error) {
+	b := s.activeBook(ledgerID, "ledger")
+	if b == nil {
+		return nil, store.ErrNotFound
+	}
+	if b.CreatedByUserID != userID {
+		return nil, store.ErrForbidden
+	}
+	return b, nil
+}
+
+func (s *Store) categoriesOf(ledgerID string) []store.LedgerCategory {
+	out := []store.LedgerCategory{}
+	for _, c := range s.categories {
+		if c.LedgerID == ledgerID {
+			out = append(out, *c)
+		}
+	}
+	return out
+}
+
+func (s *Store) ledgerCategory(ledgerID, id string) *store.LedgerCategory {
+	for _, c := range s.categories {
+		if c.LedgerID == ledgerID && c.ID == id {
+			return c
+		}
+	}
+	return nil
+}
+
+// categoryNamed reports whether another category of the ledger already has the name.
+func (s *Store) categoryNamed(ledgerID, name, exceptID string) bool {
+	for _, c := range s.categories {
+		if c.LedgerID == ledgerID && c.ID != exceptID && c.Name == name {
+			return true
+		}
+	}
+	return false
+}
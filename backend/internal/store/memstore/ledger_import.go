@@ -0,0 +1,75 @@
+package memstore
+
+import (
+	"context"
+
+	"scorehub/internal/store"
+)
+
+func (s *Store) ImportLedgerRecords(ctx context.Context, ledgerID string, userID int64, rows []store.LedgerImportRow, separate bool) (store.LedgerImportPlan, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	b := s.activeBook(ledgerID, "ledger")
+	if b == nil {
+		return store.LedgerImportPlan{}, store.ErrNotFound
+	}
+	if b.CreatedByUserID != userID {
+		return store.LedgerImportPlan{}, store.ErrForbidden
+	}
+	if b.Status != "recording" {
+		return store.LedgerImportPlan{}, store.ErrScorebookEnded
+	}
+
+	var members []store.LedgerMember
+	var owner *member
+	for _, m := range s.membersOf(b.ID) {
+		if m.Role == "owner" && owner == nil {
+			owner = m
+		}
+		members = append(members, toLedgerMember(m))
+	}
+	if owner == nil && len(members) > 0 {
+		owner = s.memberByID(b.ID, members[0].ID)
+	}
+	if owner == nil {
+		return store.LedgerImportPlan{}, store.ErrNotFound
+	}
+
+	plan := store.PlanLedgerImport(members, owner.ID, rows, separate)
+	if plan.Errors > 0 {
+		return plan, store.ErrInvalidArgument
+	}
+
+	created := map[string]*member{}
+	for i := range plan.Rows {
+		r := &plan.Rows[i]
+		target := s.memberByID(b.ID, r.MemberID)
+		if r.NewMember {
+			target = created[r.Name]
+			if target == nil || separate {
+				target = s.insertMember(b.ID, nil, "member", r.Name, "", r.Remark)
+				created[r.Name] = target
+			}
+			r.MemberID = target.ID
+		}
+
+		from, to := target, owner
+		delta := r.Amount
+		if r.Type == "expense" {
+			from, to = owner, target
+			delta = -r.Amount
+		}
+		s.insertRecord(store.ScoreRecord{
+			ScorebookID:     b.ID,
+			FromMemberID:    from.ID,
+			ToMemberID:      to.ID,
+			Delta:           delta,
+			CreatedByUserID: int64Ptr(userID),
+		})
+		s.addScore(to, cents(r.Amount))
+		s.addScore(from, -cents(r.Amount))
+	}
+	s.touch(b.ID)
+	return plan, nil
+}
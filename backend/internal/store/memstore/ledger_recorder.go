@@ -0,0 +1,188 @@
+package memstore
+
+import (
+	"context"
+
+	"scorehub/internal/store"
+)
+
+func (s *Store) GetLedgerRole(ctx context.Context, ledgerID string, userID int64) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	b := s.activeBook(ledgerID, "ledger")
+	if b == nil {
+		return "", store.ErrNotFound
+	}
+	return s.ledgerRole(b, userID), nil
+}
+
+func (s *Store) ListLedgerRecorders(ctx context.Context, ledgerID string, userID int64) ([]store.LedgerRecorder, string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	b := s.activeBook(ledgerID, "ledger")
+	if b == nil {
+		return nil, "", store.ErrNotFound
+	}
+	role := s.ledgerRole(b, userID)
+	if role == "" {
+		return nil, "", store.ErrForbidden
+	}
+	code := ""
+	if role == store.LedgerRoleOwner {
+		code = b.RecorderInviteCode
+	}
+	out := []store.LedgerRecorder{}
+	for _, r := range s.recorders {
+		if r.LedgerID == b.ID && r.UserID != b.CreatedByUserID {
+			out = append(out, s.toLedgerRecorder(r))
+		}
+	}
+	return out, code, nil
+}
+
+func (s *Store) ResetLedgerRecorderInvite(ctx context.Context, ledgerID string, userID int64, enabled bool) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	b, err := s.ownedLedger(ledgerID, userID)
+	if err != nil {
+		return "", err
+	}
+	if b.Status != "recording" {
+		return "", store.ErrScorebookEnded
+	}
+	b.RecorderInviteCode = ""
+	if enabled {
+		for b.RecorderInviteCode == "" || s.recorderCodeTaken(b) {
+			b.RecorderInviteCode = randomInviteCode(8)
+		}
+	}
+	return b.RecorderInviteCode, nil
+}
+
+func (s *Store) GetLedgerRecorderInvite(ctx context.Context, code string) (store.LedgerRecorderInvite, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	b := s.recorderInviteBook(code)
+	if b == nil {
+		return store.LedgerRecorderInvite{}, store.ErrNotFound
+	}
+	return s.toLedgerRecorderInvite(b), nil
+}
+
+func (s *Store) AcceptLedgerRecorderInvite(ctx context.Context, code string, userID int64) (store.LedgerRecorder, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	b := s.recorderInviteBook(code)
+	if b == nil {
+		return store.LedgerRecorder{}, store.ErrNotFound
+	}
+	if b.CreatedByUserID == userID {
+		return store.LedgerRecorder{}, store.ErrConflict
+	}
+	if b.Status != "recording" {
+		return store.LedgerRecorder{}, store.ErrScorebookEnded
+	}
+	if s.users[userID] == nil {
+		return store.LedgerRecorder{}, store.ErrNotFound
+	}
+	for _, r := range s.recorders {
+		if r.LedgerID == b.ID && r.UserID == userID {
+			return s.toLedgerRecorder(r), nil
+		}
+	}
+	r := &ledgerRecorder{LedgerID: b.ID, UserID: userID, CreatedAt: s.now()}
+	s.recorders = append(s.recorders, r)
+	return s.toLedgerRecorder(r), nil
+}
+
+func (s *Store) RemoveLedgerRecorder(ctx context.Context, ledgerID string, userID, recorderUserID int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	b := s.activeBook(ledgerID, "ledger")
+	if b == nil {
+		return store.ErrNotFound
+	}
+	role := s.ledgerRole(b, userID)
+	if role != store.LedgerRoleOwner && (role != store.LedgerRoleRecorder || userID != recorderUserID) {
+		return store.ErrForbidden
+	}
+	for i, r := range s.recorders {
+		if r.LedgerID == b.ID && r.UserID == recorderUserID {
+			s.recorders = append(s.recorders[:i], s.recorders[i+1:]...)
+			return nil
+		}
+	}
+	return store.ErrNotFound
+}
+
+// ledgerRole mirrors the SQL store: "owner", "recorder" or "".
+func (s *Store) ledgerRole(b *book, userID int64) string {
+	if b.CreatedByUserID == userID {
+		return store.LedgerRoleOwner
+	}
+	for _, r := range s.recorders {
+		if r.LedgerID == b.ID && r.UserID == userID {
+			return store.LedgerRoleRecorder
+		}
+	}
+	return ""
+}
+
+func (s *Store) recorderInviteBook(code string) *book {
+	if code == "" {
+		return nil
+	}
+	for _, b := range s.books {
+		if b.RecorderInviteCode == code && b.BookType == "ledger" && b.DeletedAt == nil {
+			return b
+		}
+	}
+	return nil
+}
+
+func (s *Store) recorderCodeTaken(b *book) bool {
+	for _, other := range s.books {
+		if other != b && other.RecorderInviteCode == b.RecorderInviteCode {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Store) toLedgerRecorder(r *ledgerRecorder) store.LedgerRecorder {
+	out := store.LedgerRecorder{LedgerID: r.LedgerID, UserID: r.UserID, CreatedAt: r.CreatedAt}
+	if u := s.users[r.UserID]; u != nil {
+		out.Nickname = u.WeChatNickname
+		out.AvatarURL = u.WeChatAvatarURL
+	}
+	return out
+}
+
+func (s *Store) toLedgerRecorderInvite(b *book) store.LedgerRecorderInvite {
+	inv := store.LedgerRecorderInvite{
+		LedgerID:    b.ID,
+		LedgerName:  b.Name,
+		Status:      b.Status,
+		OwnerUserID: b.CreatedByUserID,
+	}
+	if u := s.users[b.CreatedByUserID]; u != nil {
+		inv.OwnerNickname = u.WeChatNickname
+	}
+	return inv
+}
+
+// withAuthor fills in the nickname of the user who entered the record.
+func (s *Store) withAuthor(r store.LedgerRecord) store.LedgerRecord {
+	if r.CreatedByUserID != nil {
+		if u := s.users[*r.CreatedByUserID]; u != nil {
+			r.CreatedByNickname = u.WeChatNickname
+		}
+	}
+	return r
+}
@@ -0,0 +1,155 @@
+package memstore
+
+import (
+	"context"
+
+	"scorehub/internal/store"
+)
+
+func (s *Store) UpdateLedgerRecord(ctx context.Context, ledgerID string, userID int64, recordID string, in store.LedgerRecordUpdate) (store.LedgerRecord, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	b, sr, ownerID, err := s.editableLedgerRecord(ledgerID, userID, recordID)
+	if err != nil {
+		return store.LedgerRecord{}, err
+	}
+	before := toLedgerRecord(sr, ownerID)
+	after, err := store.ApplyLedgerRecordUpdate(before, in, ownerID)
+	if err != nil {
+		return store.LedgerRecord{}, err
+	}
+	if after.Snapshot() == before.Snapshot() {
+		return s.withAuthor(before), nil
+	}
+	if after.MemberID != before.MemberID && s.memberByID(b.ID, after.MemberID) == nil {
+		return store.LedgerRecord{}, store.ErrNotFound
+	}
+	if after.CategoryID != "" && after.CategoryID != before.CategoryID && s.ledgerCategory(b.ID, after.CategoryID) == nil {
+		return store.LedgerRecord{}, store.ErrInvalidArgument
+	}
+
+	s.moveLedgerRecord(b.ID, before, -1)
+	s.moveLedgerRecord(b.ID, after, 1)
+	sr.FromMemberID = after.FromMemberID
+	sr.ToMemberID = after.ToMemberID
+	sr.Note = after.Note
+	sr.CategoryID = after.CategoryID
+	switch after.Type {
+	case "income":
+		sr.Delta = after.Amount
+	case "expense":
+		sr.Delta = -after.Amount
+	}
+	snapshot := after.Snapshot()
+	s.insertRevision(b.ID, sr.ID, store.RevisionUpdate, userID, before.Snapshot(), &snapshot)
+	s.touch(b.ID)
+	return s.withAuthor(after), nil
+}
+
+func (s *Store) DeleteLedgerRecord(ctx context.Context, ledgerID string, userID int64, recordID string) (store.LedgerRecord, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	b, sr, ownerID, err := s.editableLedgerRecord(ledgerID, userID, recordID)
+	if err != nil {
+		return store.LedgerRecord{}, err
+	}
+	before := toLedgerRecord(sr, ownerID)
+	for i, r := range s.records {
+		if r == sr {
+			s.records = append(s.records[:i], s.records[i+1:]...)
+			break
+		}
+	}
+	s.moveLedgerRecord(b.ID, before, -1)
+	s.insertRevision(b.ID, sr.ID, store.RevisionDelete, userID, before.Snapshot(), nil)
+	s.touch(b.ID)
+	return s.withAuthor(before), nil
+}
+
+func (s *Store) ListRecordRevisions(ctx context.Context, ledgerID string, userID int64, recordID string, limit, offset int32) ([]store.RecordRevision, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	b, err := s.ownedLedger(ledgerID, userID)
+	if err != nil {
+		return nil, err
+	}
+	out := []store.RecordRevision{}
+	for i := len(s.revisions) - 1; i >= 0; i-- {
+		rv := s.revisions[i]
+		if rv.LedgerID != b.ID || (recordID != "" && rv.RecordID != recordID) {
+			continue
+		}
+		item := *rv
+		if item.ChangedByUserID != nil {
+			if u, ok := s.users[*item.ChangedByUserID]; ok {
+				item.ChangedByNickname = u.WeChatNickname
+			}
+		}
+		out = append(out, item)
+	}
+	from, to := page(len(out), limit, offset)
+	return out[from:to], nil
+}
+
+// editableLedgerRecord returns the record of a recording ledger owned by userID,
+// with the ledger's owner member id.
+func (s *Store) editableLedgerRecord(ledgerID string, userID int64, recordID string) (*book, *store.ScoreRecord, string, error) {
+	b, err := s.ownedLedger(ledgerID, userID)
+	if err != nil {
+		return nil, nil, "", err
+	}
+	if b.Status != "recording" {
+		return nil, nil, "", store.ErrScorebookEnded
+	}
+	for _, r := range s.records {
+		if r.ScorebookID == b.ID && r.ID == recordID {
+			return b, r, s.ledgerOwnerMemberID(b.ID), nil
+		}
+	}
+	return nil, nil, "", store.ErrNotFound
+}
+
+// ledgerOwnerMemberID mirrors the owner member lookup of the SQL store: the owner
+// role, else the first member to join.
+func (s *Store) ledgerOwnerMemberID(bookID string) string {
+	members := s.membersOf(bookID)
+	for _, m := range members {
+		if m.Role == "owner" {
+			return m.ID
+		}
+	}
+	if len(members) > 0 {
+		return members[0].ID
+	}
+	return ""
+}
+
+// moveLedgerRecord applies (sign 1) or undoes (sign -1) a record's effect on scores.
+func (s *Store) moveLedgerRecord(bookID string, r store.LedgerRecord, sign int64) {
+	c := cents(r.Amount)
+	if c == 0 {
+		return
+	}
+	if to := s.memberByID(bookID, r.ToMemberID); to != nil {
+		s.addScore(to, sign*c)
+	}
+	if from := s.memberByID(bookID, r.FromMemberID); from != nil {
+		s.addScore(from, -sign*c)
+	}
+}
+
+func (s *Store) insertRevision(bookID, recordID, action string, userID int64, before store.LedgerRecordSnapshot, after *store.LedgerRecordSnapshot) {
+	s.revisions = append(s.revisions, &store.RecordRevision{
+		ID:              newID(),
+		LedgerID:        bookID,
+		RecordID:        recordID,
+		Action:          action,
+		ChangedByUserID: int64Ptr(userID),
+		Before:          before,
+		After:           after,
+		CreatedAt:       s.now(),
+	})
+}
@@ -0,0 +1,193 @@
+package memstore
+
+import (
+	"context"
+	"sort"
+
+	"scorehub/internal/store"
+)
+
+func (s *Store) SetMemberRole(ctx context.Context, scorebookID string, userID int64, memberID, role string) (store.Member, error) {
+	if role != store.RoleAdmin && role != store.RoleMember && role != store.RoleSpectator {
+		return store.Member{}, store.ErrInvalidArgument
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	b, me := s.bookMember(scorebookID, userID)
+	if me == nil {
+		return store.Member{}, store.ErrNotFound
+	}
+	if me.Role != store.RoleOwner {
+		return store.Member{}, store.ErrForbidden
+	}
+	target := s.memberByID(b.ID, memberID)
+	if target == nil || target.UserID == nil {
+		return store.Member{}, store.ErrNotFound
+	}
+	if target.Role == store.RoleOwner {
+		return store.Member{}, store.ErrInvalidArgument
+	}
+	target.Role = role
+	target.UpdatedAt = s.now()
+	s.touch(b.ID)
+	return toMember(target), nil
+}
+
+func (s *Store) RemoveMember(ctx context.Context, scorebookID string, userID int64, memberID string, ban bool) (store.Member, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	b, me := s.bookMember(scorebookID, userID)
+	if me == nil {
+		return store.Member{}, false, store.ErrNotFound
+	}
+	if !store.CanManage(me.Role) {
+		return store.Member{}, false, store.ErrForbidden
+	}
+	target := s.memberByID(b.ID, memberID)
+	if target == nil || (target.UserID == nil && !target.Placeholder) {
+		return store.Member{}, false, store.ErrNotFound
+	}
+	if target.ID == me.ID {
+		return store.Member{}, false, store.ErrInvalidArgument
+	}
+	if target.Role == store.RoleOwner || (target.Role == store.RoleAdmin && me.Role != store.RoleOwner) {
+		return store.Member{}, false, store.ErrForbidden
+	}
+
+	out := toMember(target)
+	if t := s.pendingTransfer(b.ID); t != nil && t.ToMemberID == target.ID {
+		t.Status = store.TransferCancelled
+		t.ResolvedAt = timePtr(s.now())
+	}
+	kept := s.memberReferenced(target.ID)
+	if kept {
+		target.UserID = nil
+		target.Role = store.RoleMember
+		target.Placeholder = false
+		target.UpdatedAt = s.now()
+	} else {
+		for i, m := range s.members {
+			if m == target {
+				s.members = append(s.members[:i], s.members[i+1:]...)
+				break
+			}
+		}
+	}
+	if ban && !out.Placeholder && !s.banned(b.ID, out.UserID) {
+		s.bans = append(s.bans, &store.ScorebookBan{
+			ScorebookID:    b.ID,
+			UserID:         out.UserID,
+			Nickname:       out.Nickname,
+			BannedByUserID: userID,
+			CreatedAt:      s.now(),
+		})
+	}
+	s.touch(b.ID)
+	return out, kept, nil
+}
+
+func (s *Store) ListBans(ctx context.Context, scorebookID string, userID int64) ([]store.ScorebookBan, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	b, me := s.bookMember(scorebookID, userID)
+	if me == nil {
+		return nil, store.ErrNotFound
+	}
+	if !store.CanManage(me.Role) {
+		return nil, store.ErrForbidden
+	}
+	var out []store.ScorebookBan
+	for _, ban := range s.bans {
+		if ban.ScorebookID == b.ID {
+			out = append(out, *ban)
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].CreatedAt.After(out[j].CreatedAt) })
+	return out, nil
+}
+
+func (s *Store) ListManagerUserIDs(ctx context.Context, scorebookID string) ([]int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var out []int64
+	for _, m := range s.members {
+		if m.BookID == scorebookID && m.UserID != nil && store.CanManage(m.Role) {
+			out = append(out, *m.UserID)
+		}
+	}
+	return out, nil
+}
+
+func (s *Store) Unban(ctx context.Context, scorebookID string, userID int64, bannedUserID int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	b, me := s.bookMember(scorebookID, userID)
+	if me == nil {
+		return store.ErrNotFound
+	}
+	if !store.CanManage(me.Role) {
+		return store.ErrForbidden
+	}
+	for i, ban := range s.bans {
+		if ban.ScorebookID == b.ID && ban.UserID == bannedUserID {
+			s.bans = append(s.bans[:i], s.bans[i+1:]...)
+			return nil
+		}
+	}
+	return store.ErrNotFound
+}
+
+// bookMember returns a non-deleted scorebook and the user's membership in it;
+// the member is nil if either is missing.
+func (s *Store) bookMember(scorebookID string, userID int64) (*book, *member) {
+	b := s.activeBook(scorebookID, "scorebook")
+	if b == nil {
+		return nil, nil
+	}
+	return b, s.memberByUser(b.ID, userID)
+}
+
+func (s *Store) banned(bookID string, userID int64) bool {
+	for _, ban := range s.bans {
+		if ban.ScorebookID == bookID && ban.UserID == userID {
+			return true
+		}
+	}
+	return false
+}
+
+// memberReferenced reports whether records, rounds or settlements point at the member.
+func (s *Store) memberReferenced(memberID string) bool {
+	for _, r := range s.records {
+		if r.FromMemberID == memberID || r.ToMemberID == memberID || r.VoidedByMemberID == memberID {
+			return true
+		}
+	}
+	for _, r := range s.rounds {
+		if r.CreatedByMemberID == memberID {
+			return true
+		}
+	}
+	for _, t := range s.settlements {
+		if t.FromMemberID == memberID || t.ToMemberID == memberID || t.PaidByMemberID == memberID {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Store) activeMemberCount(bookID string) int {
+	n := 0
+	for _, m := range s.membersOf(bookID) {
+		if m.UserID != nil {
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,179 @@
+// Package memstore is an in-memory implementation of the store repositories.
+//
+// It mirrors the SQL behaviour of *store.Store closely enough (ordering, ownership
+// checks and the returned store.Err* values) for the HTTP handlers to be exercised
+// end to end without PostgreSQL. It is not meant for production use.
+package memstore
+
+import (
+	"crypto/rand"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"scorehub/internal/store"
+)
+
+type Store struct {
+	mu sync.Mutex
+
+	// Now returns the current time; tests may replace it to move the clock.
+	Now func() time.Time
+
+	nextUserID int64
+	users      map[int64]*store.User
+	openIDs    map[string]int64
+	sessions   []*session
+
+	books       []*book
+	members     []*member
+	records     []*store.ScoreRecord
+	rounds      []*store.ScoreRound
+	settlements []*store.SettlementTransfer
+	bans        []*store.ScorebookBan
+	transfers   []*store.OwnershipTransfer
+	inviteUses  []*inviteUse
+	joinReqs    []*store.JoinRequest
+	invitations []*store.ScorebookInvitation
+	templates   []*store.ScorebookTemplate
+	pointRates  []*store.PointRate
+	categories  []*store.LedgerCategory
+	revisions   []*store.RecordRevision
+	recorders   []*ledgerRecorder
+	// retiredInvites maps a regenerated invite code to its book id
+	retiredInvites map[string]string
+	events         map[string][]store.ScorebookEvent
+	eventSeq       map[string]int64
+
+	birthdays []*store.BirthdayContact
+	people    []*store.Person
+	// birthdayPeople maps a birthday contact id to its linked person id
+	birthdayPeople  map[string]string
+	depositAccounts []*store.DepositAccount
+	depositRecords  []*store.DepositRecord
+}
+
+// book is a scorebooks row; it backs both scorebooks and ledgers.
+type book struct {
+	store.Scorebook
+	DeletedAt *time.Time
+
+	InviteExpiresAt *time.Time
+	InviteMaxUses   int
+	JoinApproval    bool
+	Game            store.ScorebookGame
+	// RecorderInviteCode 为空表示未开启记账员邀请
+	RecorderInviteCode string
+}
+
+// ledgerRecorder is a ledger_recorders row.
+type ledgerRecorder struct {
+	LedgerID  string
+	UserID    int64
+	CreatedAt time.Time
+}
+
+// inviteUse is an invite_uses row.
+type inviteUse struct {
+	store.InviteUse
+	BookID string
+}
+
+// member is a scorebook_members row. Ledger members may have no user.
+type member struct {
+	ID        string
+	BookID    string
+	UserID    *int64
+	Role      string
+	Nickname  string
+	AvatarURL string
+	Remark    string
+	PersonID  string
+	Cents     int64
+	JoinedAt  time.Time
+	UpdatedAt time.Time
+
+	Placeholder bool
+}
+
+var (
+	_ store.UserRepo      = (*Store)(nil)
+	_ store.ScorebookRepo = (*Store)(nil)
+	_ store.LedgerRepo    = (*Store)(nil)
+	_ store.TemplateRepo  = (*Store)(nil)
+	_ store.BirthdayRepo  = (*Store)(nil)
+	_ store.DepositRepo   = (*Store)(nil)
+	_ store.PeopleRepo    = (*Store)(nil)
+	_ store.EventLog      = (*Store)(nil)
+)
+
+func New() *Store {
+	return &Store{
+		Now:            time.Now,
+		users:          map[int64]*store.User{},
+		openIDs:        map[string]int64{},
+		events:         map[string][]store.ScorebookEvent{},
+		retiredInvites: map[string]string{},
+		eventSeq:       map[string]int64{},
+		birthdayPeople: map[string]string{},
+	}
+}
+
+func newID() string {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		panic(err)
+	}
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
+
+func randomInviteCode(n int) string {
+	const alphabet = "23456789ABCDEFGHJKLMNPQRSTUVWXYZ"
+	var b = make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	for i := range b {
+		b[i] = alphabet[int(b[i])%len(alphabet)]
+	}
+	return string(b)
+}
+
+func (s *Store) now() time.Time {
+	return s.Now()
+}
+
+// page applies LIMIT/OFFSET semantics to n items and returns the [from, to) range.
+func page(n int, limit, offset int32) (int, int) {
+	from := int(offset)
+	if from < 0 {
+		from = 0
+	}
+	if from > n {
+		from = n
+	}
+	to := n
+	if limit >= 0 && from+int(limit) < n {
+		to = from + int(limit)
+	}
+	return from, to
+}
+
+func cents(v float64) int64 {
+	return int64(math.Round(v * 100))
+}
+
+func amount(c int64) float64 {
+	return float64(c) / 100
+}
+
+func int64Ptr(v int64) *int64 {
+	return &v
+}
+
+func timePtr(t time.Time) *time.Time {
+	return &t
+}
@@ -0,0 +1,273 @@
+package memstore
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"scorehub/internal/store"
+)
+
+func (s *Store) CreatePerson(ctx context.Context, userID int64, name, note string) (store.Person, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return store.Person{}, store.ErrInvalidArgument
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	p := &store.Person{ID: newID(), UserID: userID, Name: name, Note: strings.TrimSpace(note), CreatedAt: now, UpdatedAt: now}
+	s.people = append(s.people, p)
+	return *p, nil
+}
+
+func (s *Store) ListPeople(ctx context.Context, userID int64, query string, limit, offset int32) ([]store.Person, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	query = strings.TrimSpace(query)
+	out := []store.Person{}
+	for _, p := range s.people {
+		if p.UserID == userID && strings.Contains(p.Name, query) {
+			out = append(out, *p)
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		if out[i].Name != out[j].Name {
+			return out[i].Name < out[j].Name
+		}
+		return out[i].CreatedAt.Before(out[j].CreatedAt)
+	})
+	from, to := page(len(out), limit, offset)
+	return out[from:to], nil
+}
+
+func (s *Store) GetPerson(ctx context.Context, userID int64, personID string) (store.Person, store.PersonLinks, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	p := s.person(userID, personID)
+	if p == nil {
+		return store.Person{}, store.PersonLinks{}, store.ErrNotFound
+	}
+
+	links := store.PersonLinks{LedgerMembers: []store.PersonLedgerMember{}, BirthdayContacts: []store.PersonBirthdayContact{}}
+	for _, b := range s.personLedgers(userID) {
+		for _, m := range s.membersOf(b.ID) {
+			if m.PersonID == p.ID {
+				links.LedgerMembers = append(links.LedgerMembers, store.PersonLedgerMember{
+					LedgerID:     b.ID,
+					LedgerName:   b.Name,
+					LedgerStatus: b.Status,
+					MemberID:     m.ID,
+					Nickname:     m.Nickname,
+					Remark:       m.Remark,
+				})
+			}
+		}
+	}
+	for _, c := range s.birthdays {
+		if c.UserID == userID && s.birthdayPeople[c.ID] == p.ID {
+			links.BirthdayContacts = append(links.BirthdayContacts, store.PersonBirthdayContact{ID: c.ID, Name: c.Name, Relation: c.Relation})
+		}
+	}
+	return *p, links, nil
+}
+
+func (s *Store) UpdatePerson(ctx context.Context, userID int64, personID string, name, note *string) (store.Person, error) {
+	if name != nil && strings.TrimSpace(*name) == "" {
+		return store.Person{}, store.ErrInvalidArgument
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	p := s.person(userID, personID)
+	if p == nil {
+		return store.Person{}, store.ErrNotFound
+	}
+	if name != nil {
+		p.Name = strings.TrimSpace(*name)
+	}
+	if note != nil {
+		p.Note = strings.TrimSpace(*note)
+	}
+	p.UpdatedAt = s.now()
+	return *p, nil
+}
+
+func (s *Store) DeletePerson(ctx context.Context, userID int64, personID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, p := range s.people {
+		if p.ID == personID && p.UserID == userID {
+			s.people = append(s.people[:i], s.people[i+1:]...)
+			// ON DELETE SET NULL
+			for _, m := range s.members {
+				if m.PersonID == personID {
+					m.PersonID = ""
+				}
+			}
+			for contactID, id := range s.birthdayPeople {
+				if id == personID {
+					delete(s.birthdayPeople, contactID)
+				}
+			}
+			return nil
+		}
+	}
+	return store.ErrNotFound
+}
+
+func (s *Store) LinkLedgerMember(ctx context.Context, userID int64, personID, ledgerID, memberID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	p := s.person(userID, personID)
+	if p == nil {
+		return store.ErrNotFound
+	}
+	b, err := s.ownedLedger(ledgerID, userID)
+	if err != nil {
+		return err
+	}
+	if memberID == s.ledgerOwnerMemberID(b.ID) {
+		return store.ErrInvalidArgument
+	}
+	m := s.memberByID(b.ID, memberID)
+	if m == nil {
+		return store.ErrNotFound
+	}
+	m.PersonID = p.ID
+	return nil
+}
+
+func (s *Store) UnlinkLedgerMember(ctx context.Context, userID int64, personID, memberID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	p := s.person(userID, personID)
+	if p == nil {
+		return store.ErrNotFound
+	}
+	for _, m := range s.members {
+		if m.ID == memberID && m.PersonID == p.ID {
+			m.PersonID = ""
+			return nil
+		}
+	}
+	return store.ErrNotFound
+}
+
+func (s *Store) LinkBirthdayContact(ctx context.Context, userID int64, personID, contactID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	p := s.person(userID, personID)
+	if p == nil {
+		return store.ErrNotFound
+	}
+	c := s.birthday(userID, contactID)
+	if c == nil {
+		return store.ErrNotFound
+	}
+	s.birthdayPeople[c.ID] = p.ID
+	return nil
+}
+
+func (s *Store) UnlinkBirthdayContact(ctx context.Context, userID int64, personID, contactID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	p := s.person(userID, personID)
+	if p == nil {
+		return store.ErrNotFound
+	}
+	c := s.birthday(userID, contactID)
+	if c == nil || s.birthdayPeople[c.ID] != p.ID {
+		return store.ErrNotFound
+	}
+	delete(s.birthdayPeople, c.ID)
+	return nil
+}
+
+func (s *Store) GetPersonHistory(ctx context.Context, userID int64, personID string) (store.PersonHistory, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	p := s.person(userID, personID)
+	if p == nil {
+		return store.PersonHistory{}, store.ErrNotFound
+	}
+
+	ledgers := map[string]*book{}
+	for _, b := range s.personLedgers(userID) {
+		ledgers[b.ID] = b
+	}
+	linked := map[string]bool{}
+	for _, m := range s.members {
+		if m.PersonID == p.ID && ledgers[m.BookID] != nil {
+			linked[m.ID] = true
+		}
+	}
+
+	var entries []store.PersonHistoryEntry
+	for i := len(s.records) - 1; i >= 0; i-- {
+		r := s.records[i]
+		b := ledgers[r.ScorebookID]
+		if b == nil || r.Delta == 0 {
+			continue
+		}
+		memberID := ""
+		switch {
+		case linked[r.FromMemberID]:
+			memberID = r.FromMemberID
+		case linked[r.ToMemberID]:
+			memberID = r.ToMemberID
+		default:
+			continue
+		}
+		e := store.PersonHistoryEntry{
+			LedgerID:   b.ID,
+			LedgerName: b.Name,
+			RecordID:   r.ID,
+			MemberID:   memberID,
+			Direction:  "received",
+			Amount:     r.Delta,
+			Note:       r.Note,
+			CreatedAt:  r.CreatedAt,
+		}
+		if r.Delta < 0 {
+			e.Direction = "given"
+			e.Amount = -r.Delta
+		}
+		entries = append(entries, e)
+	}
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].CreatedAt.After(entries[j].CreatedAt) })
+	return store.SummarizePersonHistory(*p, entries), nil
+}
+
+func (s *Store) person(userID int64, id string) *store.Person {
+	for _, p := range s.people {
+		if p.ID == id && p.UserID == userID {
+			return p
+		}
+	}
+	return nil
+}
+
+// personLedgers returns the non-deleted ledgers owned by userID, newest first.
+func (s *Store) personLedgers(userID int64) []*book {
+	var out []*book
+	for i := len(s.books) - 1; i >= 0; i-- {
+		b := s.books[i]
+		if b.BookType == "ledger" && b.DeletedAt == nil && b.CreatedByUserID == userID {
+			out = append(out, b)
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].StartTime.After(out[j].StartTime) })
+	return out
+}
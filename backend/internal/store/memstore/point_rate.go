@@ -0,0 +1,136 @@
+package memstore
+
+import (
+	"context"
+	"time"
+
+	"scorehub/internal/store"
+)
+
+func (s *Store) UpdatePointRate(ctx context.Context, scorebookID string, userID int64, in store.PointRateUpdate) (store.PointRate, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	b, me := s.bookMember(scorebookID, userID)
+	if me == nil {
+		return store.PointRate{}, false, store.ErrNotFound
+	}
+	if !store.CanManage(me.Role) {
+		return store.PointRate{}, false, store.ErrForbidden
+	}
+	if b.Status != "recording" {
+		return store.PointRate{}, false, store.ErrScorebookEnded
+	}
+
+	pointValue, currency := b.Game.PointValue, b.Game.Currency
+	if in.PointValue != nil {
+		pointValue = *in.PointValue
+	}
+	if in.Currency != nil && *in.Currency != currency {
+		for _, r := range s.records {
+			if r.ScorebookID == b.ID {
+				return store.PointRate{}, false, store.ErrCurrencyLocked
+			}
+		}
+		currency = *in.Currency
+	}
+	if pointValue == b.Game.PointValue && currency == b.Game.Currency {
+		var cur store.PointRate
+		for _, r := range s.pointRates {
+			if r.ScorebookID == b.ID {
+				cur = *r
+			}
+		}
+		return cur, false, nil
+	}
+
+	b.Game.PointValue = pointValue
+	b.Game.Currency = currency
+	b.UpdatedAt = s.now()
+	r := &store.PointRate{
+		ID:              newID(),
+		ScorebookID:     b.ID,
+		PointValue:      pointValue,
+		Currency:        currency,
+		ChangedByUserID: userID,
+		EffectiveAt:     s.now(),
+	}
+	s.pointRates = append(s.pointRates, r)
+	return *r, true, nil
+}
+
+func (s *Store) ListPointRates(ctx context.Context, scorebookID string, userID int64) ([]store.PointRate, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	b, me := s.bookMember(scorebookID, userID)
+	if me == nil {
+		return nil, store.ErrNotFound
+	}
+	var out []store.PointRate
+	for _, r := range s.pointRates {
+		if r.ScorebookID == b.ID {
+			out = append(out, *r)
+		}
+	}
+	return out, nil
+}
+
+func (s *Store) ListMemberBalances(ctx context.Context, scorebookID string) ([]store.MemberWithScore, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.membersWithScore(scorebookID), nil
+}
+
+// memberMoney prices every record of the book with the rate effective when it was
+// created (a reversal with its original's rate), rounds each to cents and sums the
+// amounts per member.
+func (s *Store) memberMoney(bookID string) map[string]float64 {
+	var rates []*store.PointRate
+	for _, r := range s.pointRates {
+		if r.ScorebookID == bookID {
+			rates = append(rates, r)
+		}
+	}
+	created := map[string]time.Time{}
+	for _, r := range s.records {
+		if r.ScorebookID == bookID {
+			created[r.ID] = r.CreatedAt
+		}
+	}
+
+	sums := map[string]int64{}
+	for _, r := range s.records {
+		if r.ScorebookID != bookID {
+			continue
+		}
+		at := r.CreatedAt
+		if t, ok := created[r.ReversesRecordID]; ok {
+			at = t
+		}
+		c := cents(r.Delta * rateAt(rates, at))
+		sums[r.ToMemberID] += c
+		sums[r.FromMemberID] -= c
+	}
+	out := make(map[string]float64, len(sums))
+	for id, c := range sums {
+		out[id] = amount(c)
+	}
+	return out
+}
+
+// rateAt returns the point value effective at t; rates are in effective order.
+func rateAt(rates []*store.PointRate, t time.Time) float64 {
+	if len(rates) == 0 {
+		return 1
+	}
+	v := rates[0].PointValue
+	for _, r := range rates {
+		if r.EffectiveAt.After(t) {
+			break
+		}
+		v = r.PointValue
+	}
+	return v
+}
@@ -0,0 +1,792 @@
+package memstore
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"scorehub/internal/store"
+)
+
+func (s *Store) CreateScorebook(ctx context.Context, user store.User, name, locationText, bookType, templateID string, inviteUserIDs []int64) (store.Scorebook, store.Member, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var tpl *store.ScorebookTemplate
+	if templateID != "" {
+		if tpl = s.template(user.ID, templateID); tpl == nil {
+			return store.Scorebook{}, store.Member{}, store.ErrNotFound
+		}
+	}
+	if err := s.checkInvitees("", user.ID, inviteUserIDs); err != nil {
+		return store.Scorebook{}, store.Member{}, err
+	}
+
+	b := s.insertBook(user.ID, name, locationText, bookType)
+	owner := s.insertMember(b.ID, int64Ptr(user.ID), "owner", defaultNickname(user.WeChatNickname, "我"), strings.TrimSpace(user.WeChatAvatarURL), "")
+	if tpl != nil {
+		b.Game.TemplateID = tpl.ID
+		b.Game.GameType = tpl.GameType
+		b.Game.StakeMultiplier = tpl.StakeMultiplier
+		b.Game.DeltaPresets = append([]float64{}, tpl.DeltaPresets...)
+		b.Game.PointValue = tpl.StakeMultiplier
+		for _, name := range tpl.MemberNames {
+			s.insertMember(b.ID, nil, store.RoleMember, name, "", "").Placeholder = true
+		}
+	}
+	s.inviteUsers(b.ID, user.ID, inviteUserIDs)
+	s.pointRates = append(s.pointRates, &store.PointRate{
+		ID:          newID(),
+		ScorebookID: b.ID,
+		PointValue:  b.Game.PointValue,
+		Currency:    b.Game.Currency,
+		EffectiveAt: b.StartTime,
+	})
+	return withGame(b), toMember(owner), nil
+}
+
+// withGame returns the scorebook with its game settings, as CreateScorebook and
+// GetScorebookDetail do.
+func withGame(b *book) store.Scorebook {
+	sb := b.Scorebook
+	game := b.Game
+	game.DeltaPresets = append([]float64{}, b.Game.DeltaPresets...)
+	sb.Game = &game
+	return sb
+}
+
+func (s *Store) ListScorebooksForUser(ctx context.Context, userID int64, limit, offset int32) ([]store.ScorebookListItem, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var out []store.ScorebookListItem
+	for _, b := range s.books {
+		if b.BookType != "scorebook" || b.DeletedAt != nil {
+			continue
+		}
+		m := s.memberByUser(b.ID, userID)
+		if m == nil {
+			continue
+		}
+		out = append(out, store.ScorebookListItem{
+			ScorebookID:  b.ID,
+			Name:         b.Name,
+			LocationText: b.LocationText,
+			StartTime:    b.StartTime,
+			UpdatedAt:    b.UpdatedAt,
+			Status:       b.Status,
+			BookType:     b.BookType,
+			EndedAt:      b.EndedAt,
+			InviteCode:   b.InviteCode,
+			MyMemberID:   m.ID,
+			MyRole:       m.Role,
+			MemberCount:  int64(s.activeMemberCount(b.ID)),
+		})
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].UpdatedAt.After(out[j].UpdatedAt) })
+	from, to := page(len(out), limit, offset)
+	return out[from:to], nil
+}
+
+func (s *Store) GetScorebookDetail(ctx context.Context, scorebookID string, userID int64) (store.Scorebook, string, string, []store.MemberWithScore, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	b := s.activeBook(scorebookID, "scorebook")
+	if b == nil {
+		return store.Scorebook{}, "", "", nil, store.ErrNotFound
+	}
+	me := s.memberByUser(b.ID, userID)
+	if me == nil {
+		return store.Scorebook{}, "", "", nil, store.ErrNotFound
+	}
+	return withGame(b), me.ID, me.Role, s.membersWithScore(b.ID), nil
+}
+
+func (s *Store) GetScorebook(ctx context.Context, scorebookID string) (store.Scorebook, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	b := s.activeBook(scorebookID, "scorebook")
+	if b == nil {
+		return store.Scorebook{}, store.ErrNotFound
+	}
+	return b.Scorebook, nil
+}
+
+func (s *Store) UpdateScorebookName(ctx context.Context, scorebookID string, userID int64, name string) (store.Scorebook, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	b, me := s.bookMember(scorebookID, userID)
+	if me == nil {
+		return store.Scorebook{}, store.ErrNotFound
+	}
+	if !store.CanManage(me.Role) {
+		return store.Scorebook{}, store.ErrForbidden
+	}
+	b.Name = name
+	b.UpdatedAt = s.now()
+	return b.Scorebook, nil
+}
+
+func (s *Store) EndScorebook(ctx context.Context, scorebookID string, userID int64) (store.Scorebook, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	b, me := s.bookMember(scorebookID, userID)
+	if me == nil {
+		return store.Scorebook{}, store.ErrNotFound
+	}
+	if !store.CanManage(me.Role) {
+		return store.Scorebook{}, store.ErrForbidden
+	}
+	if b.Status != "recording" {
+		return store.Scorebook{}, store.ErrScorebookEnded
+	}
+	s.endBook(b)
+	return b.Scorebook, nil
+}
+
+func (s *Store) DeleteScorebook(ctx context.Context, scorebookID string, userID int64) (store.Scorebook, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	b := s.findBook(scorebookID)
+	if b == nil || b.BookType != "scorebook" {
+		return store.Scorebook{}, store.ErrNotFound
+	}
+	if !s.isOwner(b.ID, userID) {
+		return store.Scorebook{}, store.ErrForbidden
+	}
+	if b.Status != "ended" {
+		return store.Scorebook{}, store.ErrScorebookNotEnded
+	}
+	if b.DeletedAt != nil {
+		return store.Scorebook{}, store.ErrNotFound
+	}
+	now := s.now()
+	b.DeletedAt = timePtr(now)
+	b.UpdatedAt = now
+	return b.Scorebook, nil
+}
+
+func (s *Store) AutoEndInactiveScorebooks(ctx context.Context, inactiveFor time.Duration) ([]store.Scorebook, error) {
+	if inactiveFor <= 0 {
+		return nil, nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	threshold := s.now().Add(-inactiveFor)
+	var out []store.Scorebook
+	for _, b := range s.books {
+		if b.Status != "recording" || b.BookType != "scorebook" || b.DeletedAt != nil {
+			continue
+		}
+		last := b.StartTime
+		for _, r := range s.records {
+			if r.ScorebookID == b.ID && r.CreatedAt.After(last) {
+				last = r.CreatedAt
+			}
+		}
+		if !last.Before(threshold) {
+			continue
+		}
+		s.endBook(b)
+		out = append(out, b.Scorebook)
+	}
+	return out, nil
+}
+
+func (s *Store) JoinScorebook(ctx context.Context, scorebookID, inviteCode string, user store.User, nickname, avatarURL, role string) (store.Member, *store.JoinRequest, error) {
+	if role == "" {
+		role = store.RoleMember
+	}
+	if role != store.RoleMember && role != store.RoleSpectator {
+		return store.Member{}, nil, store.ErrInvalidArgument
+	}
+	if strings.TrimSpace(nickname) == "" {
+		nickname = user.WeChatNickname
+	}
+	if strings.TrimSpace(nickname) == "" {
+		nickname = "成员"
+	}
+	if strings.TrimSpace(avatarURL) == "" {
+		avatarURL = user.WeChatAvatarURL
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.joinScorebook(scorebookID, user, nickname, avatarURL, role, inviteCode, func(b *book) error {
+		return s.checkInviteUsable(b, inviteCode)
+	})
+}
+
+// joinScorebook mirrors store.joinScorebookTx; the caller holds s.mu.
+func (s *Store) joinScorebook(scorebookID string, user store.User, nickname, avatarURL, role, inviteCode string, admit func(*book) error) (store.Member, *store.JoinRequest, error) {
+	if existing := s.memberByUser(scorebookID, user.ID); existing != nil {
+		out := toMember(existing)
+		existing.UpdatedAt = s.now()
+		s.touch(scorebookID)
+		return out, nil, nil
+	}
+
+	b := s.activeBook(scorebookID, "scorebook")
+	if b == nil {
+		return store.Member{}, nil, store.ErrNotFound
+	}
+	if b.Status == "ended" {
+		return store.Member{}, nil, store.ErrScorebookEnded
+	}
+	if s.banned(b.ID, user.ID) {
+		return store.Member{}, nil, store.ErrBanned
+	}
+	if admit != nil {
+		if err := admit(b); err != nil {
+			return store.Member{}, nil, err
+		}
+	}
+	if b.JoinApproval {
+		req := s.requestJoin(b.ID, user.ID, nickname, avatarURL, role, inviteCode)
+		return store.Member{}, &req, nil
+	}
+	return s.insertJoinedMember(b.ID, user.ID, nickname, avatarURL, role, inviteCode, nil), nil, nil
+}
+
+func (s *Store) UpdateMyProfile(ctx context.Context, scorebookID string, userID int64, nickname, avatarURL string) (store.Member, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.activeBook(scorebookID, "scorebook") == nil {
+		return store.Member{}, store.ErrNotFound
+	}
+	m := s.memberByUser(scorebookID, userID)
+	if m == nil {
+		return store.Member{}, store.ErrNotFound
+	}
+	if nickname != "" {
+		m.Nickname = nickname
+	}
+	if avatarURL != "" {
+		m.AvatarURL = avatarURL
+	}
+	m.UpdatedAt = s.now()
+	s.touch(scorebookID)
+	return toMember(m), nil
+}
+
+func (s *Store) IsMember(ctx context.Context, scorebookID string, userID int64) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.activeBook(scorebookID, "scorebook") != nil && s.memberByUser(scorebookID, userID) != nil, nil
+}
+
+func (s *Store) CreateRecord(ctx context.Context, scorebookID string, userID int64, toMemberID string, delta float64, note string) (store.ScoreRecord, error) {
+	if strings.TrimSpace(toMemberID) == "" {
+		return store.ScoreRecord{}, store.ErrInvalidArgument
+	}
+	delta, ok := store.NormalizeAmount(delta)
+	if !ok {
+		return store.ScoreRecord{}, store.ErrInvalidDelta
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	b, err := s.recordingBook(scorebookID)
+	if err != nil {
+		return store.ScoreRecord{}, err
+	}
+	from := s.memberByUser(b.ID, userID)
+	if from == nil || from.Role == store.RoleSpectator {
+		return store.ScoreRecord{}, store.ErrForbidden
+	}
+	if from.ID == toMemberID {
+		return store.ScoreRecord{}, store.ErrInvalidArgument
+	}
+	to := s.memberByID(b.ID, toMemberID)
+	if to == nil || (to.UserID == nil && !to.Placeholder) {
+		return store.ScoreRecord{}, store.ErrNotFound
+	}
+	if to.Role == store.RoleSpectator {
+		return store.ScoreRecord{}, store.ErrInvalidArgument
+	}
+	if !store.DeltaAllowed(b.Game.DeltaPresets, delta) {
+		return store.ScoreRecord{}, store.ErrDeltaNotAllowed
+	}
+
+	r := s.insertRecord(store.ScoreRecord{
+		ScorebookID:  b.ID,
+		FromMemberID: from.ID,
+		ToMemberID:   to.ID,
+		Delta:        delta,
+		Note:         note,
+	})
+	s.addScore(to, cents(delta))
+	s.addScore(from, -cents(delta))
+	s.touch(b.ID)
+	return *r, nil
+}
+
+func (s *Store) VoidRecord(ctx context.Context, scorebookID string, userID int64, recordID string, window time.Duration) (store.ScoreRecord, store.ScoreRecord, error) {
+	if strings.TrimSpace(recordID) == "" {
+		return store.ScoreRecord{}, store.ScoreRecord{}, store.ErrInvalidArgument
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	b, err := s.recordingBook(scorebookID)
+	if err != nil {
+		return store.ScoreRecord{}, store.ScoreRecord{}, err
+	}
+	me := s.memberByUser(b.ID, userID)
+	if me == nil {
+		return store.ScoreRecord{}, store.ScoreRecord{}, store.ErrForbidden
+	}
+	var orig *store.ScoreRecord
+	for _, r := range s.records {
+		if r.ScorebookID == b.ID && r.ID == recordID {
+			orig = r
+			break
+		}
+	}
+	if orig == nil {
+		return store.ScoreRecord{}, store.ScoreRecord{}, store.ErrNotFound
+	}
+	if orig.ReversesRecordID != "" || orig.RoundID != "" {
+		return store.ScoreRecord{}, store.ScoreRecord{}, store.ErrInvalidArgument
+	}
+	if orig.VoidedAt != nil {
+		return store.ScoreRecord{}, store.ScoreRecord{}, store.ErrRecordVoided
+	}
+	if orig.FromMemberID != me.ID && !store.CanManage(me.Role) {
+		return store.ScoreRecord{}, store.ScoreRecord{}, store.ErrForbidden
+	}
+	if window > 0 && s.now().Sub(orig.CreatedAt) > window {
+		return store.ScoreRecord{}, store.ScoreRecord{}, store.ErrVoidWindowClosed
+	}
+
+	rev := s.insertRecord(store.ScoreRecord{
+		ScorebookID:      b.ID,
+		FromMemberID:     orig.ToMemberID,
+		ToMemberID:       orig.FromMemberID,
+		Delta:            orig.Delta,
+		Note:             orig.Note,
+		ReversesRecordID: orig.ID,
+	})
+	orig.VoidedAt = timePtr(s.now())
+	orig.VoidedByMemberID = me.ID
+
+	s.addScore(s.memberByID(b.ID, orig.ToMemberID), -cents(orig.Delta))
+	s.addScore(s.memberByID(b.ID, orig.FromMemberID), cents(orig.Delta))
+	s.touch(b.ID)
+	return *orig, *rev, nil
+}
+
+func (s *Store) CreateRound(ctx context.Context, scorebookID string, userID int64, deltas []store.RoundDelta, note string) (store.ScoreRound, error) {
+	balances, err := store.RoundBalances(deltas)
+	if err != nil {
+		return store.ScoreRound{}, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	b, err := s.recordingBook(scorebookID)
+	if err != nil {
+		return store.ScoreRound{}, err
+	}
+	me := s.memberByUser(b.ID, userID)
+	if me == nil || me.Role == store.RoleSpectator {
+		return store.ScoreRound{}, store.ErrForbidden
+	}
+	planned := make([]store.MemberWithScore, 0, len(balances))
+	for id, c := range balances {
+		if m := s.memberByID(b.ID, id); m == nil || (m.UserID == nil && !m.Placeholder) || m.Role == store.RoleSpectator {
+			return store.ScoreRound{}, store.ErrInvalidArgument
+		}
+		planned = append(planned, store.MemberWithScore{Member: store.Member{ID: id}, Score: amount(c)})
+	}
+	plan, err := store.PlanSettlement(planned)
+	if err != nil {
+		return store.ScoreRound{}, err
+	}
+
+	roundNo := 0
+	for _, r := range s.rounds {
+		if r.ScorebookID == b.ID && r.RoundNo > roundNo {
+			roundNo = r.RoundNo
+		}
+	}
+	round := &store.ScoreRound{
+		ID:                newID(),
+		ScorebookID:       b.ID,
+		RoundNo:           roundNo + 1,
+		CreatedByMemberID: me.ID,
+		Note:              note,
+		CreatedAt:         s.now(),
+	}
+	s.rounds = append(s.rounds, round)
+
+	out := *round
+	for _, t := range plan {
+		r := s.insertRecord(store.ScoreRecord{
+			ScorebookID:  b.ID,
+			FromMemberID: t.FromMemberID,
+			ToMemberID:   t.ToMemberID,
+			Delta:        t.Amount,
+			Note:         note,
+			RoundID:      round.ID,
+		})
+		out.Records = append(out.Records, *r)
+	}
+	for id, c := range balances {
+		s.addScore(s.memberByID(b.ID, id), c)
+	}
+	s.touch(b.ID)
+
+	for _, d := range deltas {
+		id := strings.TrimSpace(d.MemberID)
+		if balances[id] == 0 {
+			continue
+		}
+		out.Deltas = append(out.Deltas, store.RoundDelta{MemberID: id, Delta: amount(balances[id])})
+	}
+	return out, nil
+}
+
+func (s *Store) ListRecords(ctx context.Context, scorebookID string, userID int64, limit, offset int32) ([]store.ScoreRecord, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.activeBook(scorebookID, "scorebook") == nil {
+		return nil, store.ErrNotFound
+	}
+	if s.memberByUser(scorebookID, userID) == nil {
+		return nil, store.ErrForbidden
+	}
+	var out []store.ScoreRecord
+	for i := len(s.records) - 1; i >= 0; i-- {
+		r := s.records[i]
+		if r.ScorebookID == scorebookID && r.ReversesRecordID == "" {
+			out = append(out, *r)
+		}
+	}
+	from, to := page(len(out), limit, offset)
+	return out[from:to], nil
+}
+
+func (s *Store) GetTopWinners(ctx context.Context, scorebookID string) ([]store.MemberWithScore, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var out []store.MemberWithScore
+	for _, m := range s.membersWithScore(scorebookID) {
+		if m.Score > 0 {
+			out = append(out, m)
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		if out[i].Score != out[j].Score {
+			return out[i].Score > out[j].Score
+		}
+		return out[i].UpdatedAt.Before(out[j].UpdatedAt)
+	})
+	return out, nil
+}
+
+func (s *Store) GetSettlement(ctx context.Context, scorebookID string, userID int64) ([]store.SettlementTransfer, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	b := s.activeBook(scorebookID, "scorebook")
+	if b == nil {
+		return nil, false, store.ErrNotFound
+	}
+	if s.memberByUser(b.ID, userID) == nil {
+		return nil, false, store.ErrForbidden
+	}
+	if b.Status == "ended" {
+		out, err := s.ensureSettlement(b)
+		return out, true, err
+	}
+
+	out, err := store.PlanSettlement(s.membersWithScore(b.ID))
+	if err != nil {
+		return nil, false, err
+	}
+	for i := range out {
+		out[i].ScorebookID = b.ID
+		out[i].FromNickname = s.memberByID(b.ID, out[i].FromMemberID).Nickname
+		out[i].ToNickname = s.memberByID(b.ID, out[i].ToMemberID).Nickname
+	}
+	return out, false, nil
+}
+
+func (s *Store) EnsureSettlement(ctx context.Context, scorebookID string) ([]store.SettlementTransfer, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	b := s.activeBook(scorebookID, "scorebook")
+	if b == nil {
+		return nil, store.ErrNotFound
+	}
+	if b.Status != "ended" {
+		return nil, store.ErrScorebookNotEnded
+	}
+	return s.ensureSettlement(b)
+}
+
+func (s *Store) ensureSettlement(b *book) ([]store.SettlementTransfer, error) {
+	var out []store.SettlementTransfer
+	for _, t := range s.settlements {
+		if t.ScorebookID == b.ID {
+			out = append(out, s.withNicknames(*t))
+		}
+	}
+	if out == nil {
+		plan, err := store.PlanSettlement(s.membersWithScore(b.ID))
+		if err != nil {
+			return nil, err
+		}
+		now := s.now()
+		for _, t := range plan {
+			t.ID = newID()
+			t.ScorebookID = b.ID
+			t.CreatedAt = now
+			stored := t
+			s.settlements = append(s.settlements, &stored)
+			out = append(out, s.withNicknames(t))
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		if out[i].Amount != out[j].Amount {
+			return out[i].Amount > out[j].Amount
+		}
+		if !out[i].CreatedAt.Equal(out[j].CreatedAt) {
+			return out[i].CreatedAt.Before(out[j].CreatedAt)
+		}
+		return out[i].ID < out[j].ID
+	})
+	return out, nil
+}
+
+func (s *Store) MarkSettlementTransferPaid(ctx context.Context, scorebookID string, userID int64, transferID string, paid bool) (store.SettlementTransfer, error) {
+	if strings.TrimSpace(transferID) == "" {
+		return store.SettlementTransfer{}, store.ErrInvalidArgument
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.activeBook(scorebookID, "scorebook") == nil {
+		return store.SettlementTransfer{}, store.ErrForbidden
+	}
+	me := s.memberByUser(scorebookID, userID)
+	if me == nil {
+		return store.SettlementTransfer{}, store.ErrForbidden
+	}
+	var t *store.SettlementTransfer
+	for _, it := range s.settlements {
+		if it.ScorebookID == scorebookID && it.ID == transferID {
+			t = it
+			break
+		}
+	}
+	if t == nil {
+		return store.SettlementTransfer{}, store.ErrNotFound
+	}
+	if !store.CanManage(me.Role) && t.FromMemberID != me.ID && t.ToMemberID != me.ID {
+		return store.SettlementTransfer{}, store.ErrForbidden
+	}
+	if paid {
+		if t.PaidAt == nil {
+			t.PaidAt = timePtr(s.now())
+		}
+		if t.PaidByMemberID == "" {
+			t.PaidByMemberID = me.ID
+		}
+	} else {
+		t.PaidAt = nil
+		t.PaidByMemberID = ""
+	}
+	return s.withNicknames(*t), nil
+}
+
+func (s *Store) insertBook(userID int64, name, locationText, bookType string) *book {
+	now := s.now()
+	b := &book{Scorebook: store.Scorebook{
+		ID:              newID(),
+		Name:            name,
+		LocationText:    locationText,
+		StartTime:       now,
+		UpdatedAt:       now,
+		Status:          "recording",
+		BookType:        bookType,
+		CreatedByUserID: userID,
+	}, Game: store.ScorebookGame{StakeMultiplier: 1, DeltaPresets: []float64{}, PointValue: 1, Currency: store.DefaultCurrency}}
+	for {
+		b.InviteCode = randomInviteCode(8)
+		taken := false
+		for _, other := range s.books {
+			if other.InviteCode == b.InviteCode {
+				taken = true
+				break
+			}
+		}
+		if !taken {
+			break
+		}
+	}
+	s.books = append(s.books, b)
+	return b
+}
+
+func (s *Store) insertMember(bookID string, userID *int64, role, nickname, avatarURL, remark string) *member {
+	now := s.now()
+	m := &member{
+		ID:        newID(),
+		BookID:    bookID,
+		UserID:    userID,
+		Role:      role,
+		Nickname:  nickname,
+		AvatarURL: avatarURL,
+		Remark:    remark,
+		JoinedAt:  now,
+		UpdatedAt: now,
+	}
+	s.members = append(s.members, m)
+	return m
+}
+
+func (s *Store) insertRecord(r store.ScoreRecord) *store.ScoreRecord {
+	r.ID = newID()
+	r.CreatedAt = s.now()
+	s.records = append(s.records, &r)
+	return &r
+}
+
+func (s *Store) findBook(id string) *book {
+	for _, b := range s.books {
+		if b.ID == id {
+			return b
+		}
+	}
+	return nil
+}
+
+// activeBook returns the non-deleted book of the given type.
+func (s *Store) activeBook(id, bookType string) *book {
+	b := s.findBook(id)
+	if b == nil || b.BookType != bookType || b.DeletedAt != nil {
+		return nil
+	}
+	return b
+}
+
+// recordingBook returns the scorebook if records may still be written to it.
+func (s *Store) recordingBook(id string) (*book, error) {
+	b := s.activeBook(id, "scorebook")
+	if b == nil {
+		return nil, store.ErrNotFound
+	}
+	if b.Status != "recording" {
+		return nil, store.ErrScorebookEnded
+	}
+	return b, nil
+}
+
+func (s *Store) endBook(b *book) {
+	now := s.now()
+	b.Status = "ended"
+	b.EndedAt = timePtr(now)
+	b.UpdatedAt = now
+}
+
+func (s *Store) touch(bookID string) {
+	if b := s.findBook(bookID); b != nil {
+		b.UpdatedAt = s.now()
+	}
+}
+
+func (s *Store) membersOf(bookID string) []*member {
+	var out []*member
+	for _, m := range s.members {
+		if m.BookID == bookID {
+			out = append(out, m)
+		}
+	}
+	return out
+}
+
+func (s *Store) memberByUser(bookID string, userID int64) *member {
+	for _, m := range s.members {
+		if m.BookID == bookID && m.UserID != nil && *m.UserID == userID {
+			return m
+		}
+	}
+	return nil
+}
+
+func (s *Store) memberByID(bookID, id string) *member {
+	for _, m := range s.members {
+		if m.BookID == bookID && m.ID == id {
+			return m
+		}
+	}
+	return nil
+}
+
+func (s *Store) isOwner(bookID string, userID int64) bool {
+	m := s.memberByUser(bookID, userID)
+	return m != nil && m.Role == "owner"
+}
+
+func (s *Store) addScore(m *member, c int64) {
+	if m == nil {
+		return
+	}
+	m.Cents += c
+	m.UpdatedAt = s.now()
+}
+
+func (s *Store) membersWithScore(bookID string) []store.MemberWithScore {
+	money := s.memberMoney(bookID)
+	var out []store.MemberWithScore
+	for _, m := range s.membersOf(bookID) {
+		out = append(out, store.MemberWithScore{Member: toMember(m), Score: amount(m.Cents), Money: money[m.ID]})
+	}
+	return out
+}
+
+func (s *Store) withNicknames(t store.SettlementTransfer) store.SettlementTransfer {
+	if m := s.memberByID(t.ScorebookID, t.FromMemberID); m != nil {
+		t.FromNickname = m.Nickname
+	}
+	if m := s.memberByID(t.ScorebookID, t.ToMemberID); m != nil {
+		t.ToNickname = m.Nickname
+	}
+	return t
+}
+
+func toMember(m *member) store.Member {
+	out := store.Member{
+		ID:          m.ID,
+		ScorebookID: m.BookID,
+		Role:        m.Role,
+		Nickname:    m.Nickname,
+		AvatarURL:   m.AvatarURL,
+		JoinedAt:    m.JoinedAt,
+		UpdatedAt:   m.UpdatedAt,
+		Placeholder: m.Placeholder,
+	}
+	if m.UserID != nil {
+		out.UserID = *m.UserID
+	}
+	return out
+}
@@ -0,0 +1,112 @@
+package memstore
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"scorehub/internal/store"
+)
+
+// session is a user_sessions row.
+type session struct {
+	store.UserSession
+	RefreshHash  string
+	PreviousHash string
+}
+
+func (s *Store) CreateSession(ctx context.Context, userID int64, refreshHash, userAgent, ip string, ttl time.Duration) (store.UserSession, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	ss := &session{
+		UserSession: store.UserSession{
+			ID:         newID(),
+			UserID:     userID,
+			UserAgent:  userAgent,
+			IP:         ip,
+			CreatedAt:  now,
+			LastUsedAt: now,
+			ExpiresAt:  now.Add(ttl),
+		},
+		RefreshHash: refreshHash,
+	}
+	s.sessions = append(s.sessions, ss)
+	return ss.UserSession, nil
+}
+
+func (s *Store) RotateSession(ctx context.Context, oldHash, newHash string, ttl time.Duration) (store.UserSession, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	for _, ss := range s.sessions {
+		if ss.RefreshHash == oldHash {
+			if ss.RevokedAt != nil || !ss.ExpiresAt.After(now) {
+				return store.UserSession{}, store.ErrSessionRevoked
+			}
+			ss.PreviousHash = ss.RefreshHash
+			ss.RefreshHash = newHash
+			ss.LastUsedAt = now
+			ss.ExpiresAt = now.Add(ttl)
+			return ss.UserSession, nil
+		}
+	}
+	for _, ss := range s.sessions {
+		if ss.PreviousHash == oldHash && ss.RevokedAt == nil {
+			ss.RevokedAt = timePtr(now)
+			return store.UserSession{}, store.ErrSessionRevoked
+		}
+	}
+	return store.UserSession{}, store.ErrNotFound
+}
+
+func (s *Store) SessionActive(ctx context.Context, userID int64, sessionID string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ss := s.session(userID, sessionID)
+	return ss != nil && ss.RevokedAt == nil && ss.ExpiresAt.After(s.now()), nil
+}
+
+func (s *Store) ListSessions(ctx context.Context, userID int64) ([]store.UserSession, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	var out []store.UserSession
+	for _, ss := range s.sessions {
+		if ss.UserID == userID && ss.RevokedAt == nil && ss.ExpiresAt.After(now) {
+			out = append(out, ss.UserSession)
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		if !out[i].LastUsedAt.Equal(out[j].LastUsedAt) {
+			return out[i].LastUsedAt.After(out[j].LastUsedAt)
+		}
+		return out[i].CreatedAt.After(out[j].CreatedAt)
+	})
+	return out, nil
+}
+
+func (s *Store) RevokeSession(ctx context.Context, userID int64, sessionID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ss := s.session(userID, sessionID)
+	if ss == nil || ss.RevokedAt != nil {
+		return store.ErrNotFound
+	}
+	ss.RevokedAt = timePtr(s.now())
+	return nil
+}
+
+func (s *Store) session(userID int64, id string) *session {
+	for _, ss := range s.sessions {
+		if ss.ID == id && ss.UserID == userID {
+			return ss
+		}
+	}
+	return nil
+}
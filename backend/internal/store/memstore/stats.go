@@ -0,0 +1,66 @@
+package memstore
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"scorehub/internal/store"
+)
+
+func (s *Store) ListGameResults(ctx context.Context, userID int64, filter store.GameResultFilter) ([]store.GameResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	location := strings.ToLower(filter.Location)
+	var out []store.GameResult
+	for _, b := range s.books {
+		if b.BookType != "scorebook" || b.Status != "ended" || b.DeletedAt != nil || b.EndedAt == nil {
+			continue
+		}
+		if filter.From != nil && b.StartTime.Before(*filter.From) {
+			continue
+		}
+		if filter.To != nil && !b.StartTime.Before(*filter.To) {
+			continue
+		}
+		if location != "" && !strings.Contains(strings.ToLower(b.LocationText), location) {
+			continue
+		}
+		me := s.memberByUser(b.ID, userID)
+		if me == nil {
+			continue
+		}
+		g := store.GameResult{
+			ScorebookID:  b.ID,
+			Name:         b.Name,
+			LocationText: b.LocationText,
+			StartTime:    b.StartTime,
+			EndedAt:      *b.EndedAt,
+			MemberID:     me.ID,
+			Score:        amount(me.Cents),
+		}
+		if me.Cents > 0 {
+			g.Rank = 1
+		}
+		for _, m := range s.membersOf(b.ID) {
+			if m == me {
+				continue
+			}
+			if me.Cents > 0 && m.Cents > 0 && (m.Cents > me.Cents || (m.Cents == me.Cents && m.UpdatedAt.Before(me.UpdatedAt))) {
+				g.Rank++
+			}
+			if m.UserID != nil {
+				g.Opponents = append(g.Opponents, store.GameOpponent{
+					UserID:    *m.UserID,
+					Nickname:  m.Nickname,
+					AvatarURL: m.AvatarURL,
+					Score:     amount(m.Cents),
+				})
+			}
+		}
+		out = append(out, g)
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].EndedAt.Before(out[j].EndedAt) })
+	return out, nil
+}
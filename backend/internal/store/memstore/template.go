@@ -0,0 +1,129 @@
+package memstore
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"scorehub/internal/store"
+)
+
+func (s *Store) CreateScorebookTemplate(ctx context.Context, userID int64, in store.ScorebookTemplateInput) (store.ScorebookTemplate, error) {
+	name := strings.TrimSpace(in.Name)
+	if name == "" {
+		return store.ScorebookTemplate{}, store.ErrInvalidArgument
+	}
+	if in.StakeMultiplier == 0 {
+		in.StakeMultiplier = 1
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	t := &store.ScorebookTemplate{
+		ID:              newID(),
+		UserID:          userID,
+		Name:            name,
+		GameType:        strings.TrimSpace(in.GameType),
+		StakeMultiplier: in.StakeMultiplier,
+		DeltaPresets:    append([]float64{}, in.DeltaPresets...),
+		MemberNames:     append([]string{}, in.MemberNames...),
+		CreatedAt:       now,
+		UpdatedAt:       now,
+	}
+	s.templates = append(s.templates, t)
+	return copyTemplate(t), nil
+}
+
+func (s *Store) ListScorebookTemplates(ctx context.Context, userID int64, limit, offset int32) ([]store.ScorebookTemplate, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var out []store.ScorebookTemplate
+	for i := len(s.templates) - 1; i >= 0; i-- {
+		if t := s.templates[i]; t.UserID == userID {
+			out = append(out, copyTemplate(t))
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].UpdatedAt.After(out[j].UpdatedAt) })
+	from, to := page(len(out), limit, offset)
+	return out[from:to], nil
+}
+
+func (s *Store) GetScorebookTemplate(ctx context.Context, userID int64, id string) (store.ScorebookTemplate, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	t := s.template(userID, id)
+	if t == nil {
+		return store.ScorebookTemplate{}, store.ErrNotFound
+	}
+	return copyTemplate(t), nil
+}
+
+func (s *Store) UpdateScorebookTemplate(ctx context.Context, userID int64, id string, in store.ScorebookTemplateUpdate) (store.ScorebookTemplate, error) {
+	if in.Name != nil && strings.TrimSpace(*in.Name) == "" {
+		return store.ScorebookTemplate{}, store.ErrInvalidArgument
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	t := s.template(userID, id)
+	if t == nil {
+		return store.ScorebookTemplate{}, store.ErrNotFound
+	}
+	if in.Name != nil {
+		t.Name = strings.TrimSpace(*in.Name)
+	}
+	if in.GameType != nil {
+		t.GameType = strings.TrimSpace(*in.GameType)
+	}
+	if in.StakeMultiplier != nil {
+		t.StakeMultiplier = *in.StakeMultiplier
+	}
+	if in.DeltaPresets != nil {
+		t.DeltaPresets = append([]float64{}, *in.DeltaPresets...)
+	}
+	if in.MemberNames != nil {
+		t.MemberNames = append([]string{}, *in.MemberNames...)
+	}
+	t.UpdatedAt = s.now()
+	return copyTemplate(t), nil
+}
+
+func (s *Store) DeleteScorebookTemplate(ctx context.Context, userID int64, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, t := range s.templates {
+		if t.ID == id && t.UserID == userID {
+			s.templates = append(s.templates[:i], s.templates[i+1:]...)
+			// 与 ON DELETE SET NULL 一致：得分簿保留设置，只断开与模板的关联
+			for _, b := range s.books {
+				if b.Game.TemplateID == id {
+					b.Game.TemplateID = ""
+				}
+			}
+			return nil
+		}
+	}
+	return store.ErrNotFound
+}
+
+func (s *Store) template(userID int64, id string) *store.ScorebookTemplate {
+	for _, t := range s.templates {
+		if t.ID == id && t.UserID == userID {
+			return t
+		}
+	}
+	return nil
+}
+
+func copyTemplate(t *store.ScorebookTemplate) store.ScorebookTemplate {
+	out := *t
+	out.DeltaPresets = append([]float64{}, t.DeltaPresets...)
+	out.MemberNames = append([]string{}, t.MemberNames...)
+	return out
+}
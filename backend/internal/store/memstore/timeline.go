@@ -0,0 +1,69 @@
+package memstore
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"scorehub/internal/store"
+)
+
+func (s *Store) ScoreTimeline(ctx context.Context, scorebookID string, userID int64, bucket store.TimelineBucket) ([]store.Member, []store.TimelinePoint, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.activeBook(scorebookID, "scorebook") == nil || s.memberByUser(scorebookID, userID) == nil {
+		return nil, nil, store.ErrNotFound
+	}
+	if bucket.Records <= 0 && bucket.Interval <= 0 {
+		bucket.Records = 1
+	}
+	seconds := int64(bucket.Interval / time.Second)
+	if seconds < 1 {
+		seconds = 1
+	}
+
+	var members []store.Member
+	current := map[string]int64{}
+	for _, m := range s.membersOf(scorebookID) {
+		members = append(members, toMember(m))
+		current[m.ID] = 0
+	}
+
+	var recs []*store.ScoreRecord
+	for _, r := range s.records {
+		if r.ScorebookID == scorebookID {
+			recs = append(recs, r)
+		}
+	}
+	sort.SliceStable(recs, func(i, j int) bool { return recs[i].CreatedAt.Before(recs[j].CreatedAt) })
+
+	var points []store.TimelinePoint
+	var last int64 = -1
+	for i, r := range recs {
+		seq := int64(i + 1)
+		n := r.CreatedAt.Unix() / seconds
+		if bucket.Records > 0 {
+			n = (seq - 1) / int64(bucket.Records)
+		}
+		if n != last || len(points) == 0 {
+			p := store.TimelinePoint{StartAt: r.CreatedAt}
+			if bucket.Records <= 0 {
+				p.StartAt = time.Unix(n*seconds, 0).UTC()
+			}
+			points = append(points, p)
+			last = n
+		}
+		c := cents(r.Delta)
+		current[r.ToMemberID] += c
+		current[r.FromMemberID] -= c
+		p := &points[len(points)-1]
+		p.Records = seq
+		p.EndAt = r.CreatedAt
+		p.Scores = make(map[string]float64, len(current))
+		for id, v := range current {
+			p.Scores[id] = amount(v)
+		}
+	}
+	return members, points, nil
+}
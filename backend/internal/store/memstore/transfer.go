@@ -0,0 +1,140 @@
+package memstore
+
+import (
+	"context"
+
+	"scorehub/internal/store"
+)
+
+func (s *Store) RequestOwnershipTransfer(ctx context.Context, bookType, bookID string, userID int64, toMemberID string) (store.OwnershipTransfer, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	b, me := s.bookOwnership(bookType, bookID, userID)
+	if me == nil {
+		return store.OwnershipTransfer{}, store.ErrNotFound
+	}
+	if b.CreatedByUserID != userID {
+		return store.OwnershipTransfer{}, store.ErrForbidden
+	}
+	target := s.memberByID(b.ID, toMemberID)
+	if target == nil || target.UserID == nil {
+		return store.OwnershipTransfer{}, store.ErrNotFound
+	}
+	if target == me || target.Role == store.RoleSpectator {
+		return store.OwnershipTransfer{}, store.ErrInvalidArgument
+	}
+
+	now := s.now()
+	if p := s.pendingTransfer(b.ID); p != nil {
+		p.Status = store.TransferCancelled
+		p.ResolvedAt = timePtr(now)
+	}
+	t := &store.OwnershipTransfer{
+		ID:           newID(),
+		ScorebookID:  b.ID,
+		FromUserID:   userID,
+		FromMemberID: me.ID,
+		ToUserID:     *target.UserID,
+		ToMemberID:   target.ID,
+		Status:       store.TransferPending,
+		CreatedAt:    now,
+	}
+	s.transfers = append(s.transfers, t)
+	return s.toTransfer(t), nil
+}
+
+func (s *Store) GetOwnershipTransfer(ctx context.Context, bookType, bookID string, userID int64) (store.OwnershipTransfer, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	b, me := s.bookOwnership(bookType, bookID, userID)
+	if me == nil {
+		return store.OwnershipTransfer{}, store.ErrNotFound
+	}
+	t := s.pendingTransfer(b.ID)
+	if t == nil {
+		return store.OwnershipTransfer{}, store.ErrNotFound
+	}
+	return s.toTransfer(t), nil
+}
+
+func (s *Store) RespondOwnershipTransfer(ctx context.Context, bookType, bookID string, userID int64, accept bool) (store.OwnershipTransfer, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	b, me := s.bookOwnership(bookType, bookID, userID)
+	if me == nil {
+		return store.OwnershipTransfer{}, store.ErrNotFound
+	}
+	t := s.pendingTransfer(b.ID)
+	if t == nil || t.ToUserID != userID || t.ToMemberID != me.ID {
+		return store.OwnershipTransfer{}, store.ErrNotFound
+	}
+
+	now := s.now()
+	t.Status = store.TransferDeclined
+	if accept {
+		from := s.memberByID(b.ID, t.FromMemberID)
+		if t.FromUserID != b.CreatedByUserID || from == nil || from.UserID == nil || *from.UserID != t.FromUserID {
+			return store.OwnershipTransfer{}, store.ErrConflict
+		}
+		// 账本的 owner 成员是所有礼金记录的对方，转让不移动它
+		if bookType == "scorebook" {
+			from.Role, me.Role = me.Role, store.RoleOwner
+			from.UpdatedAt, me.UpdatedAt = now, now
+		}
+		b.CreatedByUserID = userID
+		b.UpdatedAt = now
+		t.Status = store.TransferAccepted
+	}
+	t.ResolvedAt = timePtr(now)
+	return s.toTransfer(t), nil
+}
+
+func (s *Store) CancelOwnershipTransfer(ctx context.Context, bookType, bookID string, userID int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	b, me := s.bookOwnership(bookType, bookID, userID)
+	if me == nil {
+		return store.ErrNotFound
+	}
+	if b.CreatedByUserID != userID {
+		return store.ErrForbidden
+	}
+	t := s.pendingTransfer(b.ID)
+	if t == nil {
+		return store.ErrNotFound
+	}
+	t.Status = store.TransferCancelled
+	t.ResolvedAt = timePtr(s.now())
+	return nil
+}
+
+// bookOwnership returns a non-deleted book of the given type and the caller's
+// membership in it; the member is nil if either is missing.
+func (s *Store) bookOwnership(bookType, bookID string, userID int64) (*book, *member) {
+	b := s.activeBook(bookID, bookType)
+	if b == nil {
+		return nil, nil
+	}
+	return b, s.memberByUser(b.ID, userID)
+}
+
+func (s *Store) pendingTransfer(bookID string) *store.OwnershipTransfer {
+	for _, t := range s.transfers {
+		if t.ScorebookID == bookID && t.Status == store.TransferPending {
+			return t
+		}
+	}
+	return nil
+}
+
+func (s *Store) toTransfer(t *store.OwnershipTransfer) store.OwnershipTransfer {
+	out := *t
+	if m := s.memberByID(t.ScorebookID, t.ToMemberID); m != nil {
+		out.ToNickname = m.Nickname
+	}
+	return out
+}
@@ -0,0 +1,75 @@
+package memstore
+
+import (
+	"context"
+	"strings"
+
+	"scorehub/internal/store"
+)
+
+func (s *Store) UpsertUserByOpenID(ctx context.Context, openid, nickname, avatarURL string) (store.User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	if id, ok := s.openIDs[openid]; ok {
+		u := s.users[id]
+		if nickname != "" {
+			u.WeChatNickname = nickname
+		}
+		if avatarURL != "" {
+			u.WeChatAvatarURL = avatarURL
+		}
+		u.UpdatedAt = now
+		return *u, nil
+	}
+
+	s.nextUserID++
+	u := &store.User{
+		ID:              s.nextUserID,
+		WeChatOpenID:    openid,
+		WeChatNickname:  nickname,
+		WeChatAvatarURL: avatarURL,
+		CreatedAt:       now,
+		UpdatedAt:       now,
+	}
+	s.users[u.ID] = u
+	s.openIDs[openid] = u.ID
+	return *u, nil
+}
+
+func (s *Store) UpdateUserProfile(ctx context.Context, userID int64, nickname, avatarURL *string) (store.User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	u, ok := s.users[userID]
+	if !ok {
+		return store.User{}, store.ErrNotFound
+	}
+	if nickname != nil {
+		u.WeChatNickname = *nickname
+	}
+	if avatarURL != nil {
+		u.WeChatAvatarURL = *avatarURL
+	}
+	u.UpdatedAt = s.now()
+	return *u, nil
+}
+
+func (s *Store) GetUserByID(ctx context.Context, userID int64) (store.User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	u, ok := s.users[userID]
+	if !ok {
+		return store.User{}, store.ErrNotFound
+	}
+	return *u, nil
+}
+
+func defaultNickname(nickname, fallback string) string {
+	if n := strings.TrimSpace(nickname); n != "" {
+		return n
+	}
+	return fallback
+}
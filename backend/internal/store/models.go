@@ -23,6 +23,69 @@ type Scorebook struct {
 	EndedAt         *time.Time
 	InviteCode      string
 	ShareDisabled   bool
+	// Game 为得分簿的玩法设置，仅创建与查询详情时返回
+	Game *ScorebookGame
+}
+
+// ScorebookGame 是得分簿的玩法设置，从模板复制而来；未使用模板时倍率为 1、预设为空。
+// PointValue/Currency 为当前的积分单价（1 分折合的金额）及币种，初始单价为模板的倍率，
+// 默认 1 CNY；StakeMultiplier 保留创建时模板的倍率，之后修改单价不会改变它。
+type ScorebookGame struct {
+	TemplateID      string
+	GameType        string
+	StakeMultiplier float64
+	DeltaPresets    []float64
+	PointValue      float64
+	Currency        string
+}
+
+const DefaultCurrency = "CNY"
+
+// PointRate 是积分单价的一次设置。修改单价会追加一条，已有记录仍按其创建时生效的单价折算金额。
+type PointRate struct {
+	ID          string
+	ScorebookID string
+	PointValue  float64
+	Currency    string
+	// ChangedByUserID 为 0 表示创建得分簿时的初始单价
+	ChangedByUserID int64
+	EffectiveAt     time.Time
+}
+
+// PointRateUpdate 中为 nil 的字段保持不变。
+type PointRateUpdate struct {
+	PointValue *float64
+	Currency   *string
+}
+
+// ScorebookTemplate 是用户保存的得分簿模板；MemberNames 为创建得分簿时添加的占位成员。
+type ScorebookTemplate struct {
+	ID              string
+	UserID          int64
+	Name            string
+	GameType        string
+	StakeMultiplier float64
+	DeltaPresets    []float64
+	MemberNames     []string
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+}
+
+type ScorebookTemplateInput struct {
+	Name            string
+	GameType        string
+	StakeMultiplier float64
+	DeltaPresets    []float64
+	MemberNames     []string
+}
+
+// ScorebookTemplateUpdate 中为 nil 的字段保持不变。
+type ScorebookTemplateUpdate struct {
+	Name            *string
+	GameType        *string
+	StakeMultiplier *float64
+	DeltaPresets    *[]float64
+	MemberNames     *[]string
 }
 
 type Member struct {
@@ -34,21 +97,179 @@ type Member struct {
 	AvatarURL   string
 	JoinedAt    time.Time
 	UpdatedAt   time.Time
+	// Placeholder 为模板创建的占位成员，没有绑定用户，但可以被记分
+	Placeholder bool
 }
 
-type MemberWithScore struct {
-	Member
-	Score float64
+// 得分簿成员角色。owner 与 admin 可以管理得分簿，spectator 只能观看不能记分。
+const (
+	RoleOwner     = "owner"
+	RoleAdmin     = "admin"
+	RoleMember    = "member"
+	RoleSpectator = "spectator"
+)
+
+// CanManage 报告该角色能否改名、结束得分簿以及移除成员。
+func CanManage(role string) bool {
+	return role == RoleOwner || role == RoleAdmin
 }
 
-type ScoreRecord struct {
+// ScorebookBan 记录被禁止通过邀请码重新加入的用户。
+type ScorebookBan struct {
+	ScorebookID    string
+	UserID         int64
+	Nickname       string
+	BannedByUserID int64
+	CreatedAt      time.Time
+}
+
+// 所有权转让状态。
+const (
+	TransferPending   = "pending"
+	TransferAccepted  = "accepted"
+	TransferDeclined  = "declined"
+	TransferCancelled = "cancelled"
+)
+
+// OwnershipTransfer 是一次得分簿/账本所有权转让：掌柜提名成员，被提名人接受后生效。
+type OwnershipTransfer struct {
 	ID           string
 	ScorebookID  string
+	FromUserID   int64
 	FromMemberID string
+	ToUserID     int64
 	ToMemberID   string
-	Delta        float64
-	Note         string
+	ToNickname   string
+	Status       string
 	CreatedAt    time.Time
+	ResolvedAt   *time.Time
+}
+
+// 加入申请状态。
+const (
+	JoinRequestPending  = "pending"
+	JoinRequestApproved = "approved"
+	JoinRequestRejected = "rejected"
+)
+
+// JoinRequest 是开启加入审核后的一次加入申请；批准后才创建成员，MemberID 为创建的成员。
+type JoinRequest struct {
+	ID               string
+	ScorebookID      string
+	UserID           int64
+	Nickname         string
+	AvatarURL        string
+	Role             string
+	InviteCode       string
+	Status           string
+	MemberID         string
+	ResolvedByUserID *int64
+	CreatedAt        time.Time
+	ResolvedAt       *time.Time
+}
+
+// 站内邀请状态。
+const (
+	InvitationPending  = "pending"
+	InvitationAccepted = "accepted"
+	InvitationDeclined = "declined"
+)
+
+// ScorebookInvitation 是掌柜或管理员向一起玩过的用户发出的站内邀请，接受后无需邀请码、
+// 也不经过加入审核即成为成员。
+type ScorebookInvitation struct {
+	ID                string
+	ScorebookID       string
+	ScorebookName     string
+	ScorebookStatus   string
+	UserID            int64
+	InvitedByUserID   int64
+	InvitedByNickname string
+	Status            string
+	MemberID          string
+	CreatedAt         time.Time
+	ResolvedAt        *time.Time
+}
+
+// FrequentPlayer 是与用户在同一得分簿出现过的其他用户；昵称头像取最近一次同局时的。
+type FrequentPlayer struct {
+	UserID           int64
+	Nickname         string
+	AvatarURL        string
+	SharedScorebooks int64
+	LastPlayedAt     time.Time
+}
+
+type MemberWithScore struct {
+	Member
+	Score float64
+	// Money 为按积分单价历史折算的金额
+	Money float64
+}
+
+type ScoreRecord struct {
+	ID               string
+	ScorebookID      string
+	FromMemberID     string
+	ToMemberID       string
+	Delta            float64
+	Note             string
+	CreatedAt        time.Time
+	ReversesRecordID string
+	VoidedAt         *time.Time
+	VoidedByMemberID string
+	RoundID          string
+	// CategoryID 与 CreatedByUserID 仅用于账本记录
+	CategoryID      string
+	CreatedByUserID *int64
+}
+
+// TimelineBucket groups records into timeline points: every Records records, or
+// by fixed Interval windows (aligned to the Unix epoch). The zero value gives one
+// point per record.
+type TimelineBucket struct {
+	Records  int
+	Interval time.Duration
+}
+
+// TimelinePoint is the members' cumulative scores after the last record of a bucket.
+type TimelinePoint struct {
+	// Records 为截至该点的记录总数（含冲正记录）
+	Records int64
+	// StartAt 为区间起点：按时间分桶时为桶的起始时间，否则为桶内第一条记录的时间
+	StartAt time.Time
+	EndAt   time.Time
+	// Scores 按成员 ID 给出累计分数，包含所有成员（未变化的沿用上一点）
+	Scores map[string]float64
+}
+
+type RoundDelta struct {
+	MemberID string
+	Delta    float64
+}
+
+type ScoreRound struct {
+	ID                string
+	ScorebookID       string
+	RoundNo           int
+	CreatedByMemberID string
+	Note              string
+	CreatedAt         time.Time
+	Deltas            []RoundDelta
+	Records           []ScoreRecord
+}
+
+type SettlementTransfer struct {
+	ID             string
+	ScorebookID    string
+	FromMemberID   string
+	FromNickname   string
+	ToMemberID     string
+	ToNickname     string
+	Amount         float64
+	PaidAt         *time.Time
+	PaidByMemberID string
+	CreatedAt      time.Time
 }
 
 type ScorebookListItem struct {
@@ -66,6 +287,70 @@ type ScorebookListItem struct {
 	MemberCount  int64
 }
 
+// GameResultFilter 筛选统计范围；From/To 按开始时间过滤（To 不含），Location 为地点的子串（不区分大小写）。
+type GameResultFilter struct {
+	From     *time.Time
+	To       *time.Time
+	Location string
+}
+
+// GameResult 是用户在一个已结束得分簿中的成绩。
+type GameResult struct {
+	ScorebookID  string
+	Name         string
+	LocationText string
+	StartTime    time.Time
+	EndedAt      time.Time
+	MemberID     string
+	Score        float64
+	// Rank 为按 GetTopWinners 规则（分数 > 0，分数降序、先达到者在前）的名次，0 表示未上榜
+	Rank      int
+	Opponents []GameOpponent
+}
+
+// GameOpponent 是同一局中绑定了用户的其他成员。
+type GameOpponent struct {
+	UserID    int64
+	Nickname  string
+	AvatarURL string
+	Score     float64
+}
+
+// PlayerStats 汇总用户在多局中的成绩：冠军记为胜，净分为负记为负，其余为平。
+type PlayerStats struct {
+	Games    int
+	Wins     int
+	Losses   int
+	Draws    int
+	WinRate  float64
+	NetScore float64
+	Best     *GameResult
+	Worst    *GameResult
+
+	LongestWinStreak  int
+	LongestLossStreak int
+	// CurrentStreak 为最近连续相同结果（win/loss/draw）的局数
+	CurrentStreak        int
+	CurrentStreakOutcome string
+
+	HeadToHead []HeadToHead
+}
+
+// HeadToHead 是与某位对手同局时的对比：分数高于对方记为胜。
+type HeadToHead struct {
+	UserID    int64
+	Nickname  string
+	AvatarURL string
+	Games     int
+	Wins      int
+	Losses    int
+	Draws     int
+	// NetScore 为这些局中自己的净分之和，OpponentNetScore 为对方的
+	NetScore         float64
+	OpponentNetScore float64
+	LastPlayedAt     time.Time
+}
+
 type LedgerMember struct {
 	ID        string
 	LedgerID  string
@@ -88,7 +373,130 @@ type LedgerRecord struct {
 	Type         string
 	Amount       float64
 	Note         string
-	CreatedAt    time.Time
+	// CategoryID 为空表示未分类
+	CategoryID string
+	// CreatedByUserID 为录入该记录的用户（账本创建者或记账员），早期记录为空
+	CreatedByUserID   *int64
+	CreatedByNickname string
+	CreatedAt         time.Time
+}
+
+// LedgerRecordUpdate holds the fields of a ledger record edit; nil fields are kept.
+type LedgerRecordUpdate struct {
+	MemberID *string
+	Type     *string
+	Amount   *float64
+	Note     *string
+	// CategoryID 为空字符串表示取消分类
+	CategoryID *string
+}
+
+// LedgerRecordSnapshot is a ledger record's editable fields as stored in a revision.
+type LedgerRecordSnapshot struct {
+	MemberID   string  `json:"memberId"`
+	Type       string  `json:"type"`
+	Amount     float64 `json:"amount"`
+	Note       string  `json:"note"`
+	CategoryID string  `json:"categoryId"`
+}
+
+// RecordRevision is one edit ("update") or deletion ("delete") of a ledger record.
+type RecordRevision struct {
+	ID                string
+	LedgerID          string
+	RecordID          string
+	Action            string
+	ChangedByUserID   *int64
+	ChangedByNickname string
+	Before            LedgerRecordSnapshot
+	// After 在删除时为 nil
+	After     *LedgerRecordSnapshot
+	CreatedAt time.Time
+}
+
+type LedgerCategory struct {
+	ID        string
+	LedgerID  string
+	Name      string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// LedgerSummaryRow is one aggregate of a ledger's records by (category, member, type),
+// as read from storage; SummarizeLedger folds the rows into a LedgerSummary.
+type LedgerSummaryRow struct {
+	CategoryID string
+	MemberID   string
+	Type       string
+	Records    int64
+	Amount     float64
+}
+
+// LedgerTotals 为一组记录的合计；Net 为收入减支出。
+type LedgerTotals struct {
+	Records int64
+	Income  float64
+	Expense float64
+	Net     float64
+}
+
+type LedgerCategoryTotal struct {
+	// CategoryID 为空表示未分类
+	CategoryID string
+	Name       string
+	LedgerTotals
+}
+
+type LedgerMemberTotal struct {
+	MemberID string
+	Nickname string
+	LedgerTotals
+}
+
+type LedgerTypeTotal struct {
+	Type    string
+	Records int64
+	Amount  float64
+}
+
+type LedgerSummary struct {
+	Total      LedgerTotals
+	ByType     []LedgerTypeTotal
+	ByCategory []LedgerCategoryTotal
+	ByMember   []LedgerMemberTotal
+}
+
+// LedgerImportRow is one data row of a ledger import file. The parser fills Line,
+// the cell values and Error; PlanLedgerImport fills the member mapping and warnings.
+type LedgerImportRow struct {
+	Line   int
+	Name   string
+	Remark string
+	Type   string
+	Amount float64
+	Error  string
+
+	// MemberID 为匹配到的已有成员；NewMember 表示导入时新建成员（提交后 MemberID 为新成员）
+	MemberID  string
+	NewMember bool
+	Warnings  []string
+}
+
+// LedgerImportDuplicate is a name that appears on several rows or matches existing members.
+type LedgerImportDuplicate struct {
+	Name            string
+	Lines           []int
+	ExistingMembers []string
+}
+
+type LedgerImportPlan struct {
+	Rows       []LedgerImportRow
+	Duplicates []LedgerImportDuplicate
+	Records    int
+	NewMembers int
+	Errors     int
+	Income     float64
+	Expense    float64
 }
 
 type LedgerListItem struct {
@@ -100,6 +508,8 @@ type LedgerListItem struct {
 	EndedAt     *time.Time
 	MemberCount int64
 	RecordCount int64
+	// Role 为当前用户在账本中的身份：owner / recorder / member
+	Role string
 }
 
 type InviteInfo struct {
@@ -109,6 +519,55 @@ type InviteInfo struct {
 	Status        string
 	ShareDisabled bool
 	UpdatedAt     time.Time
+
+	ExpiresAt *time.Time
+	MaxUses   int
+	Uses      int64
+	// UnusableReason 非空时邀请码不能用于加入，见 InviteUnusableReason
+	UnusableReason string
+	// JoinApproval 为 true 时加入需要掌柜或管理员审核
+	JoinApproval bool
+}
+
+// InviteSettings 是得分簿当前邀请码及其限制；MaxUses 为 0 表示不限次数，Uses 按当前邀请码统计。
+type InviteSettings struct {
+	ScorebookID string
+	Code        string
+	ExpiresAt   *time.Time
+	MaxUses     int
+	Uses        int64
+	// JoinApproval 为 true 时加入（无论是否通过邀请码）需要审核
+	JoinApproval bool
+}
+
+// InviteSettingsUpdate 中为 nil 的字段保持不变；ExpiresSetNull 取消过期时间，MaxUses 为 0 取消次数限制。
+type InviteSettingsUpdate struct {
+	ExpiresAt      *time.Time
+	ExpiresSetNull bool
+	MaxUses        *int
+	JoinApproval   *bool
+}
+
+// InviteUse 是一次通过邀请码加入的记录。
+type InviteUse struct {
+	Code      string
+	UserID    int64
+	MemberID  string
+	Nickname  string
+	CreatedAt time.Time
+}
+
+// InviteUnusableReason 返回邀请码不能用于加入的原因：ended、expired 或 exhausted；可用时返回空串。
+func InviteUnusableReason(status string, expiresAt *time.Time, maxUses int, uses int64, now time.Time) string {
+	switch {
+	case status == "ended":
+		return "ended"
+	case expiresAt != nil && !now.Before(*expiresAt):
+		return "expired"
+	case maxUses > 0 && uses >= int64(maxUses):
+		return "exhausted"
+	}
+	return ""
 }
 
 type BirthdayContact struct {
@@ -288,3 +747,96 @@ type DepositTagCount struct {
 	Tag   string
 	Count int
 }
+
+type ScorebookEvent struct {
+	Seq       int64
+	Payload   []byte
+	CreatedAt time.Time
+}
+
+type UserSession struct {
+	ID         string
+	UserID     int64
+	UserAgent  string
+	IP         string
+	CreatedAt  time.Time
+	LastUsedAt time.Time
+	ExpiresAt  time.Time
+	RevokedAt  *time.Time
+}
+
+// Person is one of a user's own contacts for 人情往来. Ledger members and birthday
+// contacts can link to a person so gifts can be followed across ledgers.
+type Person struct {
+	ID        string
+	UserID    int64
+	Name      string
+	Note      string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// PersonLedgerMember is a ledger member linked to a person.
+type PersonLedgerMember struct {
+	LedgerID     string
+	LedgerName   string
+	LedgerStatus string
+	MemberID     string
+	Nickname     string
+	Remark       string
+}
+
+// PersonBirthdayContact is a birthday contact linked to a person.
+type PersonBirthdayContact struct {
+	ID       string
+	Name     string
+	Relation string
+}
+
+// PersonLinks lists what is linked to a person.
+type PersonLinks struct {
+	LedgerMembers    []PersonLedgerMember
+	BirthdayContacts []PersonBirthdayContact
+}
+
+// PersonHistoryEntry is one income/expense record of a linked ledger member, seen
+// from the user's side: "received" (收礼, income) or "given" (送礼, expense).
+type PersonHistoryEntry struct {
+	LedgerID   string
+	LedgerName string
+	RecordID   string
+	MemberID   string
+	Direction  string
+	Amount     float64
+	Note       string
+	CreatedAt  time.Time
+}
+
+// PersonHistory is the reciprocity history with a person, newest entries first.
+type PersonHistory struct {
+	Person        Person
+	Entries       []PersonHistoryEntry
+	Received      float64
+	Given         float64
+	ReceivedCount int64
+	GivenCount    int64
+}
+
+// LedgerRecorder is a user who joined a ledger through its recorder invite code and
+// may add members and records to it.
+type LedgerRecorder struct {
+	LedgerID  string
+	UserID    int64
+	Nickname  string
+	AvatarURL string
+	CreatedAt time.Time
+}
+
+// LedgerRecorderInvite is what a recorder invite code shows before it is accepted.
+type LedgerRecorderInvite struct {
+	LedgerID      string
+	LedgerName    string
+	Status        string
+	OwnerUserID   int64
+	OwnerNickname string
+}
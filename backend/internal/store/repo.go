@@ -0,0 +1,181 @@
+package store
+
+import (
+	"context"
+	"time"
+)
+
+// Per-domain repositories consumed by the HTTP handlers. *Store implements all of
+// them on top of PostgreSQL; memstore provides an in-memory implementation with the
+// same error semantics for tests.
+
+type UserRepo interface {
+	UpsertUserByOpenID(ctx context.Context, openid, nickname, avatarURL string) (User, error)
+	UpdateUserProfile(ctx context.Context, userID int64, nickname, avatarURL *string) (User, error)
+	GetUserByID(ctx context.Context, userID int64) (User, error)
+
+	CreateSession(ctx context.Context, userID int64, refreshHash, userAgent, ip string, ttl time.Duration) (UserSession, error)
+	RotateSession(ctx context.Context, oldHash, newHash string, ttl time.Duration) (UserSession, error)
+	SessionActive(ctx context.Context, userID int64, sessionID string) (bool, error)
+	ListSessions(ctx context.Context, userID int64) ([]UserSession, error)
+	RevokeSession(ctx context.Context, userID int64, sessionID string) error
+}
+
+// OwnershipRepo transfers ownership of a scorebook or ledger; bookType is
+// "scorebook" or "ledger".
+type OwnershipRepo interface {
+	RequestOwnershipTransfer(ctx context.Context, bookType, bookID string, userID int64, toMemberID string) (OwnershipTransfer, error)
+	GetOwnershipTransfer(ctx context.Context, bookType, bookID string, userID int64) (OwnershipTransfer, error)
+	RespondOwnershipTransfer(ctx context.Context, bookType, bookID string, userID int64, accept bool) (OwnershipTransfer, error)
+	CancelOwnershipTransfer(ctx context.Context, bookType, bookID string, userID int64) error
+}
+
+type ScorebookRepo interface {
+	OwnershipRepo
+
+	CreateScorebook(ctx context.Context, user User, name, locationText, bookType, templateID string, inviteUserIDs []int64) (Scorebook, Member, error)
+	ListScorebooksForUser(ctx context.Context, userID int64, limit, offset int32) ([]ScorebookListItem, error)
+	ListGameResults(ctx context.Context, userID int64, filter GameResultFilter) ([]GameResult, error)
+	GetScorebookDetail(ctx context.Context, scorebookID string, userID int64) (Scorebook, string, string, []MemberWithScore, error)
+	GetScorebook(ctx context.Context, scorebookID string) (Scorebook, error)
+	UpdateScorebookName(ctx context.Context, scorebookID string, userID int64, name string) (Scorebook, error)
+	EndScorebook(ctx context.Context, scorebookID string, userID int64) (Scorebook, error)
+	DeleteScorebook(ctx context.Context, scorebookID string, userID int64) (Scorebook, error)
+	AutoEndInactiveScorebooks(ctx context.Context, inactiveFor time.Duration) ([]Scorebook, error)
+	JoinScorebook(ctx context.Context, scorebookID, inviteCode string, user User, nickname, avatarURL, role string) (Member, *JoinRequest, error)
+	UpdateMyProfile(ctx context.Context, scorebookID string, userID int64, nickname, avatarURL string) (Member, error)
+	IsMember(ctx context.Context, scorebookID string, userID int64) (bool, error)
+	SetMemberRole(ctx context.Context, scorebookID string, userID int64, memberID, role string) (Member, error)
+	RemoveMember(ctx context.Context, scorebookID string, userID int64, memberID string, ban bool) (Member, bool, error)
+	ListBans(ctx context.Context, scorebookID string, userID int64) ([]ScorebookBan, error)
+	Unban(ctx context.Context, scorebookID string, userID int64, bannedUserID int64) error
+	ListManagerUserIDs(ctx context.Context, scorebookID string) ([]int64, error)
+
+	CreateRecord(ctx context.Context, scorebookID string, userID int64, toMemberID string, delta float64, note string) (ScoreRecord, error)
+	VoidRecord(ctx context.Context, scorebookID string, userID int64, recordID string, window time.Duration) (ScoreRecord, ScoreRecord, error)
+	CreateRound(ctx context.Context, scorebookID string, userID int64, deltas []RoundDelta, note string) (ScoreRound, error)
+	ListRecords(ctx context.Context, scorebookID string, userID int64, limit, offset int32) ([]ScoreRecord, error)
+	EachRecord(ctx context.Context, scorebookID string, fn func(ScoreRecord) error) error
+	ScoreTimeline(ctx context.Context, scorebookID string, userID int64, bucket TimelineBucket) ([]Member, []TimelinePoint, error)
+	GetTopWinners(ctx context.Context, scorebookID string) ([]MemberWithScore, error)
+
+	UpdatePointRate(ctx context.Context, scorebookID string, userID int64, in PointRateUpdate) (PointRate, bool, error)
+	ListPointRates(ctx context.Context, scorebookID string, userID int64) ([]PointRate, error)
+	ListMemberBalances(ctx context.Context, scorebookID string) ([]MemberWithScore, error)
+
+	GetSettlement(ctx context.Context, scorebookID string, userID int64) ([]SettlementTransfer, bool, error)
+	EnsureSettlement(ctx context.Context, scorebookID string) ([]SettlementTransfer, error)
+	MarkSettlementTransferPaid(ctx context.Context, scorebookID string, userID int64, transferID string, paid bool) (SettlementTransfer, error)
+
+	GetInviteInfo(ctx context.Context, code string) (InviteInfo, error)
+	JoinByInviteCode(ctx context.Context, code string, user User, nickname, avatarURL, role string) (Member, *JoinRequest, error)
+	GetInviteSettings(ctx context.Context, scorebookID string, userID int64) (InviteSettings, error)
+	UpdateInviteSettings(ctx context.Context, scorebookID string, userID int64, in InviteSettingsUpdate) (InviteSettings, error)
+	RegenerateInviteCode(ctx context.Context, scorebookID string, userID int64) (InviteSettings, error)
+	ListInviteUses(ctx context.Context, scorebookID string, userID int64, limit, offset int32) ([]InviteUse, error)
+
+	ListJoinRequests(ctx context.Context, scorebookID string, userID int64, status string, limit, offset int32) ([]JoinRequest, error)
+	GetMyJoinRequest(ctx context.Context, scorebookID string, userID int64) (JoinRequest, error)
+	ApproveJoinRequest(ctx context.Context, scorebookID string, userID int64, requestID string) (JoinRequest, Member, error)
+	RejectJoinRequest(ctx context.Context, scorebookID string, userID int64, requestID string) (JoinRequest, error)
+
+	ListFrequentPlayers(ctx context.Context, userID int64, limit int32) ([]FrequentPlayer, error)
+	InviteUsers(ctx context.Context, scorebookID string, userID int64, inviteeIDs []int64) ([]ScorebookInvitation, error)
+	ListMyInvitations(ctx context.Context, userID int64, status string, limit, offset int32) ([]ScorebookInvitation, error)
+	RespondInvitation(ctx context.Context, invitationID string, user User, accept bool) (ScorebookInvitation, *Member, error)
+}
+
+type LedgerRepo interface {
+	OwnershipRepo
+
+	GetLedger(ctx context.Context, ledgerID string) (Scorebook, error)
+	CreateLedger(ctx context.Context, user User, name string) (Scorebook, LedgerMember, error)
+	ListLedgersForUser(ctx context.Context, userID int64, limit, offset int32) ([]LedgerListItem, error)
+	GetLedgerDetail(ctx context.Context, ledgerID string, limit, offset int32) (Scorebook, []LedgerMember, []LedgerRecord, error)
+	UpdateLedger(ctx context.Context, ledgerID string, userID int64, name *string, shareDisabled *bool) (Scorebook, error)
+	AddLedgerMember(ctx context.Context, ledgerID string, userID int64, nickname, avatarURL, remark string) (LedgerMember, error)
+	UpdateLedgerMember(ctx context.Context, ledgerID string, userID int64, memberID string, nickname, avatarURL, remark string) (LedgerMember, error)
+	BindLedgerMember(ctx context.Context, ledgerID string, userID int64, memberID string, nickname, avatarURL string) (LedgerMember, error)
+	AddLedgerRecord(ctx context.Context, ledgerID string, userID int64, memberID string, recordType string, amount float64, note, categoryID string) (LedgerRecord, error)
+	UpdateLedgerRecord(ctx context.Context, ledgerID string, userID int64, recordID string, in LedgerRecordUpdate) (LedgerRecord, error)
+	DeleteLedgerRecord(ctx context.Context, ledgerID string, userID int64, recordID string) (LedgerRecord, error)
+	ListRecordRevisions(ctx context.Context, ledgerID string, userID int64, recordID string, limit, offset int32) ([]RecordRevision, error)
+	ListLedgerCategories(ctx context.Context, ledgerID string, userID int64) ([]LedgerCategory, error)
+	CreateLedgerCategory(ctx context.Context, ledgerID string, userID int64, name string) (LedgerCategory, error)
+	UpdateLedgerCategory(ctx context.Context, ledgerID string, userID int64, categoryID, name string) (LedgerCategory, error)
+	DeleteLedgerCategory(ctx context.Context, ledgerID string, userID int64, categoryID string) error
+	GetLedgerSummary(ctx context.Context, ledgerID string, userID int64) (LedgerSummary, error)
+	EachLedgerRecord(ctx context.Context, ledgerID string, fn func(LedgerRecord) error) error
+	ImportLedgerRecords(ctx context.Context, ledgerID string, userID int64, rows []LedgerImportRow, separate bool) (LedgerImportPlan, error)
+	EndLedger(ctx context.Context, ledgerID string, userID int64) (Scorebook, error)
+	DeleteLedger(ctx context.Context, ledgerID string, userID int64) (Scorebook, error)
+	GetLedgerRole(ctx context.Context, ledgerID string, userID int64) (string, error)
+	ListLedgerRecorders(ctx context.Context, ledgerID string, userID int64) ([]LedgerRecorder, string, error)
+	ResetLedgerRecorderInvite(ctx context.Context, ledgerID string, userID int64, enabled bool) (string, error)
+	GetLedgerRecorderInvite(ctx context.Context, code string) (LedgerRecorderInvite, error)
+	AcceptLedgerRecorderInvite(ctx context.Context, code string, userID int64) (LedgerRecorder, error)
+	RemoveLedgerRecorder(ctx context.Context, ledgerID string, userID, recorderUserID int64) error
+}
+
+type TemplateRepo interface {
+	CreateScorebookTemplate(ctx context.Context, userID int64, in ScorebookTemplateInput) (ScorebookTemplate, error)
+	ListScorebookTemplates(ctx context.Context, userID int64, limit, offset int32) ([]ScorebookTemplate, error)
+	GetScorebookTemplate(ctx context.Context, userID int64, id string) (ScorebookTemplate, error)
+	UpdateScorebookTemplate(ctx context.Context, userID int64, id string, in ScorebookTemplateUpdate) (ScorebookTemplate, error)
+	DeleteScorebookTemplate(ctx context.Context, userID int64, id string) error
+}
+
+type BirthdayRepo interface {
+	CreateBirthdayContact(ctx context.Context, userID int64, in BirthdayContactInput) (BirthdayContact, error)
+	GetBirthdayContact(ctx context.Context, userID int64, id string) (BirthdayContact, error)
+	ListBirthdayContacts(ctx context.Context, userID int64, limit, offset int32) ([]BirthdayContactWithDays, error)
+	UpdateBirthdayContact(ctx context.Context, userID int64, id string, in BirthdayContactUpdate) (BirthdayContact, error)
+	DeleteBirthdayContact(ctx context.Context, userID int64, id string) error
+}
+
+type DepositRepo interface {
+	CreateDepositAccount(ctx context.Context, userID int64, in DepositAccountInput) (DepositAccount, error)
+	ListDepositAccounts(ctx context.Context, userID int64, limit, offset int32) ([]DepositAccount, error)
+	GetDepositAccount(ctx context.Context, userID int64, id string) (DepositAccount, error)
+	UpdateDepositAccount(ctx context.Context, userID int64, id string, in DepositAccountUpdate) (DepositAccount, error)
+	DeleteDepositAccount(ctx context.Context, userID int64, id string) error
+	CreateDepositRecord(ctx context.Context, userID int64, in DepositRecordInput) (DepositRecord, error)
+	ListDepositRecords(ctx context.Context, userID int64, accountID string, status string, tags []string, limit, offset int32) ([]DepositRecord, error)
+	GetDepositRecord(ctx context.Context, userID int64, id string) (DepositRecord, error)
+	UpdateDepositRecord(ctx context.Context, userID int64, id string, in DepositRecordUpdate) (DepositRecord, error)
+	DeleteDepositRecord(ctx context.Context, userID int64, id string) error
+	ListDepositTags(ctx context.Context, userID int64, accountID string, status string) ([]DepositTagCount, error)
+	GetDepositStats(ctx context.Context, userID int64, accountID string, status string, tags []string) (DepositStats, error)
+}
+
+// PeopleRepo manages a user's people and their links to ledger members and
+// birthday contacts.
+type PeopleRepo interface {
+	CreatePerson(ctx context.Context, userID int64, name, note string) (Person, error)
+	ListPeople(ctx context.Context, userID int64, query string, limit, offset int32) ([]Person, error)
+	GetPerson(ctx context.Context, userID int64, personID string) (Person, PersonLinks, error)
+	UpdatePerson(ctx context.Context, userID int64, personID string, name, note *string) (Person, error)
+	DeletePerson(ctx context.Context, userID int64, personID string) error
+	LinkLedgerMember(ctx context.Context, userID int64, personID, ledgerID, memberID string) error
+	UnlinkLedgerMember(ctx context.Context, userID int64, personID, memberID string) error
+	LinkBirthdayContact(ctx context.Context, userID int64, personID, contactID string) error
+	UnlinkBirthdayContact(ctx context.Context, userID int64, personID, contactID string) error
+	GetPersonHistory(ctx context.Context, userID int64, personID string) (PersonHistory, error)
+}
+
+// EventLog persists realtime events so reconnecting WebSocket clients can replay them.
+type EventLog interface {
+	AppendScorebookEvent(ctx context.Context, scorebookID string, payload []byte) (int64, error)
+	ListScorebookEventsSince(ctx context.Context, scorebookID string, since int64, limit int32) ([]ScorebookEvent, int64, bool, error)
+}
+
+var (
+	_ UserRepo      = (*Store)(nil)
+	_ ScorebookRepo = (*Store)(nil)
+	_ LedgerRepo    = (*Store)(nil)
+	_ TemplateRepo  = (*Store)(nil)
+	_ BirthdayRepo  = (*Store)(nil)
+	_ DepositRepo   = (*Store)(nil)
+	_ PeopleRepo    = (*Store)(nil)
+	_ EventLog      = (*Store)(nil)
+)
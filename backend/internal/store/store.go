@@ -28,6 +28,12 @@ func New(ctx context.Context, dsn string) (*Store, error) {
 	return &Store{pool: pool}, nil
 }
 
+// Pool exposes the underlying connection pool for infrastructure such as the
+// migration runner; handlers should go through Store methods instead.
+func (s *Store) Pool() *pgxpool.Pool {
+	return s.pool
+}
+
 func (s *Store) Close() {
 	if s.pool != nil {
 		s.pool.Close()
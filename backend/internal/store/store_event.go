@@ -0,0 +1,105 @@
+package store
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// ScorebookEventRetention is how many of the latest events are kept per scorebook
+// for replay; clients that fall further behind have to resync.
+const ScorebookEventRetention = 1000
+
+// AppendScorebookEvent stores a realtime event and returns its sequence number.
+// Sequence numbers start at 1 and increase by one per scorebook; the counter row
+// lock serializes concurrent appends.
+func (s *Store) AppendScorebookEvent(ctx context.Context, scorebookID string, payload []byte) (int64, error) {
+	var seq int64
+	err := s.pool.QueryRow(ctx, `
+WITH bumped AS (
+  UPDATE scorebooks
+  SET event_seq = event_seq + 1
+  WHERE id = $1::uuid
+  RETURNING id, event_seq
+)
+INSERT INTO scorebook_events (scorebook_id, seq, payload)
+SELECT id, event_seq, $2::jsonb FROM bumped
+RETURNING seq
+`, scorebookID, string(payload)).Scan(&seq)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, ErrNotFound
+		}
+		return 0, err
+	}
+
+	if seq > ScorebookEventRetention {
+		if _, err := s.pool.Exec(ctx, `
+DELETE FROM scorebook_events
+WHERE scorebook_id = $1::uuid AND seq <= $2
+`, scorebookID, seq-ScorebookEventRetention); err != nil {
+			return 0, err
+		}
+	}
+	return seq, nil
+}
+
+// ListScorebookEventsSince returns the events after seq `since` in order, together with
+// the latest sequence number. The returned bool is false when the gap can't be replayed:
+// the events were pruned, there are more than limit of them, or since is ahead of the log.
+func (s *Store) ListScorebookEventsSince(ctx context.Context, scorebookID string, since int64, limit int32) ([]ScorebookEvent, int64, bool, error) {
+	var latest int64
+	if err := s.pool.QueryRow(ctx, `SELECT event_seq FROM scorebooks WHERE id = $1::uuid`, scorebookID).Scan(&latest); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, 0, false, ErrNotFound
+		}
+		return nil, 0, false, err
+	}
+	if since > latest || since < 0 {
+		return nil, latest, false, nil
+	}
+	if since == latest {
+		return nil, latest, true, nil
+	}
+
+	rows, err := s.pool.Query(ctx, `
+SELECT seq, payload::text, created_at
+FROM scorebook_events
+WHERE scorebook_id = $1::uuid AND seq > $2
+ORDER BY seq ASC
+LIMIT $3
+`, scorebookID, since, limit+1)
+	if err != nil {
+		return nil, 0, false, err
+	}
+	defer rows.Close()
+
+	var out []ScorebookEvent
+	for rows.Next() {
+		var e ScorebookEvent
+		var payload string
+		if err := rows.Scan(&e.Seq, &payload, &e.CreatedAt); err != nil {
+			return nil, 0, false, err
+		}
+		e.Payload = []byte(payload)
+		out = append(out, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, false, err
+	}
+	if !Replayable(out, since, limit) {
+		return nil, latest, false, nil
+	}
+	return out, max(latest, out[len(out)-1].Seq), true, nil
+}
+
+// Replayable reports whether events (seq > since, ascending, at most limit+1 of them)
+// continue right after since. Sequence numbers have no holes other than pruned ones at
+// the start, so a matching first event means the whole range is present.
+func Replayable(events []ScorebookEvent, since int64, limit int32) bool {
+	if len(events) == 0 || len(events) > int(limit) {
+		return false
+	}
+	return events[0].Seq == since+1
+}
@@ -0,0 +1,67 @@
+package store
+
+import (
+	"context"
+)
+
+// EachRecord calls fn for every record of the scorebook, oldest first, while the
+// rows are being read, so exports never hold all records in memory. Reversal
+// records are skipped (voided records carry voided_at), as in ListRecords. The
+// caller checks access; an error from fn stops the iteration and is returned.
+func (s *Store) EachRecord(ctx context.Context, scorebookID string, fn func(ScoreRecord) error) error {
+	rows, err := s.pool.Query(ctx, `
+SELECT id::text, scorebook_id::text, from_member_id::text, to_member_id::text, delta::float8, note, created_at,
+       voided_at, COALESCE(voided_by_member_id::text, ''), COALESCE(round_id::text, '')
+FROM score_records
+WHERE scorebook_id = $1::uuid AND reverses_record_id IS NULL
+ORDER BY created_at ASC, id ASC
+`, scorebookID)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var r ScoreRecord
+		if err := rows.Scan(&r.ID, &r.ScorebookID, &r.FromMemberID, &r.ToMemberID, &r.Delta, &r.Note, &r.CreatedAt, &r.VoidedAt, &r.VoidedByMemberID, &r.RoundID); err != nil {
+			return err
+		}
+		if err := fn(r); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// EachLedgerRecord calls fn for every record of the ledger, oldest first, typed the
+// same way as GetLedgerDetail. The caller checks access.
+func (s *Store) EachLedgerRecord(ctx context.Context, ledgerID string, fn func(LedgerRecord) error) error {
+	ownerID, err := ledgerOwnerMemberID(ctx, s.pool, ledgerID)
+	if err != nil {
+		return err
+	}
+
+	rows, err := s.pool.Query(ctx, `
+SELECT id::text, scorebook_id::text, from_member_id::text, to_member_id::text, delta::float8, note, COALESCE(category_id::text, ''), created_at
+FROM score_records
+WHERE scorebook_id = $1::uuid
+ORDER BY created_at ASC, id ASC
+`, ledgerID)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var r LedgerRecord
+		var delta float64
+		if err := rows.Scan(&r.ID, &r.LedgerID, &r.FromMemberID, &r.ToMemberID, &delta, &r.Note, &r.CategoryID, &r.CreatedAt); err != nil {
+			return err
+		}
+		setLedgerRecordType(&r, delta, ownerID)
+		if err := fn(r); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
@@ -0,0 +1,289 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+)
+
+const invitationSelect = `
+SELECT i.id::text, i.scorebook_id::text, s.name, s.status::text, i.user_id, i.invited_by_user_id,
+  COALESCE(im.nickname, iu.wechat_nickname, ''), i.status, COALESCE(i.member_id::text, ''), i.created_at, i.resolved_at
+FROM scorebook_invitations i
+JOIN scorebooks s ON s.id = i.scorebook_id
+LEFT JOIN scorebook_members im ON im.scorebook_id = i.scorebook_id AND im.user_id = i.invited_by_user_id
+LEFT JOIN users iu ON iu.id = i.invited_by_user_id
+`
+
+func scanInvitation(row pgx.Row) (ScorebookInvitation, error) {
+	var inv ScorebookInvitation
+	err := row.Scan(&inv.ID, &inv.ScorebookID, &inv.ScorebookName, &inv.ScorebookStatus, &inv.UserID, &inv.InvitedByUserID,
+		&inv.InvitedByNickname, &inv.Status, &inv.MemberID, &inv.CreatedAt, &inv.ResolvedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ScorebookInvitation{}, ErrNotFound
+		}
+		return ScorebookInvitation{}, err
+	}
+	return inv, nil
+}
+
+// ListFrequentPlayers returns the users who share the most (non-deleted) scorebooks
+// with the user, most shared first.
+func (s *Store) ListFrequentPlayers(ctx context.Context, userID int64, limit int32) ([]FrequentPlayer, error) {
+	rows, err := s.pool.Query(ctx, `
+SELECT
+  o.user_id,
+  (array_agg(o.nickname ORDER BY s.updated_at DESC))[1],
+  (array_agg(o.avatar_url ORDER BY s.updated_at DESC))[1],
+  COUNT(*),
+  MAX(s.updated_at)
+FROM scorebook_members me
+JOIN scorebooks s ON s.id = me.scorebook_id AND s.book_type = 'scorebook' AND s.deleted_at IS NULL
+JOIN scorebook_members o ON o.scorebook_id = me.scorebook_id AND o.user_id IS NOT NULL AND o.user_id <> me.user_id
+WHERE me.user_id = $1
+GROUP BY o.user_id
+ORDER BY COUNT(*) DESC, MAX(s.updated_at) DESC, o.user_id ASC
+LIMIT $2
+`, userID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []FrequentPlayer
+	for rows.Next() {
+		var p FrequentPlayer
+		if err := rows.Scan(&p.UserID, &p.Nickname, &p.AvatarURL, &p.SharedScorebooks, &p.LastPlayedAt); err != nil {
+			return nil, err
+		}
+		out = append(out, p)
+	}
+	return out, rows.Err()
+}
+
+// InviteUsers invites users the caller has played with into a recording scorebook.
+// Owners and admins may do this. Users who are already members are skipped; inviting
+// a user again refreshes the pending invitation.
+func (s *Store) InviteUsers(ctx context.Context, scorebookID string, userID int64, inviteeIDs []int64) ([]ScorebookInvitation, error) {
+	tx, err := s.pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	_, role, err := s.memberRole(ctx, tx, scorebookID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !CanManage(role) {
+		return nil, ErrForbidden
+	}
+	var status string
+	if err := tx.QueryRow(ctx, `SELECT status::text FROM scorebooks WHERE id = $1::uuid`, scorebookID).Scan(&status); err != nil {
+		return nil, err
+	}
+	if status != "recording" {
+		return nil, ErrScorebookEnded
+	}
+
+	out, err := s.inviteUsersTx(ctx, tx, scorebookID, userID, inviteeIDs)
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// inviteUsersTx writes pending invitations inside tx. Every invitee must have shared
+// a scorebook with the inviter (ErrInvalidArgument otherwise, also for the inviter
+// themself); banned users get ErrBanned.
+func (s *Store) inviteUsersTx(ctx context.Context, tx pgx.Tx, scorebookID string, inviterID int64, inviteeIDs []int64) ([]ScorebookInvitation, error) {
+	var out []ScorebookInvitation
+	seen := map[int64]bool{}
+	for _, id := range inviteeIDs {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		if id == inviterID {
+			return nil, ErrInvalidArgument
+		}
+
+		var isMember, banned, coPlayer bool
+		err := tx.QueryRow(ctx, `
+SELECT
+  EXISTS (SELECT 1 FROM scorebook_members WHERE scorebook_id = $1::uuid AND user_id = $2),
+  EXISTS (SELECT 1 FROM scorebook_bans WHERE scorebook_id = $1::uuid AND user_id = $2),
+  EXISTS (
+    SELECT 1
+    FROM scorebook_members a
+    JOIN scorebook_members b ON b.scorebook_id = a.scorebook_id AND b.user_id = $2
+    JOIN scorebooks s ON s.id = a.scorebook_id AND s.book_type = 'scorebook' AND s.deleted_at IS NULL
+    WHERE a.user_id = $3
+  )
+`, scorebookID, id, inviterID).Scan(&isMember, &banned, &coPlayer)
+		if err != nil {
+			return nil, err
+		}
+		if isMember {
+			continue
+		}
+		if !coPlayer {
+			return nil, ErrInvalidArgument
+		}
+		if banned {
+			return nil, ErrBanned
+		}
+
+		var invID string
+		err = tx.QueryRow(ctx, `
+INSERT INTO scorebook_invitations (scorebook_id, user_id, invited_by_user_id)
+VALUES ($1::uuid, $2, $3)
+ON CONFLICT (scorebook_id, user_id) WHERE status = 'pending'
+DO UPDATE SET invited_by_user_id = EXCLUDED.invited_by_user_id, created_at = NOW()
+RETURNING id::text
+`, scorebookID, id, inviterID).Scan(&invID)
+		if err != nil {
+			return nil, err
+		}
+		inv, err := scanInvitation(tx.QueryRow(ctx, invitationSelect+`WHERE i.id = $1::uuid`, invID))
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, inv)
+	}
+	return out, nil
+}
+
+// ListMyInvitations returns invitations sent to the user, newest first, optionally
+// filtered by status.
+func (s *Store) ListMyInvitations(ctx context.Context, userID int64, status string, limit, offset int32) ([]ScorebookInvitation, error) {
+	switch status {
+	case "", InvitationPending, InvitationAccepted, InvitationDeclined:
+	default:
+		return nil, ErrInvalidArgument
+	}
+	rows, err := s.pool.Query(ctx, invitationSelect+`
+WHERE i.user_id = $1 AND s.deleted_at IS NULL AND ($2 = '' OR i.status = $2)
+ORDER BY i.created_at DESC, i.id DESC
+LIMIT $3 OFFSET $4
+`, userID, status, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []ScorebookInvitation
+	for rows.Next() {
+		inv, err := scanInvitation(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, inv)
+	}
+	return out, rows.Err()
+}
+
+// RespondInvitation accepts or declines one of the user's pending invitations.
+// Accepting makes the user a member (skipping join approval) and returns the member;
+// if they already joined some other way the existing member is returned.
+func (s *Store) RespondInvitation(ctx context.Context, invitationID string, user User, accept bool) (ScorebookInvitation, *Member, error) {
+	if !isUUID(invitationID) {
+		return ScorebookInvitation{}, nil, ErrNotFound
+	}
+	tx, err := s.pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return ScorebookInvitation{}, nil, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	var scorebookID, status string
+	var inviterID int64
+	err = tx.QueryRow(ctx, `
+SELECT i.scorebook_id::text, i.status, i.invited_by_user_id
+FROM scorebook_invitations i
+JOIN scorebooks s ON s.id = i.scorebook_id AND s.deleted_at IS NULL
+WHERE i.id = $1::uuid AND i.user_id = $2
+FOR UPDATE OF i
+`, invitationID, user.ID).Scan(&scorebookID, &status, &inviterID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ScorebookInvitation{}, nil, ErrNotFound
+		}
+		return ScorebookInvitation{}, nil, err
+	}
+	if status != InvitationPending {
+		return ScorebookInvitation{}, nil, ErrConflict
+	}
+
+	var member *Member
+	if accept {
+		m, err := s.acceptInvitationTx(ctx, tx, scorebookID, user, inviterID)
+		if err != nil {
+			return ScorebookInvitation{}, nil, err
+		}
+		member = &m
+	} else if _, err := tx.Exec(ctx, `
+UPDATE scorebook_invitations SET status = 'declined', resolved_at = NOW() WHERE id = $1::uuid
+`, invitationID); err != nil {
+		return ScorebookInvitation{}, nil, err
+	}
+
+	inv, err := scanInvitation(tx.QueryRow(ctx, invitationSelect+`WHERE i.id = $1::uuid`, invitationID))
+	if err != nil {
+		return ScorebookInvitation{}, nil, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return ScorebookInvitation{}, nil, err
+	}
+	return inv, member, nil
+}
+
+// acceptInvitationTx adds the invitee to the scorebook; insertJoinedMember marks the
+// invitation accepted. An existing member only resolves the invitation.
+func (s *Store) acceptInvitationTx(ctx context.Context, tx pgx.Tx, scorebookID string, user User, inviterID int64) (Member, error) {
+	existing, err := scanMember(tx.QueryRow(ctx, `
+SELECT `+memberColumns+`
+FROM scorebook_members
+WHERE scorebook_id = $1::uuid AND user_id = $2
+`, scorebookID, user.ID))
+	if err == nil {
+		_, err := tx.Exec(ctx, `
+UPDATE scorebook_invitations
+SET status = 'accepted', member_id = $3::uuid, resolved_at = NOW()
+WHERE scorebook_id = $1::uuid AND user_id = $2 AND status = 'pending'
+`, scorebookID, user.ID, existing.ID)
+		return existing, err
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return Member{}, err
+	}
+
+	var status string
+	var banned bool
+	err = tx.QueryRow(ctx, `
+SELECT s.status::text, EXISTS (SELECT 1 FROM scorebook_bans b WHERE b.scorebook_id = s.id AND b.user_id = $2)
+FROM scorebooks s
+WHERE s.id = $1::uuid
+`, scorebookID, user.ID).Scan(&status, &banned)
+	if err != nil {
+		return Member{}, err
+	}
+	if status == "ended" {
+		return Member{}, ErrScorebookEnded
+	}
+	if banned {
+		return Member{}, ErrBanned
+	}
+
+	nickname := strings.TrimSpace(user.WeChatNickname)
+	if nickname == "" {
+		nickname = "成员"
+	}
+	return s.insertJoinedMember(ctx, tx, scorebookID, user.ID, nickname, user.WeChatAvatarURL, RoleMember, "", &inviterID)
+}
@@ -3,34 +3,292 @@ package store
 import (
 	"context"
 	"errors"
+	"time"
 
 	"github.com/jackc/pgx/v5"
 )
 
+// GetInviteInfo looks up a book by its current invite code. A code that was replaced
+// by RegenerateInviteCode returns ErrInviteRegenerated.
 func (s *Store) GetInviteInfo(ctx context.Context, code string) (InviteInfo, error) {
 	var info InviteInfo
 	err := s.pool.QueryRow(ctx, `
-SELECT id::text, book_type, name, status::text, share_disabled, updated_at
-FROM scorebooks
-WHERE invite_code = $1 AND deleted_at IS NULL
-`, code).Scan(&info.BookID, &info.BookType, &info.Name, &info.Status, &info.ShareDisabled, &info.UpdatedAt)
+SELECT s.id::text, s.book_type, s.name, s.status::text, s.share_disabled, s.updated_at,
+  s.invite_expires_at, COALESCE(s.invite_max_uses, 0),
+  (SELECT COUNT(*) FROM invite_uses u WHERE u.scorebook_id = s.id AND u.invite_code = s.invite_code),
+  s.join_approval
+FROM scorebooks s
+WHERE s.invite_code = $1 AND s.deleted_at IS NULL
+`, code).Scan(&info.BookID, &info.BookType, &info.Name, &info.Status, &info.ShareDisabled, &info.UpdatedAt,
+		&info.ExpiresAt, &info.MaxUses, &info.Uses, &info.JoinApproval)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
-			return InviteInfo{}, ErrNotFound
+			return InviteInfo{}, s.unknownInvite(ctx, s.pool, code)
 		}
 		return InviteInfo{}, err
 	}
+	info.UnusableReason = InviteUnusableReason(info.Status, info.ExpiresAt, info.MaxUses, info.Uses, time.Now())
 	return info, nil
 }
 
-func (s *Store) ScorebookIDByInviteCode(ctx context.Context, code string) (string, error) {
-	var id string
-	err := s.pool.QueryRow(ctx, `SELECT id::text FROM scorebooks WHERE invite_code = $1 AND book_type = 'scorebook' AND deleted_at IS NULL`, code).Scan(&id)
+// unknownInvite returns ErrInviteRegenerated if code used to belong to a live book,
+// ErrNotFound otherwise.
+func (s *Store) unknownInvite(ctx context.Context, q rowQueryer, code string) error {
+	var retired bool
+	err := q.QueryRow(ctx, `
+SELECT EXISTS (
+  SELECT 1 FROM retired_invite_codes r
+  JOIN scorebooks s ON s.id = r.scorebook_id
+  WHERE r.code = $1 AND s.deleted_at IS NULL
+)
+`, code).Scan(&retired)
+	if err != nil {
+		return err
+	}
+	if retired {
+		return ErrInviteRegenerated
+	}
+	return ErrNotFound
+}
+
+// JoinByInviteCode joins the scorebook that currently owns code. New members are
+// refused once the code has expired or reached its usage limit, and every new member
+// is recorded in invite_uses. Existing members rejoin without any checks. When the
+// scorebook requires approval a pending JoinRequest is returned instead; the use is
+// recorded once the request is approved.
+func (s *Store) JoinByInviteCode(ctx context.Context, code string, user User, nickname, avatarURL, role string) (Member, *JoinRequest, error) {
+	tx, err := s.pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return Member{}, nil, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	var scorebookID string
+	err = tx.QueryRow(ctx, `
+SELECT id::text FROM scorebooks
+WHERE invite_code = $1 AND book_type = 'scorebook' AND deleted_at IS NULL
+FOR UPDATE
+`, code).Scan(&scorebookID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return Member{}, nil, s.unknownInvite(ctx, tx, code)
+		}
+		return Member{}, nil, err
+	}
+
+	m, req, err := s.joinScorebookTx(ctx, tx, scorebookID, user, nickname, avatarURL, role, code, func() error {
+		return s.checkInviteUsable(ctx, tx, scorebookID, code)
+	})
+	if err != nil {
+		return Member{}, nil, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return Member{}, nil, err
+	}
+	return m, req, nil
+}
+
+// checkInviteUsable locks the scorebook and checks that code may still admit a new
+// member: it must be the current invite code (ErrInviteRegenerated for one of the
+// scorebook's retired codes, ErrInviteRequired for anything else) and within its
+// expiry and usage limit.
+func (s *Store) checkInviteUsable(ctx context.Context, tx pgx.Tx, scorebookID, code string) error {
+	var current, status string
+	var expiresAt *time.Time
+	var maxUses int
+	err := tx.QueryRow(ctx, `
+SELECT invite_code, status::text, invite_expires_at, COALESCE(invite_max_uses, 0)
+FROM scorebooks
+WHERE id = $1::uuid
+FOR UPDATE
+`, scorebookID).Scan(&current, &status, &expiresAt, &maxUses)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrNotFound
+		}
+		return err
+	}
+	if code == "" {
+		return ErrInviteRequired
+	}
+	if code != current {
+		var retired bool
+		if err := tx.QueryRow(ctx, `
+SELECT EXISTS (SELECT 1 FROM retired_invite_codes WHERE code = $1 AND scorebook_id = $2::uuid)
+`, code, scorebookID).Scan(&retired); err != nil {
+			return err
+		}
+		if retired {
+			return ErrInviteRegenerated
+		}
+		return ErrInviteRequired
+	}
+
+	var uses int64
+	if err := tx.QueryRow(ctx, `
+SELECT COUNT(*) FROM invite_uses WHERE scorebook_id = $1::uuid AND invite_code = $2
+`, scorebookID, code).Scan(&uses); err != nil {
+		return err
+	}
+	switch InviteUnusableReason(status, expiresAt, maxUses, uses, time.Now()) {
+	case "expired":
+		return ErrInviteExpired
+	case "exhausted":
+		return ErrInviteExhausted
+	}
+	return nil
+}
+
+func (s *Store) getInviteSettings(ctx context.Context, q rowQueryer, scorebookID string) (InviteSettings, error) {
+	var out InviteSettings
+	err := q.QueryRow(ctx, `
+SELECT s.id::text, s.invite_code, s.invite_expires_at, COALESCE(s.invite_max_uses, 0),
+  (SELECT COUNT(*) FROM invite_uses u WHERE u.scorebook_id = s.id AND u.invite_code = s.invite_code),
+  s.join_approval
+FROM scorebooks s
+WHERE s.id = $1::uuid
+`, scorebookID).Scan(&out.ScorebookID, &out.Code, &out.ExpiresAt, &out.MaxUses, &out.Uses, &out.JoinApproval)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
-			return "", ErrNotFound
+			return InviteSettings{}, ErrNotFound
+		}
+		return InviteSettings{}, err
+	}
+	return out, nil
+}
+
+// GetInviteSettings returns the current invite code and its limits. Owners and admins may view them.
+func (s *Store) GetInviteSettings(ctx context.Context, scorebookID string, userID int64) (InviteSettings, error) {
+	_, role, err := s.memberRole(ctx, s.pool, scorebookID, userID)
+	if err != nil {
+		return InviteSettings{}, err
+	}
+	if !CanManage(role) {
+		return InviteSettings{}, ErrForbidden
+	}
+	return s.getInviteSettings(ctx, s.pool, scorebookID)
+}
+
+// UpdateInviteSettings changes the expiry time and usage limit of the current invite code
+// and whether joining requires approval.
+func (s *Store) UpdateInviteSettings(ctx context.Context, scorebookID string, userID int64, in InviteSettingsUpdate) (InviteSettings, error) {
+	if in.MaxUses != nil && *in.MaxUses < 0 {
+		return InviteSettings{}, ErrInvalidArgument
+	}
+	_, role, err := s.memberRole(ctx, s.pool, scorebookID, userID)
+	if err != nil {
+		return InviteSettings{}, err
+	}
+	if !CanManage(role) {
+		return InviteSettings{}, ErrForbidden
+	}
+
+	if _, err := s.pool.Exec(ctx, `
+UPDATE scorebooks
+SET invite_expires_at = CASE WHEN $2 THEN NULL ELSE COALESCE($3::timestamptz, invite_expires_at) END,
+    invite_max_uses = CASE WHEN $4::int IS NULL THEN invite_max_uses ELSE NULLIF($4::int, 0) END,
+    join_approval = COALESCE($5::boolean, join_approval),
+    updated_at = NOW()
+WHERE id = $1::uuid
+`, scorebookID, in.ExpiresSetNull, in.ExpiresAt, in.MaxUses, in.JoinApproval); err != nil {
+		return InviteSettings{}, err
+	}
+	return s.getInviteSettings(ctx, s.pool, scorebookID)
+}
+
+// RegenerateInviteCode replaces the invite code; the old code stops working and is
+// remembered so lookups can report ErrInviteRegenerated. Limits are kept and the
+// usage count starts over with the new code.
+func (s *Store) RegenerateInviteCode(ctx context.Context, scorebookID string, userID int64) (InviteSettings, error) {
+	tx, err := s.pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return InviteSettings{}, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	_, role, err := s.memberRole(ctx, tx, scorebookID, userID)
+	if err != nil {
+		return InviteSettings{}, err
+	}
+	if !CanManage(role) {
+		return InviteSettings{}, ErrForbidden
+	}
+
+	var old string
+	if err := tx.QueryRow(ctx, `SELECT invite_code FROM scorebooks WHERE id = $1::uuid FOR UPDATE`, scorebookID).Scan(&old); err != nil {
+		return InviteSettings{}, err
+	}
+
+	var code string
+	for i := 0; i < 5 && code == ""; i++ {
+		candidate := randomInviteCode(8)
+		var taken bool
+		if err := tx.QueryRow(ctx, `
+SELECT EXISTS (SELECT 1 FROM scorebooks WHERE invite_code = $1)
+    OR EXISTS (SELECT 1 FROM retired_invite_codes WHERE code = $1)
+`, candidate).Scan(&taken); err != nil {
+			return InviteSettings{}, err
+		}
+		if !taken {
+			code = candidate
+		}
+	}
+	if code == "" {
+		return InviteSettings{}, ErrConflict
+	}
+
+	if _, err := tx.Exec(ctx, `
+UPDATE scorebooks SET invite_code = $2, updated_at = NOW() WHERE id = $1::uuid
+`, scorebookID, code); err != nil {
+		return InviteSettings{}, err
+	}
+	if _, err := tx.Exec(ctx, `
+INSERT INTO retired_invite_codes (code, scorebook_id) VALUES ($1, $2::uuid)
+ON CONFLICT (code) DO NOTHING
+`, old, scorebookID); err != nil {
+		return InviteSettings{}, err
+	}
+	out, err := s.getInviteSettings(ctx, tx, scorebookID)
+	if err != nil {
+		return InviteSettings{}, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return InviteSettings{}, err
+	}
+	return out, nil
+}
+
+// ListInviteUses returns joins through any of the scorebook's invite codes, newest first.
+func (s *Store) ListInviteUses(ctx context.Context, scorebookID string, userID int64, limit, offset int32) ([]InviteUse, error) {
+	_, role, err := s.memberRole(ctx, s.pool, scorebookID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !CanManage(role) {
+		return nil, ErrForbidden
+	}
+
+	rows, err := s.pool.Query(ctx, `
+SELECT u.invite_code, u.user_id, COALESCE(u.member_id::text, ''), COALESCE(m.nickname, us.wechat_nickname, ''), u.created_at
+FROM invite_uses u
+LEFT JOIN scorebook_members m ON m.id = u.member_id
+LEFT JOIN users us ON us.id = u.user_id
+WHERE u.scorebook_id = $1::uuid
+ORDER BY u.created_at DESC, u.id DESC
+LIMIT $2 OFFSET $3
+`, scorebookID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []InviteUse
+	for rows.Next() {
+		var u InviteUse
+		if err := rows.Scan(&u.Code, &u.UserID, &u.MemberID, &u.Nickname, &u.CreatedAt); err != nil {
+			return nil, err
 		}
-		return "", err
+		out = append(out, u)
 	}
-	return id, nil
+	return out, rows.Err()
 }
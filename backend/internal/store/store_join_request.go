@@ -0,0 +1,261 @@
+package store
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+)
+
+const joinRequestColumns = `r.id::text, r.scorebook_id::text, r.user_id, r.nickname, r.avatar_url, r.role, COALESCE(r.invite_code, ''), r.status, COALESCE(r.member_id::text, ''), r.resolved_by_user_id, r.created_at, r.resolved_at`
+
+func scanJoinRequest(row pgx.Row) (JoinRequest, error) {
+	var r JoinRequest
+	err := row.Scan(&r.ID, &r.ScorebookID, &r.UserID, &r.Nickname, &r.AvatarURL, &r.Role, &r.InviteCode, &r.Status, &r.MemberID, &r.ResolvedByUserID, &r.CreatedAt, &r.ResolvedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return JoinRequest{}, ErrNotFound
+		}
+		return JoinRequest{}, err
+	}
+	return r, nil
+}
+
+func (s *Store) getJoinRequest(ctx context.Context, q rowQueryer, scorebookID, id string) (JoinRequest, error) {
+	return scanJoinRequest(q.QueryRow(ctx, `
+SELECT `+joinRequestColumns+`
+FROM scorebook_join_requests r
+WHERE r.id = $1::uuid AND r.scorebook_id = $2::uuid
+`, id, scorebookID))
+}
+
+// requestJoinTx records a pending join request, refreshing the user's existing pending
+// request (nickname, avatar, role and invite code) if there is one.
+func (s *Store) requestJoinTx(ctx context.Context, tx pgx.Tx, scorebookID string, userID int64, nickname, avatarURL, role, inviteCode string) (JoinRequest, error) {
+	var id string
+	if err := tx.QueryRow(ctx, `
+INSERT INTO scorebook_join_requests (scorebook_id, user_id, nickname, avatar_url, role, invite_code)
+VALUES ($1::uuid, $2, $3, $4, $5, NULLIF($6, ''))
+ON CONFLICT (scorebook_id, user_id) WHERE status = 'pending'
+DO UPDATE SET nickname = EXCLUDED.nickname, avatar_url = EXCLUDED.avatar_url,
+  role = EXCLUDED.role, invite_code = EXCLUDED.invite_code
+RETURNING id::text
+`, scorebookID, userID, nickname, avatarURL, role, inviteCode).Scan(&id); err != nil {
+		return JoinRequest{}, err
+	}
+	return s.getJoinRequest(ctx, tx, scorebookID, id)
+}
+
+// insertJoinedMember creates a new member for a join. A join through an invite code is
+// recorded in invite_uses, and the user's pending join request (if any) is marked
+// approved; resolvedBy is the approving manager, nil when no approval was needed.
+func (s *Store) insertJoinedMember(ctx context.Context, tx pgx.Tx, scorebookID string, userID int64, nickname, avatarURL, role, inviteCode string, resolvedBy *int64) (Member, error) {
+	var m Member
+	err := tx.QueryRow(ctx, `
+INSERT INTO scorebook_members (scorebook_id, user_id, role, nickname, avatar_url, updated_at)
+VALUES ($1::uuid, $2, $5, $3, $4, NOW())
+RETURNING id::text, scorebook_id::text, user_id, role::text, nickname, avatar_url, joined_at, updated_at
+`, scorebookID, userID, nickname, avatarURL, role).Scan(
+		&m.ID,
+		&m.ScorebookID,
+		&m.UserID,
+		&m.Role,
+		&m.Nickname,
+		&m.AvatarURL,
+		&m.JoinedAt,
+		&m.UpdatedAt,
+	)
+	if err != nil {
+		return Member{}, err
+	}
+	if inviteCode != "" {
+		if _, err := tx.Exec(ctx, `
+INSERT INTO invite_uses (scorebook_id, invite_code, user_id, member_id)
+VALUES ($1::uuid, $2, $3, $4::uuid)
+`, scorebookID, inviteCode, userID, m.ID); err != nil {
+			return Member{}, err
+		}
+	}
+	if _, err := tx.Exec(ctx, `
+UPDATE scorebook_join_requests
+SET status = 'approved', member_id = $3::uuid, resolved_by_user_id = $4, resolved_at = NOW()
+WHERE scorebook_id = $1::uuid AND user_id = $2 AND status = 'pending'
+`, scorebookID, userID, m.ID, resolvedBy); err != nil {
+		return Member{}, err
+	}
+	if _, err := tx.Exec(ctx, `
+UPDATE scorebook_invitations
+SET status = 'accepted', member_id = $3::uuid, resolved_at = NOW()
+WHERE scorebook_id = $1::uuid AND user_id = $2 AND status = 'pending'
+`, scorebookID, userID, m.ID); err != nil {
+		return Member{}, err
+	}
+	_, _ = tx.Exec(ctx, `UPDATE scorebooks SET updated_at = NOW() WHERE id = $1::uuid`, scorebookID)
+	return m, nil
+}
+
+// ListJoinRequests returns the scorebook's join requests, newest first, optionally
+// filtered by status. Owners and admins may view them.
+func (s *Store) ListJoinRequests(ctx context.Context, scorebookID string, userID int64, status string, limit, offset int32) ([]JoinRequest, error) {
+	switch status {
+	case "", JoinRequestPending, JoinRequestApproved, JoinRequestRejected:
+	default:
+		return nil, ErrInvalidArgument
+	}
+	_, role, err := s.memberRole(ctx, s.pool, scorebookID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !CanManage(role) {
+		return nil, ErrForbidden
+	}
+
+	rows, err := s.pool.Query(ctx, `
+SELECT `+joinRequestColumns+`
+FROM scorebook_join_requests r
+WHERE r.scorebook_id = $1::uuid AND ($2 = '' OR r.status = $2)
+ORDER BY r.created_at DESC, r.id DESC
+LIMIT $3 OFFSET $4
+`, scorebookID, status, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []JoinRequest
+	for rows.Next() {
+		r, err := scanJoinRequest(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, r)
+	}
+	return out, rows.Err()
+}
+
+// GetMyJoinRequest returns the user's most recent join request for the scorebook.
+func (s *Store) GetMyJoinRequest(ctx context.Context, scorebookID string, userID int64) (JoinRequest, error) {
+	if !isUUID(scorebookID) {
+		return JoinRequest{}, ErrNotFound
+	}
+	return scanJoinRequest(s.pool.QueryRow(ctx, `
+SELECT `+joinRequestColumns+`
+FROM scorebook_join_requests r
+WHERE r.scorebook_id = $1::uuid AND r.user_id = $2
+ORDER BY r.created_at DESC, r.id DESC
+LIMIT 1
+`, scorebookID, userID))
+}
+
+// ApproveJoinRequest admits a pending join request as a new member with the requested
+// role. Owners and admins may do this; a request that was already handled returns
+// ErrConflict. A request made through an invite code is admitted only while that code
+// is still usable (see checkInviteUsable), so approvals cannot exceed its limits.
+func (s *Store) ApproveJoinRequest(ctx context.Context, scorebookID string, userID int64, requestID string) (JoinRequest, Member, error) {
+	if !isUUID(requestID) {
+		return JoinRequest{}, Member{}, ErrNotFound
+	}
+
+	tx, err := s.pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return JoinRequest{}, Member{}, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	req, err := s.lockPendingJoinRequest(ctx, tx, scorebookID, userID, requestID)
+	if err != nil {
+		return JoinRequest{}, Member{}, err
+	}
+
+	var status string
+	if err := tx.QueryRow(ctx, `SELECT status::text FROM scorebooks WHERE id = $1::uuid FOR UPDATE`, scorebookID).Scan(&status); err != nil {
+		return JoinRequest{}, Member{}, err
+	}
+	if status == "ended" {
+		return JoinRequest{}, Member{}, ErrScorebookEnded
+	}
+	var banned bool
+	if err := tx.QueryRow(ctx, `
+SELECT EXISTS (SELECT 1 FROM scorebook_bans WHERE scorebook_id = $1::uuid AND user_id = $2)
+`, scorebookID, req.UserID).Scan(&banned); err != nil {
+		return JoinRequest{}, Member{}, err
+	}
+	if banned {
+		return JoinRequest{}, Member{}, ErrBanned
+	}
+	if req.InviteCode != "" {
+		if err := s.checkInviteUsable(ctx, tx, scorebookID, req.InviteCode); err != nil {
+			return JoinRequest{}, Member{}, err
+		}
+	}
+
+	m, err := s.insertJoinedMember(ctx, tx, scorebookID, req.UserID, req.Nickname, req.AvatarURL, req.Role, req.InviteCode, &userID)
+	if err != nil {
+		return JoinRequest{}, Member{}, err
+	}
+	req, err = s.getJoinRequest(ctx, tx, scorebookID, requestID)
+	if err != nil {
+		return JoinRequest{}, Member{}, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return JoinRequest{}, Member{}, err
+	}
+	return req, m, nil
+}
+
+// RejectJoinRequest rejects a pending join request. The user may apply again later.
+func (s *Store) RejectJoinRequest(ctx context.Context, scorebookID string, userID int64, requestID string) (JoinRequest, error) {
+	if !isUUID(requestID) {
+		return JoinRequest{}, ErrNotFound
+	}
+
+	tx, err := s.pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return JoinRequest{}, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	if _, err := s.lockPendingJoinRequest(ctx, tx, scorebookID, userID, requestID); err != nil {
+		return JoinRequest{}, err
+	}
+	if _, err := tx.Exec(ctx, `
+UPDATE scorebook_join_requests
+SET status = 'rejected', resolved_by_user_id = $2, resolved_at = NOW()
+WHERE id = $1::uuid
+`, requestID, userID); err != nil {
+		return JoinRequest{}, err
+	}
+	req, err := s.getJoinRequest(ctx, tx, scorebookID, requestID)
+	if err != nil {
+		return JoinRequest{}, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return JoinRequest{}, err
+	}
+	return req, nil
+}
+
+// lockPendingJoinRequest checks that the caller may manage the scorebook and locks the
+// request, which must still be pending.
+func (s *Store) lockPendingJoinRequest(ctx context.Context, tx pgx.Tx, scorebookID string, userID int64, requestID string) (JoinRequest, error) {
+	_, role, err := s.memberRole(ctx, tx, scorebookID, userID)
+	if err != nil {
+		return JoinRequest{}, err
+	}
+	if !CanManage(role) {
+		return JoinRequest{}, ErrForbidden
+	}
+	req, err := scanJoinRequest(tx.QueryRow(ctx, `
+SELECT `+joinRequestColumns+`
+FROM scorebook_join_requests r
+WHERE r.id = $1::uuid AND r.scorebook_id = $2::uuid
+FOR UPDATE
+`, requestID, scorebookID))
+	if err != nil {
+		return JoinRequest{}, err
+	}
+	if req.Status != JoinRequestPending {
+		return JoinRequest{}, ErrConflict
+	}
+	return req, nil
+}
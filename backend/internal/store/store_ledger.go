@@ -127,7 +127,12 @@ SELECT
   s.status::text,
   s.ended_at,
   (SELECT COUNT(*) FROM scorebook_members m WHERE m.scorebook_id = s.id) AS member_count,
-  (SELECT COUNT(*) FROM score_records r WHERE r.scorebook_id = s.id) AS record_count
+  (SELECT COUNT(*) FROM score_records r WHERE r.scorebook_id = s.id) AS record_count,
+  CASE
+    WHEN s.created_by_user_id = $1 THEN 'owner'
+    WHEN EXISTS (SELECT 1 FROM ledger_recorders lr WHERE lr.scorebook_id = s.id AND lr.user_id = $1) THEN 'recorder'
+    ELSE 'member'
+  END AS role
 FROM scorebooks s
 WHERE s.book_type = 'ledger' AND s.deleted_at IS NULL
   AND (
@@ -137,6 +142,11 @@ WHERE s.book_type = 'ledger' AND s.deleted_at IS NULL
       FROM scorebook_members m
       WHERE m.scorebook_id = s.id AND m.user_id = $1
     )
+    OR EXISTS (
+      SELECT 1
+      FROM ledger_recorders lr
+      WHERE lr.scorebook_id = s.id AND lr.user_id = $1
+    )
   )
 ORDER BY
   CASE s.status::text
@@ -164,6 +174,7 @@ LIMIT $2 OFFSET $3
 			&it.EndedAt,
 			&it.MemberCount,
 			&it.RecordCount,
+			&it.Role,
 		); err != nil {
 			return nil, err
 		}
@@ -245,10 +256,12 @@ ORDER BY joined_at ASC
 	}
 
 	recRows, err := s.pool.Query(ctx, `
-SELECT id::text, scorebook_id::text, from_member_id::text, to_member_id::text, delta::float8, note, created_at
-FROM score_records
-WHERE scorebook_id = $1::uuid
-ORDER BY created_at DESC
+SELECT r.id::text, r.scorebook_id::text, r.from_member_id::text, r.to_member_id::text, r.delta::float8, r.note, COALESCE(r.category_id::text, ''),
+  r.created_by_user_id, COALESCE(u.wechat_nickname, ''), r.created_at
+FROM score_records r
+LEFT JOIN users u ON u.id = r.created_by_user_id
+WHERE r.scorebook_id = $1::uuid
+ORDER BY r.created_at DESC
 LIMIT $2 OFFSET $3
 `, ledgerID, limit, offset)
 	if err != nil {
@@ -260,6 +273,7 @@ LIMIT $2 OFFSET $3
 	for recRows.Next() {
 		var r LedgerRecord
 		var delta float64
+		var createdBy sql.NullInt64
 		if err := recRows.Scan(
 			&r.ID,
 			&r.LedgerID,
@@ -267,34 +281,17 @@ LIMIT $2 OFFSET $3
 			&r.ToMemberID,
 			&delta,
 			&r.Note,
+			&r.CategoryID,
+			&createdBy,
+			&r.CreatedByNickname,
 			&r.CreatedAt,
 		); err != nil {
 			return Scorebook{}, nil, nil, err
 		}
-		if math.Abs(delta) < 1e-9 {
-			r.Type = "remark"
-			r.MemberID = r.ToMemberID
-			r.Amount = 0
-			records = append(records, r)
-			continue
-		}
-		if delta < 0 {
-			r.Amount = float64(-delta)
-			r.Type = "expense"
-		} else {
-			r.Amount = float64(delta)
-			r.Type = "income"
-		}
-		if ownerID != "" {
-			if r.FromMemberID == ownerID {
-				r.MemberID = r.ToMemberID
-			} else if r.ToMemberID == ownerID {
-				r.MemberID = r.FromMemberID
-			}
-		}
-		if r.MemberID == "" {
-			r.MemberID = r.ToMemberID
+		if createdBy.Valid {
+			r.CreatedByUserID = &createdBy.Int64
 		}
+		setLedgerRecordType(&r, delta, ownerID)
 		records = append(records, r)
 	}
 	if err := recRows.Err(); err != nil {
@@ -369,22 +366,10 @@ func (s *Store) AddLedgerMember(ctx context.Context, ledgerID string, userID int
 	}
 	defer func() { _ = tx.Rollback(ctx) }()
 
-	var status string
-	var ownerID int64
-	err = tx.QueryRow(ctx, `
-SELECT status::text, created_by_user_id
-FROM scorebooks
-WHERE id = $1::uuid AND book_type = 'ledger' AND deleted_at IS NULL
-`, ledgerID).Scan(&status, &ownerID)
+	status, err := s.ledgerWriterStatus(ctx, tx, ledgerID, userID)
 	if err != nil {
-		if errors.Is(err, pgx.ErrNoRows) {
-			return LedgerMember{}, ErrNotFound
-		}
 		return LedgerMember{}, err
 	}
-	if ownerID != userID {
-		return LedgerMember{}, ErrForbidden
-	}
 	if status != "recording" {
 		return LedgerMember{}, ErrScorebookEnded
 	}
@@ -435,17 +420,8 @@ func (s *Store) UpdateLedgerMember(ctx context.Context, ledgerID string, userID
 	}
 	defer func() { _ = tx.Rollback(ctx) }()
 
-	var status string
-	var ownerID int64
-	err = tx.QueryRow(ctx, `
-SELECT status::text, created_by_user_id
-FROM scorebooks
-WHERE id = $1::uuid AND book_type = 'ledger' AND deleted_at IS NULL
-`, ledgerID).Scan(&status, &ownerID)
+	status, role, err := s.ledgerRole(ctx, tx, ledgerID, userID)
 	if err != nil {
-		if errors.Is(err, pgx.ErrNoRows) {
-			return LedgerMember{}, ErrNotFound
-		}
 		return LedgerMember{}, err
 	}
 	if status != "recording" {
@@ -464,7 +440,8 @@ WHERE scorebook_id = $1::uuid AND id = $2::uuid
 		}
 		return LedgerMember{}, err
 	}
-	if ownerID != userID {
+	// 账本创建者与记账员可修改任意成员，其他人只能修改自己绑定的成员
+	if role == "" {
 		if !targetUserID.Valid || targetUserID.Int64 != userID {
 			return LedgerMember{}, ErrForbidden
 		}
@@ -615,7 +592,9 @@ RETURNING id::text, scorebook_id::text, user_id, role::text, nickname, avatar_ur
 	return updated, nil
 }
 
-func (s *Store) AddLedgerRecord(ctx context.Context, ledgerID string, userID int64, memberID string, recordType string, amount float64, note string) (LedgerRecord, error) {
+// AddLedgerRecord records income from / expense to a member, optionally in a category
+// of the ledger (an unknown category is ErrInvalidArgument).
+func (s *Store) AddLedgerRecord(ctx context.Context, ledgerID string, userID int64, memberID string, recordType string, amount float64, note, categoryID string) (LedgerRecord, error) {
 	if strings.TrimSpace(memberID) == "" {
 		return LedgerRecord{}, ErrInvalidArgument
 	}
@@ -623,7 +602,7 @@ func (s *Store) AddLedgerRecord(ctx context.Context, ledgerID string, userID int
 		return LedgerRecord{}, ErrInvalidArgument
 	}
 	var ok bool
-	amount, ok = normalizeAmount(amount)
+	amount, ok = NormalizeAmount(amount)
 	if !ok {
 		return LedgerRecord{}, ErrInvalidArgument
 	}
@@ -634,49 +613,40 @@ func (s *Store) AddLedgerRecord(ctx context.Context, ledgerID string, userID int
 	}
 	defer func() { _ = tx.Rollback(ctx) }()
 
-	var status string
-	var ownerID int64
-	err = tx.QueryRow(ctx, `
-SELECT status::text, created_by_user_id
-FROM scorebooks
-WHERE id = $1::uuid AND book_type = 'ledger' AND deleted_at IS NULL
-`, ledgerID).Scan(&status, &ownerID)
+	status, err := s.ledgerWriterStatus(ctx, tx, ledgerID, userID)
 	if err != nil {
-		if errors.Is(err, pgx.ErrNoRows) {
-			return LedgerRecord{}, ErrNotFound
-		}
 		return LedgerRecord{}, err
 	}
-	if ownerID != userID {
-		return LedgerRecord{}, ErrForbidden
-	}
 	if status != "recording" {
 		return LedgerRecord{}, ErrScorebookEnded
 	}
 
-	var ownerMemberID string
-	err = tx.QueryRow(ctx, `
-SELECT id::text
-FROM scorebook_members
-WHERE scorebook_id = $1::uuid AND role = 'owner'
-`, ledgerID).Scan(&ownerMemberID)
+	ownerMemberID, err := ledgerOwnerMemberID(ctx, tx, ledgerID)
 	if err != nil {
 		return LedgerRecord{}, err
 	}
 	if ownerMemberID == "" {
+		return LedgerRecord{}, ErrNotFound
+	}
+	if ownerMemberID == memberID {
+		return LedgerRecord{}, ErrInvalidArgument
+	}
+	var category *string
+	if categoryID != "" {
+		if !isUUID(categoryID) {
+			return LedgerRecord{}, ErrInvalidArgument
+		}
+		var found bool
 		err = tx.QueryRow(ctx, `
-SELECT id::text
-FROM scorebook_members
-WHERE scorebook_id = $1::uuid
-ORDER BY joined_at ASC
-LIMIT 1
-`, ledgerID).Scan(&ownerMemberID)
+SELECT EXISTS (SELECT 1 FROM ledger_categories WHERE scorebook_id = $1::uuid AND id = $2::uuid)
+`, ledgerID, categoryID).Scan(&found)
 		if err != nil {
 			return LedgerRecord{}, err
 		}
-	}
-	if ownerMemberID == memberID {
-		return LedgerRecord{}, ErrInvalidArgument
+		if !found {
+			return LedgerRecord{}, ErrInvalidArgument
+		}
+		category = &categoryID
 	}
 
 	var memberRemark string
@@ -708,16 +678,19 @@ WHERE scorebook_id = $1::uuid AND id = $2::uuid
 
 	var record LedgerRecord
 	err = tx.QueryRow(ctx, `
-INSERT INTO score_records (scorebook_id, from_member_id, to_member_id, delta, note)
-VALUES ($1::uuid, $2::uuid, $3::uuid, $4, $5)
-RETURNING id::text, scorebook_id::text, from_member_id::text, to_member_id::text, delta::float8, note, created_at
-`, ledgerID, fromMemberID, toMemberID, delta, note).Scan(
+INSERT INTO score_records (scorebook_id, from_member_id, to_member_id, delta, note, category_id, created_by_user_id)
+VALUES ($1::uuid, $2::uuid, $3::uuid, $4, $5, $6::uuid, $7)
+RETURNING id::text, scorebook_id::text, from_member_id::text, to_member_id::text, delta::float8, note, COALESCE(category_id::text, ''),
+  COALESCE((SELECT wechat_nickname FROM users WHERE id = $7), ''), created_at
+`, ledgerID, fromMemberID, toMemberID, delta, note, category, userID).Scan(
 		&record.ID,
 		&record.LedgerID,
 		&record.FromMemberID,
 		&record.ToMemberID,
 		&record.Amount,
 		&record.Note,
+		&record.CategoryID,
+		&record.CreatedByNickname,
 		&record.CreatedAt,
 	)
 	if err != nil {
@@ -726,6 +699,7 @@ RETURNING id::text, scorebook_id::text, from_member_id::text, to_member_id::text
 	record.Type = recordType
 	record.MemberID = memberID
 	record.Amount = amount
+	record.CreatedByUserID = &userID
 
 	if _, err := tx.Exec(ctx, `
 UPDATE scorebook_members
@@ -836,3 +810,48 @@ RETURNING id::text, name, location_text, start_time, updated_at, status::text, b
 	}
 	return sb, nil
 }
+
+// ledgerOwnerMemberID returns the ledger's own member, the counterparty of every gift
+// record: the member with the owner role, else the earliest member (as GetLedgerDetail
+// does), or "" for an empty ledger. It is fixed at creation; transferring the ledger
+// changes created_by_user_id only, so past records keep their attribution.
+func ledgerOwnerMemberID(ctx context.Context, q rowQueryer, ledgerID string) (string, error) {
+	var id string
+	err := q.QueryRow(ctx, `
+SELECT COALESCE((
+  SELECT id::text FROM scorebook_members
+  WHERE scorebook_id = $1::uuid
+  ORDER BY (role = 'owner') DESC, joined_at ASC
+  LIMIT 1
+), '')
+`, ledgerID).Scan(&id)
+	return id, err
+}
+
+// setLedgerRecordType maps a score_records row onto a ledger record: a zero delta is
+// a remark, otherwise the sign gives income/expense and MemberID is the non-owner side.
+func setLedgerRecordType(r *LedgerRecord, delta float64, ownerID string) {
+	if math.Abs(delta) < 1e-9 {
+		r.Type = "remark"
+		r.MemberID = r.ToMemberID
+		r.Amount = 0
+		return
+	}
+	if delta < 0 {
+		r.Amount = float64(-delta)
+		r.Type = "expense"
+	} else {
+		r.Amount = float64(delta)
+		r.Type = "income"
+	}
+	if ownerID != "" {
+		if r.FromMemberID == ownerID {
+			r.MemberID = r.ToMemberID
+		} else if r.ToMemberID == ownerID {
+			r.MemberID = r.FromMemberID
+		}
+	}
+	if r.MemberID == "" {
+		r.MemberID = r.ToMemberID
+	}
+}
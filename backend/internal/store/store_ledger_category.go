@@ -0,0 +1,311 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+const ledgerCategoryColumns = `id::text, scorebook_id::text, name, created_at, updated_at`
+
+func scanLedgerCategory(row pgx.Row) (LedgerCategory, error) {
+	var c LedgerCategory
+	err := row.Scan(&c.ID, &c.LedgerID, &c.Name, &c.CreatedAt, &c.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return LedgerCategory{}, ErrNotFound
+		}
+		return LedgerCategory{}, err
+	}
+	return c, nil
+}
+
+// ledgerOwnerStatus returns the status of a non-deleted ledger owned by userID:
+// ErrNotFound if there is no such ledger, ErrForbidden if the user is not its owner.
+func (s *Store) ledgerOwnerStatus(ctx context.Context, q rowQueryer, ledgerID string, userID int64) (string, error) {
+	if !isUUID(ledgerID) {
+		return "", ErrNotFound
+	}
+	var status string
+	var ownerID int64
+	err := q.QueryRow(ctx, `
+SELECT status::text, created_by_user_id
+FROM scorebooks
+WHERE id = $1::uuid AND book_type = 'ledger' AND deleted_at IS NULL
+`, ledgerID).Scan(&status, &ownerID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", ErrNotFound
+		}
+		return "", err
+	}
+	if ownerID != userID {
+		return "", ErrForbidden
+	}
+	return status, nil
+}
+
+// ListLedgerCategories returns the ledger's categories in creation order, for the
+// owner and recorders.
+func (s *Store) ListLedgerCategories(ctx context.Context, ledgerID string, userID int64) ([]LedgerCategory, error) {
+	if _, err := s.ledgerWriterStatus(ctx, s.pool, ledgerID, userID); err != nil {
+		return nil, err
+	}
+	return s.ledgerCategories(ctx, ledgerID)
+}
+
+func (s *Store) ledgerCategories(ctx context.Context, ledgerID string) ([]LedgerCategory, error) {
+	rows, err := s.pool.Query(ctx, `
+SELECT `+ledgerCategoryColumns+`
+FROM ledger_categories
+WHERE scorebook_id = $1::uuid
+ORDER BY created_at ASC, id ASC
+`, ledgerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := []LedgerCategory{}
+	for rows.Next() {
+		c, err := scanLedgerCategory(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, c)
+	}
+	return out, rows.Err()
+}
+
+// CreateLedgerCategory adds a category to a recording ledger. Names are unique per
+// ledger (ErrConflict).
+func (s *Store) CreateLedgerCategory(ctx context.Context, ledgerID string, userID int64, name string) (LedgerCategory, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return LedgerCategory{}, ErrInvalidArgument
+	}
+	status, err := s.ledgerOwnerStatus(ctx, s.pool, ledgerID, userID)
+	if err != nil {
+		return LedgerCategory{}, err
+	}
+	if status != "recording" {
+		return LedgerCategory{}, ErrScorebookEnded
+	}
+	c, err := scanLedgerCategory(s.pool.QueryRow(ctx, `
+INSERT INTO ledger_categories (scorebook_id, name)
+VALUES ($1::uuid, $2)
+RETURNING `+ledgerCategoryColumns+`
+`, ledgerID, name))
+	if isUniqueViolation(err) {
+		return LedgerCategory{}, ErrConflict
+	}
+	return c, err
+}
+
+// UpdateLedgerCategory renames a category.
+func (s *Store) UpdateLedgerCategory(ctx context.Context, ledgerID string, userID int64, categoryID, name string) (LedgerCategory, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return LedgerCategory{}, ErrInvalidArgument
+	}
+	status, err := s.ledgerOwnerStatus(ctx, s.pool, ledgerID, userID)
+	if err != nil {
+		return LedgerCategory{}, err
+	}
+	if status != "recording" {
+		return LedgerCategory{}, ErrScorebookEnded
+	}
+	if !isUUID(categoryID) {
+		return LedgerCategory{}, ErrNotFound
+	}
+	c, err := scanLedgerCategory(s.pool.QueryRow(ctx, `
+UPDATE ledger_categories
+SET name = $3, updated_at = NOW()
+WHERE scorebook_id = $1::uuid AND id = $2::uuid
+RETURNING `+ledgerCategoryColumns+`
+`, ledgerID, categoryID, name))
+	if isUniqueViolation(err) {
+		return LedgerCategory{}, ErrConflict
+	}
+	return c, err
+}
+
+// DeleteLedgerCategory deletes a category; its records become uncategorized.
+func (s *Store) DeleteLedgerCategory(ctx context.Context, ledgerID string, userID int64, categoryID string) error {
+	status, err := s.ledgerOwnerStatus(ctx, s.pool, ledgerID, userID)
+	if err != nil {
+		return err
+	}
+	if status != "recording" {
+		return ErrScorebookEnded
+	}
+	if !isUUID(categoryID) {
+		return ErrNotFound
+	}
+	tag, err := s.pool.Exec(ctx, `
+DELETE FROM ledger_categories
+WHERE scorebook_id = $1::uuid AND id = $2::uuid
+`, ledgerID, categoryID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// GetLedgerSummary totals the ledger's income/expense records by type, category and
+// member. Remark records carry no amount and are left out. Owner only.
+func (s *Store) GetLedgerSummary(ctx context.Context, ledgerID string, userID int64) (LedgerSummary, error) {
+	if _, err := s.ledgerOwnerStatus(ctx, s.pool, ledgerID, userID); err != nil {
+		return LedgerSummary{}, err
+	}
+	categories, err := s.ledgerCategories(ctx, ledgerID)
+	if err != nil {
+		return LedgerSummary{}, err
+	}
+	_, members, _, err := s.GetLedgerDetail(ctx, ledgerID, 0, 0)
+	if err != nil {
+		return LedgerSummary{}, err
+	}
+
+	ownerMemberID, err := ledgerOwnerMemberID(ctx, s.pool, ledgerID)
+	if err != nil {
+		return LedgerSummary{}, err
+	}
+
+	// 成员归属与 setLedgerRecordType 一致：取非账本所有者的一方
+	rows, err := s.pool.Query(ctx, `
+SELECT
+  COALESCE(r.category_id::text, ''),
+  (CASE
+    WHEN r.from_member_id::text = $2 THEN r.to_member_id
+    WHEN r.to_member_id::text = $2 THEN r.from_member_id
+    ELSE r.to_member_id
+  END)::text,
+  CASE WHEN r.delta < 0 THEN 'expense' ELSE 'income' END,
+  COUNT(*),
+  SUM(ABS(r.delta))::float8
+FROM score_records r
+WHERE r.scorebook_id = $1::uuid AND r.delta <> 0
+GROUP BY 1, 2, 3
+`, ledgerID, ownerMemberID)
+	if err != nil {
+		return LedgerSummary{}, err
+	}
+	defer rows.Close()
+
+	var groups []LedgerSummaryRow
+	for rows.Next() {
+		var g LedgerSummaryRow
+		if err := rows.Scan(&g.CategoryID, &g.MemberID, &g.Type, &g.Records, &g.Amount); err != nil {
+			return LedgerSummary{}, err
+		}
+		groups = append(groups, g)
+	}
+	if err := rows.Err(); err != nil {
+		return LedgerSummary{}, err
+	}
+	return SummarizeLedger(categories, members, groups), nil
+}
+
+// SummarizeLedger folds aggregated record rows into a ledger summary, summing in
+// cents. Every category is listed (in the given order, empty ones included), followed
+// by an uncategorized entry when some records have no category; rows of a category
+// that no longer exists count as uncategorized. Members with records are listed by
+// total amount (income plus expense), largest first, ties in the given order.
+func SummarizeLedger(categories []LedgerCategory, members []LedgerMember, rows []LedgerSummaryRow) LedgerSummary {
+	type totals struct {
+		records         int64
+		income, expense int64
+	}
+	add := func(t *totals, r LedgerSummaryRow, c int64) {
+		t.records += r.Records
+		if r.Type == "expense" {
+			t.expense += c
+		} else {
+			t.income += c
+		}
+	}
+	out := func(t totals) LedgerTotals {
+		return LedgerTotals{
+			Records: t.records,
+			Income:  centsToAmount(t.income),
+			Expense: centsToAmount(t.expense),
+			Net:     centsToAmount(t.income - t.expense),
+		}
+	}
+
+	known := map[string]bool{}
+	for _, c := range categories {
+		known[c.ID] = true
+	}
+	var total totals
+	byCategory := map[string]*totals{}
+	byMember := map[string]*totals{}
+	for _, r := range rows {
+		c := int64(math.Round(r.Amount * 100))
+		add(&total, r, c)
+		cat := r.CategoryID
+		if !known[cat] {
+			cat = ""
+		}
+		if byCategory[cat] == nil {
+			byCategory[cat] = &totals{}
+		}
+		add(byCategory[cat], r, c)
+		if byMember[r.MemberID] == nil {
+			byMember[r.MemberID] = &totals{}
+		}
+		add(byMember[r.MemberID], r, c)
+	}
+
+	sum := LedgerSummary{Total: out(total)}
+	var incomeRecords, expenseRecords int64
+	for _, r := range rows {
+		if r.Type == "expense" {
+			expenseRecords += r.Records
+		} else {
+			incomeRecords += r.Records
+		}
+	}
+	sum.ByType = []LedgerTypeTotal{
+		{Type: "income", Records: incomeRecords, Amount: sum.Total.Income},
+		{Type: "expense", Records: expenseRecords, Amount: sum.Total.Expense},
+	}
+
+	sum.ByCategory = make([]LedgerCategoryTotal, 0, len(categories)+1)
+	for _, c := range categories {
+		t := totals{}
+		if byCategory[c.ID] != nil {
+			t = *byCategory[c.ID]
+		}
+		sum.ByCategory = append(sum.ByCategory, LedgerCategoryTotal{CategoryID: c.ID, Name: c.Name, LedgerTotals: out(t)})
+	}
+	if t := byCategory[""]; t != nil {
+		sum.ByCategory = append(sum.ByCategory, LedgerCategoryTotal{LedgerTotals: out(*t)})
+	}
+
+	sum.ByMember = make([]LedgerMemberTotal, 0, len(byMember))
+	for _, m := range members {
+		if t := byMember[m.ID]; t != nil {
+			sum.ByMember = append(sum.ByMember, LedgerMemberTotal{MemberID: m.ID, Nickname: m.Nickname, LedgerTotals: out(*t)})
+		}
+	}
+	sort.SliceStable(sum.ByMember, func(i, j int) bool {
+		a, b := sum.ByMember[i], sum.ByMember[j]
+		return a.Income+a.Expense > b.Income+b.Expense
+	})
+	return sum
+}
+
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505"
+}
@@ -0,0 +1,232 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// Import row warnings.
+const (
+	ImportWarnDuplicateInFile = "duplicate_in_file"
+	ImportWarnExistingMember  = "existing_member"
+	ImportWarnAmbiguousMember = "ambiguous_member"
+)
+
+// PlanLedgerImport maps import rows onto ledger members. With separate set every row
+// gets a new member. Otherwise rows with the same name are the same person: they reuse
+// the existing member of that name (the earliest if several, the owner never) or share
+// one new member. Rows are never dropped; the warnings only point out duplicates.
+// existing must be in join order.
+func PlanLedgerImport(existing []LedgerMember, ownerMemberID string, rows []LedgerImportRow, separate bool) LedgerImportPlan {
+	byName := map[string][]LedgerMember{}
+	for _, m := range existing {
+		if m.ID == ownerMemberID {
+			continue
+		}
+		key := strings.TrimSpace(m.Nickname)
+		byName[key] = append(byName[key], m)
+	}
+	lines := map[string][]int{}
+	var order []string
+	for _, r := range rows {
+		if r.Error != "" {
+			continue
+		}
+		if _, ok := lines[r.Name]; !ok {
+			order = append(order, r.Name)
+		}
+		lines[r.Name] = append(lines[r.Name], r.Line)
+	}
+
+	plan := LedgerImportPlan{Rows: make([]LedgerImportRow, len(rows))}
+	var incomeCents, expenseCents int64
+	newNames := map[string]bool{}
+	for i, r := range rows {
+		r.MemberID = ""
+		r.NewMember = false
+		r.Warnings = nil
+		if r.Error != "" {
+			plan.Errors++
+			plan.Rows[i] = r
+			continue
+		}
+		matches := byName[r.Name]
+		if len(lines[r.Name]) > 1 {
+			r.Warnings = append(r.Warnings, ImportWarnDuplicateInFile)
+		}
+		if len(matches) > 0 {
+			r.Warnings = append(r.Warnings, ImportWarnExistingMember)
+		}
+		if len(matches) > 1 {
+			r.Warnings = append(r.Warnings, ImportWarnAmbiguousMember)
+		}
+		switch {
+		case separate:
+			r.NewMember = true
+			plan.NewMembers++
+		case len(matches) > 0:
+			r.MemberID = matches[0].ID
+		default:
+			r.NewMember = true
+			if !newNames[r.Name] {
+				newNames[r.Name] = true
+				plan.NewMembers++
+			}
+		}
+		c, _ := amountToCents(r.Amount)
+		if r.Type == "expense" {
+			expenseCents += c
+		} else {
+			incomeCents += c
+		}
+		plan.Records++
+		plan.Rows[i] = r
+	}
+	plan.Income = centsToAmount(incomeCents)
+	plan.Expense = centsToAmount(expenseCents)
+
+	for _, name := range order {
+		matches := byName[name]
+		if len(lines[name]) < 2 && len(matches) == 0 {
+			continue
+		}
+		d := LedgerImportDuplicate{Name: name, Lines: lines[name]}
+		for _, m := range matches {
+			d.ExistingMembers = append(d.ExistingMembers, m.ID)
+		}
+		plan.Duplicates = append(plan.Duplicates, d)
+	}
+	return plan
+}
+
+// ImportLedgerRecords re-plans rows against the current members and writes every
+// row in one transaction: new members first, then one record per row (keeping file
+// order), then the member scores. Rows with errors fail the whole import with
+// ErrInvalidArgument and the plan. Only the owner may import into a recording ledger.
+func (s *Store) ImportLedgerRecords(ctx context.Context, ledgerID string, userID int64, rows []LedgerImportRow, separate bool) (LedgerImportPlan, error) {
+	tx, err := s.pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return LedgerImportPlan{}, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	var status string
+	var ownerID int64
+	err = tx.QueryRow(ctx, `
+SELECT status::text, created_by_user_id
+FROM scorebooks
+WHERE id = $1::uuid AND book_type = 'ledger' AND deleted_at IS NULL
+FOR UPDATE
+`, ledgerID).Scan(&status, &ownerID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return LedgerImportPlan{}, ErrNotFound
+		}
+		return LedgerImportPlan{}, err
+	}
+	if ownerID != userID {
+		return LedgerImportPlan{}, ErrForbidden
+	}
+	if status != "recording" {
+		return LedgerImportPlan{}, ErrScorebookEnded
+	}
+
+	memRows, err := tx.Query(ctx, `
+SELECT id::text, nickname, role::text
+FROM scorebook_members
+WHERE scorebook_id = $1::uuid
+ORDER BY joined_at ASC, id ASC
+`, ledgerID)
+	if err != nil {
+		return LedgerImportPlan{}, err
+	}
+	var members []LedgerMember
+	ownerMemberID := ""
+	for memRows.Next() {
+		var m LedgerMember
+		if err := memRows.Scan(&m.ID, &m.Nickname, &m.Role); err != nil {
+			memRows.Close()
+			return LedgerImportPlan{}, err
+		}
+		if m.Role == "owner" && ownerMemberID == "" {
+			ownerMemberID = m.ID
+		}
+		members = append(members, m)
+	}
+	memRows.Close()
+	if err := memRows.Err(); err != nil {
+		return LedgerImportPlan{}, err
+	}
+	if ownerMemberID == "" && len(members) > 0 {
+		ownerMemberID = members[0].ID
+	}
+	if ownerMemberID == "" {
+		return LedgerImportPlan{}, ErrNotFound
+	}
+
+	plan := PlanLedgerImport(members, ownerMemberID, rows, separate)
+	if plan.Errors > 0 {
+		return plan, ErrInvalidArgument
+	}
+
+	created := map[string]string{}
+	deltas := map[string]int64{}
+	for i := range plan.Rows {
+		r := &plan.Rows[i]
+		if r.NewMember {
+			id, ok := created[r.Name]
+			if !ok || separate {
+				err := tx.QueryRow(ctx, `
+INSERT INTO scorebook_members (scorebook_id, role, nickname, avatar_url, remark, updated_at)
+VALUES ($1::uuid, 'member', $2, '', $3, NOW())
+RETURNING id::text
+`, ledgerID, r.Name, r.Remark).Scan(&id)
+				if err != nil {
+					return LedgerImportPlan{}, err
+				}
+				created[r.Name] = id
+			}
+			r.MemberID = id
+		}
+
+		from, to := r.MemberID, ownerMemberID
+		delta := r.Amount
+		if r.Type == "expense" {
+			from, to = ownerMemberID, r.MemberID
+			delta = -r.Amount
+		}
+		// 同一事务内 NOW() 不变，按行号错开微秒以保留文件中的顺序
+		if _, err := tx.Exec(ctx, `
+INSERT INTO score_records (scorebook_id, from_member_id, to_member_id, delta, note, created_by_user_id, created_at)
+VALUES ($1::uuid, $2::uuid, $3::uuid, $4, '', $6, NOW() + make_interval(secs => $5::float8 / 1000000))
+`, ledgerID, from, to, delta, i, userID); err != nil {
+			return LedgerImportPlan{}, err
+		}
+		c, _ := amountToCents(r.Amount)
+		deltas[to] += c
+		deltas[from] -= c
+	}
+
+	for memberID, c := range deltas {
+		if c == 0 {
+			continue
+		}
+		if _, err := tx.Exec(ctx, `
+UPDATE scorebook_members
+SET score = score + $1, updated_at = NOW()
+WHERE scorebook_id = $2::uuid AND id = $3::uuid
+`, centsToAmount(c), ledgerID, memberID); err != nil {
+			return LedgerImportPlan{}, err
+		}
+	}
+	if _, err := tx.Exec(ctx, `UPDATE scorebooks SET updated_at = NOW() WHERE id = $1::uuid`, ledgerID); err != nil {
+		return LedgerImportPlan{}, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return LedgerImportPlan{}, err
+	}
+	return plan, nil
+}
@@ -0,0 +1,217 @@
+package store
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// Ledger roles returned by GetLedgerRole.
+const (
+	LedgerRoleOwner    = "owner"
+	LedgerRoleRecorder = "recorder"
+)
+
+// ledgerWriterStatus returns the status of a non-deleted ledger userID may record in,
+// as its owner or a recorder: ErrNotFound if there is no such ledger, ErrForbidden
+// if the user is neither.
+func (s *Store) ledgerWriterStatus(ctx context.Context, q rowQueryer, ledgerID string, userID int64) (string, error) {
+	status, role, err := s.ledgerRole(ctx, q, ledgerID, userID)
+	if err != nil {
+		return "", err
+	}
+	if role == "" {
+		return "", ErrForbidden
+	}
+	return status, nil
+}
+
+func (s *Store) ledgerRole(ctx context.Context, q rowQueryer, ledgerID string, userID int64) (string, string, error) {
+	if !isUUID(ledgerID) {
+		return "", "", ErrNotFound
+	}
+	var status string
+	var ownerID int64
+	var recorder bool
+	err := q.QueryRow(ctx, `
+SELECT status::text, created_by_user_id,
+  EXISTS (SELECT 1 FROM ledger_recorders r WHERE r.scorebook_id = s.id AND r.user_id = $2)
+FROM scorebooks s
+WHERE s.id = $1::uuid AND s.book_type = 'ledger' AND s.deleted_at IS NULL
+`, ledgerID, userID).Scan(&status, &ownerID, &recorder)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", "", ErrNotFound
+		}
+		return "", "", err
+	}
+	switch {
+	case ownerID == userID:
+		return status, LedgerRoleOwner, nil
+	case recorder:
+		return status, LedgerRoleRecorder, nil
+	}
+	return status, "", nil
+}
+
+// GetLedgerRole returns "owner", "recorder" or "" for the user in a non-deleted ledger.
+func (s *Store) GetLedgerRole(ctx context.Context, ledgerID string, userID int64) (string, error) {
+	_, role, err := s.ledgerRole(ctx, s.pool, ledgerID, userID)
+	return role, err
+}
+
+// ListLedgerRecorders lists the ledger's recorders in joining order, for its owner
+// and recorders. The recorder invite code is only returned to the owner ("" when
+// disabled).
+func (s *Store) ListLedgerRecorders(ctx context.Context, ledgerID string, userID int64) ([]LedgerRecorder, string, error) {
+	_, role, err := s.ledgerRole(ctx, s.pool, ledgerID, userID)
+	if err != nil {
+		return nil, "", err
+	}
+	if role == "" {
+		return nil, "", ErrForbidden
+	}
+
+	var code string
+	if role == LedgerRoleOwner {
+		err := s.pool.QueryRow(ctx, `
+SELECT COALESCE(recorder_invite_code, '') FROM scorebooks WHERE id = $1::uuid
+`, ledgerID).Scan(&code)
+		if err != nil {
+			return nil, "", err
+		}
+	}
+
+	rows, err := s.pool.Query(ctx, `
+SELECT r.scorebook_id::text, r.user_id, u.wechat_nickname, u.wechat_avatar_url, r.created_at
+FROM ledger_recorders r
+JOIN users u ON u.id = r.user_id
+JOIN scorebooks s ON s.id = r.scorebook_id
+WHERE r.scorebook_id = $1::uuid AND r.user_id <> s.created_by_user_id
+ORDER BY r.created_at ASC, r.user_id ASC
+`, ledgerID)
+	if err != nil {
+		return nil, "", err
+	}
+	defer rows.Close()
+
+	out := []LedgerRecorder{}
+	for rows.Next() {
+		var r LedgerRecorder
+		if err := rows.Scan(&r.LedgerID, &r.UserID, &r.Nickname, &r.AvatarURL, &r.CreatedAt); err != nil {
+			return nil, "", err
+		}
+		out = append(out, r)
+	}
+	return out, code, rows.Err()
+}
+
+// ResetLedgerRecorderInvite replaces the recorder invite code with a new one, or
+// disables it. Existing recorders are kept. Owner only, while recording.
+func (s *Store) ResetLedgerRecorderInvite(ctx context.Context, ledgerID string, userID int64, enabled bool) (string, error) {
+	status, err := s.ledgerOwnerStatus(ctx, s.pool, ledgerID, userID)
+	if err != nil {
+		return "", err
+	}
+	if status != "recording" {
+		return "", ErrScorebookEnded
+	}
+	if !enabled {
+		_, err := s.pool.Exec(ctx, `UPDATE scorebooks SET recorder_invite_code = NULL WHERE id = $1::uuid`, ledgerID)
+		return "", err
+	}
+	for i := 0; i < 5; i++ {
+		code := randomInviteCode(8)
+		_, err = s.pool.Exec(ctx, `UPDATE scorebooks SET recorder_invite_code = $2 WHERE id = $1::uuid`, ledgerID, code)
+		if err == nil {
+			return code, nil
+		}
+		if !isUniqueViolation(err) {
+			return "", err
+		}
+	}
+	return "", err
+}
+
+// GetLedgerRecorderInvite resolves a recorder invite code of a non-deleted ledger.
+func (s *Store) GetLedgerRecorderInvite(ctx context.Context, code string) (LedgerRecorderInvite, error) {
+	if code == "" {
+		return LedgerRecorderInvite{}, ErrNotFound
+	}
+	var inv LedgerRecorderInvite
+	err := s.pool.QueryRow(ctx, `
+SELECT s.id::text, s.name, s.status::text, s.created_by_user_id, COALESCE(u.wechat_nickname, '')
+FROM scorebooks s
+LEFT JOIN users u ON u.id = s.created_by_user_id
+WHERE s.recorder_invite_code = $1 AND s.book_type = 'ledger' AND s.deleted_at IS NULL
+`, code).Scan(&inv.LedgerID, &inv.LedgerName, &inv.Status, &inv.OwnerUserID, &inv.OwnerNickname)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return LedgerRecorderInvite{}, ErrNotFound
+		}
+		return LedgerRecorderInvite{}, err
+	}
+	return inv, nil
+}
+
+// AcceptLedgerRecorderInvite makes the user a recorder of the ledger the code
+// belongs to. Accepting again returns the existing recorder; the owner gets
+// ErrConflict and an ended ledger ErrScorebookEnded.
+func (s *Store) AcceptLedgerRecorderInvite(ctx context.Context, code string, userID int64) (LedgerRecorder, error) {
+	inv, err := s.GetLedgerRecorderInvite(ctx, code)
+	if err != nil {
+		return LedgerRecorder{}, err
+	}
+	if inv.OwnerUserID == userID {
+		return LedgerRecorder{}, ErrConflict
+	}
+	if inv.Status != "recording" {
+		return LedgerRecorder{}, ErrScorebookEnded
+	}
+
+	var r LedgerRecorder
+	err = s.pool.QueryRow(ctx, `
+WITH ins AS (
+  INSERT INTO ledger_recorders (scorebook_id, user_id)
+  VALUES ($1::uuid, $2)
+  ON CONFLICT (scorebook_id, user_id) DO NOTHING
+  RETURNING created_at
+)
+SELECT $1::uuid::text, u.id, u.wechat_nickname, u.wechat_avatar_url,
+  COALESCE((SELECT created_at FROM ins),
+           (SELECT created_at FROM ledger_recorders WHERE scorebook_id = $1::uuid AND user_id = $2))
+FROM users u
+WHERE u.id = $2
+`, inv.LedgerID, userID).Scan(&r.LedgerID, &r.UserID, &r.Nickname, &r.AvatarURL, &r.CreatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return LedgerRecorder{}, ErrNotFound
+		}
+		return LedgerRecorder{}, err
+	}
+	return r, nil
+}
+
+// RemoveLedgerRecorder removes a recorder; the owner may remove anyone, a recorder
+// only themselves. Records they entered are kept.
+func (s *Store) RemoveLedgerRecorder(ctx context.Context, ledgerID string, userID, recorderUserID int64) error {
+	_, role, err := s.ledgerRole(ctx, s.pool, ledgerID, userID)
+	if err != nil {
+		return err
+	}
+	if role != LedgerRoleOwner && (role != LedgerRoleRecorder || userID != recorderUserID) {
+		return ErrForbidden
+	}
+	tag, err := s.pool.Exec(ctx, `
+DELETE FROM ledger_recorders
+WHERE scorebook_id = $1::uuid AND user_id = $2
+`, ledgerID, recorderUserID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
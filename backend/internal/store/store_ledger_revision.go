@@ -0,0 +1,333 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sort"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// Revision actions.
+const (
+	RevisionUpdate = "update"
+	RevisionDelete = "delete"
+)
+
+// Snapshot returns the record's editable fields.
+func (r LedgerRecord) Snapshot() LedgerRecordSnapshot {
+	return LedgerRecordSnapshot{MemberID: r.MemberID, Type: r.Type, Amount: r.Amount, Note: r.Note, CategoryID: r.CategoryID}
+}
+
+// ApplyLedgerRecordUpdate returns the record with the update applied and its from/to
+// sides recomputed around the owner member. Remark records (no amount) only take a
+// new note or category. It does not check that a new member or category exists.
+func ApplyLedgerRecordUpdate(r LedgerRecord, in LedgerRecordUpdate, ownerMemberID string) (LedgerRecord, error) {
+	if r.Type == "remark" && (in.MemberID != nil || in.Type != nil || in.Amount != nil) {
+		return LedgerRecord{}, ErrInvalidArgument
+	}
+	if in.MemberID != nil {
+		id := strings.TrimSpace(*in.MemberID)
+		if id == "" || id == ownerMemberID {
+			return LedgerRecord{}, ErrInvalidArgument
+		}
+		r.MemberID = id
+	}
+	if in.Type != nil {
+		if *in.Type != "income" && *in.Type != "expense" {
+			return LedgerRecord{}, ErrInvalidArgument
+		}
+		r.Type = *in.Type
+	}
+	if in.Amount != nil {
+		amount, ok := NormalizeAmount(*in.Amount)
+		if !ok {
+			return LedgerRecord{}, ErrInvalidArgument
+		}
+		r.Amount = amount
+	}
+	if in.Note != nil {
+		r.Note = strings.TrimSpace(*in.Note)
+	}
+	if in.CategoryID != nil {
+		r.CategoryID = strings.TrimSpace(*in.CategoryID)
+	}
+	switch r.Type {
+	case "income":
+		r.FromMemberID, r.ToMemberID = r.MemberID, ownerMemberID
+	case "expense":
+		r.FromMemberID, r.ToMemberID = ownerMemberID, r.MemberID
+	}
+	return r, nil
+}
+
+// ledgerDelta is the score_records delta of a ledger record.
+func ledgerDelta(r LedgerRecord) float64 {
+	switch r.Type {
+	case "income":
+		return r.Amount
+	case "expense":
+		return -r.Amount
+	}
+	return 0
+}
+
+// ledgerScoreChanges returns the member score changes (in cents) of replacing
+// record before with after; a nil after undoes before.
+func ledgerScoreChanges(before LedgerRecord, after *LedgerRecord) map[string]int64 {
+	changes := map[string]int64{}
+	move := func(r LedgerRecord, sign int64) {
+		c, _ := amountToCents(r.Amount)
+		if c == 0 {
+			return
+		}
+		changes[r.ToMemberID] += sign * c
+		changes[r.FromMemberID] -= sign * c
+	}
+	move(before, -1)
+	if after != nil {
+		move(*after, 1)
+	}
+	for id, c := range changes {
+		if c == 0 {
+			delete(changes, id)
+		}
+	}
+	return changes
+}
+
+// lockLedgerRecord loads a ledger record FOR UPDATE, typed around the owner member,
+// and returns it with the owner member id.
+func (s *Store) lockLedgerRecord(ctx context.Context, tx pgx.Tx, ledgerID, recordID string) (LedgerRecord, string, error) {
+	if !isUUID(recordID) {
+		return LedgerRecord{}, "", ErrNotFound
+	}
+	ownerMemberID, err := ledgerOwnerMemberID(ctx, tx, ledgerID)
+	if err != nil {
+		return LedgerRecord{}, "", err
+	}
+
+	var r LedgerRecord
+	var delta float64
+	err = tx.QueryRow(ctx, `
+SELECT id::text, scorebook_id::text, from_member_id::text, to_member_id::text, delta::float8, note, COALESCE(category_id::text, ''),
+  created_by_user_id, COALESCE((SELECT wechat_nickname FROM users WHERE id = created_by_user_id), ''), created_at
+FROM score_records
+WHERE scorebook_id = $1::uuid AND id = $2::uuid
+FOR UPDATE
+`, ledgerID, recordID).Scan(&r.ID, &r.LedgerID, &r.FromMemberID, &r.ToMemberID, &delta, &r.Note, &r.CategoryID, &r.CreatedByUserID, &r.CreatedByNickname, &r.CreatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return LedgerRecord{}, "", ErrNotFound
+		}
+		return LedgerRecord{}, "", err
+	}
+	setLedgerRecordType(&r, delta, ownerMemberID)
+	return r, ownerMemberID, nil
+}
+
+// applyLedgerScoreChanges updates member scores in id order, so concurrent edits
+// lock member rows in the same order.
+func applyLedgerScoreChanges(ctx context.Context, tx pgx.Tx, ledgerID string, changes map[string]int64) error {
+	ids := make([]string, 0, len(changes))
+	for id := range changes {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		if _, err := tx.Exec(ctx, `
+UPDATE scorebook_members
+SET score = score + $1, updated_at = NOW()
+WHERE scorebook_id = $2::uuid AND id = $3::uuid
+`, centsToAmount(changes[id]), ledgerID, id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func insertRecordRevision(ctx context.Context, tx pgx.Tx, ledgerID, recordID, action string, userID int64, before LedgerRecordSnapshot, after *LedgerRecordSnapshot) error {
+	beforeJSON, err := json.Marshal(before)
+	if err != nil {
+		return err
+	}
+	var afterJSON *string
+	if after != nil {
+		raw, err := json.Marshal(after)
+		if err != nil {
+			return err
+		}
+		v := string(raw)
+		afterJSON = &v
+	}
+	_, err = tx.Exec(ctx, `
+INSERT INTO record_revisions (scorebook_id, record_id, action, changed_by_user_id, before, after)
+VALUES ($1::uuid, $2::uuid, $3, $4, $5::jsonb, $6::jsonb)
+`, ledgerID, recordID, action, userID, string(beforeJSON), afterJSON)
+	return err
+}
+
+// UpdateLedgerRecord edits a ledger record, moving the member scores from the old
+// values to the new ones and appending a revision, all in one transaction. An edit
+// that changes nothing writes nothing. Owner only, while the ledger is recording.
+func (s *Store) UpdateLedgerRecord(ctx context.Context, ledgerID string, userID int64, recordID string, in LedgerRecordUpdate) (LedgerRecord, error) {
+	tx, err := s.pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return LedgerRecord{}, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	status, err := s.ledgerOwnerStatus(ctx, tx, ledgerID, userID)
+	if err != nil {
+		return LedgerRecord{}, err
+	}
+	if status != "recording" {
+		return LedgerRecord{}, ErrScorebookEnded
+	}
+	before, ownerMemberID, err := s.lockLedgerRecord(ctx, tx, ledgerID, recordID)
+	if err != nil {
+		return LedgerRecord{}, err
+	}
+	after, err := ApplyLedgerRecordUpdate(before, in, ownerMemberID)
+	if err != nil {
+		return LedgerRecord{}, err
+	}
+	if after.Snapshot() == before.Snapshot() {
+		return before, nil
+	}
+	if after.MemberID != before.MemberID {
+		if !isUUID(after.MemberID) {
+			return LedgerRecord{}, ErrNotFound
+		}
+		var found bool
+		err := tx.QueryRow(ctx, `
+SELECT EXISTS (SELECT 1 FROM scorebook_members WHERE scorebook_id = $1::uuid AND id = $2::uuid)
+`, ledgerID, after.MemberID).Scan(&found)
+		if err != nil {
+			return LedgerRecord{}, err
+		}
+		if !found {
+			return LedgerRecord{}, ErrNotFound
+		}
+	}
+	if after.CategoryID != "" && after.CategoryID != before.CategoryID {
+		if !isUUID(after.CategoryID) {
+			return LedgerRecord{}, ErrInvalidArgument
+		}
+		var found bool
+		err := tx.QueryRow(ctx, `
+SELECT EXISTS (SELECT 1 FROM ledger_categories WHERE scorebook_id = $1::uuid AND id = $2::uuid)
+`, ledgerID, after.CategoryID).Scan(&found)
+		if err != nil {
+			return LedgerRecord{}, err
+		}
+		if !found {
+			return LedgerRecord{}, ErrInvalidArgument
+		}
+	}
+
+	if _, err := tx.Exec(ctx, `
+UPDATE score_records
+SET from_member_id = $3::uuid, to_member_id = $4::uuid, delta = $5, note = $6, category_id = NULLIF($7, '')::uuid
+WHERE scorebook_id = $1::uuid AND id = $2::uuid
+`, ledgerID, after.ID, after.FromMemberID, after.ToMemberID, ledgerDelta(after), after.Note, after.CategoryID); err != nil {
+		return LedgerRecord{}, err
+	}
+	if err := applyLedgerScoreChanges(ctx, tx, ledgerID, ledgerScoreChanges(before, &after)); err != nil {
+		return LedgerRecord{}, err
+	}
+	snapshot := after.Snapshot()
+	if err := insertRecordRevision(ctx, tx, ledgerID, after.ID, RevisionUpdate, userID, before.Snapshot(), &snapshot); err != nil {
+		return LedgerRecord{}, err
+	}
+	_, _ = tx.Exec(ctx, `UPDATE scorebooks SET updated_at = NOW() WHERE id = $1::uuid`, ledgerID)
+
+	if err := tx.Commit(ctx); err != nil {
+		return LedgerRecord{}, err
+	}
+	return after, nil
+}
+
+// DeleteLedgerRecord deletes a ledger record, undoing its effect on the member
+// scores and keeping it in a revision. Owner only, while the ledger is recording.
+func (s *Store) DeleteLedgerRecord(ctx context.Context, ledgerID string, userID int64, recordID string) (LedgerRecord, error) {
+	tx, err := s.pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return LedgerRecord{}, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	status, err := s.ledgerOwnerStatus(ctx, tx, ledgerID, userID)
+	if err != nil {
+		return LedgerRecord{}, err
+	}
+	if status != "recording" {
+		return LedgerRecord{}, ErrScorebookEnded
+	}
+	before, _, err := s.lockLedgerRecord(ctx, tx, ledgerID, recordID)
+	if err != nil {
+		return LedgerRecord{}, err
+	}
+
+	if _, err := tx.Exec(ctx, `
+DELETE FROM score_records
+WHERE scorebook_id = $1::uuid AND id = $2::uuid
+`, ledgerID, before.ID); err != nil {
+		return LedgerRecord{}, err
+	}
+	if err := applyLedgerScoreChanges(ctx, tx, ledgerID, ledgerScoreChanges(before, nil)); err != nil {
+		return LedgerRecord{}, err
+	}
+	if err := insertRecordRevision(ctx, tx, ledgerID, before.ID, RevisionDelete, userID, before.Snapshot(), nil); err != nil {
+		return LedgerRecord{}, err
+	}
+	_, _ = tx.Exec(ctx, `UPDATE scorebooks SET updated_at = NOW() WHERE id = $1::uuid`, ledgerID)
+
+	if err := tx.Commit(ctx); err != nil {
+		return LedgerRecord{}, err
+	}
+	return before, nil
+}
+
+// ListRecordRevisions returns the ledger's record revisions, newest first, optionally
+// only those of one record. Owner only.
+func (s *Store) ListRecordRevisions(ctx context.Context, ledgerID string, userID int64, recordID string, limit, offset int32) ([]RecordRevision, error) {
+	if _, err := s.ledgerOwnerStatus(ctx, s.pool, ledgerID, userID); err != nil {
+		return nil, err
+	}
+	rows, err := s.pool.Query(ctx, `
+SELECT rv.id::text, rv.scorebook_id::text, rv.record_id::text, rv.action, rv.changed_by_user_id,
+       COALESCE(u.wechat_nickname, ''), rv.before, rv.after, rv.created_at
+FROM record_revisions rv
+LEFT JOIN users u ON u.id = rv.changed_by_user_id
+WHERE rv.scorebook_id = $1::uuid AND ($2 = '' OR rv.record_id::text = $2)
+ORDER BY rv.created_at DESC, rv.id DESC
+LIMIT $3 OFFSET $4
+`, ledgerID, recordID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := []RecordRevision{}
+	for rows.Next() {
+		var rv RecordRevision
+		var before, after []byte
+		if err := rows.Scan(&rv.ID, &rv.LedgerID, &rv.RecordID, &rv.Action, &rv.ChangedByUserID, &rv.ChangedByNickname, &before, &after, &rv.CreatedAt); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(before, &rv.Before); err != nil {
+			return nil, err
+		}
+		if after != nil {
+			rv.After = &LedgerRecordSnapshot{}
+			if err := json.Unmarshal(after, rv.After); err != nil {
+				return nil, err
+			}
+		}
+		out = append(out, rv)
+	}
+	return out, rows.Err()
+}
@@ -0,0 +1,275 @@
+package store
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+)
+
+type rowQueryer interface {
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+// memberRole returns the caller's member id and role in a non-deleted scorebook,
+// or ErrNotFound if the scorebook does not exist or the user is not a member.
+func (s *Store) memberRole(ctx context.Context, q rowQueryer, scorebookID string, userID int64) (string, string, error) {
+	var memberID, role string
+	err := q.QueryRow(ctx, `
+SELECT m.id::text, m.role::text
+FROM scorebook_members m
+JOIN scorebooks s ON s.id = m.scorebook_id
+WHERE m.scorebook_id = $1::uuid AND m.user_id = $2 AND s.book_type = 'scorebook' AND s.deleted_at IS NULL
+`, scorebookID, userID).Scan(&memberID, &role)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", "", ErrNotFound
+		}
+		return "", "", err
+	}
+	return memberID, role, nil
+}
+
+// SetMemberRole changes a member's role to admin, member or spectator. Only the owner
+// may do this, and the owner's own role cannot be changed here.
+func (s *Store) SetMemberRole(ctx context.Context, scorebookID string, userID int64, memberID, role string) (Member, error) {
+	if role != RoleAdmin && role != RoleMember && role != RoleSpectator {
+		return Member{}, ErrInvalidArgument
+	}
+	if !isUUID(memberID) {
+		return Member{}, ErrNotFound
+	}
+
+	tx, err := s.pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return Member{}, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	_, myRole, err := s.memberRole(ctx, tx, scorebookID, userID)
+	if err != nil {
+		return Member{}, err
+	}
+	if myRole != RoleOwner {
+		return Member{}, ErrForbidden
+	}
+
+	target, err := scanMember(tx.QueryRow(ctx, `
+SELECT `+memberColumns+`
+FROM scorebook_members
+WHERE scorebook_id = $1::uuid AND id = $2::uuid AND user_id IS NOT NULL
+FOR UPDATE
+`, scorebookID, memberID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return Member{}, ErrNotFound
+		}
+		return Member{}, err
+	}
+	if target.Role == RoleOwner {
+		return Member{}, ErrInvalidArgument
+	}
+
+	m, err := scanMember(tx.QueryRow(ctx, `
+UPDATE scorebook_members
+SET role = $2, updated_at = NOW()
+WHERE id = $1::uuid
+RETURNING `+memberColumns, memberID, role))
+	if err != nil {
+		return Member{}, err
+	}
+	if _, err := tx.Exec(ctx, `UPDATE scorebooks SET updated_at = NOW() WHERE id = $1::uuid`, scorebookID); err != nil {
+		return Member{}, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return Member{}, err
+	}
+	return m, nil
+}
+
+// RemoveMember removes a member (or placeholder member) from a scorebook and
+// optionally bans the user from rejoining. Owners may remove anyone but themselves;
+// admins may remove members and spectators. A member that is referenced by records, rounds or settlements is never
+// deleted: it is detached from the user (user_id = NULL) so the history stays
+// attributable. It returns the member as it was before removal and whether its row
+// was kept.
+func (s *Store) RemoveMember(ctx context.Context, scorebookID string, userID int64, memberID string, ban bool) (Member, bool, error) {
+	if !isUUID(memberID) {
+		return Member{}, false, ErrNotFound
+	}
+
+	tx, err := s.pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return Member{}, false, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	myMemberID, myRole, err := s.memberRole(ctx, tx, scorebookID, userID)
+	if err != nil {
+		return Member{}, false, err
+	}
+	if !CanManage(myRole) {
+		return Member{}, false, ErrForbidden
+	}
+
+	target, err := scanMember(tx.QueryRow(ctx, `
+SELECT `+memberColumns+`
+FROM scorebook_members
+WHERE scorebook_id = $1::uuid AND id = $2::uuid AND (user_id IS NOT NULL OR placeholder)
+FOR UPDATE
+`, scorebookID, memberID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return Member{}, false, ErrNotFound
+		}
+		return Member{}, false, err
+	}
+	if target.ID == myMemberID {
+		return Member{}, false, ErrInvalidArgument
+	}
+	if target.Role == RoleOwner || (target.Role == RoleAdmin && myRole != RoleOwner) {
+		return Member{}, false, ErrForbidden
+	}
+
+	var referenced bool
+	err = tx.QueryRow(ctx, `
+SELECT
+  EXISTS (SELECT 1 FROM score_records WHERE from_member_id = $1::uuid OR to_member_id = $1::uuid OR voided_by_member_id = $1::uuid)
+  OR EXISTS (SELECT 1 FROM score_rounds WHERE created_by_member_id = $1::uuid)
+  OR EXISTS (SELECT 1 FROM scorebook_settlements WHERE from_member_id = $1::uuid OR to_member_id = $1::uuid OR paid_by_member_id = $1::uuid)
+`, target.ID).Scan(&referenced)
+	if err != nil {
+		return Member{}, false, err
+	}
+	// 被移出的成员不能再接受所有权转让
+	if _, err := tx.Exec(ctx, `
+UPDATE ownership_transfers
+SET status = 'cancelled', resolved_at = NOW()
+WHERE to_member_id = $1::uuid AND status = 'pending'
+`, target.ID); err != nil {
+		return Member{}, false, err
+	}
+	if referenced {
+		_, err = tx.Exec(ctx, `
+UPDATE scorebook_members
+SET user_id = NULL, role = 'member', placeholder = FALSE, updated_at = NOW()
+WHERE id = $1::uuid
+`, target.ID)
+	} else {
+		_, err = tx.Exec(ctx, `DELETE FROM scorebook_members WHERE id = $1::uuid`, target.ID)
+	}
+	if err != nil {
+		return Member{}, false, err
+	}
+
+	// 占位成员没有用户，无从禁止
+	if ban && !target.Placeholder {
+		if _, err := tx.Exec(ctx, `
+INSERT INTO scorebook_bans (scorebook_id, user_id, nickname, banned_by_user_id)
+VALUES ($1::uuid, $2, $3, $4)
+ON CONFLICT (scorebook_id, user_id) DO NOTHING
+`, scorebookID, target.UserID, target.Nickname, userID); err != nil {
+			return Member{}, false, err
+		}
+	}
+	if _, err := tx.Exec(ctx, `UPDATE scorebooks SET updated_at = NOW() WHERE id = $1::uuid`, scorebookID); err != nil {
+		return Member{}, false, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return Member{}, false, err
+	}
+	return target, referenced, nil
+}
+
+// ListBans returns the users banned from a scorebook, newest first. Owners and admins may list them.
+func (s *Store) ListBans(ctx context.Context, scorebookID string, userID int64) ([]ScorebookBan, error) {
+	_, role, err := s.memberRole(ctx, s.pool, scorebookID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !CanManage(role) {
+		return nil, ErrForbidden
+	}
+
+	rows, err := s.pool.Query(ctx, `
+SELECT scorebook_id::text, user_id, nickname, COALESCE(banned_by_user_id, 0), created_at
+FROM scorebook_bans
+WHERE scorebook_id = $1::uuid
+ORDER BY created_at DESC
+`, scorebookID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []ScorebookBan
+	for rows.Next() {
+		var b ScorebookBan
+		if err := rows.Scan(&b.ScorebookID, &b.UserID, &b.Nickname, &b.BannedByUserID, &b.CreatedAt); err != nil {
+			return nil, err
+		}
+		out = append(out, b)
+	}
+	return out, rows.Err()
+}
+
+// Unban lifts a ban so the user may rejoin via the invite code. Owners and admins may do this.
+func (s *Store) Unban(ctx context.Context, scorebookID string, userID int64, bannedUserID int64) error {
+	_, role, err := s.memberRole(ctx, s.pool, scorebookID, userID)
+	if err != nil {
+		return err
+	}
+	if !CanManage(role) {
+		return ErrForbidden
+	}
+
+	tag, err := s.pool.Exec(ctx, `DELETE FROM scorebook_bans WHERE scorebook_id = $1::uuid AND user_id = $2`, scorebookID, bannedUserID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// ListManagerUserIDs returns the user ids of the scorebook's owner and admins, used to
+// deliver notifications only they should see.
+func (s *Store) ListManagerUserIDs(ctx context.Context, scorebookID string) ([]int64, error) {
+	rows, err := s.pool.Query(ctx, `
+SELECT user_id FROM scorebook_members
+WHERE scorebook_id = $1::uuid AND user_id IS NOT NULL AND role IN ('owner', 'admin')
+`, scorebookID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		out = append(out, id)
+	}
+	return out, rows.Err()
+}
+
+const memberColumns = `id::text, scorebook_id::text, COALESCE(user_id, 0), role::text, nickname, avatar_url, joined_at, updated_at, placeholder`
+
+func scanMember(row pgx.Row) (Member, error) {
+	var m Member
+	err := row.Scan(
+		&m.ID,
+		&m.ScorebookID,
+		&m.UserID,
+		&m.Role,
+		&m.Nickname,
+		&m.AvatarURL,
+		&m.JoinedAt,
+		&m.UpdatedAt,
+		&m.Placeholder,
+	)
+	return m, err
+}
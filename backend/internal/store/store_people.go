@@ -0,0 +1,336 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"math"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+)
+
+const personColumns = `id::text, user_id, name, note, created_at, updated_at`
+
+func scanPerson(row pgx.Row) (Person, error) {
+	var p Person
+	err := row.Scan(&p.ID, &p.UserID, &p.Name, &p.Note, &p.CreatedAt, &p.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return Person{}, ErrNotFound
+		}
+		return Person{}, err
+	}
+	return p, nil
+}
+
+func (s *Store) CreatePerson(ctx context.Context, userID int64, name, note string) (Person, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return Person{}, ErrInvalidArgument
+	}
+	return scanPerson(s.pool.QueryRow(ctx, `
+INSERT INTO people (user_id, name, note)
+VALUES ($1, $2, $3)
+RETURNING `+personColumns+`
+`, userID, name, strings.TrimSpace(note)))
+}
+
+// ListPeople lists the user's people by name; query filters by a name substring.
+func (s *Store) ListPeople(ctx context.Context, userID int64, query string, limit, offset int32) ([]Person, error) {
+	rows, err := s.pool.Query(ctx, `
+SELECT `+personColumns+`
+FROM people
+WHERE user_id = $1 AND ($2 = '' OR strpos(name, $2) > 0)
+ORDER BY name ASC, created_at ASC, id ASC
+LIMIT $3 OFFSET $4
+`, userID, strings.TrimSpace(query), limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := []Person{}
+	for rows.Next() {
+		p, err := scanPerson(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, p)
+	}
+	return out, rows.Err()
+}
+
+// GetPerson returns the person with its linked ledger members (in non-deleted
+// ledgers) and birthday contacts.
+func (s *Store) GetPerson(ctx context.Context, userID int64, personID string) (Person, PersonLinks, error) {
+	p, err := s.person(ctx, userID, personID)
+	if err != nil {
+		return Person{}, PersonLinks{}, err
+	}
+
+	links := PersonLinks{LedgerMembers: []PersonLedgerMember{}, BirthdayContacts: []PersonBirthdayContact{}}
+	rows, err := s.pool.Query(ctx, `
+SELECT s.id::text, s.name, s.status::text, m.id::text, m.nickname, m.remark
+FROM scorebook_members m
+JOIN scorebooks s ON s.id = m.scorebook_id
+WHERE m.person_id = $1::uuid AND s.book_type = 'ledger' AND s.deleted_at IS NULL AND s.created_by_user_id = $2
+ORDER BY s.start_time DESC, m.joined_at ASC
+`, p.ID, userID)
+	if err != nil {
+		return Person{}, PersonLinks{}, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var m PersonLedgerMember
+		if err := rows.Scan(&m.LedgerID, &m.LedgerName, &m.LedgerStatus, &m.MemberID, &m.Nickname, &m.Remark); err != nil {
+			return Person{}, PersonLinks{}, err
+		}
+		links.LedgerMembers = append(links.LedgerMembers, m)
+	}
+	if err := rows.Err(); err != nil {
+		return Person{}, PersonLinks{}, err
+	}
+
+	rows, err = s.pool.Query(ctx, `
+SELECT id::text, name, relation
+FROM birthday_contacts
+WHERE person_id = $1::uuid AND user_id = $2
+ORDER BY created_at ASC, id ASC
+`, p.ID, userID)
+	if err != nil {
+		return Person{}, PersonLinks{}, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var c PersonBirthdayContact
+		if err := rows.Scan(&c.ID, &c.Name, &c.Relation); err != nil {
+			return Person{}, PersonLinks{}, err
+		}
+		links.BirthdayContacts = append(links.BirthdayContacts, c)
+	}
+	if err := rows.Err(); err != nil {
+		return Person{}, PersonLinks{}, err
+	}
+	return p, links, nil
+}
+
+func (s *Store) UpdatePerson(ctx context.Context, userID int64, personID string, name, note *string) (Person, error) {
+	if name != nil {
+		v := strings.TrimSpace(*name)
+		if v == "" {
+			return Person{}, ErrInvalidArgument
+		}
+		name = &v
+	}
+	if note != nil {
+		v := strings.TrimSpace(*note)
+		note = &v
+	}
+	if !isUUID(personID) {
+		return Person{}, ErrNotFound
+	}
+	return scanPerson(s.pool.QueryRow(ctx, `
+UPDATE people
+SET name = COALESCE($3, name), note = COALESCE($4, note), updated_at = NOW()
+WHERE id = $1::uuid AND user_id = $2
+RETURNING `+personColumns+`
+`, personID, userID, name, note))
+}
+
+// DeletePerson deletes a person; linked members and contacts are only unlinked.
+func (s *Store) DeletePerson(ctx context.Context, userID int64, personID string) error {
+	if !isUUID(personID) {
+		return ErrNotFound
+	}
+	tag, err := s.pool.Exec(ctx, `DELETE FROM people WHERE id = $1::uuid AND user_id = $2`, personID, userID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// LinkLedgerMember links a member of a ledger the user owns to the person, replacing
+// any previous link of that member. The ledger owner's own member cannot be linked
+// (ErrInvalidArgument).
+func (s *Store) LinkLedgerMember(ctx context.Context, userID int64, personID, ledgerID, memberID string) error {
+	p, err := s.person(ctx, userID, personID)
+	if err != nil {
+		return err
+	}
+	if _, err := s.ledgerOwnerStatus(ctx, s.pool, ledgerID, userID); err != nil {
+		return err
+	}
+	if !isUUID(memberID) {
+		return ErrNotFound
+	}
+
+	ownerMemberID, err := ledgerOwnerMemberID(ctx, s.pool, ledgerID)
+	if err != nil {
+		return err
+	}
+	if ownerMemberID == memberID {
+		return ErrInvalidArgument
+	}
+
+	tag, err := s.pool.Exec(ctx, `
+UPDATE scorebook_members
+SET person_id = $3::uuid
+WHERE scorebook_id = $1::uuid AND id = $2::uuid
+`, ledgerID, memberID, p.ID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (s *Store) UnlinkLedgerMember(ctx context.Context, userID int64, personID, memberID string) error {
+	p, err := s.person(ctx, userID, personID)
+	if err != nil {
+		return err
+	}
+	if !isUUID(memberID) {
+		return ErrNotFound
+	}
+	tag, err := s.pool.Exec(ctx, `
+UPDATE scorebook_members
+SET person_id = NULL
+WHERE id = $1::uuid AND person_id = $2::uuid
+`, memberID, p.ID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// LinkBirthdayContact links one of the user's birthday contacts to the person,
+// replacing any previous link of that contact.
+func (s *Store) LinkBirthdayContact(ctx context.Context, userID int64, personID, contactID string) error {
+	p, err := s.person(ctx, userID, personID)
+	if err != nil {
+		return err
+	}
+	if !isUUID(contactID) {
+		return ErrNotFound
+	}
+	tag, err := s.pool.Exec(ctx, `
+UPDATE birthday_contacts
+SET person_id = $3::uuid
+WHERE id = $1::uuid AND user_id = $2
+`, contactID, userID, p.ID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (s *Store) UnlinkBirthdayContact(ctx context.Context, userID int64, personID, contactID string) error {
+	p, err := s.person(ctx, userID, personID)
+	if err != nil {
+		return err
+	}
+	if !isUUID(contactID) {
+		return ErrNotFound
+	}
+	tag, err := s.pool.Exec(ctx, `
+UPDATE birthday_contacts
+SET person_id = NULL
+WHERE id = $1::uuid AND user_id = $2 AND person_id = $3::uuid
+`, contactID, userID, p.ID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// GetPersonHistory lists the income/expense records of the person's linked members
+// across the user's non-deleted ledgers, newest first, with received/given totals.
+// Income from a member is "received", expense to a member is "given".
+func (s *Store) GetPersonHistory(ctx context.Context, userID int64, personID string) (PersonHistory, error) {
+	p, err := s.person(ctx, userID, personID)
+	if err != nil {
+		return PersonHistory{}, err
+	}
+
+	rows, err := s.pool.Query(ctx, `
+SELECT s.id::text, s.name, r.id::text, m.id::text, r.delta::float8, r.note, r.created_at
+FROM scorebook_members m
+JOIN scorebooks s ON s.id = m.scorebook_id
+JOIN score_records r ON r.scorebook_id = s.id AND (r.from_member_id = m.id OR r.to_member_id = m.id)
+WHERE m.person_id = $1::uuid
+  AND s.book_type = 'ledger' AND s.deleted_at IS NULL AND s.created_by_user_id = $2
+  AND r.delta <> 0
+ORDER BY r.created_at DESC, r.id DESC
+`, p.ID, userID)
+	if err != nil {
+		return PersonHistory{}, err
+	}
+	defer rows.Close()
+
+	var entries []PersonHistoryEntry
+	for rows.Next() {
+		var e PersonHistoryEntry
+		var delta float64
+		if err := rows.Scan(&e.LedgerID, &e.LedgerName, &e.RecordID, &e.MemberID, &delta, &e.Note, &e.CreatedAt); err != nil {
+			return PersonHistory{}, err
+		}
+		e.Direction = "received"
+		if delta < 0 {
+			e.Direction = "given"
+		}
+		e.Amount = math.Abs(delta)
+		entries = append(entries, e)
+	}
+	if err := rows.Err(); err != nil {
+		return PersonHistory{}, err
+	}
+	return SummarizePersonHistory(p, entries), nil
+}
+
+// SummarizePersonHistory totals history entries (already in display order) in cents.
+func SummarizePersonHistory(p Person, entries []PersonHistoryEntry) PersonHistory {
+	h := PersonHistory{Person: p, Entries: entries}
+	if h.Entries == nil {
+		h.Entries = []PersonHistoryEntry{}
+	}
+	var received, given int64
+	for _, e := range h.Entries {
+		// 账本金额以两位小数存储，总能换算成分
+		cents, _ := amountToCents(e.Amount)
+		if e.Direction == "given" {
+			given += cents
+			h.GivenCount++
+		} else {
+			received += cents
+			h.ReceivedCount++
+		}
+	}
+	h.Received = centsToAmount(received)
+	h.Given = centsToAmount(given)
+	return h
+}
+
+func (s *Store) person(ctx context.Context, userID int64, personID string) (Person, error) {
+	if !isUUID(personID) {
+		return Person{}, ErrNotFound
+	}
+	return scanPerson(s.pool.QueryRow(ctx, `
+SELECT `+personColumns+`
+FROM people
+WHERE id = $1::uuid AND user_id = $2
+`, personID, userID))
+}
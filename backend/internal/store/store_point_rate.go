@@ -0,0 +1,198 @@
+package store
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+)
+
+const pointRateColumns = `id::text, scorebook_id::text, point_value::float8, currency, COALESCE(changed_by_user_id, 0), effective_at`
+
+func scanPointRate(row pgx.Row) (PointRate, error) {
+	var r PointRate
+	err := row.Scan(&r.ID, &r.ScorebookID, &r.PointValue, &r.Currency, &r.ChangedByUserID, &r.EffectiveAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return PointRate{}, ErrNotFound
+		}
+		return PointRate{}, err
+	}
+	return r, nil
+}
+
+// UpdatePointRate changes a recording scorebook's point value and/or currency. Owners
+// and admins may do this. A change appends a rate to the history; records written
+// before it keep their old rate. The currency can only change before the first record
+// (ErrCurrencyLocked afterwards), so all money in a scorebook is in one currency. The
+// returned bool is false (and the current rate is returned) if nothing changed.
+func (s *Store) UpdatePointRate(ctx context.Context, scorebookID string, userID int64, in PointRateUpdate) (PointRate, bool, error) {
+	tx, err := s.pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return PointRate{}, false, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	_, role, err := s.memberRole(ctx, tx, scorebookID, userID)
+	if err != nil {
+		return PointRate{}, false, err
+	}
+	if !CanManage(role) {
+		return PointRate{}, false, ErrForbidden
+	}
+
+	var status, currency string
+	var pointValue float64
+	err = tx.QueryRow(ctx, `
+SELECT status::text, point_value::float8, currency
+FROM scorebooks
+WHERE id = $1::uuid
+FOR UPDATE
+`, scorebookID).Scan(&status, &pointValue, &currency)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return PointRate{}, false, ErrNotFound
+		}
+		return PointRate{}, false, err
+	}
+	if status != "recording" {
+		return PointRate{}, false, ErrScorebookEnded
+	}
+
+	changed := false
+	if in.PointValue != nil && *in.PointValue != pointValue {
+		pointValue = *in.PointValue
+		changed = true
+	}
+	if in.Currency != nil && *in.Currency != currency {
+		var hasRecords bool
+		if err := tx.QueryRow(ctx, `
+SELECT EXISTS (SELECT 1 FROM score_records WHERE scorebook_id = $1::uuid)
+`, scorebookID).Scan(&hasRecords); err != nil {
+			return PointRate{}, false, err
+		}
+		if hasRecords {
+			return PointRate{}, false, ErrCurrencyLocked
+		}
+		currency = *in.Currency
+		changed = true
+	}
+	if !changed {
+		r, err := scanPointRate(tx.QueryRow(ctx, `
+SELECT `+pointRateColumns+`
+FROM scorebook_point_rates
+WHERE scorebook_id = $1::uuid
+ORDER BY effective_at DESC
+LIMIT 1
+`, scorebookID))
+		return r, false, err
+	}
+
+	if _, err := tx.Exec(ctx, `
+UPDATE scorebooks
+SET point_value = $2, currency = $3, updated_at = NOW()
+WHERE id = $1::uuid
+`, scorebookID, pointValue, currency); err != nil {
+		return PointRate{}, false, err
+	}
+	r, err := scanPointRate(tx.QueryRow(ctx, `
+INSERT INTO scorebook_point_rates (scorebook_id, point_value, currency, changed_by_user_id)
+VALUES ($1::uuid, $2, $3, $4)
+RETURNING `+pointRateColumns+`
+`, scorebookID, pointValue, currency, userID))
+	if err != nil {
+		return PointRate{}, false, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return PointRate{}, false, err
+	}
+	return r, true, nil
+}
+
+// ListPointRates returns a scorebook's point rate history, oldest first. Any member
+// may read it.
+func (s *Store) ListPointRates(ctx context.Context, scorebookID string, userID int64) ([]PointRate, error) {
+	if _, _, err := s.memberRole(ctx, s.pool, scorebookID, userID); err != nil {
+		return nil, err
+	}
+
+	rows, err := s.pool.Query(ctx, `
+SELECT `+pointRateColumns+`
+FROM scorebook_point_rates
+WHERE scorebook_id = $1::uuid
+ORDER BY effective_at ASC, id ASC
+`, scorebookID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []PointRate
+	for rows.Next() {
+		r, err := scanPointRate(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, r)
+	}
+	return out, rows.Err()
+}
+
+// ListMemberBalances returns every member of the scorebook with their points and
+// money, in join order.
+func (s *Store) ListMemberBalances(ctx context.Context, scorebookID string) ([]MemberWithScore, error) {
+	return s.listMembersWithScore(ctx, s.pool, scorebookID)
+}
+
+// fillMoney sets Money on the members from their score records. Each record is priced
+// with the rate effective when it was created (a reversal with the rate of the record
+// it reverses); records older than the first rate use the first rate. Each record is
+// rounded to cents before summing, so its debit and credit match and the members'
+// money always sums to zero.
+func (s *Store) fillMoney(ctx context.Context, q queryer, scorebookID string, members []MemberWithScore) error {
+	rows, err := q.Query(ctx, `
+WITH priced AS (
+  SELECT r.from_member_id, r.to_member_id,
+    ROUND(r.delta * COALESCE(
+      (SELECT pr.point_value FROM scorebook_point_rates pr
+       WHERE pr.scorebook_id = r.scorebook_id AND pr.effective_at <= COALESCE(o.created_at, r.created_at)
+       ORDER BY pr.effective_at DESC LIMIT 1),
+      (SELECT pr.point_value FROM scorebook_point_rates pr
+       WHERE pr.scorebook_id = r.scorebook_id
+       ORDER BY pr.effective_at ASC LIMIT 1),
+      1), 2) AS amount
+  FROM score_records r
+  LEFT JOIN score_records o ON o.id = r.reverses_record_id
+  WHERE r.scorebook_id = $1::uuid
+)
+SELECT x.member_id::text, SUM(x.amount)::float8
+FROM (
+  SELECT to_member_id AS member_id, amount FROM priced
+  UNION ALL
+  SELECT from_member_id, -amount FROM priced
+) x
+GROUP BY x.member_id
+`, scorebookID)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	money := map[string]float64{}
+	for rows.Next() {
+		var memberID string
+		var v float64
+		if err := rows.Scan(&memberID, &v); err != nil {
+			return err
+		}
+		money[memberID] = v
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	for i := range members {
+		members[i].Money = money[members[i].ID]
+	}
+	return nil
+}
@@ -0,0 +1,173 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// CreateRound records one zero-sum round (e.g. a hand of cards/mahjong) among several members.
+//
+// The deltas must reference distinct non-spectator members of the scorebook and sum to zero. They are
+// stored as pairwise score_records (payer -> receiver) linked to a new score_rounds row, and
+// all member scores are updated in the same transaction.
+func (s *Store) CreateRound(ctx context.Context, scorebookID string, userID int64, deltas []RoundDelta, note string) (ScoreRound, error) {
+	balances, err := RoundBalances(deltas)
+	if err != nil {
+		return ScoreRound{}, err
+	}
+
+	tx, err := s.pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return ScoreRound{}, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	var status string
+	err = tx.QueryRow(ctx, `SELECT status::text FROM scorebooks WHERE id = $1::uuid AND book_type = 'scorebook' AND deleted_at IS NULL FOR UPDATE`, scorebookID).Scan(&status)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ScoreRound{}, ErrNotFound
+		}
+		return ScoreRound{}, err
+	}
+	if status != "recording" {
+		return ScoreRound{}, ErrScorebookEnded
+	}
+
+	var myMemberID string
+	var myRole string
+	err = tx.QueryRow(ctx, `
+SELECT id::text, role::text
+FROM scorebook_members
+WHERE scorebook_id = $1::uuid AND user_id = $2
+`, scorebookID, userID).Scan(&myMemberID, &myRole)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ScoreRound{}, ErrForbidden
+		}
+		return ScoreRound{}, err
+	}
+	if myRole == RoleSpectator {
+		return ScoreRound{}, ErrForbidden
+	}
+
+	memberIDs := make([]string, 0, len(balances))
+	for id := range balances {
+		memberIDs = append(memberIDs, id)
+	}
+	var found int
+	err = tx.QueryRow(ctx, `
+SELECT COUNT(*)
+FROM scorebook_members
+WHERE scorebook_id = $1::uuid AND id::text = ANY($2::text[])
+  AND (user_id IS NOT NULL OR placeholder) AND role <> 'spectator'
+`, scorebookID, memberIDs).Scan(&found)
+	if err != nil {
+		return ScoreRound{}, err
+	}
+	if found != len(memberIDs) {
+		return ScoreRound{}, ErrInvalidArgument
+	}
+
+	var round ScoreRound
+	err = tx.QueryRow(ctx, `
+INSERT INTO score_rounds (scorebook_id, round_no, created_by_member_id, note)
+VALUES (
+  $1::uuid,
+  (SELECT COALESCE(MAX(round_no), 0) + 1 FROM score_rounds WHERE scorebook_id = $1::uuid),
+  $2::uuid,
+  $3
+)
+RETURNING id::text, scorebook_id::text, round_no, created_by_member_id::text, note, created_at
+`, scorebookID, myMemberID, note).Scan(
+		&round.ID,
+		&round.ScorebookID,
+		&round.RoundNo,
+		&round.CreatedByMemberID,
+		&round.Note,
+		&round.CreatedAt,
+	)
+	if err != nil {
+		return ScoreRound{}, err
+	}
+
+	for _, t := range planTransfers(balances) {
+		var r ScoreRecord
+		err = tx.QueryRow(ctx, `
+INSERT INTO score_records (scorebook_id, from_member_id, to_member_id, delta, note, round_id)
+VALUES ($1::uuid, $2::uuid, $3::uuid, $4, $5, $6::uuid)
+RETURNING id::text, scorebook_id::text, from_member_id::text, to_member_id::text, delta::float8, note, created_at, round_id::text
+`, scorebookID, t.FromMemberID, t.ToMemberID, centsToAmount(t.Cents), note, round.ID).Scan(
+			&r.ID,
+			&r.ScorebookID,
+			&r.FromMemberID,
+			&r.ToMemberID,
+			&r.Delta,
+			&r.Note,
+			&r.CreatedAt,
+			&r.RoundID,
+		)
+		if err != nil {
+			return ScoreRound{}, err
+		}
+		round.Records = append(round.Records, r)
+	}
+
+	for _, id := range memberIDs {
+		if _, err := tx.Exec(ctx, `
+UPDATE scorebook_members
+SET score = score + $1, updated_at = NOW()
+WHERE scorebook_id = $2::uuid AND id = $3::uuid
+`, centsToAmount(balances[id]), scorebookID, id); err != nil {
+			return ScoreRound{}, err
+		}
+	}
+	_, _ = tx.Exec(ctx, `UPDATE scorebooks SET updated_at = NOW() WHERE id = $1::uuid`, scorebookID)
+
+	if err := tx.Commit(ctx); err != nil {
+		return ScoreRound{}, err
+	}
+
+	for _, d := range deltas {
+		id := strings.TrimSpace(d.MemberID)
+		if balances[id] == 0 {
+			continue
+		}
+		round.Deltas = append(round.Deltas, RoundDelta{MemberID: id, Delta: centsToAmount(balances[id])})
+	}
+	return round, nil
+}
+
+// RoundBalances validates round deltas and converts them to cents keyed by member id.
+// Zero deltas are dropped; at least two members must remain and the total must be zero.
+func RoundBalances(deltas []RoundDelta) (map[string]int64, error) {
+	balances := make(map[string]int64, len(deltas))
+	var sum int64
+	for _, d := range deltas {
+		id := strings.TrimSpace(d.MemberID)
+		if id == "" {
+			return nil, ErrInvalidArgument
+		}
+		if _, dup := balances[id]; dup {
+			return nil, ErrInvalidArgument
+		}
+		cents, ok := amountToCents(d.Delta)
+		if !ok {
+			return nil, ErrInvalidDelta
+		}
+		balances[id] = cents
+		sum += cents
+	}
+	for id, cents := range balances {
+		if cents == 0 {
+			delete(balances, id)
+		}
+	}
+	if len(balances) < 2 || sum != 0 {
+		return nil, ErrInvalidDelta
+	}
+	return balances, nil
+}
@@ -11,7 +11,11 @@ import (
 	"github.com/jackc/pgx/v5/pgconn"
 )
 
-func (s *Store) CreateScorebook(ctx context.Context, user User, name, locationText, bookType string) (Scorebook, Member, error) {
+// CreateScorebook creates a scorebook owned by user. With a templateID (which must be
+// one of the user's templates) the template's game settings are copied onto the
+// scorebook, its stake multiplier becomes the initial point value, and its roster is
+// added as placeholder members.
+func (s *Store) CreateScorebook(ctx context.Context, user User, name, locationText, bookType, templateID string, inviteUserIDs []int64) (Scorebook, Member, error) {
 	tx, err := s.pool.BeginTx(ctx, pgx.TxOptions{})
 	if err != nil {
 		return Scorebook{}, Member{}, err
@@ -21,13 +25,36 @@ func (s *Store) CreateScorebook(ctx context.Context, user User, name, locationTe
 	var sb Scorebook
 	var owner Member
 
+	game := ScorebookGame{StakeMultiplier: 1, DeltaPresets: []float64{}, PointValue: 1, Currency: DefaultCurrency}
+	var placeholders []string
+	if templateID != "" {
+		if !isUUID(templateID) {
+			return Scorebook{}, Member{}, ErrNotFound
+		}
+		tpl, err := scanTemplate(tx.QueryRow(ctx, `
+SELECT `+templateColumns+`
+FROM scorebook_templates
+WHERE id = $1::uuid AND user_id = $2
+`, templateID, user.ID))
+		if err != nil {
+			return Scorebook{}, Member{}, err
+		}
+		game.TemplateID = tpl.ID
+		game.GameType = tpl.GameType
+		game.StakeMultiplier = tpl.StakeMultiplier
+		game.DeltaPresets = tpl.DeltaPresets
+		game.PointValue = tpl.StakeMultiplier
+		placeholders = tpl.MemberNames
+	}
+
 	for i := 0; i < 5; i++ {
 		invite := randomInviteCode(8)
 		err = tx.QueryRow(ctx, `
-INSERT INTO scorebooks (name, location_text, book_type, created_by_user_id, invite_code, updated_at)
-VALUES ($1, $2, $3, $4, $5, NOW())
+INSERT INTO scorebooks (name, location_text, book_type, created_by_user_id, invite_code, updated_at,
+  template_id, game_type, stake_multiplier, delta_presets, point_value, currency)
+VALUES ($1, $2, $3, $4, $5, NOW(), NULLIF($6, '')::uuid, $7, $8, $9::float8[], $10, $11)
 RETURNING id::text, name, location_text, start_time, updated_at, status::text, book_type, created_by_user_id, ended_at, invite_code, share_disabled
-`, name, locationText, bookType, user.ID, invite).Scan(
+`, name, locationText, bookType, user.ID, invite, game.TemplateID, game.GameType, game.StakeMultiplier, game.DeltaPresets, game.PointValue, game.Currency).Scan(
 			&sb.ID,
 			&sb.Name,
 			&sb.LocationText,
@@ -77,9 +104,32 @@ RETURNING id::text, scorebook_id::text, user_id, role::text, nickname, avatar_ur
 		return Scorebook{}, Member{}, err
 	}
 
+	if _, err := tx.Exec(ctx, `
+INSERT INTO scorebook_point_rates (scorebook_id, point_value, currency, effective_at)
+VALUES ($1::uuid, $2, $3, $4)
+`, sb.ID, game.PointValue, game.Currency, sb.StartTime); err != nil {
+		return Scorebook{}, Member{}, err
+	}
+
+	if len(inviteUserIDs) > 0 {
+		if _, err := s.inviteUsersTx(ctx, tx, sb.ID, user.ID, inviteUserIDs); err != nil {
+			return Scorebook{}, Member{}, err
+		}
+	}
+
+	for _, nickname := range placeholders {
+		if _, err := tx.Exec(ctx, `
+INSERT INTO scorebook_members (scorebook_id, user_id, role, nickname, placeholder, updated_at)
+VALUES ($1::uuid, NULL, 'member', $2, TRUE, NOW())
+`, sb.ID, nickname); err != nil {
+			return Scorebook{}, Member{}, err
+		}
+	}
+
 	if err := tx.Commit(ctx); err != nil {
 		return Scorebook{}, Member{}, err
 	}
+	sb.Game = &game
 	return sb, owner, nil
 }
 
@@ -97,7 +147,7 @@ SELECT
   s.invite_code,
   m.id::text AS my_member_id,
   m.role::text AS my_role,
-  (SELECT COUNT(*) FROM scorebook_members mm WHERE mm.scorebook_id = s.id) AS member_count
+  (SELECT COUNT(*) FROM scorebook_members mm WHERE mm.scorebook_id = s.id AND mm.user_id IS NOT NULL) AS member_count
 FROM scorebooks s
 JOIN scorebook_members m ON m.scorebook_id = s.id AND m.user_id = $1
 WHERE s.book_type = 'scorebook' AND s.deleted_at IS NULL
@@ -135,6 +185,7 @@ LIMIT $2 OFFSET $3
 
 func (s *Store) GetScorebookDetail(ctx context.Context, scorebookID string, userID int64) (Scorebook, string, string, []MemberWithScore, error) {
 	var sb Scorebook
+	var game ScorebookGame
 	var myMemberID string
 	var myRole string
 	err := s.pool.QueryRow(ctx, `
@@ -149,6 +200,12 @@ SELECT
   s.created_by_user_id,
   s.ended_at,
   s.invite_code,
+  COALESCE(s.template_id::text, ''),
+  s.game_type,
+  s.stake_multiplier::float8,
+  s.delta_presets::float8[],
+  s.point_value::float8,
+  s.currency,
   m.id::text AS my_member_id,
   m.role::text AS my_role
 FROM scorebooks s
@@ -165,6 +222,12 @@ WHERE s.id = $1::uuid AND s.book_type = 'scorebook' AND s.deleted_at IS NULL
 		&sb.CreatedByUserID,
 		&sb.EndedAt,
 		&sb.InviteCode,
+		&game.TemplateID,
+		&game.GameType,
+		&game.StakeMultiplier,
+		&game.DeltaPresets,
+		&game.PointValue,
+		&game.Currency,
 		&myMemberID,
 		&myRole,
 	)
@@ -179,12 +242,13 @@ WHERE s.id = $1::uuid AND s.book_type = 'scorebook' AND s.deleted_at IS NULL
 SELECT
   m.id::text,
   m.scorebook_id::text,
-  m.user_id,
+  COALESCE(m.user_id, 0),
   m.role::text,
   m.nickname,
   m.avatar_url,
   m.joined_at,
   m.updated_at,
+  m.placeholder,
   m.score::float8
 FROM scorebook_members m
 WHERE m.scorebook_id = $1::uuid
@@ -207,6 +271,7 @@ ORDER BY m.joined_at ASC
 			&m.AvatarURL,
 			&m.JoinedAt,
 			&m.UpdatedAt,
+			&m.Placeholder,
 			&m.Score,
 		); err != nil {
 			return Scorebook{}, "", "", nil, err
@@ -216,24 +281,37 @@ ORDER BY m.joined_at ASC
 	if err := rows.Err(); err != nil {
 		return Scorebook{}, "", "", nil, err
 	}
+	if err := s.fillMoney(ctx, s.pool, scorebookID, members); err != nil {
+		return Scorebook{}, "", "", nil, err
+	}
 
+	if game.DeltaPresets == nil {
+		game.DeltaPresets = []float64{}
+	}
+	sb.Game = &game
 	return sb, myMemberID, myRole, members, nil
 }
 
+// UpdateScorebookName renames a scorebook. Owners and admins may do this.
 func (s *Store) UpdateScorebookName(ctx context.Context, scorebookID string, userID int64, name string) (Scorebook, error) {
+	_, role, err := s.memberRole(ctx, s.pool, scorebookID, userID)
+	if err != nil {
+		return Scorebook{}, err
+	}
+	if !CanManage(role) {
+		return Scorebook{}, ErrForbidden
+	}
+
 	var sb Scorebook
-	err := s.pool.QueryRow(ctx, `
+	err = s.pool.QueryRow(ctx, `
 UPDATE scorebooks s
-SET name = $3,
+SET name = $2,
     updated_at = NOW()
 WHERE s.id = $1::uuid
   AND s.book_type = 'scorebook'
-  AND EXISTS (
-    SELECT 1 FROM scorebook_members m
-    WHERE m.scorebook_id = s.id AND m.user_id = $2 AND m.role = 'owner'
-  )
+  AND s.deleted_at IS NULL
 RETURNING id::text, name, location_text, start_time, updated_at, status::text, book_type, created_by_user_id, ended_at, invite_code, share_disabled
-`, scorebookID, userID, name).Scan(
+`, scorebookID, name).Scan(
 		&sb.ID,
 		&sb.Name,
 		&sb.LocationText,
@@ -255,9 +333,19 @@ RETURNING id::text, name, location_text, start_time, updated_at, status::text, b
 	return sb, nil
 }
 
+// EndScorebook ends a recording scorebook. Owners and admins may do this; ending an
+// already ended scorebook returns ErrScorebookEnded.
 func (s *Store) EndScorebook(ctx context.Context, scorebookID string, userID int64) (Scorebook, error) {
+	_, role, err := s.memberRole(ctx, s.pool, scorebookID, userID)
+	if err != nil {
+		return Scorebook{}, err
+	}
+	if !CanManage(role) {
+		return Scorebook{}, ErrForbidden
+	}
+
 	var sb Scorebook
-	err := s.pool.QueryRow(ctx, `
+	err = s.pool.QueryRow(ctx, `
 UPDATE scorebooks s
 SET status = 'ended',
     ended_at = NOW(),
@@ -265,12 +353,9 @@ SET status = 'ended',
 WHERE s.id = $1::uuid
   AND s.book_type = 'scorebook'
   AND s.status = 'recording'
-  AND EXISTS (
-    SELECT 1 FROM scorebook_members m
-    WHERE m.scorebook_id = s.id AND m.user_id = $2 AND m.role = 'owner'
-  )
+  AND s.deleted_at IS NULL
 RETURNING id::text, name, location_text, start_time, updated_at, status::text, book_type, created_by_user_id, ended_at, invite_code, share_disabled
-`, scorebookID, userID).Scan(
+`, scorebookID).Scan(
 		&sb.ID,
 		&sb.Name,
 		&sb.LocationText,
@@ -285,7 +370,7 @@ RETURNING id::text, name, location_text, start_time, updated_at, status::text, b
 	)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
-			return Scorebook{}, ErrNotFound
+			return Scorebook{}, ErrScorebookEnded
 		}
 		return Scorebook{}, err
 	}
@@ -414,7 +499,19 @@ RETURNING id::text, name, location_text, start_time, updated_at, status::text, b
 	return out, nil
 }
 
-func (s *Store) JoinScorebook(ctx context.Context, scorebookID string, user User, nickname, avatarURL string) (Member, error) {
+// JoinScorebook adds the user to a recording scorebook as a member or spectator
+// (role "" means member). Joining again returns the existing membership unchanged;
+// users banned from the scorebook get ErrBanned. New members must present the
+// scorebook's current invite code, which is checked and counted exactly like
+// JoinByInviteCode. When the scorebook requires join approval a pending JoinRequest
+// is returned instead and no member is created.
+func (s *Store) JoinScorebook(ctx context.Context, scorebookID, inviteCode string, user User, nickname, avatarURL, role string) (Member, *JoinRequest, error) {
+	if role == "" {
+		role = RoleMember
+	}
+	if role != RoleMember && role != RoleSpectator {
+		return Member{}, nil, ErrInvalidArgument
+	}
 	if strings.TrimSpace(nickname) == "" {
 		nickname = user.WeChatNickname
 	}
@@ -427,13 +524,31 @@ func (s *Store) JoinScorebook(ctx context.Context, scorebookID string, user User
 
 	tx, err := s.pool.BeginTx(ctx, pgx.TxOptions{})
 	if err != nil {
-		return Member{}, err
+		return Member{}, nil, err
 	}
 	defer func() { _ = tx.Rollback(ctx) }()
 
+	m, req, err := s.joinScorebookTx(ctx, tx, scorebookID, user, nickname, avatarURL, role, inviteCode, func() error {
+		return s.checkInviteUsable(ctx, tx, scorebookID, inviteCode)
+	})
+	if err != nil {
+		return Member{}, nil, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return Member{}, nil, err
+	}
+	return m, req, nil
+}
+
+// joinScorebookTx adds the user to the scorebook inside tx. Rejoining returns the
+// existing member without any checks. For a new member admit (if set) runs after the
+// ended/ban checks and may refuse the join; if the scorebook requires approval a
+// pending join request is returned instead. inviteCode is the code used to join,
+// empty when the join needs no code (admit is then nil).
+func (s *Store) joinScorebookTx(ctx context.Context, tx pgx.Tx, scorebookID string, user User, nickname, avatarURL, role, inviteCode string, admit func() error) (Member, *JoinRequest, error) {
 	// 已加入过：允许（哪怕已结束也允许打开详情），保持幂等。
 	var existing Member
-	err = tx.QueryRow(ctx, `
+	err := tx.QueryRow(ctx, `
 SELECT id::text, scorebook_id::text, user_id, role::text, nickname, avatar_url, joined_at, updated_at
 FROM scorebook_members
 WHERE scorebook_id = $1::uuid AND user_id = $2
@@ -450,52 +565,56 @@ WHERE scorebook_id = $1::uuid AND user_id = $2
 	if err == nil {
 		_, _ = tx.Exec(ctx, `UPDATE scorebook_members SET updated_at = NOW() WHERE id = $1::uuid`, existing.ID)
 		_, _ = tx.Exec(ctx, `UPDATE scorebooks SET updated_at = NOW() WHERE id = $1::uuid`, scorebookID)
-		if err := tx.Commit(ctx); err != nil {
-			return Member{}, err
-		}
-		return existing, nil
+		return existing, nil, nil
 	}
 	if !errors.Is(err, pgx.ErrNoRows) {
-		return Member{}, err
+		return Member{}, nil, err
 	}
 
 	// 若已结束，不允许新增。
 	var status string
-	err = tx.QueryRow(ctx, `SELECT status::text FROM scorebooks WHERE id = $1::uuid AND book_type = 'scorebook' AND deleted_at IS NULL`, scorebookID).Scan(&status)
+	var approval bool
+	err = tx.QueryRow(ctx, `SELECT status::text, join_approval FROM scorebooks WHERE id = $1::uuid AND book_type = 'scorebook' AND deleted_at IS NULL`, scorebookID).Scan(&status, &approval)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
-			return Member{}, ErrNotFound
+			return Member{}, nil, ErrNotFound
 		}
-		return Member{}, err
+		return Member{}, nil, err
 	}
 	if status == "ended" {
-		return Member{}, ErrScorebookEnded
+		return Member{}, nil, ErrScorebookEnded
 	}
 
-	var m Member
+	var banned bool
 	err = tx.QueryRow(ctx, `
-INSERT INTO scorebook_members (scorebook_id, user_id, role, nickname, avatar_url, updated_at)
-VALUES ($1::uuid, $2, 'member', $3, $4, NOW())
-RETURNING id::text, scorebook_id::text, user_id, role::text, nickname, avatar_url, joined_at, updated_at
-`, scorebookID, user.ID, nickname, avatarURL).Scan(
-		&m.ID,
-		&m.ScorebookID,
-		&m.UserID,
-		&m.Role,
-		&m.Nickname,
-		&m.AvatarURL,
-		&m.JoinedAt,
-		&m.UpdatedAt,
-	)
+SELECT EXISTS (SELECT 1 FROM scorebook_bans WHERE scorebook_id = $1::uuid AND user_id = $2)
+`, scorebookID, user.ID).Scan(&banned)
 	if err != nil {
-		return Member{}, err
+		return Member{}, nil, err
+	}
+	if banned {
+		return Member{}, nil, ErrBanned
+	}
+	if admit != nil {
+		if err := admit(); err != nil {
+			return Member{}, nil, err
+		}
 	}
-	_, _ = tx.Exec(ctx, `UPDATE scorebooks SET updated_at = NOW() WHERE id = $1::uuid`, scorebookID)
 
-	if err := tx.Commit(ctx); err != nil {
-		return Member{}, err
+	// 需要审核：记录（或更新）待审核的申请，由掌柜或管理员批准后再创建成员。
+	if approval {
+		req, err := s.requestJoinTx(ctx, tx, scorebookID, user.ID, nickname, avatarURL, role, inviteCode)
+		if err != nil {
+			return Member{}, nil, err
+		}
+		return Member{}, &req, nil
 	}
-	return m, nil
+
+	m, err := s.insertJoinedMember(ctx, tx, scorebookID, user.ID, nickname, avatarURL, role, inviteCode, nil)
+	if err != nil {
+		return Member{}, nil, err
+	}
+	return m, nil, nil
 }
 
 func (s *Store) UpdateMyProfile(ctx context.Context, scorebookID string, userID int64, nickname, avatarURL string) (Member, error) {
@@ -536,7 +655,7 @@ func (s *Store) CreateRecord(ctx context.Context, scorebookID string, userID int
 		return ScoreRecord{}, ErrInvalidArgument
 	}
 	var ok bool
-	delta, ok = normalizeAmount(delta)
+	delta, ok = NormalizeAmount(delta)
 	if !ok {
 		return ScoreRecord{}, ErrInvalidDelta
 	}
@@ -548,7 +667,8 @@ func (s *Store) CreateRecord(ctx context.Context, scorebookID string, userID int
 	defer func() { _ = tx.Rollback(ctx) }()
 
 	var status string
-	err = tx.QueryRow(ctx, `SELECT status::text FROM scorebooks WHERE id = $1::uuid AND book_type = 'scorebook' AND deleted_at IS NULL FOR UPDATE`, scorebookID).Scan(&status)
+	var presets []float64
+	err = tx.QueryRow(ctx, `SELECT status::text, delta_presets::float8[] FROM scorebooks WHERE id = $1::uuid AND book_type = 'scorebook' AND deleted_at IS NULL FOR UPDATE`, scorebookID).Scan(&status, &presets)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return ScoreRecord{}, ErrNotFound
@@ -560,33 +680,45 @@ func (s *Store) CreateRecord(ctx context.Context, scorebookID string, userID int
 	}
 
 	var fromMemberID string
+	var fromRole string
 	err = tx.QueryRow(ctx, `
-SELECT id::text
+SELECT id::text, role::text
 FROM scorebook_members
 WHERE scorebook_id = $1::uuid AND user_id = $2
-`, scorebookID, userID).Scan(&fromMemberID)
+`, scorebookID, userID).Scan(&fromMemberID, &fromRole)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return ScoreRecord{}, ErrForbidden
 		}
 		return ScoreRecord{}, err
 	}
+	if fromRole == RoleSpectator {
+		return ScoreRecord{}, ErrForbidden
+	}
 	if fromMemberID == toMemberID {
 		return ScoreRecord{}, ErrInvalidArgument
 	}
 
-	var tmp string
+	// 只能给仍在得分簿中的非观众成员（含占位成员）记分
+	var toRole string
 	err = tx.QueryRow(ctx, `
-SELECT id::text
+SELECT role::text
 FROM scorebook_members
-WHERE scorebook_id = $1::uuid AND id = $2::uuid
-`, scorebookID, toMemberID).Scan(&tmp)
+WHERE scorebook_id = $1::uuid AND id = $2::uuid AND (user_id IS NOT NULL OR placeholder)
+`, scorebookID, toMemberID).Scan(&toRole)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return ScoreRecord{}, ErrNotFound
 		}
 		return ScoreRecord{}, err
 	}
+	if toRole == RoleSpectator {
+		return ScoreRecord{}, ErrInvalidArgument
+	}
+	// 模板设置了分值预设时，单条记分只能使用预设中的分值
+	if !DeltaAllowed(presets, delta) {
+		return ScoreRecord{}, ErrDeltaNotAllowed
+	}
 
 	var r ScoreRecord
 	err = tx.QueryRow(ctx, `
@@ -628,12 +760,147 @@ WHERE scorebook_id = $2::uuid AND id = $3::uuid
 	return r, nil
 }
 
+// VoidRecord voids a score record by writing a reversing entry linked to it and
+// restoring both members' scores in the same transaction.
+//
+// Only the record's author (from member) or a scorebook owner/admin may void, and only
+// within window after the record was created (window <= 0 means no limit).
+// It returns the voided original record and the reversing entry.
+func (s *Store) VoidRecord(ctx context.Context, scorebookID string, userID int64, recordID string, window time.Duration) (ScoreRecord, ScoreRecord, error) {
+	if strings.TrimSpace(recordID) == "" {
+		return ScoreRecord{}, ScoreRecord{}, ErrInvalidArgument
+	}
+
+	tx, err := s.pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return ScoreRecord{}, ScoreRecord{}, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	var status string
+	err = tx.QueryRow(ctx, `SELECT status::text FROM scorebooks WHERE id = $1::uuid AND book_type = 'scorebook' AND deleted_at IS NULL FOR UPDATE`, scorebookID).Scan(&status)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ScoreRecord{}, ScoreRecord{}, ErrNotFound
+		}
+		return ScoreRecord{}, ScoreRecord{}, err
+	}
+	if status != "recording" {
+		return ScoreRecord{}, ScoreRecord{}, ErrScorebookEnded
+	}
+
+	var myMemberID string
+	var myRole string
+	err = tx.QueryRow(ctx, `
+SELECT id::text, role::text
+FROM scorebook_members
+WHERE scorebook_id = $1::uuid AND user_id = $2
+`, scorebookID, userID).Scan(&myMemberID, &myRole)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ScoreRecord{}, ScoreRecord{}, ErrForbidden
+		}
+		return ScoreRecord{}, ScoreRecord{}, err
+	}
+
+	var orig ScoreRecord
+	var reversesID string
+	err = tx.QueryRow(ctx, `
+SELECT id::text, scorebook_id::text, from_member_id::text, to_member_id::text, delta::float8, note, created_at,
+       voided_at, COALESCE(voided_by_member_id::text, ''), COALESCE(reverses_record_id::text, ''), COALESCE(round_id::text, '')
+FROM score_records
+WHERE scorebook_id = $1::uuid AND id = $2::uuid
+FOR UPDATE
+`, scorebookID, recordID).Scan(
+		&orig.ID,
+		&orig.ScorebookID,
+		&orig.FromMemberID,
+		&orig.ToMemberID,
+		&orig.Delta,
+		&orig.Note,
+		&orig.CreatedAt,
+		&orig.VoidedAt,
+		&orig.VoidedByMemberID,
+		&reversesID,
+		&orig.RoundID,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ScoreRecord{}, ScoreRecord{}, ErrNotFound
+		}
+		return ScoreRecord{}, ScoreRecord{}, err
+	}
+	if reversesID != "" || orig.RoundID != "" {
+		// 冲正记录本身、以及整局记分中的单条记录不可单独作废
+		return ScoreRecord{}, ScoreRecord{}, ErrInvalidArgument
+	}
+	if orig.VoidedAt != nil {
+		return ScoreRecord{}, ScoreRecord{}, ErrRecordVoided
+	}
+	if orig.FromMemberID != myMemberID && !CanManage(myRole) {
+		return ScoreRecord{}, ScoreRecord{}, ErrForbidden
+	}
+	if window > 0 && time.Since(orig.CreatedAt) > window {
+		return ScoreRecord{}, ScoreRecord{}, ErrVoidWindowClosed
+	}
+
+	var rev ScoreRecord
+	err = tx.QueryRow(ctx, `
+INSERT INTO score_records (scorebook_id, from_member_id, to_member_id, delta, note, reverses_record_id)
+VALUES ($1::uuid, $2::uuid, $3::uuid, $4, $5, $6::uuid)
+RETURNING id::text, scorebook_id::text, from_member_id::text, to_member_id::text, delta::float8, note, created_at, reverses_record_id::text
+`, scorebookID, orig.ToMemberID, orig.FromMemberID, orig.Delta, orig.Note, orig.ID).Scan(
+		&rev.ID,
+		&rev.ScorebookID,
+		&rev.FromMemberID,
+		&rev.ToMemberID,
+		&rev.Delta,
+		&rev.Note,
+		&rev.CreatedAt,
+		&rev.ReversesRecordID,
+	)
+	if err != nil {
+		return ScoreRecord{}, ScoreRecord{}, err
+	}
+
+	err = tx.QueryRow(ctx, `
+UPDATE score_records
+SET voided_at = NOW(), voided_by_member_id = $2::uuid
+WHERE id = $1::uuid
+RETURNING voided_at, voided_by_member_id::text
+`, orig.ID, myMemberID).Scan(&orig.VoidedAt, &orig.VoidedByMemberID)
+	if err != nil {
+		return ScoreRecord{}, ScoreRecord{}, err
+	}
+
+	if _, err := tx.Exec(ctx, `
+UPDATE scorebook_members
+SET score = score - $1, updated_at = NOW()
+WHERE scorebook_id = $2::uuid AND id = $3::uuid
+`, orig.Delta, scorebookID, orig.ToMemberID); err != nil {
+		return ScoreRecord{}, ScoreRecord{}, err
+	}
+	if _, err := tx.Exec(ctx, `
+UPDATE scorebook_members
+SET score = score + $1, updated_at = NOW()
+WHERE scorebook_id = $2::uuid AND id = $3::uuid
+`, orig.Delta, scorebookID, orig.FromMemberID); err != nil {
+		return ScoreRecord{}, ScoreRecord{}, err
+	}
+	_, _ = tx.Exec(ctx, `UPDATE scorebooks SET updated_at = NOW() WHERE id = $1::uuid`, scorebookID)
+
+	if err := tx.Commit(ctx); err != nil {
+		return ScoreRecord{}, ScoreRecord{}, err
+	}
+	return orig, rev, nil
+}
+
 func (s *Store) GetTopWinners(ctx context.Context, scorebookID string) ([]MemberWithScore, error) {
 	rows, err := s.pool.Query(ctx, `
 SELECT
   m.id::text,
   m.scorebook_id::text,
-  m.user_id,
+  COALESCE(m.user_id, 0),
   m.role::text,
   m.nickname,
   m.avatar_url,
@@ -671,6 +938,9 @@ ORDER BY m.score DESC, m.updated_at ASC
 	if err := rows.Err(); err != nil {
 		return nil, err
 	}
+	if err := s.fillMoney(ctx, s.pool, scorebookID, out); err != nil {
+		return nil, err
+	}
 	return out, nil
 }
 
@@ -697,10 +967,12 @@ SELECT EXISTS (SELECT 1 FROM scorebook_members WHERE scorebook_id = $1::uuid AND
 		return nil, ErrForbidden
 	}
 
+	// 冲正记录不单独展示，被作废的原记录带 voided_at 返回，由前端划线显示。
 	rows, err := s.pool.Query(ctx, `
-SELECT id::text, scorebook_id::text, from_member_id::text, to_member_id::text, delta::float8, note, created_at
+SELECT id::text, scorebook_id::text, from_member_id::text, to_member_id::text, delta::float8, note, created_at,
+       voided_at, COALESCE(voided_by_member_id::text, ''), COALESCE(round_id::text, '')
 FROM score_records
-WHERE scorebook_id = $1::uuid
+WHERE scorebook_id = $1::uuid AND reverses_record_id IS NULL
 ORDER BY created_at DESC
 LIMIT $2 OFFSET $3
 `, scorebookID, limit, offset)
@@ -712,7 +984,7 @@ LIMIT $2 OFFSET $3
 	var out []ScoreRecord
 	for rows.Next() {
 		var r ScoreRecord
-		if err := rows.Scan(&r.ID, &r.ScorebookID, &r.FromMemberID, &r.ToMemberID, &r.Delta, &r.Note, &r.CreatedAt); err != nil {
+		if err := rows.Scan(&r.ID, &r.ScorebookID, &r.FromMemberID, &r.ToMemberID, &r.Delta, &r.Note, &r.CreatedAt, &r.VoidedAt, &r.VoidedByMemberID, &r.RoundID); err != nil {
 			return nil, err
 		}
 		out = append(out, r)
@@ -753,6 +1025,8 @@ WHERE scorebook_id = $1::uuid AND user_id = $2
 	return m, nil
 }
 
+// IsMember reports whether the user is a member of the scorebook. Ledgers don't
+// count: their members are gated by GetLedgerRole instead.
 func (s *Store) IsMember(ctx context.Context, scorebookID string, userID int64) (bool, error) {
 	var ok bool
 	err := s.pool.QueryRow(ctx, `
@@ -760,7 +1034,8 @@ SELECT EXISTS(
   SELECT 1
   FROM scorebook_members m
   JOIN scorebooks s ON s.id = m.scorebook_id
-  WHERE m.scorebook_id = $1::uuid AND m.user_id = $2 AND s.deleted_at IS NULL
+  WHERE m.scorebook_id = $1::uuid AND m.user_id = $2
+    AND s.book_type = 'scorebook' AND s.deleted_at IS NULL
 )
 `, scorebookID, userID).Scan(&ok)
 	if err != nil {
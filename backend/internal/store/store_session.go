@@ -0,0 +1,149 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+const sessionColumns = `id::text, user_id, user_agent, ip, created_at, last_used_at, expires_at, revoked_at`
+
+func scanSession(row pgx.Row) (UserSession, error) {
+	var ss UserSession
+	err := row.Scan(
+		&ss.ID,
+		&ss.UserID,
+		&ss.UserAgent,
+		&ss.IP,
+		&ss.CreatedAt,
+		&ss.LastUsedAt,
+		&ss.ExpiresAt,
+		&ss.RevokedAt,
+	)
+	return ss, err
+}
+
+// CreateSession starts a login session identified by the hash of its refresh token.
+// The session expires ttl after creation, measured on the database clock.
+func (s *Store) CreateSession(ctx context.Context, userID int64, refreshHash, userAgent, ip string, ttl time.Duration) (UserSession, error) {
+	return scanSession(s.pool.QueryRow(ctx, `
+INSERT INTO user_sessions (user_id, refresh_token_hash, user_agent, ip, expires_at)
+VALUES ($1, $2, $3, $4, NOW() + $5::bigint * INTERVAL '1 microsecond')
+RETURNING `+sessionColumns, userID, refreshHash, userAgent, ip, ttl.Microseconds()))
+}
+
+// RotateSession exchanges a refresh token for a new one. Presenting a token that was
+// already rotated means it leaked, so the whole session is revoked (ErrSessionRevoked).
+// Unknown tokens return ErrNotFound; revoked or expired sessions ErrSessionRevoked.
+func (s *Store) RotateSession(ctx context.Context, oldHash, newHash string, ttl time.Duration) (UserSession, error) {
+	tx, err := s.pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return UserSession{}, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	ss, err := scanSession(tx.QueryRow(ctx, `
+SELECT `+sessionColumns+`
+FROM user_sessions
+WHERE refresh_token_hash = $1
+FOR UPDATE
+`, oldHash))
+	if errors.Is(err, pgx.ErrNoRows) {
+		tag, err := tx.Exec(ctx, `
+UPDATE user_sessions
+SET revoked_at = NOW()
+WHERE previous_token_hash = $1 AND revoked_at IS NULL
+`, oldHash)
+		if err != nil {
+			return UserSession{}, err
+		}
+		if tag.RowsAffected() == 0 {
+			return UserSession{}, ErrNotFound
+		}
+		if err := tx.Commit(ctx); err != nil {
+			return UserSession{}, err
+		}
+		return UserSession{}, ErrSessionRevoked
+	}
+	if err != nil {
+		return UserSession{}, err
+	}
+
+	ss, err = scanSession(tx.QueryRow(ctx, `
+UPDATE user_sessions
+SET refresh_token_hash = $2,
+    previous_token_hash = refresh_token_hash,
+    last_used_at = NOW(),
+    expires_at = NOW() + $3::bigint * INTERVAL '1 microsecond'
+WHERE id = $1::uuid AND revoked_at IS NULL AND expires_at > NOW()
+RETURNING `+sessionColumns, ss.ID, newHash, ttl.Microseconds()))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return UserSession{}, ErrSessionRevoked
+	}
+	if err != nil {
+		return UserSession{}, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return UserSession{}, err
+	}
+	return ss, nil
+}
+
+// SessionActive reports whether the session exists for the user and is neither
+// revoked nor expired.
+func (s *Store) SessionActive(ctx context.Context, userID int64, sessionID string) (bool, error) {
+	var ok bool
+	err := s.pool.QueryRow(ctx, `
+SELECT EXISTS (
+  SELECT 1 FROM user_sessions
+  WHERE id = $1::uuid AND user_id = $2 AND revoked_at IS NULL AND expires_at > NOW()
+)
+`, sessionID, userID).Scan(&ok)
+	return ok, err
+}
+
+// ListSessions returns the user's active sessions, most recently used first.
+func (s *Store) ListSessions(ctx context.Context, userID int64) ([]UserSession, error) {
+	rows, err := s.pool.Query(ctx, `
+SELECT `+sessionColumns+`
+FROM user_sessions
+WHERE user_id = $1 AND revoked_at IS NULL AND expires_at > NOW()
+ORDER BY last_used_at DESC, created_at DESC
+`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []UserSession
+	for rows.Next() {
+		ss, err := scanSession(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, ss)
+	}
+	return out, rows.Err()
+}
+
+// RevokeSession signs a session out. Sessions of other users, already revoked ones and
+// malformed ids all return ErrNotFound.
+func (s *Store) RevokeSession(ctx context.Context, userID int64, sessionID string) error {
+	if !isUUID(sessionID) {
+		return ErrNotFound
+	}
+	tag, err := s.pool.Exec(ctx, `
+UPDATE user_sessions
+SET revoked_at = NOW()
+WHERE id = $1::uuid AND user_id = $2 AND revoked_at IS NULL
+`, sessionID, userID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
@@ -0,0 +1,376 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// PlanSettlement computes the payer -> payee transfers that settle the given final
+// balances. Scores are converted to integer cents first so float rounding can't leak
+// into the plan; members with a zero score are skipped. A score that isn't a whole
+// number of cents is an error rather than being left out of the plan.
+func PlanSettlement(members []MemberWithScore) ([]SettlementTransfer, error) {
+	balances := make(map[string]int64, len(members))
+	for _, m := range members {
+		cents, ok := amountToCents(m.Score)
+		if !ok {
+			return nil, fmt.Errorf("settle member %s: score %v is not a whole number of cents", m.ID, m.Score)
+		}
+		if cents == 0 {
+			continue
+		}
+		balances[m.ID] = cents
+	}
+	var out []SettlementTransfer
+	for _, t := range planTransfers(balances) {
+		out = append(out, SettlementTransfer{
+			FromMemberID: t.FromMemberID,
+			ToMemberID:   t.ToMemberID,
+			Amount:       centsToAmount(t.Cents),
+		})
+	}
+	return out, nil
+}
+
+// GetSettlement returns the settlement plan of a scorebook for one of its members.
+// For an ended scorebook the plan is persisted (generated on first access) and
+// transfers carry ids and paid state; for a recording one it's a preview computed
+// from current scores. The returned bool reports whether the plan is final.
+func (s *Store) GetSettlement(ctx context.Context, scorebookID string, userID int64) ([]SettlementTransfer, bool, error) {
+	status, err := s.GetScorebookStatus(ctx, scorebookID)
+	if err != nil {
+		return nil, false, err
+	}
+	isMember, err := s.IsMember(ctx, scorebookID, userID)
+	if err != nil {
+		return nil, false, err
+	}
+	if !isMember {
+		return nil, false, ErrForbidden
+	}
+
+	if status == "ended" {
+		out, err := s.EnsureSettlement(ctx, scorebookID)
+		return out, true, err
+	}
+
+	members, err := s.listMembersWithScore(ctx, s.pool, scorebookID)
+	if err != nil {
+		return nil, false, err
+	}
+	out, err := PlanSettlement(members)
+	if err != nil {
+		return nil, false, err
+	}
+	nicknames := make(map[string]string, len(members))
+	for _, m := range members {
+		nicknames[m.ID] = m.Nickname
+	}
+	for i := range out {
+		out[i].ScorebookID = scorebookID
+		out[i].FromNickname = nicknames[out[i].FromMemberID]
+		out[i].ToNickname = nicknames[out[i].ToMemberID]
+	}
+	return out, false, nil
+}
+
+// EnsureSettlement persists the settlement plan of an ended scorebook if it hasn't
+// been generated yet, and returns the stored transfers.
+func (s *Store) EnsureSettlement(ctx context.Context, scorebookID string) ([]SettlementTransfer, error) {
+	tx, err := s.pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	var status string
+	err = tx.QueryRow(ctx, `SELECT status::text FROM scorebooks WHERE id = $1::uuid AND book_type = 'scorebook' AND deleted_at IS NULL FOR UPDATE`, scorebookID).Scan(&status)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	if status != "ended" {
+		return nil, ErrScorebookNotEnded
+	}
+
+	var exists bool
+	err = tx.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM scorebook_settlements WHERE scorebook_id = $1::uuid)`, scorebookID).Scan(&exists)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		members, err := s.listMembersWithScore(ctx, tx, scorebookID)
+		if err != nil {
+			return nil, err
+		}
+		plan, err := PlanSettlement(members)
+		if err != nil {
+			return nil, err
+		}
+		for _, t := range plan {
+			if _, err := tx.Exec(ctx, `
+INSERT INTO scorebook_settlements (scorebook_id, from_member_id, to_member_id, amount)
+VALUES ($1::uuid, $2::uuid, $3::uuid, $4)
+`, scorebookID, t.FromMemberID, t.ToMemberID, t.Amount); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	rows, err := tx.Query(ctx, `
+SELECT
+  st.id::text,
+  st.scorebook_id::text,
+  st.from_member_id::text,
+  fm.nickname,
+  st.to_member_id::text,
+  tm.nickname,
+  st.amount::float8,
+  st.paid_at,
+  COALESCE(st.paid_by_member_id::text, ''),
+  st.created_at
+FROM scorebook_settlements st
+JOIN scorebook_members fm ON fm.id = st.from_member_id
+JOIN scorebook_members tm ON tm.id = st.to_member_id
+WHERE st.scorebook_id = $1::uuid
+ORDER BY st.amount DESC, st.created_at ASC, st.id ASC
+`, scorebookID)
+	if err != nil {
+		return nil, err
+	}
+	var out []SettlementTransfer
+	for rows.Next() {
+		t, err := scanSettlementTransfer(rows)
+		if err != nil {
+			rows.Close()
+			return nil, err
+		}
+		out = append(out, t)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// MarkSettlementTransferPaid marks (or unmarks) one settlement transfer as paid.
+// The payer, the payee and the scorebook owners/admins may do this.
+func (s *Store) MarkSettlementTransferPaid(ctx context.Context, scorebookID string, userID int64, transferID string, paid bool) (SettlementTransfer, error) {
+	if strings.TrimSpace(transferID) == "" {
+		return SettlementTransfer{}, ErrInvalidArgument
+	}
+
+	var myMemberID string
+	var myRole string
+	err := s.pool.QueryRow(ctx, `
+SELECT m.id::text, m.role::text
+FROM scorebook_members m
+JOIN scorebooks s ON s.id = m.scorebook_id
+WHERE m.scorebook_id = $1::uuid AND m.user_id = $2 AND s.book_type = 'scorebook' AND s.deleted_at IS NULL
+`, scorebookID, userID).Scan(&myMemberID, &myRole)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return SettlementTransfer{}, ErrForbidden
+		}
+		return SettlementTransfer{}, err
+	}
+
+	t, err := scanSettlementTransfer(s.pool.QueryRow(ctx, `
+WITH updated AS (
+  UPDATE scorebook_settlements st
+  SET paid_at = CASE WHEN $4 THEN COALESCE(st.paid_at, NOW()) ELSE NULL END,
+      paid_by_member_id = CASE WHEN $4 THEN COALESCE(st.paid_by_member_id, $3::uuid) ELSE NULL END
+  WHERE st.scorebook_id = $1::uuid AND st.id = $2::uuid
+    AND ($5 IN ('owner', 'admin') OR st.from_member_id = $3::uuid OR st.to_member_id = $3::uuid)
+  RETURNING st.*
+)
+SELECT
+  u.id::text,
+  u.scorebook_id::text,
+  u.from_member_id::text,
+  fm.nickname,
+  u.to_member_id::text,
+  tm.nickname,
+  u.amount::float8,
+  u.paid_at,
+  COALESCE(u.paid_by_member_id::text, ''),
+  u.created_at
+FROM updated u
+JOIN scorebook_members fm ON fm.id = u.from_member_id
+JOIN scorebook_members tm ON tm.id = u.to_member_id
+`, scorebookID, transferID, myMemberID, paid, myRole))
+	if err != nil {
+		if !errors.Is(err, pgx.ErrNoRows) {
+			return SettlementTransfer{}, err
+		}
+		var exists bool
+		if err := s.pool.QueryRow(ctx, `
+SELECT EXISTS (SELECT 1 FROM scorebook_settlements WHERE scorebook_id = $1::uuid AND id = $2::uuid)
+`, scorebookID, transferID).Scan(&exists); err != nil {
+			return SettlementTransfer{}, err
+		}
+		if exists {
+			return SettlementTransfer{}, ErrForbidden
+		}
+		return SettlementTransfer{}, ErrNotFound
+	}
+	return t, nil
+}
+
+type queryer interface {
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+}
+
+func (s *Store) listMembersWithScore(ctx context.Context, q queryer, scorebookID string) ([]MemberWithScore, error) {
+	rows, err := q.Query(ctx, `
+SELECT
+  m.id::text,
+  m.scorebook_id::text,
+  COALESCE(m.user_id, 0),
+  m.role::text,
+  m.nickname,
+  m.avatar_url,
+  m.joined_at,
+  m.updated_at,
+  m.score::float8
+FROM scorebook_members m
+WHERE m.scorebook_id = $1::uuid
+ORDER BY m.joined_at ASC
+`, scorebookID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []MemberWithScore
+	for rows.Next() {
+		var m MemberWithScore
+		if err := rows.Scan(
+			&m.ID,
+			&m.ScorebookID,
+			&m.UserID,
+			&m.Role,
+			&m.Nickname,
+			&m.AvatarURL,
+			&m.JoinedAt,
+			&m.UpdatedAt,
+			&m.Score,
+		); err != nil {
+			return nil, err
+		}
+		out = append(out, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if err := s.fillMoney(ctx, q, scorebookID, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func scanSettlementTransfer(row rowScanner) (SettlementTransfer, error) {
+	var t SettlementTransfer
+	err := row.Scan(
+		&t.ID,
+		&t.ScorebookID,
+		&t.FromMemberID,
+		&t.FromNickname,
+		&t.ToMemberID,
+		&t.ToNickname,
+		&t.Amount,
+		&t.PaidAt,
+		&t.PaidByMemberID,
+		&t.CreatedAt,
+	)
+	return t, err
+}
+
+type transfer struct {
+	FromMemberID string
+	ToMemberID   string
+	Cents        int64
+}
+
+// planTransfers turns net balances (in cents, summing to zero) into payer -> receiver
+// transfers. Debtors and creditors with exactly matching amounts are paired first, the
+// rest is settled by greedily matching the largest debtor with the largest creditor.
+// The result has at most len(balances)-1 transfers and is deterministic for the same input.
+func planTransfers(balances map[string]int64) []transfer {
+	type entry struct {
+		id    string
+		cents int64
+	}
+	var debtors, creditors []entry
+	for id, cents := range balances {
+		switch {
+		case cents < 0:
+			debtors = append(debtors, entry{id: id, cents: -cents})
+		case cents > 0:
+			creditors = append(creditors, entry{id: id, cents: cents})
+		}
+	}
+	byAmount := func(list []entry) func(i, j int) bool {
+		return func(i, j int) bool {
+			if list[i].cents != list[j].cents {
+				return list[i].cents > list[j].cents
+			}
+			return list[i].id < list[j].id
+		}
+	}
+	sort.Slice(debtors, byAmount(debtors))
+	sort.Slice(creditors, byAmount(creditors))
+
+	var out []transfer
+	for i := range debtors {
+		for j := range creditors {
+			if creditors[j].cents > 0 && creditors[j].cents == debtors[i].cents {
+				out = append(out, transfer{FromMemberID: debtors[i].id, ToMemberID: creditors[j].id, Cents: debtors[i].cents})
+				debtors[i].cents = 0
+				creditors[j].cents = 0
+				break
+			}
+		}
+	}
+	nonZero := func(list []entry) []entry {
+		out := list[:0]
+		for _, e := range list {
+			if e.cents > 0 {
+				out = append(out, e)
+			}
+		}
+		return out
+	}
+	debtors = nonZero(debtors)
+	creditors = nonZero(creditors)
+
+	i, j := 0, 0
+	for i < len(debtors) && j < len(creditors) {
+		amt := debtors[i].cents
+		if creditors[j].cents < amt {
+			amt = creditors[j].cents
+		}
+		out = append(out, transfer{FromMemberID: debtors[i].id, ToMemberID: creditors[j].id, Cents: amt})
+		debtors[i].cents -= amt
+		creditors[j].cents -= amt
+		if debtors[i].cents == 0 {
+			i++
+		}
+		if creditors[j].cents == 0 {
+			j++
+		}
+	}
+	return out
+}
@@ -0,0 +1,188 @@
+package store
+
+import (
+	"context"
+	"math"
+	"sort"
+)
+
+// ListGameResults returns the user's result in every ended scorebook they are still a
+// member of, oldest ended first, with the other bound members of each game.
+func (s *Store) ListGameResults(ctx context.Context, userID int64, filter GameResultFilter) ([]GameResult, error) {
+	rows, err := s.pool.Query(ctx, `
+SELECT
+  s.id::text,
+  s.name,
+  s.location_text,
+  s.start_time,
+  COALESCE(s.ended_at, s.updated_at),
+  m.id::text,
+  m.score::float8,
+  CASE WHEN m.score > 0 THEN 1 + (
+    SELECT COUNT(*)
+    FROM scorebook_members o
+    WHERE o.scorebook_id = s.id AND o.id <> m.id AND o.score > 0
+      AND (o.score > m.score OR (o.score = m.score AND o.updated_at < m.updated_at))
+  ) ELSE 0 END
+FROM scorebooks s
+JOIN scorebook_members m ON m.scorebook_id = s.id AND m.user_id = $1
+WHERE s.book_type = 'scorebook'
+  AND s.status = 'ended'
+  AND s.deleted_at IS NULL
+  AND ($2::timestamptz IS NULL OR s.start_time >= $2)
+  AND ($3::timestamptz IS NULL OR s.start_time < $3)
+  AND ($4 = '' OR strpos(lower(s.location_text), lower($4)) > 0)
+ORDER BY COALESCE(s.ended_at, s.updated_at) ASC, s.id ASC
+`, userID, filter.From, filter.To, filter.Location)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []GameResult
+	index := map[string]int{}
+	var ids []string
+	for rows.Next() {
+		var g GameResult
+		if err := rows.Scan(&g.ScorebookID, &g.Name, &g.LocationText, &g.StartTime, &g.EndedAt, &g.MemberID, &g.Score, &g.Rank); err != nil {
+			return nil, err
+		}
+		index[g.ScorebookID] = len(out)
+		ids = append(ids, g.ScorebookID)
+		out = append(out, g)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(ids) == 0 {
+		return out, nil
+	}
+
+	rows, err = s.pool.Query(ctx, `
+SELECT m.scorebook_id::text, m.user_id, m.nickname, m.avatar_url, m.score::float8
+FROM scorebook_members m
+WHERE m.scorebook_id = ANY($1::uuid[]) AND m.user_id IS NOT NULL AND m.user_id <> $2
+ORDER BY m.joined_at ASC
+`, ids, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var id string
+		var o GameOpponent
+		if err := rows.Scan(&id, &o.UserID, &o.Nickname, &o.AvatarURL, &o.Score); err != nil {
+			return nil, err
+		}
+		i := index[id]
+		out[i].Opponents = append(out[i].Opponents, o)
+	}
+	return out, rows.Err()
+}
+
+// SummarizeGames aggregates game results (oldest ended first, as ListGameResults
+// returns them) into player stats, keeping head-to-head records for the opponents
+// met most often (up to maxOpponents). Scores are summed in cents.
+func SummarizeGames(games []GameResult, maxOpponents int) PlayerStats {
+	var st PlayerStats
+	var net int64
+	type versus struct {
+		HeadToHead
+		mine, theirs int64
+	}
+	h2h := map[int64]*versus{}
+	streak := 0
+	var winStreak, lossStreak int
+	last := ""
+
+	for i := range games {
+		g := games[i]
+		myCents, _ := amountToCents(g.Score)
+		net += myCents
+		st.Games++
+
+		outcome := "draw"
+		switch {
+		case g.Rank == 1:
+			outcome = "win"
+			st.Wins++
+		case myCents < 0:
+			outcome = "loss"
+			st.Losses++
+		default:
+			st.Draws++
+		}
+		if outcome == last {
+			streak++
+		} else {
+			streak = 1
+			last = outcome
+		}
+		if outcome == "win" && streak > winStreak {
+			winStreak = streak
+		}
+		if outcome == "loss" && streak > lossStreak {
+			lossStreak = streak
+		}
+
+		if st.Best == nil || g.Score > st.Best.Score {
+			st.Best = &games[i]
+		}
+		if st.Worst == nil || g.Score < st.Worst.Score {
+			st.Worst = &games[i]
+		}
+
+		for _, o := range g.Opponents {
+			theirCents, _ := amountToCents(o.Score)
+			r := h2h[o.UserID]
+			if r == nil {
+				r = &versus{HeadToHead: HeadToHead{UserID: o.UserID}}
+				h2h[o.UserID] = r
+			}
+			// 昵称、头像取最近一局的
+			r.Nickname = o.Nickname
+			r.AvatarURL = o.AvatarURL
+			r.LastPlayedAt = g.EndedAt
+			r.Games++
+			switch {
+			case myCents > theirCents:
+				r.Wins++
+			case myCents < theirCents:
+				r.Losses++
+			default:
+				r.Draws++
+			}
+			r.mine += myCents
+			r.theirs += theirCents
+		}
+	}
+
+	st.NetScore = centsToAmount(net)
+	if st.Games > 0 {
+		st.WinRate = math.Round(float64(st.Wins)/float64(st.Games)*10000) / 10000
+	}
+	st.LongestWinStreak = winStreak
+	st.LongestLossStreak = lossStreak
+	st.CurrentStreak = streak
+	st.CurrentStreakOutcome = last
+
+	for _, r := range h2h {
+		r.NetScore = centsToAmount(r.mine)
+		r.OpponentNetScore = centsToAmount(r.theirs)
+		st.HeadToHead = append(st.HeadToHead, r.HeadToHead)
+	}
+	sort.Slice(st.HeadToHead, func(i, j int) bool {
+		a, b := st.HeadToHead[i], st.HeadToHead[j]
+		if a.Games != b.Games {
+			return a.Games > b.Games
+		}
+		if !a.LastPlayedAt.Equal(b.LastPlayedAt) {
+			return a.LastPlayedAt.After(b.LastPlayedAt)
+		}
+		return a.UserID < b.UserID
+	})
+	if len(st.HeadToHead) > maxOpponents {
+		st.HeadToHead = st.HeadToHead[:maxOpponents]
+	}
+	return st
+}
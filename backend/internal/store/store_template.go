@@ -0,0 +1,140 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+)
+
+const templateColumns = `id::text, user_id, name, game_type, stake_multiplier::float8, delta_presets::float8[], member_names, created_at, updated_at`
+
+func scanTemplate(row pgx.Row) (ScorebookTemplate, error) {
+	var t ScorebookTemplate
+	err := row.Scan(&t.ID, &t.UserID, &t.Name, &t.GameType, &t.StakeMultiplier, &t.DeltaPresets, &t.MemberNames, &t.CreatedAt, &t.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ScorebookTemplate{}, ErrNotFound
+		}
+		return ScorebookTemplate{}, err
+	}
+	if t.DeltaPresets == nil {
+		t.DeltaPresets = []float64{}
+	}
+	if t.MemberNames == nil {
+		t.MemberNames = []string{}
+	}
+	return t, nil
+}
+
+func (s *Store) CreateScorebookTemplate(ctx context.Context, userID int64, in ScorebookTemplateInput) (ScorebookTemplate, error) {
+	name := strings.TrimSpace(in.Name)
+	if name == "" {
+		return ScorebookTemplate{}, ErrInvalidArgument
+	}
+	if in.StakeMultiplier == 0 {
+		in.StakeMultiplier = 1
+	}
+	// 空列表写入 '{}' 而不是 NULL
+	if in.DeltaPresets == nil {
+		in.DeltaPresets = []float64{}
+	}
+	if in.MemberNames == nil {
+		in.MemberNames = []string{}
+	}
+	return scanTemplate(s.pool.QueryRow(ctx, `
+INSERT INTO scorebook_templates (user_id, name, game_type, stake_multiplier, delta_presets, member_names)
+VALUES ($1, $2, $3, $4, $5::float8[], $6::text[])
+RETURNING `+templateColumns+`
+`, userID, name, strings.TrimSpace(in.GameType), in.StakeMultiplier, in.DeltaPresets, in.MemberNames))
+}
+
+func (s *Store) ListScorebookTemplates(ctx context.Context, userID int64, limit, offset int32) ([]ScorebookTemplate, error) {
+	rows, err := s.pool.Query(ctx, `
+SELECT `+templateColumns+`
+FROM scorebook_templates
+WHERE user_id = $1
+ORDER BY updated_at DESC, id DESC
+LIMIT $2 OFFSET $3
+`, userID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []ScorebookTemplate
+	for rows.Next() {
+		t, err := scanTemplate(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, t)
+	}
+	return out, rows.Err()
+}
+
+func (s *Store) GetScorebookTemplate(ctx context.Context, userID int64, id string) (ScorebookTemplate, error) {
+	if !isUUID(id) {
+		return ScorebookTemplate{}, ErrNotFound
+	}
+	return scanTemplate(s.pool.QueryRow(ctx, `
+SELECT `+templateColumns+`
+FROM scorebook_templates
+WHERE id = $1::uuid AND user_id = $2
+`, id, userID))
+}
+
+func (s *Store) UpdateScorebookTemplate(ctx context.Context, userID int64, id string, in ScorebookTemplateUpdate) (ScorebookTemplate, error) {
+	if !isUUID(id) {
+		return ScorebookTemplate{}, ErrNotFound
+	}
+	if in.Name != nil && strings.TrimSpace(*in.Name) == "" {
+		return ScorebookTemplate{}, ErrInvalidArgument
+	}
+
+	var name, gameType *string
+	if in.Name != nil {
+		v := strings.TrimSpace(*in.Name)
+		name = &v
+	}
+	if in.GameType != nil {
+		v := strings.TrimSpace(*in.GameType)
+		gameType = &v
+	}
+	// nil 表示不修改；传入的空列表写入 '{}'
+	var presets []float64
+	if in.DeltaPresets != nil {
+		presets = append([]float64{}, *in.DeltaPresets...)
+	}
+	var members []string
+	if in.MemberNames != nil {
+		members = append([]string{}, *in.MemberNames...)
+	}
+	return scanTemplate(s.pool.QueryRow(ctx, `
+UPDATE scorebook_templates
+SET name = COALESCE($3, name),
+    game_type = COALESCE($4, game_type),
+    stake_multiplier = COALESCE($5, stake_multiplier),
+    delta_presets = COALESCE($6::float8[], delta_presets),
+    member_names = COALESCE($7::text[], member_names),
+    updated_at = NOW()
+WHERE id = $1::uuid AND user_id = $2
+RETURNING `+templateColumns+`
+`, id, userID, name, gameType, in.StakeMultiplier, presets, members))
+}
+
+// DeleteScorebookTemplate deletes a template; scorebooks created from it keep their settings.
+func (s *Store) DeleteScorebookTemplate(ctx context.Context, userID int64, id string) error {
+	if !isUUID(id) {
+		return ErrNotFound
+	}
+	tag, err := s.pool.Exec(ctx, `DELETE FROM scorebook_templates WHERE id = $1::uuid AND user_id = $2`, id, userID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
@@ -0,0 +1,124 @@
+package store
+
+import (
+	"context"
+	"time"
+)
+
+// ScoreTimeline returns every member of the scorebook and their cumulative scores
+// over time, bucketed as requested. Reversal records are included at the time they
+// were written, so a voided record shows up as a step back. Only members may read it.
+func (s *Store) ScoreTimeline(ctx context.Context, scorebookID string, userID int64, bucket TimelineBucket) ([]Member, []TimelinePoint, error) {
+	if !isUUID(scorebookID) {
+		return nil, nil, ErrNotFound
+	}
+	if _, _, err := s.memberRole(ctx, s.pool, scorebookID, userID); err != nil {
+		return nil, nil, err
+	}
+	if bucket.Records <= 0 && bucket.Interval <= 0 {
+		bucket.Records = 1
+	}
+	seconds := int64(bucket.Interval / time.Second)
+
+	rows, err := s.pool.Query(ctx, `
+SELECT `+memberColumns+`
+FROM scorebook_members
+WHERE scorebook_id = $1::uuid
+ORDER BY joined_at ASC, id ASC
+`, scorebookID)
+	if err != nil {
+		return nil, nil, err
+	}
+	var members []Member
+	for rows.Next() {
+		m, err := scanMember(rows)
+		if err != nil {
+			rows.Close()
+			return nil, nil, err
+		}
+		members = append(members, m)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	// 每条记录拆成收方 +delta、付方 -delta 两条变动，按成员开窗累加；
+	// 每个桶每个成员取桶内最后一次变动后的累计值。
+	rows, err = s.pool.Query(ctx, `
+WITH recs AS (
+  SELECT created_at, from_member_id, to_member_id, delta,
+         row_number() OVER (ORDER BY created_at, id) AS seq
+  FROM score_records
+  WHERE scorebook_id = $1::uuid
+),
+moves AS (
+  SELECT seq, created_at, to_member_id AS member_id, delta FROM recs
+  UNION ALL
+  SELECT seq, created_at, from_member_id, -delta FROM recs
+),
+running AS (
+  SELECT seq, created_at, member_id,
+         SUM(delta) OVER (PARTITION BY member_id ORDER BY seq) AS score,
+         CASE WHEN $2::int > 0 THEN (seq - 1) / GREATEST($2::int, 1)
+              ELSE floor(extract(epoch FROM created_at) / GREATEST($3::bigint, 1))::bigint END AS bucket
+  FROM moves
+),
+buckets AS (
+  SELECT bucket, MAX(seq) AS last_seq, MIN(created_at) AS first_at, MAX(created_at) AS last_at
+  FROM running
+  GROUP BY bucket
+)
+SELECT DISTINCT ON (r.bucket, r.member_id)
+  r.bucket, b.last_seq, b.first_at, b.last_at, r.member_id::text, r.score::float8
+FROM running r
+JOIN buckets b ON b.bucket = r.bucket
+ORDER BY r.bucket, r.member_id, r.seq DESC
+`, scorebookID, bucket.Records, seconds)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	current := map[string]float64{}
+	for _, m := range members {
+		current[m.ID] = 0
+	}
+	var points []TimelinePoint
+	var last int64 = -1
+	for rows.Next() {
+		var n int64
+		var p TimelinePoint
+		var memberID string
+		var score float64
+		if err := rows.Scan(&n, &p.Records, &p.StartAt, &p.EndAt, &memberID, &score); err != nil {
+			return nil, nil, err
+		}
+		if n != last || len(points) == 0 {
+			if len(points) > 0 {
+				points[len(points)-1].Scores = copyScores(current)
+			}
+			if bucket.Records <= 0 {
+				p.StartAt = time.Unix(n*seconds, 0).UTC()
+			}
+			points = append(points, p)
+			last = n
+		}
+		current[memberID] = score
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, err
+	}
+	if len(points) > 0 {
+		points[len(points)-1].Scores = copyScores(current)
+	}
+	return members, points, nil
+}
+
+func copyScores(in map[string]float64) map[string]float64 {
+	out := make(map[string]float64, len(in))
+	for k, v := range in {
+		out[k] = v
+	}
+	return out
+}
@@ -0,0 +1,235 @@
+package store
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+)
+
+const transferColumns = `t.id::text, t.scorebook_id::text, t.from_user_id, t.from_member_id::text, t.to_user_id, t.to_member_id::text, m.nickname, t.status, t.created_at, t.resolved_at`
+
+// bookOwnership returns the owner (created_by_user_id) of a non-deleted book of the
+// given type together with the caller's member id and role. It returns ErrNotFound
+// if the book does not exist or the caller is not a member bound to it.
+func (s *Store) bookOwnership(ctx context.Context, q rowQueryer, bookType, bookID string, userID int64, lock bool) (int64, string, string, error) {
+	query := `
+SELECT s.created_by_user_id, m.id::text, m.role::text
+FROM scorebooks s
+JOIN scorebook_members m ON m.scorebook_id = s.id AND m.user_id = $3
+WHERE s.id = $1::uuid AND s.book_type = $2 AND s.deleted_at IS NULL
+`
+	if lock {
+		query += "FOR UPDATE OF s\n"
+	}
+	var ownerID int64
+	var memberID, role string
+	if err := q.QueryRow(ctx, query, bookID, bookType, userID).Scan(&ownerID, &memberID, &role); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, "", "", ErrNotFound
+		}
+		return 0, "", "", err
+	}
+	return ownerID, memberID, role, nil
+}
+
+func (s *Store) getTransfer(ctx context.Context, q rowQueryer, id string) (OwnershipTransfer, error) {
+	var t OwnershipTransfer
+	err := q.QueryRow(ctx, `
+SELECT `+transferColumns+`
+FROM ownership_transfers t
+JOIN scorebook_members m ON m.id = t.to_member_id
+WHERE t.id = $1::uuid
+`, id).Scan(&t.ID, &t.ScorebookID, &t.FromUserID, &t.FromMemberID, &t.ToUserID, &t.ToMemberID, &t.ToNickname, &t.Status, &t.CreatedAt, &t.ResolvedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return OwnershipTransfer{}, ErrNotFound
+		}
+		return OwnershipTransfer{}, err
+	}
+	return t, nil
+}
+
+// RequestOwnershipTransfer nominates a member as the new owner of a scorebook or ledger.
+// Only the owner may do this; the nominee must be another member bound to a user and
+// not a spectator. A previous pending nomination is cancelled.
+func (s *Store) RequestOwnershipTransfer(ctx context.Context, bookType, bookID string, userID int64, toMemberID string) (OwnershipTransfer, error) {
+	if !isUUID(toMemberID) {
+		return OwnershipTransfer{}, ErrNotFound
+	}
+
+	tx, err := s.pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return OwnershipTransfer{}, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	ownerID, myMemberID, _, err := s.bookOwnership(ctx, tx, bookType, bookID, userID, true)
+	if err != nil {
+		return OwnershipTransfer{}, err
+	}
+	if ownerID != userID {
+		return OwnershipTransfer{}, ErrForbidden
+	}
+
+	var toUserID int64
+	var toRole string
+	err = tx.QueryRow(ctx, `
+SELECT user_id, role::text
+FROM scorebook_members
+WHERE scorebook_id = $1::uuid AND id = $2::uuid AND user_id IS NOT NULL
+`, bookID, toMemberID).Scan(&toUserID, &toRole)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return OwnershipTransfer{}, ErrNotFound
+		}
+		return OwnershipTransfer{}, err
+	}
+	if toMemberID == myMemberID || toRole == RoleSpectator {
+		return OwnershipTransfer{}, ErrInvalidArgument
+	}
+
+	if _, err := tx.Exec(ctx, `
+UPDATE ownership_transfers
+SET status = 'cancelled', resolved_at = NOW()
+WHERE scorebook_id = $1::uuid AND status = 'pending'
+`, bookID); err != nil {
+		return OwnershipTransfer{}, err
+	}
+
+	var id string
+	if err := tx.QueryRow(ctx, `
+INSERT INTO ownership_transfers (scorebook_id, from_user_id, from_member_id, to_user_id, to_member_id)
+VALUES ($1::uuid, $2, $3::uuid, $4, $5::uuid)
+RETURNING id::text
+`, bookID, userID, myMemberID, toUserID, toMemberID).Scan(&id); err != nil {
+		return OwnershipTransfer{}, err
+	}
+	t, err := s.getTransfer(ctx, tx, id)
+	if err != nil {
+		return OwnershipTransfer{}, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return OwnershipTransfer{}, err
+	}
+	return t, nil
+}
+
+// GetOwnershipTransfer returns the pending transfer of a book; any bound member may view it.
+func (s *Store) GetOwnershipTransfer(ctx context.Context, bookType, bookID string, userID int64) (OwnershipTransfer, error) {
+	if _, _, _, err := s.bookOwnership(ctx, s.pool, bookType, bookID, userID, false); err != nil {
+		return OwnershipTransfer{}, err
+	}
+	var id string
+	err := s.pool.QueryRow(ctx, `
+SELECT id::text FROM ownership_transfers WHERE scorebook_id = $1::uuid AND status = 'pending'
+`, bookID).Scan(&id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return OwnershipTransfer{}, ErrNotFound
+		}
+		return OwnershipTransfer{}, err
+	}
+	return s.getTransfer(ctx, s.pool, id)
+}
+
+// RespondOwnershipTransfer lets the nominee accept or decline the pending transfer.
+// On accept the book's created_by_user_id moves to the nominee. In a scorebook the two
+// member roles are swapped as well: the nominee becomes owner and the previous owner
+// takes the nominee's former role. A ledger's owner-role member is the counterparty of
+// every gift record (see ledgerOwnerMemberID), so ledger member roles are left alone.
+func (s *Store) RespondOwnershipTransfer(ctx context.Context, bookType, bookID string, userID int64, accept bool) (OwnershipTransfer, error) {
+	tx, err := s.pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return OwnershipTransfer{}, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	ownerID, myMemberID, myRole, err := s.bookOwnership(ctx, tx, bookType, bookID, userID, true)
+	if err != nil {
+		return OwnershipTransfer{}, err
+	}
+
+	var id, fromMemberID string
+	var fromUserID int64
+	err = tx.QueryRow(ctx, `
+SELECT id::text, from_user_id, from_member_id::text
+FROM ownership_transfers
+WHERE scorebook_id = $1::uuid AND status = 'pending' AND to_user_id = $2 AND to_member_id = $3::uuid
+FOR UPDATE
+`, bookID, userID, myMemberID).Scan(&id, &fromUserID, &fromMemberID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return OwnershipTransfer{}, ErrNotFound
+		}
+		return OwnershipTransfer{}, err
+	}
+
+	status := TransferDeclined
+	if accept {
+		if fromUserID != ownerID {
+			return OwnershipTransfer{}, ErrConflict
+		}
+		if bookType == "scorebook" {
+			tag, err := tx.Exec(ctx, `
+UPDATE scorebook_members
+SET role = $3, updated_at = NOW()
+WHERE id = $1::uuid AND user_id = $2
+`, fromMemberID, fromUserID, myRole)
+			if err != nil {
+				return OwnershipTransfer{}, err
+			}
+			if tag.RowsAffected() != 1 {
+				return OwnershipTransfer{}, ErrConflict
+			}
+			if _, err := tx.Exec(ctx, `
+UPDATE scorebook_members SET role = 'owner', updated_at = NOW() WHERE id = $1::uuid
+`, myMemberID); err != nil {
+				return OwnershipTransfer{}, err
+			}
+		}
+		if _, err := tx.Exec(ctx, `
+UPDATE scorebooks SET created_by_user_id = $2, updated_at = NOW() WHERE id = $1::uuid
+`, bookID, userID); err != nil {
+			return OwnershipTransfer{}, err
+		}
+		status = TransferAccepted
+	}
+
+	if _, err := tx.Exec(ctx, `
+UPDATE ownership_transfers SET status = $2, resolved_at = NOW() WHERE id = $1::uuid
+`, id, status); err != nil {
+		return OwnershipTransfer{}, err
+	}
+	t, err := s.getTransfer(ctx, tx, id)
+	if err != nil {
+		return OwnershipTransfer{}, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return OwnershipTransfer{}, err
+	}
+	return t, nil
+}
+
+// CancelOwnershipTransfer withdraws the pending nomination. Only the owner may cancel.
+func (s *Store) CancelOwnershipTransfer(ctx context.Context, bookType, bookID string, userID int64) error {
+	ownerID, _, _, err := s.bookOwnership(ctx, s.pool, bookType, bookID, userID, false)
+	if err != nil {
+		return err
+	}
+	if ownerID != userID {
+		return ErrForbidden
+	}
+	tag, err := s.pool.Exec(ctx, `
+UPDATE ownership_transfers
+SET status = 'cancelled', resolved_at = NOW()
+WHERE scorebook_id = $1::uuid AND status = 'pending'
+`, bookID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
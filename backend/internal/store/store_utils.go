@@ -25,7 +25,8 @@ func randomInviteCode(n int) string {
 	return b.String()
 }
 
-func normalizeAmount(v float64) (float64, bool) {
+// NormalizeAmount validates a positive amount with at most 2 decimals and rounds it to cents.
+func NormalizeAmount(v float64) (float64, bool) {
 	if v <= 0 || math.IsNaN(v) || math.IsInf(v, 0) {
 		return 0, false
 	}
@@ -35,3 +36,58 @@ func normalizeAmount(v float64) (float64, bool) {
 	}
 	return rounded, true
 }
+
+// amountToCents converts a (possibly negative) amount with at most 2 decimals to cents.
+func amountToCents(v float64) (int64, bool) {
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		return 0, false
+	}
+	cents := math.Round(v * 100)
+	if math.Abs(v*100-cents) > 1e-6 {
+		return 0, false
+	}
+	return int64(cents), true
+}
+
+func centsToAmount(cents int64) float64 {
+	return float64(cents) / 100
+}
+
+// DeltaAllowed reports whether delta may be recorded in a scorebook with the given
+// delta presets: any delta when there are no presets, otherwise one of them.
+func DeltaAllowed(presets []float64, delta float64) bool {
+	if len(presets) == 0 {
+		return true
+	}
+	want, ok := amountToCents(delta)
+	if !ok {
+		return false
+	}
+	for _, p := range presets {
+		if c, ok := amountToCents(p); ok && c == want {
+			return true
+		}
+	}
+	return false
+}
+
+// isUUID reports whether s is a canonical 8-4-4-4-12 hex UUID, so malformed ids can be
+// treated as not found instead of failing the ::uuid cast.
+func isUUID(s string) bool {
+	if len(s) != 36 {
+		return false
+	}
+	for i, r := range s {
+		switch i {
+		case 8, 13, 18, 23:
+			if r != '-' {
+				return false
+			}
+		default:
+			if !(r >= '0' && r <= '9' || r >= 'a' && r <= 'f' || r >= 'A' && r <= 'F') {
+				return false
+			}
+		}
+	}
+	return true
+}
@@ -0,0 +1,12 @@
+-- Void score records (compensating reversal entries)
+
+ALTER TABLE score_records
+  ADD COLUMN IF NOT EXISTS reverses_record_id UUID NULL REFERENCES score_records(id);
+
+ALTER TABLE score_records
+  ADD COLUMN IF NOT EXISTS voided_at TIMESTAMPTZ NULL;
+
+ALTER TABLE score_records
+  ADD COLUMN IF NOT EXISTS voided_by_member_id UUID NULL REFERENCES scorebook_members(id);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_records_reverses ON score_records(reverses_record_id) WHERE reverses_record_id IS NOT NULL;
@@ -0,0 +1,17 @@
+-- Multi-party rounds: one zero-sum hand settled among several members at once.
+-- The per-member deltas are stored as pairwise score_records linked by round_id.
+
+CREATE TABLE IF NOT EXISTS score_rounds (
+  id                   UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  scorebook_id         UUID NOT NULL REFERENCES scorebooks(id) ON DELETE CASCADE,
+  round_no             INTEGER NOT NULL,
+  created_by_member_id UUID NOT NULL REFERENCES scorebook_members(id),
+  note                 TEXT NOT NULL DEFAULT '',
+  created_at           TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  UNIQUE(scorebook_id, round_no)
+);
+
+ALTER TABLE score_records
+  ADD COLUMN IF NOT EXISTS round_id UUID NULL REFERENCES score_rounds(id) ON DELETE CASCADE;
+
+CREATE INDEX IF NOT EXISTS idx_records_round ON score_records(round_id) WHERE round_id IS NOT NULL;
@@ -0,0 +1,14 @@
+-- End-of-game settlement: minimal payer -> payee transfers, generated once a scorebook ends.
+
+CREATE TABLE IF NOT EXISTS scorebook_settlements (
+  id                UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  scorebook_id      UUID NOT NULL REFERENCES scorebooks(id) ON DELETE CASCADE,
+  from_member_id    UUID NOT NULL REFERENCES scorebook_members(id),
+  to_member_id      UUID NOT NULL REFERENCES scorebook_members(id),
+  amount            NUMERIC(12,2) NOT NULL CHECK (amount > 0),
+  paid_at           TIMESTAMPTZ NULL,
+  paid_by_member_id UUID NULL REFERENCES scorebook_members(id),
+  created_at        TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_settlements_scorebook ON scorebook_settlements(scorebook_id);
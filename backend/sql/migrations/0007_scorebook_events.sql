@@ -0,0 +1,14 @@
+-- Realtime event log: every hub broadcast gets a per-scorebook sequence number so
+-- reconnecting clients can replay what they missed (`/ws/scorebooks/:id?since=<seq>`).
+-- Only the most recent events are kept; older gaps require a full resync.
+
+ALTER TABLE scorebooks
+  ADD COLUMN IF NOT EXISTS event_seq BIGINT NOT NULL DEFAULT 0;
+
+CREATE TABLE IF NOT EXISTS scorebook_events (
+  scorebook_id UUID NOT NULL REFERENCES scorebooks(id) ON DELETE CASCADE,
+  seq          BIGINT NOT NULL,
+  payload      JSONB NOT NULL,
+  created_at   TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  PRIMARY KEY (scorebook_id, seq)
+);
@@ -0,0 +1,20 @@
+-- Login sessions: short-lived access tokens carry the session id (`sid`), and each
+-- session holds a rotating refresh token (only its SHA-256 hash is stored).
+-- Revoking a session invalidates its access tokens immediately.
+
+CREATE TABLE IF NOT EXISTS user_sessions (
+  id                  UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  user_id             BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  refresh_token_hash  TEXT NOT NULL UNIQUE,
+  -- 上一个刷新令牌，再次使用说明令牌泄露，整个会话作废
+  previous_token_hash TEXT NULL,
+  user_agent          TEXT NOT NULL DEFAULT '',
+  ip                  TEXT NOT NULL DEFAULT '',
+  created_at          TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  last_used_at        TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  expires_at          TIMESTAMPTZ NOT NULL,
+  revoked_at          TIMESTAMPTZ NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_sessions_user ON user_sessions(user_id, last_used_at DESC);
+CREATE INDEX IF NOT EXISTS idx_user_sessions_previous ON user_sessions(previous_token_hash) WHERE previous_token_hash IS NOT NULL;
@@ -0,0 +1,18 @@
+-- Scorebook member roles: admins may rename/end a book and remove members,
+-- spectators may watch (WebSocket) but not score. Removed users can be banned
+-- from rejoining via the invite code. A removed member who already has records
+-- is detached (user_id = NULL) instead of deleted so the records stay attributable.
+
+ALTER TABLE scorebook_members DROP CONSTRAINT IF EXISTS scorebook_members_role_check;
+ALTER TABLE scorebook_members
+  ADD CONSTRAINT scorebook_members_role_check CHECK (role IN ('owner', 'admin', 'member', 'spectator'));
+
+CREATE TABLE IF NOT EXISTS scorebook_bans (
+  scorebook_id      UUID NOT NULL REFERENCES scorebooks(id) ON DELETE CASCADE,
+  user_id           BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  -- 被封禁时的成员昵称，成员行可能已被删除
+  nickname          TEXT NOT NULL DEFAULT '',
+  banned_by_user_id BIGINT NULL REFERENCES users(id) ON DELETE SET NULL,
+  created_at        TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  PRIMARY KEY (scorebook_id, user_id)
+);
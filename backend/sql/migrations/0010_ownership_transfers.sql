@@ -0,0 +1,19 @@
+-- Ownership transfer for scorebooks and ledgers: the owner nominates a member,
+-- the nominee accepts or declines. On accept created_by_user_id and the two
+-- member roles are swapped in one transaction. At most one pending transfer per book.
+
+CREATE TABLE IF NOT EXISTS ownership_transfers (
+  id             UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  scorebook_id   UUID NOT NULL REFERENCES scorebooks(id) ON DELETE CASCADE,
+  from_user_id   BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  from_member_id UUID NOT NULL REFERENCES scorebook_members(id) ON DELETE CASCADE,
+  to_user_id     BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  to_member_id   UUID NOT NULL REFERENCES scorebook_members(id) ON DELETE CASCADE,
+  status         TEXT NOT NULL DEFAULT 'pending'
+                 CONSTRAINT ownership_transfers_status_check CHECK (status IN ('pending', 'accepted', 'declined', 'cancelled')),
+  created_at     TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  resolved_at    TIMESTAMPTZ NULL
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS ownership_transfers_pending_idx
+  ON ownership_transfers(scorebook_id) WHERE status = 'pending';
@@ -0,0 +1,26 @@
+-- Invite code lifecycle: owners/admins can regenerate a scorebook's invite code
+-- (the old code is kept in retired_invite_codes so lookups can say why it stopped
+-- working), and limit it by expiry time and number of uses. Every join through an
+-- invite code is recorded in invite_uses; uses are counted per code.
+
+ALTER TABLE scorebooks
+  ADD COLUMN IF NOT EXISTS invite_expires_at TIMESTAMPTZ NULL,
+  ADD COLUMN IF NOT EXISTS invite_max_uses INT NULL
+    CONSTRAINT scorebooks_invite_max_uses_check CHECK (invite_max_uses > 0);
+
+CREATE TABLE IF NOT EXISTS retired_invite_codes (
+  code         TEXT PRIMARY KEY,
+  scorebook_id UUID NOT NULL REFERENCES scorebooks(id) ON DELETE CASCADE,
+  retired_at   TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS invite_uses (
+  id           BIGSERIAL PRIMARY KEY,
+  scorebook_id UUID NOT NULL REFERENCES scorebooks(id) ON DELETE CASCADE,
+  invite_code  TEXT NOT NULL,
+  user_id      BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  member_id    UUID NULL REFERENCES scorebook_members(id) ON DELETE SET NULL,
+  created_at   TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS invite_uses_code_idx ON invite_uses(scorebook_id, invite_code);
@@ -0,0 +1,28 @@
+-- Join approval: when scorebooks.join_approval is on, joining a scorebook (by id or
+-- invite code) creates a pending join request instead of a member. Owners/admins
+-- approve or reject it; approval creates the member. A user has at most one pending
+-- request per scorebook.
+
+ALTER TABLE scorebooks
+  ADD COLUMN IF NOT EXISTS join_approval BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE TABLE IF NOT EXISTS scorebook_join_requests (
+  id                  UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  scorebook_id        UUID NOT NULL REFERENCES scorebooks(id) ON DELETE CASCADE,
+  user_id             BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  nickname            TEXT NOT NULL,
+  avatar_url          TEXT NOT NULL DEFAULT '',
+  role                TEXT NOT NULL DEFAULT 'member',
+  invite_code         TEXT NULL,
+  status              TEXT NOT NULL DEFAULT 'pending'
+    CONSTRAINT scorebook_join_requests_status_check CHECK (status IN ('pending', 'approved', 'rejected')),
+  member_id           UUID NULL REFERENCES scorebook_members(id) ON DELETE SET NULL,
+  resolved_by_user_id BIGINT NULL REFERENCES users(id) ON DELETE SET NULL,
+  created_at          TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  resolved_at         TIMESTAMPTZ NULL
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS scorebook_join_requests_pending_idx
+  ON scorebook_join_requests(scorebook_id, user_id) WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS scorebook_join_requests_book_idx
+  ON scorebook_join_requests(scorebook_id, created_at DESC);
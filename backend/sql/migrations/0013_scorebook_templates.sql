@@ -0,0 +1,28 @@
+-- Scorebook templates: a user's saved game presets (game type, base stake multiplier,
+-- quick delta presets and a default roster). Creating a scorebook from a template
+-- copies the settings onto the scorebook and adds the roster as placeholder members,
+-- i.e. members not bound to any user (unlike removed members, which also have no user).
+
+CREATE TABLE IF NOT EXISTS scorebook_templates (
+  id               UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  user_id          BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  name             TEXT NOT NULL,
+  game_type        TEXT NOT NULL DEFAULT '',
+  stake_multiplier NUMERIC(12, 2) NOT NULL DEFAULT 1
+    CONSTRAINT scorebook_templates_stake_multiplier_check CHECK (stake_multiplier > 0),
+  delta_presets    NUMERIC(12, 2)[] NOT NULL DEFAULT '{}',
+  member_names     TEXT[] NOT NULL DEFAULT '{}',
+  created_at       TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  updated_at       TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS scorebook_templates_user_idx ON scorebook_templates(user_id, updated_at DESC);
+
+ALTER TABLE scorebooks
+  ADD COLUMN IF NOT EXISTS template_id UUID NULL REFERENCES scorebook_templates(id) ON DELETE SET NULL,
+  ADD COLUMN IF NOT EXISTS game_type TEXT NOT NULL DEFAULT '',
+  ADD COLUMN IF NOT EXISTS stake_multiplier NUMERIC(12, 2) NOT NULL DEFAULT 1,
+  ADD COLUMN IF NOT EXISTS delta_presets NUMERIC(12, 2)[] NOT NULL DEFAULT '{}';
+
+ALTER TABLE scorebook_members
+  ADD COLUMN IF NOT EXISTS placeholder BOOLEAN NOT NULL DEFAULT FALSE;
@@ -0,0 +1,28 @@
+-- Point value & currency: how much one point is worth when a scorebook is settled in
+-- money. Every change appends a row to scorebook_point_rates (the audit trail); a score
+-- record is priced with the rate effective when it was created, and a voiding reversal
+-- with the rate of the record it reverses, so voids always cancel out in money too.
+
+ALTER TABLE scorebooks
+  ADD COLUMN IF NOT EXISTS point_value NUMERIC(12, 4) NOT NULL DEFAULT 1
+    CONSTRAINT scorebooks_point_value_check CHECK (point_value > 0),
+  ADD COLUMN IF NOT EXISTS currency TEXT NOT NULL DEFAULT 'CNY';
+
+CREATE TABLE IF NOT EXISTS scorebook_point_rates (
+  id                 UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  scorebook_id       UUID NOT NULL REFERENCES scorebooks(id) ON DELETE CASCADE,
+  point_value        NUMERIC(12, 4) NOT NULL CHECK (point_value > 0),
+  currency           TEXT NOT NULL,
+  -- NULL for the initial rate written when the scorebook was created
+  changed_by_user_id BIGINT NULL REFERENCES users(id) ON DELETE SET NULL,
+  effective_at       TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS scorebook_point_rates_scorebook_idx ON scorebook_point_rates(scorebook_id, effective_at);
+
+-- Existing scorebooks start with 1 point = 1 CNY from their start time.
+INSERT INTO scorebook_point_rates (scorebook_id, point_value, currency, effective_at)
+SELECT s.id, s.point_value, s.currency, s.start_time
+FROM scorebooks s
+WHERE s.book_type = 'scorebook'
+  AND NOT EXISTS (SELECT 1 FROM scorebook_point_rates pr WHERE pr.scorebook_id = s.id);
@@ -0,0 +1,22 @@
+-- In-app invitations: the owner/admin of a scorebook can invite users they have
+-- played with before (co-members of another scorebook), either when creating the
+-- scorebook or later. The invitee accepts without an invite code and skips join
+-- approval; joining by any other way also marks the pending invitation accepted.
+-- A user has at most one pending invitation per scorebook.
+
+CREATE TABLE IF NOT EXISTS scorebook_invitations (
+  id                 UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  scorebook_id       UUID NOT NULL REFERENCES scorebooks(id) ON DELETE CASCADE,
+  user_id            BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  invited_by_user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  status             TEXT NOT NULL DEFAULT 'pending'
+    CONSTRAINT scorebook_invitations_status_check CHECK (status IN ('pending', 'accepted', 'declined')),
+  member_id          UUID NULL REFERENCES scorebook_members(id) ON DELETE SET NULL,
+  created_at         TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  resolved_at        TIMESTAMPTZ NULL
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS scorebook_invitations_pending_idx
+  ON scorebook_invitations(scorebook_id, user_id) WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS scorebook_invitations_user_idx
+  ON scorebook_invitations(user_id, created_at DESC);
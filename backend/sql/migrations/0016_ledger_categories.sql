@@ -0,0 +1,18 @@
+-- Ledger categories: user-defined buckets (catering, venue, red envelopes, ...) per
+-- ledger. A ledger record (a score_records row of a ledger) may carry one category;
+-- deleting a category leaves its records uncategorized.
+
+CREATE TABLE IF NOT EXISTS ledger_categories (
+  id           UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  scorebook_id UUID NOT NULL REFERENCES scorebooks(id) ON DELETE CASCADE,
+  name         TEXT NOT NULL,
+  created_at   TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  updated_at   TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS ledger_categories_name_idx ON ledger_categories(scorebook_id, name);
+
+ALTER TABLE score_records
+  ADD COLUMN IF NOT EXISTS category_id UUID NULL REFERENCES ledger_categories(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS score_records_category_idx ON score_records(category_id) WHERE category_id IS NOT NULL;
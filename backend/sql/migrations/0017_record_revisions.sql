@@ -0,0 +1,19 @@
+-- Record revisions: the audit trail of edits to and deletions of ledger records.
+-- Each row keeps the record as it was before the change and, for edits, after it;
+-- record_id has no foreign key so the history outlives a deleted record.
+
+CREATE TABLE IF NOT EXISTS record_revisions (
+  id                 UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  scorebook_id       UUID NOT NULL REFERENCES scorebooks(id) ON DELETE CASCADE,
+  record_id          UUID NOT NULL,
+  action             TEXT NOT NULL
+    CONSTRAINT record_revisions_action_check CHECK (action IN ('update', 'delete')),
+  changed_by_user_id BIGINT NULL REFERENCES users(id) ON DELETE SET NULL,
+  -- {"memberId","type","amount","note","categoryId"}
+  before             JSONB NOT NULL,
+  after              JSONB NULL,
+  created_at         TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS record_revisions_scorebook_idx ON record_revisions(scorebook_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS record_revisions_record_idx ON record_revisions(record_id, created_at DESC);
@@ -0,0 +1,23 @@
+-- People: a user's own list of real people (人情往来). Ledger members (in ledgers
+-- the user owns) and birthday contacts can link to a person, so gifts received from
+-- and given to that person can be followed across ledgers. Deleting a person only
+-- removes the links.
+
+CREATE TABLE IF NOT EXISTS people (
+  id         UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  user_id    BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  name       TEXT NOT NULL,
+  note       TEXT NOT NULL DEFAULT '',
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS people_user_idx ON people(user_id, name);
+
+ALTER TABLE scorebook_members
+  ADD COLUMN IF NOT EXISTS person_id UUID NULL REFERENCES people(id) ON DELETE SET NULL;
+ALTER TABLE birthday_contacts
+  ADD COLUMN IF NOT EXISTS person_id UUID NULL REFERENCES people(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS scorebook_members_person_idx ON scorebook_members(person_id) WHERE person_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS birthday_contacts_person_idx ON birthday_contacts(person_id) WHERE person_id IS NOT NULL;
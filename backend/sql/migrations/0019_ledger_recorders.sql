@@ -0,0 +1,22 @@
+-- Ledger recorders: users who joined a ledger through its recorder invite code may add
+-- members and records (e.g. relatives taking gifts at the door); deleting, editing and
+-- ending stay with the owner. score_records.created_by_user_id keeps who entered each
+-- record (NULL for records entered before this migration).
+
+ALTER TABLE scorebooks
+  ADD COLUMN IF NOT EXISTS recorder_invite_code TEXT NULL;
+
+CREATE UNIQUE INDEX IF NOT EXISTS scorebooks_recorder_invite_code_idx
+  ON scorebooks(recorder_invite_code) WHERE recorder_invite_code IS NOT NULL;
+
+CREATE TABLE IF NOT EXISTS ledger_recorders (
+  scorebook_id UUID NOT NULL REFERENCES scorebooks(id) ON DELETE CASCADE,
+  user_id      BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  created_at   TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  PRIMARY KEY (scorebook_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS ledger_recorders_user_idx ON ledger_recorders(user_id);
+
+ALTER TABLE score_records
+  ADD COLUMN IF NOT EXISTS created_by_user_id BIGINT NULL REFERENCES users(id) ON DELETE SET NULL;
@@ -0,0 +1,18 @@
+-- Ledger owner member: a ledger's owner-role member is the counterparty of every gift
+-- record, so accepting an ownership transfer no longer moves the role. Ledgers that
+-- were transferred before this change get the role back on the member it started on
+-- (the sender of their first accepted transfer).
+
+WITH firsts AS (
+  SELECT DISTINCT ON (t.scorebook_id) t.scorebook_id, t.from_member_id
+  FROM ownership_transfers t
+  JOIN scorebooks s ON s.id = t.scorebook_id AND s.book_type = 'ledger'
+  WHERE t.status = 'accepted'
+  ORDER BY t.scorebook_id, t.resolved_at ASC, t.created_at ASC
+)
+UPDATE scorebook_members m
+SET role = CASE WHEN m.id = f.from_member_id THEN 'owner' ELSE 'member' END,
+    updated_at = NOW()
+FROM firsts f
+WHERE m.scorebook_id = f.scorebook_id
+  AND (m.id = f.from_member_id OR m.role = 'owner');
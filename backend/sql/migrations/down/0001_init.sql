@@ -0,0 +1,6 @@
+-- Rollback: init schema
+
+DROP TABLE IF EXISTS score_records;
+DROP TABLE IF EXISTS scorebook_members;
+DROP TABLE IF EXISTS scorebooks;
+DROP TABLE IF EXISTS users;
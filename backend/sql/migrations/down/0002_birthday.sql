@@ -0,0 +1,3 @@
+-- Rollback: birthday contacts
+
+DROP TABLE IF EXISTS birthday_contacts;
@@ -0,0 +1,4 @@
+-- Rollback: deposit book
+
+DROP TABLE IF EXISTS deposit_records;
+DROP TABLE IF EXISTS deposit_accounts;
@@ -0,0 +1,7 @@
+-- Rollback: void score records (reversal entries are kept as plain records so scores still add up)
+
+DROP INDEX IF EXISTS idx_records_reverses;
+
+ALTER TABLE score_records DROP COLUMN IF EXISTS voided_by_member_id;
+ALTER TABLE score_records DROP COLUMN IF EXISTS voided_at;
+ALTER TABLE score_records DROP COLUMN IF EXISTS reverses_record_id;
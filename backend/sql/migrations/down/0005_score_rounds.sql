@@ -0,0 +1,7 @@
+-- Rollback: multi-party rounds (round records are kept as plain records)
+
+DROP INDEX IF EXISTS idx_records_round;
+
+ALTER TABLE score_records DROP COLUMN IF EXISTS round_id;
+
+DROP TABLE IF EXISTS score_rounds;
@@ -0,0 +1,3 @@
+-- Rollback: end-of-game settlement
+
+DROP TABLE IF EXISTS scorebook_settlements;
@@ -0,0 +1,5 @@
+-- Rollback: realtime event log
+
+DROP TABLE IF EXISTS scorebook_events;
+
+ALTER TABLE scorebooks DROP COLUMN IF EXISTS event_seq;
@@ -0,0 +1,3 @@
+-- Rollback: login sessions
+
+DROP TABLE IF EXISTS user_sessions;
@@ -0,0 +1,9 @@
+-- Rollback: scorebook member roles and bans
+
+DROP TABLE IF EXISTS scorebook_bans;
+
+UPDATE scorebook_members SET role = 'member' WHERE role IN ('admin', 'spectator');
+
+ALTER TABLE scorebook_members DROP CONSTRAINT IF EXISTS scorebook_members_role_check;
+ALTER TABLE scorebook_members
+  ADD CONSTRAINT scorebook_members_role_check CHECK (role IN ('owner', 'member'));
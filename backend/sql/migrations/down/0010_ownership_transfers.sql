@@ -0,0 +1,3 @@
+-- Rollback: ownership transfers
+
+DROP TABLE IF EXISTS ownership_transfers;
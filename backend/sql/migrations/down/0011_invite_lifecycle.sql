@@ -0,0 +1,8 @@
+-- Rollback: invite code lifecycle
+
+DROP TABLE IF EXISTS invite_uses;
+DROP TABLE IF EXISTS retired_invite_codes;
+
+ALTER TABLE scorebooks
+  DROP COLUMN IF EXISTS invite_max_uses,
+  DROP COLUMN IF EXISTS invite_expires_at;
@@ -0,0 +1,6 @@
+-- Rollback: join approval
+
+DROP TABLE IF EXISTS scorebook_join_requests;
+
+ALTER TABLE scorebooks
+  DROP COLUMN IF EXISTS join_approval;
@@ -0,0 +1,12 @@
+-- Rollback: scorebook templates
+
+ALTER TABLE scorebook_members
+  DROP COLUMN IF EXISTS placeholder;
+
+ALTER TABLE scorebooks
+  DROP COLUMN IF EXISTS delta_presets,
+  DROP COLUMN IF EXISTS stake_multiplier,
+  DROP COLUMN IF EXISTS game_type,
+  DROP COLUMN IF EXISTS template_id;
+
+DROP TABLE IF EXISTS scorebook_templates;
@@ -0,0 +1,7 @@
+-- Rollback: point value & currency
+
+DROP TABLE IF EXISTS scorebook_point_rates;
+
+ALTER TABLE scorebooks
+  DROP COLUMN IF EXISTS currency,
+  DROP COLUMN IF EXISTS point_value;
@@ -0,0 +1,3 @@
+-- Rollback: in-app scorebook invitations
+
+DROP TABLE IF EXISTS scorebook_invitations;
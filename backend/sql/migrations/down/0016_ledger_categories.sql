@@ -0,0 +1,6 @@
+-- Rollback: ledger categories
+
+ALTER TABLE score_records
+  DROP COLUMN IF EXISTS category_id;
+
+DROP TABLE IF EXISTS ledger_categories;
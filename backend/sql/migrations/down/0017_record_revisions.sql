@@ -0,0 +1,3 @@
+-- Rollback: record revisions
+
+DROP TABLE IF EXISTS record_revisions;
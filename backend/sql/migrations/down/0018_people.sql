@@ -0,0 +1,8 @@
+-- Rollback: people
+
+ALTER TABLE birthday_contacts
+  DROP COLUMN IF EXISTS person_id;
+ALTER TABLE scorebook_members
+  DROP COLUMN IF EXISTS person_id;
+
+DROP TABLE IF EXISTS people;
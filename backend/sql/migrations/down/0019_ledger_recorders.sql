@@ -0,0 +1,10 @@
+-- Rollback: ledger recorders
+
+ALTER TABLE score_records
+  DROP COLUMN IF EXISTS created_by_user_id;
+
+DROP TABLE IF EXISTS ledger_recorders;
+
+DROP INDEX IF EXISTS scorebooks_recorder_invite_code_idx;
+ALTER TABLE scorebooks
+  DROP COLUMN IF EXISTS recorder_invite_code;
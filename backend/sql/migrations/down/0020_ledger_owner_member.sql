@@ -0,0 +1,3 @@
+-- Rollback: ledger owner member (data only; the restored roles are kept)
+
+SELECT 1;
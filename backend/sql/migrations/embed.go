@@ -0,0 +1,9 @@
+package migrations
+
+import "embed"
+
+// FS holds the versioned SQL migrations (NNNN_name.sql) and their rollback
+// scripts (down/NNNN_name.sql).
+//
+//go:embed *.sql down/*.sql
+var FS embed.FS
@@ -10,46 +10,138 @@ const API_BASE = normalizeBase(import.meta.env.VITE_SCOREHUB_API_BASE || 'https:
 const WS_BASE = normalizeBase(import.meta.env.VITE_SCOREHUB_WS_BASE || 'wss://wxapi.wcoder.com')
 const REQUEST_TIMEOUT_MS = 10_000
 
+type Session = { token: string; refreshToken?: string; user?: any }
+
 function getToken(): string {
   return (uni.getStorageSync('token') as string) || ''
 }
 
-async function request<T>(method: UniApp.RequestOptions['method'], path: string, data?: any): Promise<T> {
-  const token = getToken()
-  const res = await new Promise<UniApp.RequestSuccessCallbackResult>((resolve, reject) => {
+function saveSession(body: Session) {
+  uni.setStorageSync('token', body.token)
+  if (body.refreshToken) uni.setStorageSync('refreshToken', body.refreshToken)
+  if (body.user) uni.setStorageSync('user', body.user)
+}
+
+export function clearSession() {
+  uni.removeStorageSync('token')
+  uni.removeStorageSync('refreshToken')
+  uni.removeStorageSync('user')
+}
+
+function send(
+  method: UniApp.RequestOptions['method'],
+  path: string,
+  data: any,
+  token: string,
+  responseType?: 'arraybuffer',
+): Promise<UniApp.RequestSuccessCallbackResult> {
+  return new Promise((resolve, reject) => {
     uni.request({
       url: `${API_BASE}${path}`,
       method,
       data,
       timeout: REQUEST_TIMEOUT_MS,
       header: {
-        'Content-Type': 'application/json',
+        ...(responseType ? {} : { 'Content-Type': 'application/json' }),
         ...(token ? { Authorization: `Bearer ${token}` } : {}),
       },
+      ...(responseType ? { responseType } : {}),
       success: resolve,
       fail: reject,
     })
   })
+}
+
+let renewing: Promise<boolean> | null = null
 
+// 访问令牌失效（401）时续期：先用刷新令牌换新令牌，失败时在微信内静默重新登录，
+// 都不行就清除登录态，由页面提示重新登录。并发的请求共用同一次续期。
+function renewSession(): Promise<boolean> {
+  if (!renewing) {
+    renewing = doRenewSession().finally(() => {
+      renewing = null
+    })
+  }
+  return renewing
+}
+
+async function doRenewSession(): Promise<boolean> {
+  const refreshToken = (uni.getStorageSync('refreshToken') as string) || ''
+  if (refreshToken) {
+    try {
+      const res = await send('POST', '/auth/refresh', { refreshToken }, '')
+      const body = res.data as any
+      if (res.statusCode === 200 && body?.token) {
+        saveSession(body)
+        return true
+      }
+    } catch (e) {}
+  }
+
+  // #ifdef MP-WEIXIN
+  try {
+    const loginRes = await new Promise<UniApp.LoginRes>((resolve, reject) => {
+      uni.login({ success: resolve, fail: reject })
+    })
+    if (loginRes.code) {
+      const res = await send('POST', '/auth/wechat_login', { code: loginRes.code }, '')
+      const body = res.data as any
+      if (res.statusCode === 200 && body?.token) {
+        saveSession(body)
+        return true
+      }
+    }
+  } catch (e) {}
+  // #endif
+
+  clearSession()
+  return false
+}
+
+// authed 带上当前令牌发送请求；令牌失效时续期后重试一次。
+async function authed(
+  method: UniApp.RequestOptions['method'],
+  path: string,
+  data?: any,
+  responseType?: 'arraybuffer',
+): Promise<UniApp.RequestSuccessCallbackResult> {
+  const token = getToken()
+  const res = await send(method, path, data, token, responseType)
+  if (res.statusCode === 401 && token && !path.startsWith('/auth/') && (await renewSession())) {
+    return send(method, path, data, getToken(), responseType)
+  }
+  return res
+}
+
+async function request<T>(method: UniApp.RequestOptions['method'], path: string, data?: any): Promise<T> {
+  const res = await authed(method, path, data)
   const body = res.data as any
   if (body?.error) throw body.error as ApiError
   return body as T
 }
 
 export async function devLogin(openid: string, nickname: string, avatarUrl: string) {
-  const body = await request<{ token: string; user: any }>('POST', '/auth/dev_login', { openid, nickname, avatarUrl })
-  uni.setStorageSync('token', body.token)
-  uni.setStorageSync('user', body.user)
+  const body = await request<Session & { user: any }>('POST', '/auth/dev_login', { openid, nickname, avatarUrl })
+  saveSession(body)
   return body
 }
 
 export async function wechatLogin(code: string) {
-  const body = await request<{ token: string; user: any }>('POST', '/auth/wechat_login', { code })
-  uni.setStorageSync('token', body.token)
-  uni.setStorageSync('user', body.user)
+  const body = await request<Session & { user: any }>('POST', '/auth/wechat_login', { code })
+  saveSession(body)
   return body
 }
 
+// logout 注销当前会话（服务端失败也照常清除本地登录态）。
+export async function logout() {
+  if (getToken()) {
+    try {
+      await send('POST', '/auth/logout', undefined, getToken())
+    } catch (e) {}
+  }
+  clearSession()
+}
+
 export async function getMe() {
   return request<{ user: any }>('GET', '/me')
 }
@@ -85,7 +177,7 @@ export async function deleteScorebook(id: string) {
   return request<{ scorebook: any }>('DELETE', `/scorebooks/${id}`)
 }
 
-export async function joinScorebook(id: string, payload: { nickname?: string; avatarUrl?: string }) {
+export async function joinScorebook(id: string, payload: { nickname?: string; avatarUrl?: string; inviteCode?: string }) {
   return request<{ member: any }>('POST', `/scorebooks/${id}/join`, payload)
 }
 
@@ -111,18 +203,7 @@ export async function joinByInviteCode(code: string, payload: { nickname?: strin
 }
 
 export async function getInviteQRCode(scorebookId: string) {
-  const token = getToken()
-  const res = await new Promise<UniApp.RequestSuccessCallbackResult>((resolve, reject) => {
-    uni.request({
-      url: `${API_BASE}/scorebooks/${encodeURIComponent(scorebookId)}/invite_qrcode`,
-      method: 'GET',
-      timeout: REQUEST_TIMEOUT_MS,
-      header: token ? { Authorization: `Bearer ${token}` } : {},
-      responseType: 'arraybuffer',
-      success: resolve,
-      fail: reject,
-    })
-  })
+  const res = await authed('GET', `/scorebooks/${encodeURIComponent(scorebookId)}/invite_qrcode`, undefined, 'arraybuffer')
 
   if (res.statusCode !== 200) {
     try {
@@ -140,18 +221,7 @@ export async function getInviteQRCode(scorebookId: string) {
 }
 
 export async function getLedgerInviteQRCode(ledgerId: string) {
-  const token = getToken()
-  const res = await new Promise<UniApp.RequestSuccessCallbackResult>((resolve, reject) => {
-    uni.request({
-      url: `${API_BASE}/ledgers/${encodeURIComponent(ledgerId)}/invite_qrcode`,
-      method: 'GET',
-      timeout: REQUEST_TIMEOUT_MS,
-      header: token ? { Authorization: `Bearer ${token}` } : {},
-      responseType: 'arraybuffer',
-      success: resolve,
-      fail: reject,
-    })
-  })
+  const res = await authed('GET', `/ledgers/${encodeURIComponent(ledgerId)}/invite_qrcode`, undefined, 'arraybuffer')
 
   if (res.statusCode !== 200) {
     try {